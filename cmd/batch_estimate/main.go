@@ -0,0 +1,131 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gobuffalo/pop"
+	"github.com/namsral/flag"
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/batchestimate"
+	"github.com/transcom/mymove/pkg/route"
+)
+
+// This executable prices a CSV or JSONL file of moves through the rate engine and writes
+// the full cost breakdown of each move, including which discount was used and why any
+// move could not be priced.
+//
+// CSV input needs a header with the columns weight,origin_zip,destination_zip,move_date and
+// optionally sit_days and cos. JSONL input uses the same names as keys.
+//
+// Run using go run cmd/batch_estimate/main.go -input=moves.csv -output=estimates.csv -planner=here
+func main() {
+	config := flag.String("config-dir", "config", "The location of server config files")
+	env := flag.String("env", "development", "The environment to run in, configures the database, presenetly.")
+	debugLogging := flag.Bool("debug_logging", false, "log messages at the debug level.")
+	inputFile := flag.String("input", "", "CSV or JSONL file of moves to price.")
+	inputFormat := flag.String("input_format", "", "Format of the input, csv or jsonl. Defaults to the input file extension.")
+	outputFile := flag.String("output", "", "File to write the results to. Defaults to stdout.")
+	outputFormat := flag.String("output_format", "csv", "Format of the output, csv or jsonl.")
+	plannerName := flag.String("planner", "here", "Route planner used to find the distance of each move: here, bing or offline.")
+	mileageTable := flag.String("mileage_table", "", "CSV of source,destination,miles ZIP5 or ZIP3 distances used by the offline planner.")
+	roadFactor := flag.Float64("road_factor", 0, "Ratio of road to great-circle distance used by the offline planner for moves not in the mileage table. Calibrated from the table when 0.")
+
+	hereGeoEndpoint := flag.String("here_maps_geocode_endpoint", "", "URL for the HERE maps geocoder endpoint")
+	hereRouteEndpoint := flag.String("here_maps_routing_endpoint", "", "URL for the HERE maps routing endpoint")
+	hereAppID := flag.String("here_maps_app_id", "", "HERE maps App ID for this application")
+	hereAppCode := flag.String("here_maps_app_code", "", "HERE maps App API code")
+	bingMapsEndpoint := flag.String("bing_maps_endpoint", "", "URL for the Bing Maps Truck endpoint to use")
+	bingMapsKey := flag.String("bing_maps_key", "", "Authentication key to use for the Bing Maps endpoint")
+	flag.Parse()
+
+	var logger *zap.Logger
+	var err error
+	if *debugLogging {
+		logger, err = zap.NewDevelopment()
+	} else {
+		logger, err = zap.NewProduction()
+	}
+	if err != nil {
+		log.Fatalf("Failed to initialize Zap logging due to %v", err)
+	}
+	zap.ReplaceGlobals(logger)
+
+	if *inputFile == "" {
+		log.Fatal("An -input file is required")
+	}
+	if *inputFormat == "" {
+		*inputFormat = strings.TrimPrefix(strings.ToLower(filepath.Ext(*inputFile)), ".")
+	}
+
+	err = pop.AddLookupPaths(*config)
+	if err != nil {
+		log.Panic(err)
+	}
+	dbConnection, err := pop.Connect(*env)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	// The offline planner needs ZIP locations to estimate distances and calibrate its road factor
+	if err := route.LoadZip5Locations(dbConnection); err != nil {
+		log.Fatalf("Could not load ZIP locations: %v", err)
+	}
+
+	var planner route.Planner
+	switch *plannerName {
+	case "here":
+		planner = route.NewHEREPlanner(logger, hereGeoEndpoint, hereRouteEndpoint, hereAppID, hereAppCode, route.DefaultTruckProfile)
+	case "bing":
+		planner = route.NewBingPlanner(logger, bingMapsEndpoint, bingMapsKey, route.DefaultTruckProfile)
+	case "offline":
+		table := route.NewMileageTable()
+		if *mileageTable != "" {
+			table, err = route.LoadMileageTableFile(*mileageTable)
+			if err != nil {
+				log.Fatalf("Could not load mileage table: %v", err)
+			}
+		}
+		planner = route.NewMileageTablePlanner(logger, table, *roadFactor)
+	default:
+		log.Fatalf("Unknown planner %q", *plannerName)
+	}
+
+	input, err := os.Open(*inputFile)
+	if err != nil {
+		log.Fatalf("Could not open input: %v", err)
+	}
+	defer input.Close()
+	rows, err := batchestimate.ReadRows(input, *inputFormat)
+	if err != nil {
+		log.Fatalf("Could not read input: %v", err)
+	}
+
+	estimator := batchestimate.NewEstimator(dbConnection, logger, planner)
+	results := estimator.Run(rows)
+
+	var output io.Writer = os.Stdout
+	if *outputFile != "" {
+		file, err := os.Create(*outputFile)
+		if err != nil {
+			log.Fatalf("Could not create output: %v", err)
+		}
+		defer file.Close()
+		output = file
+	}
+	if err := batchestimate.WriteResults(output, *outputFormat, results); err != nil {
+		log.Fatalf("Could not write results: %v", err)
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	logger.Info("Batch estimate complete", zap.Int("rows", len(results)), zap.Int("failed", failed))
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"time"
+
+	"github.com/gobuffalo/pop"
+	"github.com/namsral/flag"
+
+	"github.com/transcom/mymove/pkg/tariffmap"
+)
+
+// This executable writes a GeoJSON FeatureCollection with a point at the centroid of each ZIP3,
+// grouped and colored by its rate area, region or service area, so that the tariff's ZIP3
+// assignments can be checked on a map, e.g. at geojson.io.
+//
+// Run using go run cmd/export_tariff_map/main.go -group_by=service_area -output=service_areas.geojson
+func main() {
+	config := flag.String("config-dir", "config", "The location of server config files")
+	env := flag.String("env", "development", "The environment to run in, configures the database, presenetly.")
+	groupBy := flag.String("group_by", string(tariffmap.GroupByRateArea), "Tariff area ZIP3s are grouped by: rate_area, region or service_area.")
+	dateString := flag.String("date", "", "Date, as YYYY-MM-DD, whose service area rates are included. Defaults to today.")
+	output := flag.String("output", "", "File to write the GeoJSON to. Defaults to stdout.")
+	flag.Parse()
+
+	date := time.Now()
+	if *dateString != "" {
+		var err error
+		date, err = time.Parse("2006-01-02", *dateString)
+		if err != nil {
+			log.Fatalf("Could not parse date %q: %v", *dateString, err)
+		}
+	}
+
+	err := pop.AddLookupPaths(*config)
+	if err != nil {
+		log.Panic(err)
+	}
+	dbConnection, err := pop.Connect(*env)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	collection, err := tariffmap.Fetch(dbConnection, tariffmap.GroupBy(*groupBy), date)
+	if err != nil {
+		log.Fatalf("Could not build tariff map: %v", err)
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		out, err = os.Create(*output)
+		if err != nil {
+			log.Fatalf("Could not create %s: %v", *output, err)
+		}
+		defer out.Close()
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(collection); err != nil {
+		log.Fatalf("Could not write tariff map: %v", err)
+	}
+}
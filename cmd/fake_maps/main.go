@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gobuffalo/pop"
+	"github.com/namsral/flag"
+
+	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/route"
+	"github.com/transcom/mymove/pkg/route/fakemaps"
+)
+
+// This executable serves stand-ins for the HERE and Bing maps APIs, so that the webserver can be
+// run end to end without the real services. Addresses are located by their ZIP, using either a
+// CSV in the free_zipcode_data format or the zip5_locations table.
+//
+// Point the webserver at it with -here_maps_geocode_endpoint=http://localhost:8090/here/geocode,
+// -here_maps_routing_endpoint=http://localhost:8090/here/route and
+// -bing_maps_endpoint=http://localhost:8090/REST/v1/Routes/Truck, along with any app ID, app code and key.
+//
+// Failures can be made to happen on every request, e.g. -fail=here_route=server_error,bing=empty_result
+//
+// Run using go run cmd/fake_maps/main.go -zips=pkg/route/testdata/zip5_locations.csv
+func main() {
+	config := flag.String("config-dir", "config", "The location of server config files")
+	env := flag.String("env", "development", "The environment to run in, which configures the database.")
+	zipsFile := flag.String("zips", "", "CSV file of ZIP5 locations. The zip5_locations table is used if it isn't given.")
+	port := flag.String("port", "8090", "the HTTP `port` to listen on.")
+	roadFactor := flag.Float64("road_factor", fakemaps.DefaultRoadFactor, "How much longer than the great circle distance routes are.")
+	failures := flag.String("fail", "", "Comma separated api=failure pairs. APIs are here_geocode, here_route and bing; failures are server_error, malformed_json and empty_result.")
+	flag.Parse()
+
+	var locations models.Zip5Locations
+	if *zipsFile != "" {
+		file, err := os.Open(*zipsFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		locations, err = route.ReadZip5Locations(file)
+		if err != nil {
+			log.Fatalf("Could not read %s: %v", *zipsFile, err)
+		}
+	} else {
+		err := pop.AddLookupPaths(*config)
+		if err != nil {
+			log.Fatal(err)
+		}
+		db, err := pop.Connect(*env)
+		if err != nil {
+			log.Fatal(err)
+		}
+		locations, err = models.FetchAllZip5Locations(db)
+		if err != nil {
+			log.Fatalf("Could not fetch ZIP5 locations: %v", err)
+		}
+	}
+
+	handler := fakemaps.NewHandler(locations)
+	handler.SetRoadFactor(*roadFactor)
+	for _, pair := range strings.Split(*failures, ",") {
+		if pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			log.Fatalf("Invalid failure %q, expected api=failure", pair)
+		}
+		handler.SetFailure(fakemaps.API(parts[0]), fakemaps.Failure(parts[1]))
+	}
+
+	fmt.Printf("Serving fake maps for %d ZIP5s on port %s\n", len(locations), *port)
+	log.Fatal(http.ListenAndServe(":"+*port, handler))
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gobuffalo/pop"
+	"github.com/namsral/flag"
+	"github.com/pkg/errors"
+
+	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/unit"
+)
+
+// This executable loads the weekly DOE diesel price index and the 400NG fuel surcharge
+// brackets from CSV files into the database.
+//
+// The prices file has the columns effective_date_lower,effective_date_upper,price_per_gallon
+// e.g. 2018-06-18,2018-06-25,3.271
+//
+// The brackets file has the columns effective_date_lower,effective_date_upper,price_lower,price_upper,surcharge_percent
+// e.g. 2018-05-15,2019-05-15,3.25,3.30,10.5
+//
+// Run using go run cmd/load_fuel_prices/main.go -prices=fuel_prices.csv -brackets=fuel_surcharge_brackets.csv
+func main() {
+	config := flag.String("config-dir", "config", "The location of server config files")
+	env := flag.String("env", "development", "The environment to run in, which configures the database.")
+	pricesFile := flag.String("prices", "", "CSV file of diesel prices per gallon and the dates they are effective.")
+	bracketsFile := flag.String("brackets", "", "CSV file of fuel surcharge brackets and the dates they are effective.")
+	flag.Parse()
+
+	if *pricesFile == "" && *bracketsFile == "" {
+		log.Fatal("At least one of -prices or -brackets must be provided")
+	}
+
+	err := pop.AddLookupPaths(*config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, err := pop.Connect(*env)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = db.Transaction(func(tx *pop.Connection) error {
+		if *pricesFile != "" {
+			indices, err := readFuelPriceIndices(*pricesFile)
+			if err != nil {
+				return err
+			}
+			for i := range indices {
+				verrs, err := tx.ValidateAndCreate(&indices[i])
+				if err != nil || verrs.HasAny() {
+					return errors.Errorf("could not save fuel price for %s: %v %v",
+						indices[i].EffectiveDateLower.Format("2006-01-02"), err, verrs)
+				}
+			}
+			fmt.Printf("Loaded %d fuel prices\n", len(indices))
+		}
+		if *bracketsFile != "" {
+			brackets, err := readFuelSurchargeBrackets(*bracketsFile)
+			if err != nil {
+				return err
+			}
+			for i := range brackets {
+				verrs, err := tx.ValidateAndCreate(&brackets[i])
+				if err != nil || verrs.HasAny() {
+					return errors.Errorf("could not save fuel surcharge bracket starting at %d millicents: %v %v",
+						brackets[i].PriceMillicentsLower, err, verrs)
+				}
+			}
+			fmt.Printf("Loaded %d fuel surcharge brackets\n", len(brackets))
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Failed to load fuel data: %v", err)
+	}
+}
+
+func readFuelPriceIndices(path string) (models.FuelPriceIndices, error) {
+	var indices models.FuelPriceIndices
+	err := readCSV(path, 3, func(record []string) error {
+		lower, upper, err := parseDates(record[0], record[1])
+		if err != nil {
+			return err
+		}
+		price, err := dollarsToMillicents(record[2])
+		if err != nil {
+			return err
+		}
+		indices = append(indices, models.FuelPriceIndex{
+			DieselPriceMillicents: price,
+			EffectiveDateLower:    lower,
+			EffectiveDateUpper:    upper,
+		})
+		return nil
+	})
+	return indices, err
+}
+
+func readFuelSurchargeBrackets(path string) (models.Tariff400ngFuelSurchargeBrackets, error) {
+	var brackets models.Tariff400ngFuelSurchargeBrackets
+	err := readCSV(path, 5, func(record []string) error {
+		lower, upper, err := parseDates(record[0], record[1])
+		if err != nil {
+			return err
+		}
+		priceLower, err := dollarsToMillicents(record[2])
+		if err != nil {
+			return err
+		}
+		priceUpper, err := dollarsToMillicents(record[3])
+		if err != nil {
+			return err
+		}
+		surcharge, err := percentToBasisPoints(record[4])
+		if err != nil {
+			return err
+		}
+		brackets = append(brackets, models.Tariff400ngFuelSurchargeBracket{
+			PriceMillicentsLower: priceLower,
+			PriceMillicentsUpper: priceUpper,
+			SurchargeBasisPoints: surcharge,
+			EffectiveDateLower:   lower,
+			EffectiveDateUpper:   upper,
+		})
+		return nil
+	})
+	return brackets, err
+}
+
+// readCSV calls handle for each record in the file, skipping a header row if present
+func readCSV(path string, fields int, handle func([]string) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrapf(err, "could not open %s", path)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = fields
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "could not read %s", path)
+		}
+		if line == 1 && strings.HasPrefix(strings.TrimSpace(record[0]), "effective_date") {
+			continue
+		}
+		if err := handle(record); err != nil {
+			return errors.Wrapf(err, "%s line %d", path, line)
+		}
+	}
+}
+
+func parseDates(lower string, upper string) (time.Time, time.Time, error) {
+	lowerDate, err := time.Parse("2006-01-02", strings.TrimSpace(lower))
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.Wrapf(err, "invalid date %q", lower)
+	}
+	upperDate, err := time.Parse("2006-01-02", strings.TrimSpace(upper))
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.Wrapf(err, "invalid date %q", upper)
+	}
+	return lowerDate, upperDate, nil
+}
+
+// dollarsToMillicents converts a price such as "3.271" into millicents without going
+// through a float, so that prices are stored exactly.
+func dollarsToMillicents(dollars string) (unit.Millicents, error) {
+	dollars = strings.TrimPrefix(strings.TrimSpace(dollars), "$")
+	parts := strings.SplitN(dollars, ".", 2)
+	whole, err := strconv.Atoi(parts[0])
+	if err != nil || whole < 0 {
+		return 0, errors.Errorf("invalid price %q", dollars)
+	}
+	fraction := 0
+	if len(parts) == 2 {
+		digits := parts[1]
+		if len(digits) > 5 {
+			return 0, errors.Errorf("price %q is more precise than a millicent", dollars)
+		}
+		digits += strings.Repeat("0", 5-len(digits))
+		fraction, err = strconv.Atoi(digits)
+		if err != nil {
+			return 0, errors.Errorf("invalid price %q", dollars)
+		}
+	}
+	return unit.Millicents(whole*100000 + fraction), nil
+}
+
+// percentToBasisPoints converts a percentage such as "10.5" into basis points without going
+// through a float, so that surcharges are stored exactly.
+func percentToBasisPoints(percent string) (unit.BasisPoints, error) {
+	percent = strings.TrimSuffix(strings.TrimSpace(percent), "%")
+	parts := strings.SplitN(percent, ".", 2)
+	whole, err := strconv.Atoi(parts[0])
+	if err != nil || whole < 0 {
+		return 0, errors.Errorf("invalid surcharge percent %q", percent)
+	}
+	fraction := 0
+	if len(parts) == 2 {
+		digits := parts[1]
+		if len(digits) > 2 {
+			return 0, errors.Errorf("surcharge percent %q is more precise than a basis point", percent)
+		}
+		digits += strings.Repeat("0", 2-len(digits))
+		fraction, err = strconv.Atoi(digits)
+		if err != nil {
+			return 0, errors.Errorf("invalid surcharge percent %q", percent)
+		}
+	}
+	return unit.BasisPoints(whole*100 + fraction), nil
+}
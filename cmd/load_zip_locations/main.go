@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/gobuffalo/pop"
+	"github.com/namsral/flag"
+
+	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/route"
+)
+
+// This executable adds or updates ZIP5 locations in the zip5_locations table from a CSV export
+// of https://github.com/midwire/free_zipcode_data, which has the columns
+// code,city,state,county,area_code,lat,lon
+// e.g. 94103,San Francisco,CA,San Francisco,415,37.775678,-122.412131
+//
+// ZIPs which are not in the file are left as they are. The webserver reads the table at
+// startup, so it needs to be restarted to pick up new ZIPs.
+//
+// Run using go run cmd/load_zip_locations/main.go -zips=us_zipcodes.csv
+func main() {
+	config := flag.String("config-dir", "config", "The location of server config files")
+	env := flag.String("env", "development", "The environment to run in, which configures the database.")
+	zipsFile := flag.String("zips", "", "CSV file of ZIP5 locations in the free_zipcode_data format.")
+	flag.Parse()
+
+	if *zipsFile == "" {
+		log.Fatal("A -zips file is required")
+	}
+
+	file, err := os.Open(*zipsFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+	locations, err := route.ReadZip5Locations(file)
+	if err != nil {
+		log.Fatalf("Could not read %s: %v", *zipsFile, err)
+	}
+
+	err = pop.AddLookupPaths(*config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, err := pop.Connect(*env)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = db.Transaction(func(tx *pop.Connection) error {
+		for _, location := range locations {
+			if err := models.SaveZip5Location(tx, location); err != nil {
+				return fmt.Errorf("ZIP5 %s: %v", location.Zip5, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Failed to load ZIP5 locations: %v", err)
+	}
+	fmt.Printf("Loaded %d ZIP5 locations\n", len(locations))
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/gobuffalo/pop"
+	"github.com/namsral/flag"
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/storage"
+	"github.com/transcom/mymove/pkg/uploader"
+)
+
+// This executable compares the files in storage with the uploads table, and reports files which
+// no upload refers to, uploads whose file is missing, uploads whose file has a different size
+// or checksum, and chunked uploads which expired unfinished. With -fix it also repairs them. It exits with status 1 if anything was found.
+//
+// Run using go run cmd/reconcile_uploads/main.go -storage_backend=s3 -aws_s3_bucket_name=... -fix
+func main() {
+	config := flag.String("config-dir", "config", "The location of server config files")
+	env := flag.String("env", "development", "The environment to run in, configures the database, presenetly.")
+	fix := flag.Bool("fix", false, "Delete orphaned files and uploads without files, and update uploads which don't match their files.")
+	verifyContent := flag.Bool("verify_content", false, "Fetch and checksum stored files whose size and checksum storage doesn't report, such as encrypted ones.")
+	minAge := flag.Duration("min_age", time.Hour, "Leave alone files and uploads changed more recently than this.")
+	storageBackend := flag.String("storage_backend", "filesystem", "Storage backend to use, either filesystem or s3.")
+	s3Bucket := flag.String("aws_s3_bucket_name", "", "S3 bucket used for file storage")
+	s3Region := flag.String("aws_s3_region", "", "AWS region used for S3 file storage")
+	s3KeyNamespace := flag.String("aws_s3_key_namespace", "", "Key prefix for all objects written to S3")
+	storageEncryptionKeys := flag.String("storage_encryption_keys", "", "Comma separated id:key master keys which stored files are encrypted with, as given to the server.")
+	flag.Parse()
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("Failed to initialize Zap logging due to %v", err)
+	}
+
+	storer, _, err := storage.New(storage.Config{
+		Backend:        *storageBackend,
+		S3Bucket:       *s3Bucket,
+		S3Region:       *s3Region,
+		S3KeyNamespace: *s3KeyNamespace,
+		EncryptionKeys: *storageEncryptionKeys,
+	}, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = pop.AddLookupPaths(*config)
+	if err != nil {
+		log.Panic(err)
+	}
+	dbConnection, err := pop.Connect(*env)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	up := uploader.NewUploader(dbConnection, logger, storer, nil)
+	report, err := up.Reconcile(*minAge, *verifyContent)
+	if err != nil {
+		log.Fatalf("Could not reconcile uploads: %v", err)
+	}
+
+	for _, object := range report.OrphanedObjects {
+		fmt.Printf("ORPHANED %s (%d bytes, last modified %s)\n", object.Key, object.Bytes, object.LastModified.Format(time.RFC3339))
+	}
+	for _, upload := range report.MissingObjects {
+		fmt.Printf("MISSING  upload %s of document %s\n", upload.ID, upload.DocumentID)
+	}
+	for _, mismatch := range report.ChecksumMismatches {
+		fmt.Printf("MISMATCH upload %s: %d bytes %s in the table, %d bytes %s in storage\n", mismatch.Upload.ID,
+			mismatch.Upload.Bytes, mismatch.Upload.Checksum, mismatch.Object.Bytes, mismatch.Object.Checksum)
+	}
+	for _, upload := range report.UnverifiedUploads {
+		fmt.Printf("UNVERIFIED upload %s: size and checksum of the stored file are unknown\n", upload.ID)
+	}
+	for _, session := range report.ExpiredUploadSessions {
+		fmt.Printf("EXPIRED  upload session %s with %d chunks\n", session.ID, session.Chunks)
+	}
+	fmt.Printf("Found %d orphaned files, %d missing files, %d checksum mismatches, %d unverified files, %d expired upload sessions\n",
+		len(report.OrphanedObjects), len(report.MissingObjects), len(report.ChecksumMismatches), len(report.UnverifiedUploads), len(report.ExpiredUploadSessions))
+
+	if !report.HasAny() {
+		return
+	}
+	if *fix {
+		if err := up.FixReconciled(report); err != nil {
+			log.Fatalf("Could not fix uploads: %v", err)
+		}
+		fmt.Println("Fixed")
+		return
+	}
+	os.Exit(1)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gobuffalo/pop"
+	"github.com/namsral/flag"
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/reestimate"
+	"github.com/transcom/mymove/pkg/route"
+	"github.com/transcom/mymove/pkg/unit"
+)
+
+// This executable recomputes the cached estimates of DRAFT and SUBMITTED PPMs after a
+// tariff load or a TSP performance discount refresh and lists the members whose estimate
+// moved by more than the threshold so that they can be notified.
+//
+// Run using go run cmd/reestimate_ppms/main.go -start=2018-05-15 -end=2018-10-01 -reason="2018 peak discounts"
+func main() {
+	config := flag.String("config-dir", "config", "The location of server config files")
+	env := flag.String("env", "development", "The environment to run in, configures the database, presenetly.")
+	debugLogging := flag.Bool("debug_logging", false, "log messages at the debug level.")
+	startDate := flag.String("start", "", "First planned move date to re-estimate, YYYY-MM-DD.")
+	endDate := flag.String("end", "", "Planned move date to stop re-estimating at (exclusive), YYYY-MM-DD.")
+	originZip3s := flag.String("origin_zip3s", "", "Comma separated list of origin ZIP3s to limit the run to.")
+	destinationZip3s := flag.String("destination_zip3s", "", "Comma separated list of destination ZIP3s to limit the run to.")
+	reason := flag.String("reason", "", "Reason recorded on the estimate audit records.")
+	threshold := flag.Int("threshold_cents", 10000, "Report members whose maximum incentive moved by more than this many cents.")
+	dryRun := flag.Bool("dry_run", false, "Compute and report changes without saving them.")
+
+	hereGeoEndpoint := flag.String("here_maps_geocode_endpoint", "", "URL for the HERE maps geocoder endpoint")
+	hereRouteEndpoint := flag.String("here_maps_routing_endpoint", "", "URL for the HERE maps routing endpoint")
+	hereAppID := flag.String("here_maps_app_id", "", "HERE maps App ID for this application")
+	hereAppCode := flag.String("here_maps_app_code", "", "HERE maps App API code")
+	flag.Parse()
+
+	var logger *zap.Logger
+	var err error
+	if *debugLogging {
+		logger, err = zap.NewDevelopment()
+	} else {
+		logger, err = zap.NewProduction()
+	}
+	if err != nil {
+		log.Fatalf("Failed to initialize Zap logging due to %v", err)
+	}
+	zap.ReplaceGlobals(logger)
+
+	start, err := time.Parse("2006-01-02", *startDate)
+	if err != nil {
+		log.Fatalf("Invalid start date %q: %v", *startDate, err)
+	}
+	end, err := time.Parse("2006-01-02", *endDate)
+	if err != nil {
+		log.Fatalf("Invalid end date %q: %v", *endDate, err)
+	}
+
+	err = pop.AddLookupPaths(*config)
+	if err != nil {
+		log.Panic(err)
+	}
+	dbConnection, err := pop.Connect(*env)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	planner := route.NewHEREPlanner(logger, hereGeoEndpoint, hereRouteEndpoint, hereAppID, hereAppCode, route.DefaultTruckProfile)
+	reestimator := reestimate.NewReestimator(dbConnection, logger, planner)
+
+	report, err := reestimator.Run(reestimate.Criteria{
+		MoveDateStart:    start,
+		MoveDateEnd:      end,
+		OriginZip3s:      splitList(*originZip3s),
+		DestinationZip3s: splitList(*destinationZip3s),
+		Reason:           *reason,
+		Threshold:        unit.Cents(*threshold),
+		DryRun:           *dryRun,
+	})
+	if err != nil {
+		log.Fatalf("Re-estimation failed: %+v", err)
+	}
+
+	fmt.Printf("Examined %d PPMs, updated %d, %d failed\n", report.Examined, report.Updated, len(report.Failures))
+	for _, failure := range report.Failures {
+		fmt.Printf("FAILED ppm=%s: %v\n", failure.PPMID, failure.Err)
+	}
+	fmt.Printf("%d members with an estimate change above %s:\n", len(report.Notable), unit.Cents(*threshold).ToDollarString())
+	for _, change := range report.Notable {
+		fmt.Printf("service_member=%s move=%s ppm=%s previous_max=%s new_max=%s\n",
+			change.ServiceMemberID,
+			change.MoveID,
+			change.PPMID,
+			change.PreviousEstimateMax.ToDollarString(),
+			change.IncentiveEstimateMax.ToDollarString())
+	}
+}
+
+func splitList(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/gobuffalo/pop"
+	"github.com/namsral/flag"
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/storage"
+	"github.com/transcom/mymove/pkg/uploader"
+)
+
+// This executable encrypts the files of uploads with the current master key: files stored before
+// encryption was enabled are encrypted, and files encrypted with an older master key have their
+// data keys rewrapped. Once it has run, older master keys can be removed from
+// storage_encryption_keys. Files made from uploads, cached combined PDFs and previews, are
+// rewrapped too.
+//
+// Run using go run cmd/rewrap_uploads/main.go -storage_encryption_keys=new:...,old:... -limit=1000
+func main() {
+	config := flag.String("config-dir", "config", "The location of server config files")
+	env := flag.String("env", "development", "The environment to run in, configures the database, presenetly.")
+	limit := flag.Int("limit", 1000, "Number of uploads to rewrap, oldest first.")
+	storageBackend := flag.String("storage_backend", "filesystem", "Storage backend to use, either filesystem or s3.")
+	s3Bucket := flag.String("aws_s3_bucket_name", "", "S3 bucket used for file storage")
+	s3Region := flag.String("aws_s3_region", "", "AWS region used for S3 file storage")
+	s3KeyNamespace := flag.String("aws_s3_key_namespace", "", "Key prefix for all objects written to S3")
+	storageEncryptionKeys := flag.String("storage_encryption_keys", "", "Comma separated id:key master keys which stored files are encrypted with, as given to the server. The first is current.")
+	flag.Parse()
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("Failed to initialize Zap logging due to %v", err)
+	}
+
+	storer, _, err := storage.New(storage.Config{
+		Backend:        *storageBackend,
+		S3Bucket:       *s3Bucket,
+		S3Region:       *s3Region,
+		S3KeyNamespace: *s3KeyNamespace,
+		EncryptionKeys: *storageEncryptionKeys,
+	}, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	encrypted, ok := storer.(*storage.Encrypted)
+	if !ok {
+		log.Fatal("storage_encryption_keys must be given")
+	}
+
+	err = pop.AddLookupPaths(*config)
+	if err != nil {
+		log.Panic(err)
+	}
+	dbConnection, err := pop.Connect(*env)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	uploads, err := models.FetchUploadsToRewrap(dbConnection, encrypted.CurrentKeyID(), *limit)
+	if err != nil {
+		log.Fatalf("Could not fetch uploads: %v", err)
+	}
+
+	up := uploader.NewUploader(dbConnection, logger, encrypted, nil)
+	failed := 0
+	for i := range uploads {
+		upload := &uploads[i]
+		if err := up.RewrapUpload(upload); err != nil {
+			fmt.Printf("FAILED %s: %v\n", upload.ID, err)
+			failed++
+		}
+	}
+	fmt.Printf("Rewrapped %d uploads with master key %s, %d failed\n", len(uploads)-failed, encrypted.CurrentKeyID(), failed)
+
+	objects, err := encrypted.List(encrypted.Key("documents") + "/")
+	if err != nil {
+		log.Fatalf("Could not list stored files: %v", err)
+	}
+	rewrapped := 0
+	failed = 0
+	for _, object := range objects {
+		if !strings.Contains(object.Key, "/combined/") && !strings.Contains(object.Key, "/previews/") {
+			continue
+		}
+		if _, err := encrypted.Rewrap(object.Key); err != nil {
+			fmt.Printf("FAILED %s: %v\n", object.Key, err)
+			failed++
+			continue
+		}
+		rewrapped++
+	}
+	fmt.Printf("Rewrapped %d combined PDFs and previews, %d failed\n", rewrapped, failed)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gobuffalo/pop"
+	"github.com/namsral/flag"
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/scanner"
+	"github.com/transcom/mymove/pkg/storage"
+	"github.com/transcom/mymove/pkg/uploader"
+)
+
+// This executable scans the files of uploads which are still PENDING, because clamd was
+// unavailable when they were uploaded or because the server was run without it. Clean
+// uploads can then be opened, and infected ones are moved to quarantine.
+//
+// Run using go run cmd/scan_uploads/main.go -clamd_address=tcp://localhost:3310 -limit=1000
+func main() {
+	config := flag.String("config-dir", "config", "The location of server config files")
+	env := flag.String("env", "development", "The environment to run in, configures the database, presenetly.")
+	limit := flag.Int("limit", 1000, "Number of pending uploads to scan, oldest first.")
+	clamdAddress := flag.String("clamd_address", "", "Address of the clamd that scans uploads for malware, e.g. unix:///var/run/clamav/clamd.ctl or tcp://localhost:3310.")
+	clamdTimeout := flag.Duration("clamd_timeout", 30*time.Second, "How long clamd may take to scan an upload.")
+	storageBackend := flag.String("storage_backend", "filesystem", "Storage backend to use, either filesystem or s3.")
+	s3Bucket := flag.String("aws_s3_bucket_name", "", "S3 bucket used for file storage")
+	s3Region := flag.String("aws_s3_region", "", "AWS region used for S3 file storage")
+	s3KeyNamespace := flag.String("aws_s3_key_namespace", "", "Key prefix for all objects written to S3")
+	storageEncryptionKeys := flag.String("storage_encryption_keys", "", "Comma separated id:key master keys which stored files are encrypted with, as given to the server.")
+	flag.Parse()
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("Failed to initialize Zap logging due to %v", err)
+	}
+
+	clamd, err := scanner.NewClamdScanner(*clamdAddress, *clamdTimeout)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	storer, _, err := storage.New(storage.Config{
+		Backend:        *storageBackend,
+		S3Bucket:       *s3Bucket,
+		S3Region:       *s3Region,
+		S3KeyNamespace: *s3KeyNamespace,
+		EncryptionKeys: *storageEncryptionKeys,
+	}, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = pop.AddLookupPaths(*config)
+	if err != nil {
+		log.Panic(err)
+	}
+	dbConnection, err := pop.Connect(*env)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	uploads, err := models.FetchPendingUploads(dbConnection, *limit)
+	if err != nil {
+		log.Fatalf("Could not fetch pending uploads: %v", err)
+	}
+
+	up := uploader.NewUploader(dbConnection, logger, storer, clamd)
+	counts := map[models.UploadScanStatus]int{}
+	for i := range uploads {
+		upload := &uploads[i]
+		if err := up.ScanUpload(upload); err != nil {
+			fmt.Printf("FAILED %s: %v\n", upload.ID, err)
+		}
+		counts[upload.ScanStatus]++
+	}
+	fmt.Printf("Scanned %d uploads: %d clean, %d infected, %d still pending\n", len(uploads),
+		counts[models.UploadScanStatusCLEAN], counts[models.UploadScanStatusINFECTED], counts[models.UploadScanStatusPENDING])
+}
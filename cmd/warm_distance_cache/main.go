@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/gobuffalo/pop"
+	"github.com/namsral/flag"
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/route"
+)
+
+// This executable pre-populates the planner_distances table with the transit distances
+// between the most common pairs of current and new duty station ZIPs, so that members
+// moving along those routes are priced without waiting on the route planner.
+//
+// Run using go run cmd/warm_distance_cache/main.go -limit=500 -planner=here
+func main() {
+	config := flag.String("config-dir", "config", "The location of server config files")
+	env := flag.String("env", "development", "The environment to run in, configures the database, presenetly.")
+	debugLogging := flag.Bool("debug_logging", false, "log messages at the debug level.")
+	limit := flag.Int("limit", 500, "Number of the most common duty station ZIP pairs to look up.")
+	plannerName := flag.String("planner", "here", "Route planner whose distances are cached: here or bing.")
+	ttl := flag.Duration("distance_cache_ttl", route.DefaultDistanceCacheTTL, "Cached distances older than this are looked up again.")
+
+	hereGeoEndpoint := flag.String("here_maps_geocode_endpoint", "", "URL for the HERE maps geocoder endpoint")
+	hereRouteEndpoint := flag.String("here_maps_routing_endpoint", "", "URL for the HERE maps routing endpoint")
+	hereAppID := flag.String("here_maps_app_id", "", "HERE maps App ID for this application")
+	hereAppCode := flag.String("here_maps_app_code", "", "HERE maps App API code")
+	bingMapsEndpoint := flag.String("bing_maps_endpoint", "", "URL for the Bing Maps Truck endpoint to use")
+	bingMapsKey := flag.String("bing_maps_key", "", "Authentication key to use for the Bing Maps endpoint")
+	truckType := flag.String("truck_type", route.DefaultTruckProfile.TruckType, "HERE truck type of the moving truck routes are planned for: truck or tractorTruck.")
+	truckHeight := flag.Float64("truck_height_meters", route.DefaultTruckProfile.HeightMeters, "Height in meters of the moving truck routes are planned for.")
+	truckWeight := flag.Int("truck_weight_kg", route.DefaultTruckProfile.WeightKilograms, "Loaded weight in kilograms of the moving truck routes are planned for.")
+	flag.Parse()
+
+	var logger *zap.Logger
+	var err error
+	if *debugLogging {
+		logger, err = zap.NewDevelopment()
+	} else {
+		logger, err = zap.NewProduction()
+	}
+	if err != nil {
+		log.Fatalf("Failed to initialize Zap logging due to %v", err)
+	}
+	zap.ReplaceGlobals(logger)
+
+	err = pop.AddLookupPaths(*config)
+	if err != nil {
+		log.Panic(err)
+	}
+	dbConnection, err := pop.Connect(*env)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	// Distances are cached per vehicle, so these should match the webserver's truck flags
+	truckProfile := route.VehicleProfile{
+		Type:            route.VehicleTruck,
+		TruckType:       *truckType,
+		HeightMeters:    *truckHeight,
+		WeightKilograms: *truckWeight,
+	}
+
+	var planner route.Planner
+	switch *plannerName {
+	case "here":
+		planner = route.NewHEREPlanner(logger, hereGeoEndpoint, hereRouteEndpoint, hereAppID, hereAppCode, truckProfile)
+	case "bing":
+		planner = route.NewBingPlanner(logger, bingMapsEndpoint, bingMapsKey, truckProfile)
+	default:
+		log.Fatalf("Unknown planner %q, expected here or bing", *plannerName)
+	}
+	planner = route.NewCachingPlanner(logger, dbConnection, planner, *plannerName, *ttl)
+
+	pairs, err := models.FetchCommonDutyStationPostalCodePairs(dbConnection, *limit)
+	if err != nil {
+		log.Fatalf("Could not fetch duty station pairs: %v", err)
+	}
+
+	failures := route.WarmDistanceCache(planner, pairs)
+	for pair, err := range failures {
+		fmt.Printf("FAILED %s -> %s: %v\n", pair.OriginPostalCode, pair.DestinationPostalCode, err)
+	}
+	fmt.Printf("Looked up %d duty station pairs, %d failed\n", len(pairs), len(failures))
+}
@@ -2,12 +2,11 @@ package main
 
 import (
 	"crypto/tls"
-	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"path"
-	"path/filepath"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	awssession "github.com/aws/aws-sdk-go/aws/session"
@@ -19,12 +18,16 @@ import (
 	"goji.io"
 	"goji.io/pat"
 
+	"github.com/transcom/mymove/pkg/addressvalidation"
 	"github.com/transcom/mymove/pkg/auth"
 	"github.com/transcom/mymove/pkg/auth/authentication"
 	"github.com/transcom/mymove/pkg/handlers"
 	"github.com/transcom/mymove/pkg/logging"
+	"github.com/transcom/mymove/pkg/models"
 	"github.com/transcom/mymove/pkg/route"
+	"github.com/transcom/mymove/pkg/scanner"
 	"github.com/transcom/mymove/pkg/storage"
+	"github.com/transcom/mymove/pkg/uploader"
 )
 
 var logger *zap.Logger
@@ -93,19 +96,34 @@ func main() {
 	loginGovOfficeClientID := flag.String("login_gov_office_client_id", "", "Client ID registered with login gov.")
 	loginGovHostname := flag.String("login_gov_hostname", "", "Hostname for communicating with login gov.")
 
-	/* For bing Maps use the following
-	bingMapsEndpoint := flag.String("bing_maps_endpoint", "", "URL for the Bing Maps Truck endpoint to use")
+	bingMapsEndpoint := flag.String("bing_maps_endpoint", "", "URL for the Bing Maps Truck endpoint to use. Bing is used when HERE is unavailable.")
 	bingMapsKey := flag.String("bing_maps_key", "", "Authentication key to use for the Bing Maps endpoint")
-	*/
 	hereGeoEndpoint := flag.String("here_maps_geocode_endpoint", "", "URL for the HERE maps geocoder endpoint")
 	hereRouteEndpoint := flag.String("here_maps_routing_endpoint", "", "URL for the HERE maps routing endpoint")
 	hereAppID := flag.String("here_maps_app_id", "", "HERE maps App ID for this application")
 	hereAppCode := flag.String("here_maps_app_code", "", "HERE maps App API code")
+	distanceCacheTTL := flag.Duration("distance_cache_ttl", route.DefaultDistanceCacheTTL, "How long a cached transit distance is used before asking the route planner again.")
+	mileageTable := flag.String("mileage_table", "", "CSV of source,destination,miles used by the offline planner. When set, remote distances are cross-checked against it.")
+	plannerFailureThreshold := flag.Int("planner_failure_threshold", route.DefaultCircuitFailureThreshold, "Consecutive failures after which a route planner is skipped.")
+	plannerCooldown := flag.Duration("planner_cooldown", route.DefaultCircuitCooldown, "How long a failing route planner is skipped before it is tried again.")
+	plannerTolerance := flag.Float64("planner_disagreement_tolerance", route.DefaultDisagreementTolerance, "Fraction by which route planner distances may differ before they are logged as disagreeing.")
+	truckType := flag.String("truck_type", route.DefaultTruckProfile.TruckType, "HERE truck type of the moving truck routes are planned for: truck or tractorTruck.")
+	truckHeight := flag.Float64("truck_height_meters", route.DefaultTruckProfile.HeightMeters, "Height in meters of the moving truck routes are planned for.")
+	truckWeight := flag.Int("truck_weight_kg", route.DefaultTruckProfile.WeightKilograms, "Loaded weight in kilograms of the moving truck routes are planned for.")
+	geocodeAddresses := flag.Bool("geocode_addresses", false, "Locate addresses with the HERE geocoder when they are validated.")
 	storageBackend := flag.String("storage_backend", "filesystem", "Storage backend to use, either filesystem or s3.")
 	s3Bucket := flag.String("aws_s3_bucket_name", "", "S3 bucket used for file storage")
 	s3Region := flag.String("aws_s3_region", "", "AWS region used for S3 file storage")
 	s3KeyNamespace := flag.String("aws_s3_key_namespace", "", "Key prefix for all objects written to S3")
+	storageURLExpiry := flag.Duration("storage_url_expiry", storage.DefaultURLExpiry, "How long a URL to a stored file gives access to it.")
+	storageEncryptionKeys := flag.String("storage_encryption_keys", "", "Comma separated id:key master keys, each 32 base64 encoded bytes, which stored files are encrypted with. The first is current; the rest are kept to read older files. Files aren't encrypted if unset.")
+	storageContentAddressed := flag.Bool("storage_content_addressed", false, "Store each uploaded file once under its SHA-256, however many uploads have the same content.")
+	storageURLSecret := flag.String("storage_url_secret", "", "Secret used to sign URLs to files in filesystem storage. Random if unset, which only works for a single server.")
 	awsSesRegion := flag.String("aws_ses_region", "", "AWS region used for SES")
+	clamdAddress := flag.String("clamd_address", "", "Address of the clamd that scans uploads for malware, e.g. unix:///var/run/clamav/clamd.ctl or tcp://localhost:3310.")
+	clamdTimeout := flag.Duration("clamd_timeout", 30*time.Second, "How long clamd may take to scan an upload before it is left pending.")
+	disableMalwareScanning := flag.Bool("disable_malware_scanning", false, "Treat every upload as clean without scanning it. Only for local development without clamd.")
+	previewInterval := flag.Duration("preview_interval", time.Minute, "How often to look for uploads needing previews, besides whenever one is created. Previews aren't made if 0.")
 
 	flag.Parse()
 
@@ -170,39 +188,99 @@ func main() {
 	// Serves files out of build folder
 	clientHandler := http.FileServer(http.Dir(*build))
 
-	// Get route planner for handlers to calculate transit distances
-	// routePlanner := route.NewBingPlanner(logger, bingMapsEndpoint, bingMapsKey)
-	routePlanner := route.NewHEREPlanner(logger, hereGeoEndpoint, hereRouteEndpoint, hereAppID, hereAppCode)
-	handlerContext.SetPlanner(routePlanner)
+	// ZIP locations are used by the route planners to find distances between ZIPs
+	if err := route.LoadZip5Locations(dbConnection); err != nil {
+		logger.Fatal("Loading ZIP locations", zap.Error(err))
+	}
+	// Addresses are checked against the city of their ZIP, which the migrations don't load
+	zipsWithoutCity, err := models.CountZip5LocationsWithoutCity(dbConnection)
+	if err != nil {
+		logger.Fatal("Checking ZIP locations", zap.Error(err))
+	}
+	if zipsWithoutCity > 0 {
+		logger.Warn("ZIP locations have no city, load them with cmd/load_zip_locations to check the cities of addresses",
+			zap.Int("zips_without_city", zipsWithoutCity))
+	}
 
-	var storer storage.FileStorer
-	if *storageBackend == "s3" {
-		zap.L().Info("Using s3 storage backend")
-		if len(*s3Bucket) == 0 {
-			log.Fatalln(errors.New("must provide aws_s3_bucket_name parameter, exiting"))
-		}
-		if *s3Region == "" {
-			log.Fatalln(errors.New("Must provide aws_s3_region parameter, exiting"))
-		}
-		if *s3KeyNamespace == "" {
-			log.Fatalln(errors.New("Must provide aws_s3_key_namespace parameter, exiting"))
-		}
-		aws := awssession.Must(awssession.NewSession(&aws.Config{
-			Region: s3Region,
-		}))
+	// Routes are planned for this truck unless a PPM is being moved by car
+	truckProfile := route.VehicleProfile{
+		Type:            route.VehicleTruck,
+		TruckType:       *truckType,
+		HeightMeters:    *truckHeight,
+		WeightKilograms: *truckWeight,
+	}
 
-		storer = storage.NewS3(*s3Bucket, *s3KeyNamespace, logger, aws)
-	} else {
-		zap.L().Info("Using filesystem storage backend")
-		absTmpPath, err := filepath.Abs("tmp")
+	// Get route planner for handlers to calculate transit distances. HERE is tried first, then
+	// Bing if it is configured, and finally the offline planner, which always has an answer.
+	providers := []route.PlannerProvider{{
+		Name:    "here",
+		Planner: route.NewCachingPlanner(logger, dbConnection, route.NewHEREPlanner(logger, hereGeoEndpoint, hereRouteEndpoint, hereAppID, hereAppCode, truckProfile), "here", *distanceCacheTTL),
+	}}
+	if *bingMapsEndpoint != "" {
+		providers = append(providers, route.PlannerProvider{
+			Name:    "bing",
+			Planner: route.NewCachingPlanner(logger, dbConnection, route.NewBingPlanner(logger, bingMapsEndpoint, bingMapsKey, truckProfile), "bing", *distanceCacheTTL),
+		})
+	}
+	table := route.NewMileageTable()
+	if *mileageTable != "" {
+		table, err = route.LoadMileageTableFile(*mileageTable)
 		if err != nil {
-			log.Fatalln(errors.New("could not get absolute path for tmp"))
+			logger.Fatal("Loading mileage table", zap.Error(err))
 		}
-		storagePath := path.Join(absTmpPath, "storage")
-		webRoot := "/" + "storage"
-		storer = storage.NewFilesystem(storagePath, webRoot, logger)
+	}
+	providers = append(providers, route.PlannerProvider{
+		Name:       "offline",
+		Planner:    route.NewMileageTablePlanner(logger, table, 0),
+		CrossCheck: *mileageTable != "",
+	})
+	routePlanner := route.NewFallbackPlanner(logger, providers, *plannerFailureThreshold, *plannerCooldown, *plannerTolerance)
+	handlerContext.SetPlanner(routePlanner)
+
+	// Addresses are checked against the ZIP locations, and optionally geocoded, before they are saved
+	var geocoder route.Geocoder
+	if *geocodeAddresses {
+		geocoder = route.NewHEREGeocoder(logger, hereGeoEndpoint, hereAppID, hereAppCode)
+	}
+	handlerContext.SetAddressValidator(addressvalidation.NewValidator(dbConnection, logger, geocoder))
+
+	storer, fileStorageHandler, err := storage.New(storage.Config{
+		Backend:        *storageBackend,
+		S3Bucket:       *s3Bucket,
+		S3Region:       *s3Region,
+		S3KeyNamespace: *s3KeyNamespace,
+		URLExpiry:      *storageURLExpiry,
+		URLSecret:      []byte(*storageURLSecret),
+		EncryptionKeys: *storageEncryptionKeys,
+	}, logger)
+	if err != nil {
+		log.Fatalln(err)
 	}
 	handlerContext.SetFileStorer(storer)
+	if *storageContentAddressed {
+		handlerContext.SetContentAddressedStorage()
+	}
+
+	if *clamdAddress != "" {
+		clamd, err := scanner.NewClamdScanner(*clamdAddress, *clamdTimeout)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		handlerContext.SetScanner(clamd)
+	} else if *disableMalwareScanning {
+		zap.L().Warn("Malware scanning is disabled, uploads will be treated as clean without being scanned")
+		handlerContext.SetScanner(scanner.NewDisabledScanner(logger))
+	} else {
+		// Fail safe: uploads stay pending, and can't be opened, until cmd/scan_uploads scans them
+		zap.L().Error("No clamd_address given, uploads will not be viewable until they are scanned")
+	}
+
+	// Previews of uploads are made in the background, including those scanned by cmd/scan_uploads
+	if *previewInterval > 0 {
+		previewWorker := uploader.NewPreviewWorker(dbConnection, logger, storer, *previewInterval)
+		go previewWorker.Run(nil)
+		handlerContext.SetPreviewWorker(previewWorker)
+	}
 
 	// Base routes
 	site := goji.NewMux()
@@ -260,10 +338,8 @@ func main() {
 		localAuthMux.Handle(pat.Post("/new"), authentication.NewCreateUserHandler(authContext, dbConnection, *clientAuthSecretKey, *noSessionTimeout))
 	}
 
-	if *storageBackend == "filesystem" {
-		// Add a file handler to provide access to files uploaded in development
-		fs := storage.NewFilesystemHandler("tmp")
-		root.Handle(pat.Get("/storage/*"), fs)
+	if fileStorageHandler != nil {
+		root.Handle(pat.Get("/storage/*"), fileStorageHandler)
 	}
 
 	root.Handle(pat.Get("/static/*"), clientHandler)
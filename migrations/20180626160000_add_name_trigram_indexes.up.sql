@@ -0,0 +1,6 @@
+-- Trigram indexes for ranking duty station and transportation office searches by similarity to the
+-- search string.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX duty_stations_name_trgm_idx ON duty_stations USING gin (name gin_trgm_ops);
+CREATE INDEX transportation_offices_name_trgm_idx ON transportation_offices USING gin (name gin_trgm_ops);
@@ -0,0 +1,11 @@
+-- The document access log is an audit trail, so its rows may be added but never changed or
+-- deleted. Documents and uploads may be deleted, so their IDs aren't foreign keys.
+CREATE FUNCTION document_accesses_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'document_accesses is append only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER document_accesses_append_only
+	BEFORE UPDATE OR DELETE ON document_accesses
+	FOR EACH ROW EXECUTE PROCEDURE document_accesses_append_only();
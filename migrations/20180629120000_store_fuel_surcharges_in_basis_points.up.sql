@@ -0,0 +1,6 @@
+-- Fuel surcharge percentages are published to a hundredth of a percent, so they are stored as
+-- integer basis points (10.5% is 1050) rather than as floats.
+ALTER TABLE tariff400ng_fuel_surcharge_brackets ADD COLUMN surcharge_basis_points integer;
+UPDATE tariff400ng_fuel_surcharge_brackets SET surcharge_basis_points = round(surcharge_percent * 100);
+ALTER TABLE tariff400ng_fuel_surcharge_brackets ALTER COLUMN surcharge_basis_points SET NOT NULL;
+ALTER TABLE tariff400ng_fuel_surcharge_brackets DROP COLUMN surcharge_percent;
@@ -0,0 +1,7 @@
+-- ZIP5 locations were loaded without a state. Each ZIP3 lies in one state in the 400NG tariff, so
+-- take the state from there until cmd/load_zip_locations replaces it along with the city and county.
+UPDATE zip5_locations
+	SET state = tariff400ng_zip3s.state, updated_at = now()
+	FROM tariff400ng_zip3s
+	WHERE zip5_locations.state = ''
+		AND tariff400ng_zip3s.zip3 = LEFT(zip5_locations.zip5, 3);
@@ -0,0 +1,179 @@
+// Package addressvalidation checks addresses entered by members before they are saved, so that a
+// mistyped ZIP or state is caught when it is entered rather than deep inside a later estimate.
+// Addresses are normalized in the style of USPS Publication 28, checked against the zip5_locations
+// table and, optionally, located with a geocoder.
+package addressvalidation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobuffalo/pop"
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/route"
+)
+
+// maxSuggestions is the largest number of suggested addresses returned for an address
+const maxSuggestions = 5
+
+// Issue is a problem with one field of an address. Field is the JSON name of the field, e.g. postal_code.
+type Issue struct {
+	Field   string
+	Message string
+}
+
+// Result is the outcome of validating an address
+type Result struct {
+	// Address is the normalized address
+	Address models.Address
+	// Issues are the problems which make the address invalid
+	Issues []Issue
+	// Warnings are problems which don't make the address invalid, such as the geocoder not finding it
+	Warnings []Issue
+	// Suggestions are corrected versions of the address, e.g. with the city and state of its ZIP
+	Suggestions []models.Address
+	// Location is where the geocoder located the address, if a geocoder is configured and found it
+	Location *route.LatLong
+}
+
+// Valid reports whether no issues were found with the address
+func (r Result) Valid() bool {
+	return len(r.Issues) == 0
+}
+
+func (r *Result) addIssue(field string, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, Issue{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *Result) addWarning(field string, format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, Issue{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// addSuggestion adds a copy of the address with the given city, state and ZIP, unless it is already suggested
+func (r *Result) addSuggestion(city string, state string, zip5 string) {
+	suggestion := r.Address
+	suggestion.City = normalizeCity(city)
+	suggestion.State = strings.ToUpper(state)
+	suggestion.PostalCode = zip5
+	if zip5 == postalCodeZip5(r.Address.PostalCode) {
+		// Keep the +4
+		suggestion.PostalCode = r.Address.PostalCode
+	}
+	for _, existing := range r.Suggestions {
+		if existing.City == suggestion.City && existing.State == suggestion.State && existing.PostalCode == suggestion.PostalCode {
+			return
+		}
+	}
+	if len(r.Suggestions) < maxSuggestions {
+		r.Suggestions = append(r.Suggestions, suggestion)
+	}
+}
+
+// postalCodeZip5 returns the ZIP5 part of a normalized postal code
+func postalCodeZip5(postalCode string) string {
+	if len(postalCode) < 5 {
+		return postalCode
+	}
+	return postalCode[:5]
+}
+
+// Validator normalizes and checks addresses
+type Validator struct {
+	db       *pop.Connection
+	logger   *zap.Logger
+	geocoder route.Geocoder
+}
+
+// NewValidator constructs and returns a Validator which checks addresses against the zip5_locations table.
+// If geocoder is not nil, addresses are also located with it.
+func NewValidator(db *pop.Connection, logger *zap.Logger, geocoder route.Geocoder) *Validator {
+	return &Validator{db: db, logger: logger, geocoder: geocoder}
+}
+
+// Validate normalizes address and checks that its ZIP exists and agrees with its state and city. The
+// error is only set when the check itself failed, e.g. because the database couldn't be reached.
+func (v *Validator) Validate(address models.Address) (Result, error) {
+	result := Result{Address: Normalize(address)}
+	normalized := &result.Address
+
+	if normalized.StreetAddress1 == "" {
+		result.addIssue("street_address_1", "Street address is required")
+	}
+	if normalized.City == "" {
+		result.addIssue("city", "City is required")
+	}
+	if !validStateCodes[normalized.State] {
+		result.addIssue("state", "%q is not a US state", address.State)
+	}
+
+	if len(normalized.PostalCode) != 5 && len(normalized.PostalCode) != 10 {
+		result.addIssue("postal_code", "%q is not a ZIP or ZIP+4", address.PostalCode)
+	} else if err := v.checkZip5(&result); err != nil {
+		return result, err
+	}
+
+	if v.geocoder != nil && result.Valid() {
+		location, err := v.geocoder.GeocodeAddress(normalized)
+		if err != nil {
+			v.logger.Info("Could not geocode address", zap.Object("address", normalized), zap.Error(err))
+			result.addWarning("street_address_1", "The address could not be located")
+		} else {
+			result.Location = &location
+		}
+	}
+	return result, nil
+}
+
+// checkZip5 checks that the ZIP of the result's address exists and, where its state is known, that it
+// matches the address. Otherwise it suggests the ZIP's city and state, or the ZIPs in the city. A city
+// other than the ZIP's is a warning.
+func (v *Validator) checkZip5(result *Result) error {
+	normalized := result.Address
+	zip5 := postalCodeZip5(normalized.PostalCode)
+
+	location, err := models.FetchZip5Location(v.db, zip5)
+	if err == models.ErrFetchNotFound {
+		result.addIssue("postal_code", "ZIP %s does not exist", zip5)
+		return v.suggestZip5s(result)
+	}
+	if err != nil {
+		return err
+	}
+
+	// ZIPs loaded without a city and state can only be checked for existence
+	if location.State != "" && validStateCodes[normalized.State] && !strings.EqualFold(location.State, normalized.State) {
+		result.addIssue("state", "ZIP %s is in %s, not %s", zip5, strings.ToUpper(location.State), normalized.State)
+	}
+	// USPS accepts other names for the cities of many ZIPs, e.g. Hollywood for 90028, so another city
+	// is only a warning, suggesting the ZIP's primary city
+	city := normalizeCity(location.City)
+	otherCity := city != "" && normalized.City != "" && city != normalized.City
+	if otherCity {
+		result.addWarning("city", "The city of ZIP %s is %s, not %s", zip5, city, normalized.City)
+	}
+	if (otherCity || !result.Valid()) && location.City != "" && location.State != "" {
+		result.addSuggestion(location.City, location.State, zip5)
+	}
+	if !result.Valid() && location.City != "" && location.State != "" {
+		// The ZIP may be the mistake rather than the city and state
+		return v.suggestZip5s(result)
+	}
+	return nil
+}
+
+// suggestZip5s suggests the address with each of the ZIPs in its city
+func (v *Validator) suggestZip5s(result *Result) error {
+	if result.Address.City == "" || result.Address.State == "" {
+		return nil
+	}
+	locations, err := models.FetchZip5LocationsByCity(v.db, result.Address.City, result.Address.State, maxSuggestions)
+	if err != nil {
+		return err
+	}
+	for _, location := range locations {
+		result.addSuggestion(location.City, location.State, location.Zip5)
+	}
+	return nil
+}
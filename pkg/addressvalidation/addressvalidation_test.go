@@ -0,0 +1,207 @@
+package addressvalidation
+
+import (
+	"log"
+	"testing"
+
+	"github.com/gobuffalo/pop"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/route"
+)
+
+type AddressValidationSuite struct {
+	suite.Suite
+	db     *pop.Connection
+	logger *zap.Logger
+}
+
+func (suite *AddressValidationSuite) SetupTest() {
+	suite.db.TruncateAll()
+	for _, location := range []models.Zip5Location{
+		{Zip5: "94103", City: "San Francisco", State: "CA", Latitude: 37.775678, Longitude: -122.412131},
+		{Zip5: "94107", City: "San Francisco", State: "CA", Latitude: 37.766529, Longitude: -122.39577},
+		{Zip5: "20301", City: "Washington", State: "DC", Latitude: 38.8933, Longitude: -77.0146},
+		// Loaded without a city or state
+		{Zip5: "32168", Latitude: 29.0238, Longitude: -81.0262},
+	} {
+		suite.Nil(models.SaveZip5Location(suite.db, location))
+	}
+}
+
+// stubGeocoder locates every address at the same place, or fails
+type stubGeocoder struct {
+	location route.LatLong
+	err      error
+	calls    int
+}
+
+func (g *stubGeocoder) GeocodeAddress(address *models.Address) (route.LatLong, error) {
+	g.calls++
+	return g.location, g.err
+}
+
+func (suite *AddressValidationSuite) issueFields(result Result) []string {
+	var fields []string
+	for _, issue := range result.Issues {
+		fields = append(fields, issue.Field)
+	}
+	return fields
+}
+
+func (suite *AddressValidationSuite) TestValidAddressIsNormalized() {
+	validator := NewValidator(suite.db, suite.logger, nil)
+
+	result, err := validator.Validate(models.Address{
+		StreetAddress1: "1 South Van Ness Avenue",
+		City:           "san francisco",
+		State:          "California",
+		PostalCode:     "94103-2426",
+	})
+	suite.Nil(err)
+	suite.True(result.Valid(), "unexpected issues: %v", result.Issues)
+	suite.Equal("1 S VAN NESS AVE", result.Address.StreetAddress1)
+	suite.Equal("SAN FRANCISCO", result.Address.City)
+	suite.Equal("CA", result.Address.State)
+	suite.Equal("94103-2426", result.Address.PostalCode)
+	suite.Empty(result.Suggestions)
+	suite.Nil(result.Location)
+}
+
+func (suite *AddressValidationSuite) TestMissingAndMalformedFields() {
+	validator := NewValidator(suite.db, suite.logger, nil)
+
+	result, err := validator.Validate(models.Address{City: " ", State: "Cascadia", PostalCode: "9410"})
+	suite.Nil(err)
+	suite.False(result.Valid())
+	suite.Equal([]string{"street_address_1", "city", "state", "postal_code"}, suite.issueFields(result))
+}
+
+func (suite *AddressValidationSuite) TestWrongCityAndStateForZip() {
+	validator := NewValidator(suite.db, suite.logger, nil)
+
+	result, err := validator.Validate(models.Address{
+		StreetAddress1: "1 Main St",
+		City:           "Washington",
+		State:          "DC",
+		PostalCode:     "94103",
+	})
+	suite.Nil(err)
+	suite.Equal([]string{"state"}, suite.issueFields(result))
+	suite.Equal("ZIP 94103 is in CA, not DC", result.Issues[0].Message)
+	if suite.Len(result.Warnings, 1) {
+		suite.Equal("city", result.Warnings[0].Field)
+	}
+
+	// Either the city and state of the ZIP, or the ZIP of the city and state
+	if suite.Len(result.Suggestions, 2) {
+		suite.Equal("SAN FRANCISCO", result.Suggestions[0].City)
+		suite.Equal("CA", result.Suggestions[0].State)
+		suite.Equal("94103", result.Suggestions[0].PostalCode)
+		suite.Equal("WASHINGTON", result.Suggestions[1].City)
+		suite.Equal("20301", result.Suggestions[1].PostalCode)
+		suite.Equal("1 MAIN ST", result.Suggestions[1].StreetAddress1)
+	}
+}
+
+func (suite *AddressValidationSuite) TestOtherCityForZip() {
+	validator := NewValidator(suite.db, suite.logger, nil)
+
+	// Like Hollywood for 90028, a name USPS accepts which isn't the ZIP's city
+	result, err := validator.Validate(models.Address{
+		StreetAddress1: "1 Main St",
+		City:           "Mission District",
+		State:          "CA",
+		PostalCode:     "94103",
+	})
+	suite.Nil(err)
+	suite.True(result.Valid())
+	if suite.Len(result.Warnings, 1) {
+		suite.Equal("city", result.Warnings[0].Field)
+		suite.Equal("The city of ZIP 94103 is SAN FRANCISCO, not MISSION DISTRICT", result.Warnings[0].Message)
+	}
+	if suite.Len(result.Suggestions, 1) {
+		suite.Equal("SAN FRANCISCO", result.Suggestions[0].City)
+		suite.Equal("94103", result.Suggestions[0].PostalCode)
+	}
+	suite.Equal("MISSION DISTRICT", result.Address.City)
+}
+
+func (suite *AddressValidationSuite) TestUnknownZip() {
+	validator := NewValidator(suite.db, suite.logger, nil)
+
+	result, err := validator.Validate(models.Address{
+		StreetAddress1: "1 Main St",
+		City:           "San Francisco",
+		State:          "CA",
+		PostalCode:     "94199",
+	})
+	suite.Nil(err)
+	suite.Equal([]string{"postal_code"}, suite.issueFields(result))
+	if suite.Len(result.Suggestions, 2) {
+		suite.Equal("94103", result.Suggestions[0].PostalCode)
+		suite.Equal("94107", result.Suggestions[1].PostalCode)
+	}
+}
+
+func (suite *AddressValidationSuite) TestZipWithoutCityOrState() {
+	validator := NewValidator(suite.db, suite.logger, nil)
+
+	result, err := validator.Validate(models.Address{
+		StreetAddress1: "1 Main St",
+		City:           "Anywhere",
+		State:          "FL",
+		PostalCode:     "32168",
+	})
+	suite.Nil(err)
+	suite.True(result.Valid())
+}
+
+func (suite *AddressValidationSuite) TestGeocoding() {
+	geocoder := &stubGeocoder{location: route.LatLong{Latitude: 37.7, Longitude: -122.4}}
+	validator := NewValidator(suite.db, suite.logger, geocoder)
+	address := models.Address{
+		StreetAddress1: "1 Main St",
+		City:           "San Francisco",
+		State:          "CA",
+		PostalCode:     "94107",
+	}
+
+	result, err := validator.Validate(address)
+	suite.Nil(err)
+	suite.True(result.Valid())
+	if suite.NotNil(result.Location) {
+		suite.Equal(geocoder.location, *result.Location)
+	}
+
+	// A geocoder failure is only a warning
+	geocoder.err = errors.New("no View in geocoder response")
+	result, err = validator.Validate(address)
+	suite.Nil(err)
+	suite.True(result.Valid())
+	suite.Nil(result.Location)
+	suite.Len(result.Warnings, 1)
+
+	// Invalid addresses aren't geocoded
+	address.PostalCode = "94199"
+	_, err = validator.Validate(address)
+	suite.Nil(err)
+	suite.Equal(2, geocoder.calls)
+}
+
+func TestAddressValidationSuite(t *testing.T) {
+	configLocation := "../../config"
+	pop.AddLookupPaths(configLocation)
+	db, err := pop.Connect("test")
+	if err != nil {
+		log.Panic(err)
+	}
+
+	logger, _ := zap.NewDevelopment()
+
+	hs := &AddressValidationSuite{db: db, logger: logger}
+	suite.Run(t, hs)
+}
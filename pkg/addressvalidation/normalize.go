@@ -0,0 +1,269 @@
+package addressvalidation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/transcom/mymove/pkg/models"
+)
+
+// stateCodes maps the name of each state, district and territory, and each military "state", to its
+// two letter USPS code
+var stateCodes = map[string]string{
+	"ALABAMA":                  "AL",
+	"ALASKA":                   "AK",
+	"AMERICAN SAMOA":           "AS",
+	"ARIZONA":                  "AZ",
+	"ARKANSAS":                 "AR",
+	"CALIFORNIA":               "CA",
+	"COLORADO":                 "CO",
+	"CONNECTICUT":              "CT",
+	"DELAWARE":                 "DE",
+	"DISTRICT OF COLUMBIA":     "DC",
+	"FLORIDA":                  "FL",
+	"GEORGIA":                  "GA",
+	"GUAM":                     "GU",
+	"HAWAII":                   "HI",
+	"IDAHO":                    "ID",
+	"ILLINOIS":                 "IL",
+	"INDIANA":                  "IN",
+	"IOWA":                     "IA",
+	"KANSAS":                   "KS",
+	"KENTUCKY":                 "KY",
+	"LOUISIANA":                "LA",
+	"MAINE":                    "ME",
+	"MARYLAND":                 "MD",
+	"MASSACHUSETTS":            "MA",
+	"MICHIGAN":                 "MI",
+	"MINNESOTA":                "MN",
+	"MISSISSIPPI":              "MS",
+	"MISSOURI":                 "MO",
+	"MONTANA":                  "MT",
+	"NEBRASKA":                 "NE",
+	"NEVADA":                   "NV",
+	"NEW HAMPSHIRE":            "NH",
+	"NEW JERSEY":               "NJ",
+	"NEW MEXICO":               "NM",
+	"NEW YORK":                 "NY",
+	"NORTH CAROLINA":           "NC",
+	"NORTH DAKOTA":             "ND",
+	"NORTHERN MARIANA ISLANDS": "MP",
+	"OHIO":                     "OH",
+	"OKLAHOMA":                 "OK",
+	"OREGON":                   "OR",
+	"PENNSYLVANIA":             "PA",
+	"PUERTO RICO":              "PR",
+	"RHODE ISLAND":             "RI",
+	"SOUTH CAROLINA":           "SC",
+	"SOUTH DAKOTA":             "SD",
+	"TENNESSEE":                "TN",
+	"TEXAS":                    "TX",
+	"UTAH":                     "UT",
+	"VERMONT":                  "VT",
+	"VIRGIN ISLANDS":           "VI",
+	"VIRGINIA":                 "VA",
+	"WASHINGTON":               "WA",
+	"WEST VIRGINIA":            "WV",
+	"WISCONSIN":                "WI",
+	"WYOMING":                  "WY",
+	"ARMED FORCES AMERICAS":    "AA",
+	"ARMED FORCES EUROPE":      "AE",
+	"ARMED FORCES PACIFIC":     "AP",
+	"WASHINGTON DC":            "DC",
+	"US VIRGIN ISLANDS":        "VI",
+}
+
+// validStateCodes holds every two letter code in stateCodes
+var validStateCodes = map[string]bool{}
+
+func init() {
+	for _, code := range stateCodes {
+		validStateCodes[code] = true
+	}
+}
+
+// streetSuffixes maps common street suffixes to their USPS Publication 28 (Appendix C1) abbreviations
+var streetSuffixes = map[string]string{
+	"ALLEY":      "ALY",
+	"AVENUE":     "AVE",
+	"AV":         "AVE",
+	"AVEN":       "AVE",
+	"BOULEVARD":  "BLVD",
+	"BOUL":       "BLVD",
+	"CIRCLE":     "CIR",
+	"COURT":      "CT",
+	"COVE":       "CV",
+	"CROSSING":   "XING",
+	"DRIVE":      "DR",
+	"EXPRESSWAY": "EXPY",
+	"FREEWAY":    "FWY",
+	"HIGHWAY":    "HWY",
+	"LANE":       "LN",
+	"LOOP":       "LOOP",
+	"PARKWAY":    "PKWY",
+	"PIKE":       "PIKE",
+	"PLACE":      "PL",
+	"PLAZA":      "PLZ",
+	"POINT":      "PT",
+	"ROAD":       "RD",
+	"ROUTE":      "RTE",
+	"SQUARE":     "SQ",
+	"STREET":     "ST",
+	"STR":        "ST",
+	"TERRACE":    "TER",
+	"TRAIL":      "TRL",
+	"TURNPIKE":   "TPKE",
+	"WAY":        "WAY",
+}
+
+// directionals maps compass directions, whether spelled out or already abbreviated, to their USPS
+// Publication 28 abbreviations
+var directionals = map[string]string{
+	"N":         "N",
+	"S":         "S",
+	"E":         "E",
+	"W":         "W",
+	"NE":        "NE",
+	"NW":        "NW",
+	"SE":        "SE",
+	"SW":        "SW",
+	"NORTH":     "N",
+	"SOUTH":     "S",
+	"EAST":      "E",
+	"WEST":      "W",
+	"NORTHEAST": "NE",
+	"NORTHWEST": "NW",
+	"SOUTHEAST": "SE",
+	"SOUTHWEST": "SW",
+}
+
+// unitDesignators maps secondary unit designators to their USPS Publication 28 (Appendix C2) abbreviations
+var unitDesignators = map[string]string{
+	"APARTMENT":  "APT",
+	"BUILDING":   "BLDG",
+	"DEPARTMENT": "DEPT",
+	"FLOOR":      "FL",
+	"HANGAR":     "HNGR",
+	"LOT":        "LOT",
+	"OFFICE":     "OFC",
+	"ROOM":       "RM",
+	"SPACE":      "SPC",
+	"SUITE":      "STE",
+	"TRAILER":    "TRLR",
+	"UNIT":       "UNIT",
+}
+
+// punctuation matches the characters Publication 28 leaves out of address lines. Hyphens, slashes and
+// the # unit designator are kept.
+var punctuation = regexp.MustCompile(`[,;:'"()]`)
+
+// nonDigits matches everything which isn't part of a ZIP or ZIP+4
+var nonDigits = regexp.MustCompile(`[^0-9]`)
+
+// words uppercases s, drops punctuation and splits it on whitespace. Periods are dropped without
+// splitting, so N.W. becomes NW.
+func words(s string) []string {
+	s = strings.Replace(s, ".", "", -1)
+	return strings.Fields(strings.ToUpper(punctuation.ReplaceAllString(s, " ")))
+}
+
+// normalizeStreet normalizes an address line. A unit designator followed by a unit number is
+// abbreviated, as are the pre and post directionals and the suffix of the street before it.
+func normalizeStreet(line string) string {
+	fields := words(line)
+
+	// The street ends where a secondary unit, e.g. APT 2, begins
+	end := len(fields)
+	for i := 0; i < len(fields)-1; i++ {
+		abbreviation, ok := unitDesignators[fields[i]]
+		if !ok && fields[i] != "#" {
+			continue
+		}
+		if ok {
+			fields[i] = abbreviation
+		}
+		if end == len(fields) {
+			end = i
+		}
+	}
+	if end == 0 {
+		return strings.Join(fields, " ")
+	}
+
+	// The street suffix is the last word of the street, or the word before a post directional
+	suffix := end - 1
+	if abbreviation, ok := directionals[fields[suffix]]; ok && suffix > 0 {
+		fields[suffix] = abbreviation
+		suffix--
+	}
+	// The street name follows the house number, if there is one
+	name := 0
+	if unicode.IsDigit(rune(fields[0][0])) {
+		name = 1
+	}
+	// A directional is only a pre directional if a street name follows it, e.g. not in N ST
+	if name < suffix-1 {
+		if abbreviation, ok := directionals[fields[name]]; ok {
+			fields[name] = abbreviation
+		}
+	}
+	if abbreviation, ok := streetSuffixes[fields[suffix]]; ok && suffix > name {
+		fields[suffix] = abbreviation
+	}
+	return strings.Join(fields, " ")
+}
+
+// normalizeCity uppercases a city and drops its punctuation, e.g. ST LOUIS
+func normalizeCity(city string) string {
+	return strings.Join(words(city), " ")
+}
+
+// normalizeOptionalStreet normalizes an optional address line, returning nil if it is blank
+func normalizeOptionalStreet(line *string) *string {
+	if line == nil {
+		return nil
+	}
+	normalized := normalizeStreet(*line)
+	if normalized == "" {
+		return nil
+	}
+	return &normalized
+}
+
+// normalizeState returns the two letter code for a state given by code or by name. Unknown states are
+// returned uppercased.
+func normalizeState(state string) string {
+	name := strings.Join(words(state), " ")
+	if code, ok := stateCodes[name]; ok {
+		return code
+	}
+	return name
+}
+
+// normalizePostalCode formats a ZIP or ZIP+4 as 12345 or 12345-6789. Anything else is returned trimmed.
+func normalizePostalCode(postalCode string) string {
+	digits := nonDigits.ReplaceAllString(postalCode, "")
+	switch len(digits) {
+	case 5:
+		return digits
+	case 9:
+		return fmt.Sprintf("%s-%s", digits[:5], digits[5:])
+	}
+	return strings.TrimSpace(postalCode)
+}
+
+// Normalize returns a copy of address formatted in the style of USPS Publication 28: uppercased, without
+// punctuation, with standard abbreviations for street suffixes, directionals, unit designators and states,
+// and with the postal code formatted as a ZIP or ZIP+4.
+func Normalize(address models.Address) models.Address {
+	normalized := address
+	normalized.StreetAddress1 = normalizeStreet(address.StreetAddress1)
+	normalized.StreetAddress2 = normalizeOptionalStreet(address.StreetAddress2)
+	normalized.StreetAddress3 = normalizeOptionalStreet(address.StreetAddress3)
+	normalized.City = normalizeCity(address.City)
+	normalized.State = normalizeState(address.State)
+	normalized.PostalCode = normalizePostalCode(address.PostalCode)
+	return normalized
+}
@@ -0,0 +1,107 @@
+package addressvalidation
+
+import (
+	"testing"
+
+	"github.com/transcom/mymove/pkg/models"
+)
+
+func TestNormalizeStreet(t *testing.T) {
+	lines := map[string]string{
+		"123 Main Street":                "123 MAIN ST",
+		"  123   north  main street  ":   "123 N MAIN ST",
+		"1600 Pennsylvania Avenue, N.W.": "1600 PENNSYLVANIA AVE NW",
+		"500 Ocean Boulevard South":      "500 OCEAN BLVD S",
+		"100 North Street":               "100 NORTH ST",
+		"10 Main St. Apartment 2":        "10 MAIN ST APT 2",
+		"10 Main Street Suite #200":      "10 MAIN ST STE #200",
+		"10 Main Street # 3":             "10 MAIN ST # 3",
+		"Apt. 2":                         "APT 2",
+		"Building 7, Floor 3":            "BLDG 7 FL 3",
+		"West Way":                       "WEST WAY",
+		"PO Box 1234":                    "PO BOX 1234",
+		"":                               "",
+		"123":                            "123",
+		"1 Northeast Lake Shore Drive":   "1 NE LAKE SHORE DR",
+		"2 Avenue of the Americas":       "2 AVENUE OF THE AMERICAS",
+		"Unit":                           "UNIT",
+		"12 Oak Court East Apartment 4":  "12 OAK CT E APT 4",
+		"Montmârtre":                     "MONTMÂRTRE",
+	}
+	for line, expected := range lines {
+		if normalized := normalizeStreet(line); normalized != expected {
+			t.Errorf("normalizing %q: expected %q, got %q", line, expected, normalized)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	line2 := "apartment 9000"
+	blank := "  "
+	address := models.Address{
+		StreetAddress1: "123 Main Avenue",
+		StreetAddress2: &line2,
+		StreetAddress3: &blank,
+		City:           "St. Louis",
+		State:          "missouri",
+		PostalCode:     "631011234",
+	}
+
+	normalized := Normalize(address)
+	if normalized.StreetAddress1 != "123 MAIN AVE" {
+		t.Errorf("wrong street address 1: %q", normalized.StreetAddress1)
+	}
+	if normalized.StreetAddress2 == nil || *normalized.StreetAddress2 != "APT 9000" {
+		t.Errorf("wrong street address 2: %v", normalized.StreetAddress2)
+	}
+	if normalized.StreetAddress3 != nil {
+		t.Errorf("expected a blank street address 3 to be removed, got %q", *normalized.StreetAddress3)
+	}
+	if normalized.City != "ST LOUIS" {
+		t.Errorf("wrong city: %q", normalized.City)
+	}
+	if normalized.State != "MO" {
+		t.Errorf("wrong state: %q", normalized.State)
+	}
+	if normalized.PostalCode != "63101-1234" {
+		t.Errorf("wrong postal code: %q", normalized.PostalCode)
+	}
+	// The original is left alone
+	if *address.StreetAddress2 != "apartment 9000" {
+		t.Errorf("original address was changed: %q", *address.StreetAddress2)
+	}
+}
+
+func TestNormalizeState(t *testing.T) {
+	states := map[string]string{
+		"CA":                   "CA",
+		"ca":                   "CA",
+		"California":           "CA",
+		"district of columbia": "DC",
+		"Washington, D.C.":     "DC",
+		"Armed Forces Europe":  "AE",
+		"Cascadia":             "CASCADIA",
+	}
+	for state, expected := range states {
+		if normalized := normalizeState(state); normalized != expected {
+			t.Errorf("normalizing %q: expected %q, got %q", state, expected, normalized)
+		}
+	}
+}
+
+func TestNormalizePostalCode(t *testing.T) {
+	postalCodes := map[string]string{
+		"94103":      "94103",
+		" 94103 ":    "94103",
+		"94103-1234": "94103-1234",
+		"94103 1234": "94103-1234",
+		"941031234":  "94103-1234",
+		"9410":       "9410",
+		"SW1A 1AA":   "SW1A 1AA",
+	}
+	for postalCode, expected := range postalCodes {
+		if normalized := normalizePostalCode(postalCode); normalized != expected {
+			t.Errorf("normalizing %q: expected %q, got %q", postalCode, expected, normalized)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Package batchestimate prices a list of hypothetical PPMs through the rate engine and
+// reports the full cost breakdown for each of them. Finance uses bulk runs like this for
+// budget projections and to check tariff and discount loads.
+package batchestimate
+
+import (
+	"time"
+
+	"github.com/gobuffalo/pop"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/rateengine"
+	"github.com/transcom/mymove/pkg/route"
+	"github.com/transcom/mymove/pkg/unit"
+)
+
+// Discount sources reported for each row
+const (
+	// DiscountSourceCOSD means the COS D discount was found for the move
+	DiscountSourceCOSD = "COS D"
+	// DiscountSourceCOS2Fallback means there was no COS D discount, so COS 2 was used
+	DiscountSourceCOS2Fallback = "COS 2 fallback"
+	// DiscountSourceRequested means the row asked for a specific Code of Service
+	DiscountSourceRequested = "requested"
+)
+
+// Row is a single move to be priced.
+type Row struct {
+	// Line is the line of the input the row was read from, starting at 1.
+	Line           int
+	Weight         unit.Pound
+	OriginZip      string
+	DestinationZip string
+	MoveDate       time.Time
+	SITDays        int
+	// CodeOfService, when set, is the only Code of Service whose discount will be used.
+	// Otherwise COS D is tried first, falling back to COS 2.
+	CodeOfService string
+	// Err records a problem reading the row, in which case it is not priced.
+	Err error
+}
+
+// Result is the outcome of pricing a Row.
+type Result struct {
+	Row
+	CodeOfService        string
+	DiscountSource       string
+	LinehaulDiscount     unit.DiscountRate
+	SITDiscount          unit.DiscountRate
+	Cost                 rateengine.CostComputation
+	IncentiveEstimateMin unit.Cents
+	IncentiveEstimateMax unit.Cents
+	// Err records why the row could not be priced.
+	Err error
+}
+
+// Estimator prices Rows using the rate engine.
+type Estimator struct {
+	db     *pop.Connection
+	logger *zap.Logger
+	engine *rateengine.RateEngine
+}
+
+// NewEstimator creates a new Estimator
+func NewEstimator(db *pop.Connection, logger *zap.Logger, planner route.Planner) *Estimator {
+	return &Estimator{db: db, logger: logger, engine: rateengine.NewRateEngine(db, logger, planner)}
+}
+
+// Run prices every row. A row that cannot be priced does not stop the run; its error
+// is recorded on its Result instead.
+func (e *Estimator) Run(rows []Row) []Result {
+	results := make([]Result, 0, len(rows))
+	for _, row := range rows {
+		result := Result{Row: row}
+		if row.Err != nil {
+			result.Err = row.Err
+		} else {
+			result.Err = e.estimate(&result)
+		}
+		if result.Err != nil {
+			e.logger.Info("Could not price row", zap.Int("line", row.Line), zap.Error(result.Err))
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func (e *Estimator) estimate(result *Result) error {
+	row := result.Row
+	if row.CodeOfService != "" {
+		lhDiscount, sitDiscount, err := models.FetchDiscountRates(e.db, row.OriginZip, row.DestinationZip, row.CodeOfService, row.MoveDate)
+		if err != nil {
+			return errors.Wrapf(err, "fetching discount rates for COS %s", row.CodeOfService)
+		}
+		result.CodeOfService = row.CodeOfService
+		result.DiscountSource = DiscountSourceRequested
+		result.LinehaulDiscount = lhDiscount
+		result.SITDiscount = sitDiscount
+	} else {
+		discount, err := rateengine.FetchPPMDiscount(e.db, e.logger, row.OriginZip, row.DestinationZip, row.MoveDate)
+		if err != nil {
+			return errors.Wrap(err, "fetching discount rates for COS D or 2")
+		}
+		result.CodeOfService = discount.CodeOfService
+		result.DiscountSource = DiscountSourceCOSD
+		if discount.CodeOfService == "2" {
+			result.DiscountSource = DiscountSourceCOS2Fallback
+		}
+		result.LinehaulDiscount = discount.LinehaulDiscount
+		result.SITDiscount = discount.SITDiscount
+	}
+
+	cost, err := e.engine.ComputePPM(row.Weight,
+		row.OriginZip,
+		row.DestinationZip,
+		row.MoveDate,
+		row.SITDays,
+		result.LinehaulDiscount,
+		result.SITDiscount)
+	if err != nil {
+		return errors.Wrap(err, "computing PPM")
+	}
+
+	result.Cost = cost
+	result.IncentiveEstimateMin = cost.GCC.MultiplyRate(unit.NewRate(95, 100), unit.RoundHalfUp)
+	result.IncentiveEstimateMax = cost.GCC.MultiplyRate(unit.NewRate(105, 100), unit.RoundHalfUp)
+	return nil
+}
@@ -0,0 +1,122 @@
+package batchestimate
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/gobuffalo/pop"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/route"
+	"github.com/transcom/mymove/pkg/testdatagen/scenario"
+	"github.com/transcom/mymove/pkg/unit"
+)
+
+var scenario1MoveDate = time.Date(2018, time.June, 18, 0, 0, 0, 0, time.UTC)
+
+func scenario1Row(line int, cos string) Row {
+	return Row{
+		Line:           line,
+		Weight:         unit.Pound(4000),
+		OriginZip:      "32168",
+		DestinationZip: "29429",
+		MoveDate:       scenario1MoveDate,
+		CodeOfService:  cos,
+	}
+}
+
+func (suite *BatchEstimateSuite) Test_RunReportsDiscountSource() {
+	if err := scenario.RunRateEngineScenario1(suite.db); err != nil {
+		suite.FailNow("failed to run scenario 1: %+v", err)
+	}
+
+	rows := []Row{
+		scenario1Row(1, ""),
+		scenario1Row(2, "2"),
+		scenario1Row(3, "D"),
+		{Line: 4, Err: errors.New("invalid weight")},
+	}
+
+	estimator := NewEstimator(suite.db, suite.logger, suite.planner)
+	results := estimator.Run(rows)
+	if !suite.Len(results, 4) {
+		return
+	}
+
+	// Scenario 1 only has COS 2 discounts
+	suite.Nil(results[0].Err)
+	suite.Equal("2", results[0].CodeOfService)
+	suite.Equal(DiscountSourceCOS2Fallback, results[0].DiscountSource)
+	suite.Equal(unit.DiscountRate(0.67), results[0].LinehaulDiscount)
+	suite.Equal(unit.Cents(263300), results[0].Cost.GCC)
+	suite.Equal(362, results[0].Cost.Mileage)
+	suite.Equal(unit.Cents(276465), results[0].IncentiveEstimateMax)
+
+	suite.Nil(results[1].Err)
+	suite.Equal(DiscountSourceRequested, results[1].DiscountSource)
+	suite.Equal(unit.Cents(263300), results[1].Cost.GCC)
+
+	suite.NotNil(results[2].Err)
+	suite.NotNil(results[3].Err)
+}
+
+func (suite *BatchEstimateSuite) Test_WriteResultsCSV() {
+	if err := scenario.RunRateEngineScenario1(suite.db); err != nil {
+		suite.FailNow("failed to run scenario 1: %+v", err)
+	}
+
+	estimator := NewEstimator(suite.db, suite.logger, suite.planner)
+	results := estimator.Run([]Row{scenario1Row(2, ""), scenario1Row(3, "D")})
+
+	var buf bytes.Buffer
+	suite.Nil(WriteResults(&buf, FormatCSV, results))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	suite.Nil(err)
+	if !suite.Len(records, 3) {
+		return
+	}
+	suite.Equal(outputColumns, records[0])
+
+	column := map[string]int{}
+	for i, name := range records[0] {
+		column[name] = i
+	}
+	suite.Equal("263300", records[1][column["gcc"]])
+	suite.Equal("true", records[1][column["fuel_surcharge_missing"]])
+	suite.Equal(DiscountSourceCOS2Fallback, records[1][column["discount_source"]])
+	suite.Equal("", records[1][column["error"]])
+	suite.Equal("", records[2][column["gcc"]])
+	suite.NotEqual("", records[2][column["error"]])
+}
+
+type BatchEstimateSuite struct {
+	suite.Suite
+	db      *pop.Connection
+	logger  *zap.Logger
+	planner route.Planner
+}
+
+func (suite *BatchEstimateSuite) SetupTest() {
+	suite.db.TruncateAll()
+}
+
+func TestBatchEstimateSuite(t *testing.T) {
+	configLocation := "../../config"
+	pop.AddLookupPaths(configLocation)
+	db, err := pop.Connect("test")
+	if err != nil {
+		log.Panic(err)
+	}
+
+	logger, _ := zap.NewDevelopment()
+	planner := route.NewTestingPlanner(362)
+
+	hs := &BatchEstimateSuite{db: db, logger: logger, planner: planner}
+	suite.Run(t, hs)
+}
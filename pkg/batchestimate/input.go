@@ -0,0 +1,163 @@
+package batchestimate
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/transcom/mymove/pkg/unit"
+)
+
+// Input formats accepted by ReadRows
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+const dateFormat = "2006-01-02"
+
+// csvColumns are the CSV header names. weight, origin_zip, destination_zip and
+// move_date are required, sit_days and cos are optional.
+var csvColumns = []string{"weight", "origin_zip", "destination_zip", "move_date", "sit_days", "cos"}
+
+// jsonRow is the form of a line of JSONL input
+type jsonRow struct {
+	Weight         *int    `json:"weight"`
+	OriginZip      string  `json:"origin_zip"`
+	DestinationZip string  `json:"destination_zip"`
+	MoveDate       string  `json:"move_date"`
+	SITDays        int     `json:"sit_days"`
+	CodeOfService  *string `json:"cos"`
+}
+
+// ReadRows reads rows in the given format. Rows that cannot be parsed are returned with
+// Err set so that they can be reported alongside the priced rows; an error is only returned
+// if the input as a whole cannot be read.
+func ReadRows(r io.Reader, format string) ([]Row, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r)
+	case FormatJSONL:
+		return readJSONL(r)
+	}
+	return nil, errors.Errorf("unknown input format %q", format)
+}
+
+func readCSV(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.Wrap(err, "reading CSV header")
+	}
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range csvColumns[:4] {
+		if _, ok := index[required]; !ok {
+			return nil, errors.Errorf("CSV header is missing the %s column", required)
+		}
+	}
+
+	var rows []Row
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			if _, ok := err.(*csv.ParseError); ok {
+				rows = append(rows, Row{Line: line, Err: err})
+				continue
+			}
+			return nil, errors.Wrap(err, "reading CSV")
+		}
+		field := func(name string) string {
+			i, ok := index[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		row := Row{
+			Line:           line,
+			OriginZip:      field("origin_zip"),
+			DestinationZip: field("destination_zip"),
+			CodeOfService:  field("cos"),
+		}
+		row.Err = parseRow(&row, field("weight"), field("move_date"), field("sit_days"))
+		rows = append(rows, row)
+	}
+}
+
+func readJSONL(r io.Reader) ([]Row, error) {
+	var rows []Row
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		row := Row{Line: line}
+		var input jsonRow
+		if err := json.Unmarshal([]byte(text), &input); err != nil {
+			row.Err = errors.Wrap(err, "invalid JSON")
+			rows = append(rows, row)
+			continue
+		}
+
+		row.OriginZip = strings.TrimSpace(input.OriginZip)
+		row.DestinationZip = strings.TrimSpace(input.DestinationZip)
+		if input.CodeOfService != nil {
+			row.CodeOfService = strings.TrimSpace(*input.CodeOfService)
+		}
+		weight := ""
+		if input.Weight != nil {
+			weight = strconv.Itoa(*input.Weight)
+		}
+		row.Err = parseRow(&row, weight, input.MoveDate, strconv.Itoa(input.SITDays))
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading JSONL")
+	}
+	return rows, nil
+}
+
+// parseRow fills in and validates the fields of row that need parsing
+func parseRow(row *Row, weight string, moveDate string, sitDays string) error {
+	w, err := strconv.Atoi(weight)
+	if err != nil || w <= 0 {
+		return errors.Errorf("invalid weight %q", weight)
+	}
+	row.Weight = unit.Pound(w)
+
+	if len(row.OriginZip) != 5 {
+		return errors.Errorf("invalid origin ZIP %q", row.OriginZip)
+	}
+	if len(row.DestinationZip) != 5 {
+		return errors.Errorf("invalid destination ZIP %q", row.DestinationZip)
+	}
+
+	row.MoveDate, err = time.Parse(dateFormat, moveDate)
+	if err != nil {
+		return errors.Errorf("invalid move date %q, expected YYYY-MM-DD", moveDate)
+	}
+
+	if sitDays != "" {
+		row.SITDays, err = strconv.Atoi(sitDays)
+		if err != nil || row.SITDays < 0 {
+			return errors.Errorf("invalid SIT days %q", sitDays)
+		}
+	}
+	return nil
+}
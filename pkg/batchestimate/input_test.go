@@ -0,0 +1,62 @@
+package batchestimate
+
+import (
+	"strings"
+	"time"
+
+	"github.com/transcom/mymove/pkg/unit"
+)
+
+func (suite *BatchEstimateSuite) Test_ReadRowsCSV() {
+	input := `weight,origin_zip,destination_zip,move_date,sit_days,cos
+4000,32168,29429,2018-06-18,10,
+800, 32168 ,29429,2018-06-18,,D
+heavy,32168,29429,2018-06-18,,
+4000,3216,29429,2018-06-18,,
+`
+	rows, err := ReadRows(strings.NewReader(input), FormatCSV)
+	suite.Nil(err)
+	if !suite.Len(rows, 4) {
+		return
+	}
+
+	suite.Nil(rows[0].Err)
+	suite.Equal(2, rows[0].Line)
+	suite.Equal(unit.Pound(4000), rows[0].Weight)
+	suite.Equal(time.Date(2018, time.June, 18, 0, 0, 0, 0, time.UTC), rows[0].MoveDate)
+	suite.Equal(10, rows[0].SITDays)
+	suite.Equal("", rows[0].CodeOfService)
+
+	suite.Nil(rows[1].Err)
+	suite.Equal("32168", rows[1].OriginZip)
+	suite.Equal("D", rows[1].CodeOfService)
+
+	suite.NotNil(rows[2].Err)
+	suite.NotNil(rows[3].Err)
+}
+
+func (suite *BatchEstimateSuite) Test_ReadRowsCSVMissingColumn() {
+	_, err := ReadRows(strings.NewReader("weight,origin_zip,move_date\n"), FormatCSV)
+	suite.NotNil(err)
+}
+
+func (suite *BatchEstimateSuite) Test_ReadRowsJSONL() {
+	input := `{"weight": 4000, "origin_zip": "32168", "destination_zip": "29429", "move_date": "2018-06-18", "cos": "2"}
+
+{"weight": 4000, "origin_zip": "32168", "destination_zip": "29429", "move_date": "06/18/2018"}
+not json
+`
+	rows, err := ReadRows(strings.NewReader(input), FormatJSONL)
+	suite.Nil(err)
+	if !suite.Len(rows, 3) {
+		return
+	}
+
+	suite.Nil(rows[0].Err)
+	suite.Equal("2", rows[0].CodeOfService)
+	suite.Equal(0, rows[0].SITDays)
+
+	suite.Equal(3, rows[1].Line)
+	suite.NotNil(rows[1].Err)
+	suite.NotNil(rows[2].Err)
+}
@@ -0,0 +1,158 @@
+package batchestimate
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// resultRecord is the flattened form of a Result that is written out. All amounts are in cents.
+type resultRecord struct {
+	Line                      int     `json:"line"`
+	Weight                    int     `json:"weight"`
+	OriginZip                 string  `json:"origin_zip"`
+	DestinationZip            string  `json:"destination_zip"`
+	MoveDate                  string  `json:"move_date"`
+	SITDays                   int     `json:"sit_days"`
+	CodeOfService             string  `json:"cos"`
+	DiscountSource            string  `json:"discount_source"`
+	LinehaulDiscount          float64 `json:"linehaul_discount"`
+	SITDiscount               float64 `json:"sit_discount"`
+	Mileage                   int     `json:"mileage"`
+	BaseLinehaul              int     `json:"base_linehaul"`
+	OriginLinehaulFactor      int     `json:"origin_linehaul_factor"`
+	DestinationLinehaulFactor int     `json:"destination_linehaul_factor"`
+	ShorthaulCharge           int     `json:"shorthaul_charge"`
+	LinehaulChargeTotal       int     `json:"linehaul_charge_total"`
+	FuelSurcharge             int     `json:"fuel_surcharge"`
+	FuelSurchargeMissing      bool    `json:"fuel_surcharge_missing"`
+	OriginServiceFee          int     `json:"origin_service_fee"`
+	DestinationServiceFee     int     `json:"destination_service_fee"`
+	PackFee                   int     `json:"pack_fee"`
+	UnpackFee                 int     `json:"unpack_fee"`
+	SITFee                    int     `json:"sit_fee"`
+	SITMax                    int     `json:"sit_max"`
+	GCC                       int     `json:"gcc"`
+	IncentiveEstimateMin      int     `json:"incentive_estimate_min"`
+	IncentiveEstimateMax      int     `json:"incentive_estimate_max"`
+	Error                     string  `json:"error"`
+}
+
+var outputColumns = []string{
+	"line", "weight", "origin_zip", "destination_zip", "move_date", "sit_days",
+	"cos", "discount_source", "linehaul_discount", "sit_discount", "mileage",
+	"base_linehaul", "origin_linehaul_factor", "destination_linehaul_factor", "shorthaul_charge",
+	"linehaul_charge_total", "fuel_surcharge", "fuel_surcharge_missing", "origin_service_fee", "destination_service_fee",
+	"pack_fee", "unpack_fee", "sit_fee", "sit_max", "gcc",
+	"incentive_estimate_min", "incentive_estimate_max", "error",
+}
+
+func newResultRecord(result Result) resultRecord {
+	record := resultRecord{
+		Line:           result.Line,
+		Weight:         result.Weight.Int(),
+		OriginZip:      result.OriginZip,
+		DestinationZip: result.DestinationZip,
+		SITDays:        result.SITDays,
+	}
+	if !result.MoveDate.IsZero() {
+		record.MoveDate = result.MoveDate.Format(dateFormat)
+	}
+	if result.Err != nil {
+		record.Error = result.Err.Error()
+		return record
+	}
+
+	cost := result.Cost
+	record.CodeOfService = result.CodeOfService
+	record.DiscountSource = result.DiscountSource
+	record.LinehaulDiscount = result.LinehaulDiscount.Float64()
+	record.SITDiscount = result.SITDiscount.Float64()
+	record.Mileage = cost.Mileage
+	record.BaseLinehaul = cost.BaseLinehaul.Int()
+	record.OriginLinehaulFactor = cost.OriginLinehaulFactor.Int()
+	record.DestinationLinehaulFactor = cost.DestinationLinehaulFactor.Int()
+	record.ShorthaulCharge = cost.ShorthaulCharge.Int()
+	record.LinehaulChargeTotal = cost.LinehaulChargeTotal.Int()
+	record.FuelSurcharge = cost.FuelSurcharge.Int()
+	record.FuelSurchargeMissing = cost.FuelSurchargeMissing
+	record.OriginServiceFee = cost.OriginServiceFee.Int()
+	record.DestinationServiceFee = cost.DestinationServiceFee.Int()
+	record.PackFee = cost.PackFee.Int()
+	record.UnpackFee = cost.UnpackFee.Int()
+	record.SITFee = cost.SITFee.Int()
+	record.SITMax = cost.SITMax.Int()
+	record.GCC = cost.GCC.Int()
+	record.IncentiveEstimateMin = result.IncentiveEstimateMin.Int()
+	record.IncentiveEstimateMax = result.IncentiveEstimateMax.Int()
+	return record
+}
+
+func (r resultRecord) values() []string {
+	values := []string{
+		strconv.Itoa(r.Line), strconv.Itoa(r.Weight), r.OriginZip, r.DestinationZip, r.MoveDate, strconv.Itoa(r.SITDays),
+		r.CodeOfService, r.DiscountSource,
+	}
+	if r.Error != "" {
+		// Leave the breakdown empty rather than reporting zeroes
+		values = append(values, make([]string, len(outputColumns)-len(values)-1)...)
+		return append(values, r.Error)
+	}
+	values = append(values,
+		strconv.FormatFloat(r.LinehaulDiscount, 'f', -1, 64),
+		strconv.FormatFloat(r.SITDiscount, 'f', -1, 64),
+	)
+	for _, amount := range []int{
+		r.Mileage, r.BaseLinehaul, r.OriginLinehaulFactor, r.DestinationLinehaulFactor, r.ShorthaulCharge,
+		r.LinehaulChargeTotal, r.FuelSurcharge,
+	} {
+		values = append(values, strconv.Itoa(amount))
+	}
+	values = append(values, strconv.FormatBool(r.FuelSurchargeMissing))
+	for _, amount := range []int{
+		r.OriginServiceFee, r.DestinationServiceFee,
+		r.PackFee, r.UnpackFee, r.SITFee, r.SITMax, r.GCC,
+		r.IncentiveEstimateMin, r.IncentiveEstimateMax,
+	} {
+		values = append(values, strconv.Itoa(amount))
+	}
+	return append(values, r.Error)
+}
+
+// WriteResults writes the results in the given format, one row per result.
+func WriteResults(w io.Writer, format string, results []Result) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, results)
+	case FormatJSONL:
+		return writeJSONL(w, results)
+	}
+	return errors.Errorf("unknown output format %q", format)
+}
+
+func writeCSV(w io.Writer, results []Result) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(outputColumns); err != nil {
+		return errors.Wrap(err, "writing CSV header")
+	}
+	for _, result := range results {
+		if err := writer.Write(newResultRecord(result).values()); err != nil {
+			return errors.Wrap(err, "writing CSV")
+		}
+	}
+	writer.Flush()
+	return errors.Wrap(writer.Error(), "writing CSV")
+}
+
+func writeJSONL(w io.Writer, results []Result) error {
+	encoder := json.NewEncoder(w)
+	for _, result := range results {
+		if err := encoder.Encode(newResultRecord(result)); err != nil {
+			return errors.Wrap(err, "writing JSONL")
+		}
+	}
+	return nil
+}
@@ -1,8 +1,14 @@
 package handlers
 
 import (
+	"fmt"
+
+	"github.com/go-openapi/runtime/middleware"
 	"github.com/go-openapi/swag"
+	"github.com/gobuffalo/validate"
 
+	"github.com/transcom/mymove/pkg/addressvalidation"
+	addressop "github.com/transcom/mymove/pkg/gen/internalapi/internaloperations/addresses"
 	"github.com/transcom/mymove/pkg/gen/internalmessages"
 	"github.com/transcom/mymove/pkg/models"
 )
@@ -46,3 +52,89 @@ func updateAddressWithPayload(a *models.Address, payload *internalmessages.Addre
 	a.PostalCode = *payload.PostalCode
 	a.Country = payload.Country
 }
+
+// addressFromPayload returns the address in payload, normalized and checked by validator, for a
+// handler to save. If existing isn't nil it is updated and returned, otherwise a new address is.
+// Issues found with it are added to verrs, keyed by the field prefixed with key, e.g.
+// residential_address.postal_code. Every handler which writes an address gets it from here, so
+// that none skip the checks. Addresses are only checked if validator isn't nil.
+func addressFromPayload(validator *addressvalidation.Validator, key string, existing *models.Address, payload *internalmessages.Address, verrs *validate.Errors) (*models.Address, error) {
+	if payload == nil {
+		return existing, nil
+	}
+	address := existing
+	if address == nil {
+		address = addressModelFromPayload(payload)
+	} else {
+		updateAddressWithPayload(address, payload)
+	}
+	if validator == nil {
+		return address, nil
+	}
+
+	result, err := validator.Validate(*address)
+	if err != nil {
+		return address, err
+	}
+	for _, issue := range result.Issues {
+		verrs.Add(fmt.Sprintf("%s.%s", key, issue.Field), issue.Message)
+	}
+	*address = result.Address
+	return address, nil
+}
+
+func payloadForAddressIssues(issues []addressvalidation.Issue) []*internalmessages.AddressIssue {
+	payload := make([]*internalmessages.AddressIssue, len(issues))
+	for i, issue := range issues {
+		payload[i] = &internalmessages.AddressIssue{
+			Field:   swag.String(issue.Field),
+			Message: swag.String(issue.Message),
+		}
+	}
+	return payload
+}
+
+func payloadForAddressValidationResult(result addressvalidation.Result) *internalmessages.AddressValidation {
+	suggestions := make([]*internalmessages.Address, len(result.Suggestions))
+	for i := range result.Suggestions {
+		suggestions[i] = payloadForAddressModel(&result.Suggestions[i])
+	}
+	payload := internalmessages.AddressValidation{
+		Address:     payloadForAddressModel(&result.Address),
+		Valid:       swag.Bool(result.Valid()),
+		Issues:      payloadForAddressIssues(result.Issues),
+		Warnings:    payloadForAddressIssues(result.Warnings),
+		Suggestions: suggestions,
+	}
+	if result.Location != nil {
+		payload.Latitude = swag.Float32(result.Location.Latitude)
+		payload.Longitude = swag.Float32(result.Location.Longitude)
+	}
+	return &payload
+}
+
+// ValidateAddressHandler normalizes and checks an address without saving it
+type ValidateAddressHandler HandlerContext
+
+// Handle returns the normalized address along with any issues and suggested corrections
+func (h ValidateAddressHandler) Handle(params addressop.ValidateAddressParams) middleware.Responder {
+	validator := h.addressValidator
+	if validator == nil {
+		validator = addressvalidation.NewValidator(h.db, h.logger, nil)
+	}
+
+	payload := params.ValidateAddressPayload
+	result, err := validator.Validate(models.Address{
+		StreetAddress1: *payload.StreetAddress1,
+		StreetAddress2: payload.StreetAddress2,
+		StreetAddress3: payload.StreetAddress3,
+		City:           *payload.City,
+		State:          *payload.State,
+		PostalCode:     *payload.PostalCode,
+		Country:        payload.Country,
+	})
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+	return addressop.NewValidateAddressOK().WithPayload(payloadForAddressValidationResult(result))
+}
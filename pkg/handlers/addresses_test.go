@@ -1,9 +1,18 @@
 package handlers
 
 import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-openapi/strfmt"
 	"github.com/go-openapi/swag"
 
+	"github.com/transcom/mymove/pkg/addressvalidation"
+	addressop "github.com/transcom/mymove/pkg/gen/internalapi/internaloperations/addresses"
+	servicememberop "github.com/transcom/mymove/pkg/gen/internalapi/internaloperations/service_members"
 	"github.com/transcom/mymove/pkg/gen/internalmessages"
+	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/testdatagen"
 )
 
 func fakeAddressPayload() *internalmessages.Address {
@@ -16,3 +25,112 @@ func fakeAddressPayload() *internalmessages.Address {
 		PostalCode:     swag.String("01234"),
 	}
 }
+
+func (suite *HandlerSuite) saveSanFranciscoZip5() {
+	suite.Nil(models.SaveZip5Location(suite.db, models.Zip5Location{
+		Zip5: "94103", City: "San Francisco", State: "CA", County: "San Francisco", Latitude: 37.775678, Longitude: -122.412131,
+	}))
+}
+
+func (suite *HandlerSuite) TestValidateAddressHandler() {
+	suite.saveSanFranciscoZip5()
+	handler := ValidateAddressHandler(NewHandlerContext(suite.db, suite.logger))
+
+	params := addressop.ValidateAddressParams{
+		HTTPRequest: httptest.NewRequest("POST", "/addresses/validate", nil),
+		ValidateAddressPayload: &internalmessages.ValidateAddressPayload{
+			StreetAddress1: swag.String("1 South Van Ness Avenue"),
+			City:           swag.String("Oakland"),
+			State:          swag.String("California"),
+			PostalCode:     swag.String("94103"),
+		},
+	}
+	response := handler.Handle(params)
+
+	suite.Assertions.IsType(&addressop.ValidateAddressOK{}, response)
+	payload := response.(*addressop.ValidateAddressOK).Payload
+	// Another city for a ZIP is only a warning, as USPS accepts other names for many
+	suite.True(*payload.Valid)
+	suite.Equal("1 S VAN NESS AVE", *payload.Address.StreetAddress1)
+	suite.Equal("CA", *payload.Address.State)
+	suite.Empty(payload.Issues)
+	if suite.Len(payload.Warnings, 1) {
+		suite.Equal("city", *payload.Warnings[0].Field)
+	}
+	if suite.Len(payload.Suggestions, 1) {
+		suite.Equal("SAN FRANCISCO", *payload.Suggestions[0].City)
+	}
+}
+
+func (suite *HandlerSuite) TestCreateServiceMemberValidatesAddresses() {
+	suite.saveSanFranciscoZip5()
+	user, _ := testdatagen.MakeUser(suite.db)
+	context := NewHandlerContext(suite.db, suite.logger)
+	context.SetAddressValidator(addressvalidation.NewValidator(suite.db, suite.logger, nil))
+	handler := CreateServiceMemberHandler(context)
+
+	address := &internalmessages.Address{
+		StreetAddress1: swag.String("1 South Van Ness Avenue"),
+		City:           swag.String("San Francisco"),
+		State:          swag.String("CA"),
+		PostalCode:     swag.String("94199"),
+	}
+	req := suite.authenticateUserRequest(httptest.NewRequest("POST", "/service_members", nil), user)
+	params := servicememberop.CreateServiceMemberParams{
+		CreateServiceMemberPayload: &internalmessages.CreateServiceMemberPayload{
+			UserID:             strfmt.UUID(user.ID.String()),
+			ResidentialAddress: address,
+		},
+		HTTPRequest: req,
+	}
+
+	// An unknown ZIP is rejected
+	response := handler.Handle(params)
+	suite.Assertions.IsType(&errResponse{}, response)
+	suite.Equal(http.StatusBadRequest, response.(*errResponse).code)
+
+	// A valid address is saved normalized
+	address.PostalCode = swag.String("94103")
+	response = handler.Handle(params)
+	suite.Assertions.IsType(&CookieUpdateResponder{}, response)
+	created := response.(*CookieUpdateResponder).responder.(*servicememberop.CreateServiceMemberCreated)
+	suite.Equal("1 S VAN NESS AVE", *created.Payload.ResidentialAddress.StreetAddress1)
+	suite.Equal("SAN FRANCISCO", *created.Payload.ResidentialAddress.City)
+}
+
+func (suite *HandlerSuite) TestPatchServiceMemberValidatesAddresses() {
+	suite.saveSanFranciscoZip5()
+	user, _ := testdatagen.MakeUser(suite.db)
+	serviceMember := models.ServiceMember{UserID: user.ID}
+	suite.mustSave(&serviceMember)
+	context := NewHandlerContext(suite.db, suite.logger)
+	context.SetAddressValidator(addressvalidation.NewValidator(suite.db, suite.logger, nil))
+	handler := PatchServiceMemberHandler(context)
+
+	address := &internalmessages.Address{
+		StreetAddress1: swag.String("1 South Van Ness Avenue"),
+		City:           swag.String("San Francisco"),
+		State:          swag.String("CA"),
+		PostalCode:     swag.String("94199"),
+	}
+	req := suite.authenticateRequest(httptest.NewRequest("PATCH", "/service_members/some_id", nil), serviceMember)
+	params := servicememberop.PatchServiceMemberParams{
+		HTTPRequest:     req,
+		ServiceMemberID: strfmt.UUID(serviceMember.ID.String()),
+		PatchServiceMemberPayload: &internalmessages.PatchServiceMemberPayload{
+			BackupMailingAddress: address,
+		},
+	}
+
+	// An unknown ZIP is rejected
+	response := handler.Handle(params)
+	suite.Assertions.IsType(&errResponse{}, response)
+	suite.Equal(http.StatusBadRequest, response.(*errResponse).code)
+
+	// A valid address is saved normalized
+	address.PostalCode = swag.String("94103")
+	response = handler.Handle(params)
+	suite.Assertions.IsType(&servicememberop.PatchServiceMemberOK{}, response)
+	patched := response.(*servicememberop.PatchServiceMemberOK)
+	suite.Equal("1 S VAN NESS AVE", *patched.Payload.BackupMailingAddress.StreetAddress1)
+}
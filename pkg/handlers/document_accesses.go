@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/gobuffalo/pop"
+	"github.com/gobuffalo/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/auth"
+	officeop "github.com/transcom/mymove/pkg/gen/internalapi/internaloperations/office"
+	"github.com/transcom/mymove/pkg/gen/internalmessages"
+	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/storage"
+	uploaderpkg "github.com/transcom/mymove/pkg/uploader"
+)
+
+// documentAccessor gives the user making a request access to documents and their files, and
+// records each access in the document access log. An access which can't be recorded fails, so
+// nothing is seen without a trace.
+type documentAccessor struct {
+	db      *pop.Connection
+	logger  *zap.Logger
+	storer  storage.FileStorer
+	request *http.Request
+}
+
+// newDocumentAccessor returns a documentAccessor for a request
+func newDocumentAccessor(db *pop.Connection, logger *zap.Logger, storer storage.FileStorer, request *http.Request) *documentAccessor {
+	return &documentAccessor{
+		db:      db,
+		logger:  logger,
+		storer:  storer,
+		request: request,
+	}
+}
+
+// record logs an access to a document, or one of its uploads if upload isn't nil
+func (a *documentAccessor) record(document models.Document, upload *models.Upload, purpose models.DocumentAccessPurpose) error {
+	session := auth.SessionFromRequestContext(a.request)
+	if session == nil {
+		return errors.New("can't record a document access without a session")
+	}
+
+	access := models.NewDocumentAccess(session, document, upload, purpose)
+	access.IPAddress = a.request.RemoteAddr
+	if host, _, err := net.SplitHostPort(a.request.RemoteAddr); err == nil {
+		access.IPAddress = host
+	}
+	if forwardedFor := a.request.Header.Get("X-Forwarded-For"); forwardedFor != "" {
+		access.ForwardedFor = &forwardedFor
+	}
+	if err := models.RecordDocumentAccess(a.db, &access); err != nil {
+		a.logger.Error("failed to record document access", zap.String("document_id", document.ID.String()), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// presignedURL returns a URL to a stored file of a document, and records that it was given out.
+// Storage which doesn't allow direct access returns storage.ErrNoPresignedURL, and nothing is
+// recorded until the file is downloaded.
+func (a *documentAccessor) presignedURL(document models.Document, upload *models.Upload, key string, contentType string, purpose models.DocumentAccessPurpose) (string, error) {
+	url, err := a.storer.PresignedURL(key, contentType)
+	if err != nil {
+		return "", err
+	}
+	if err := a.record(document, upload, purpose); err != nil {
+		return "", err
+	}
+	return url, nil
+}
+
+// uploadURL returns the URL of an upload's file. It is empty until the file has been scanned
+// clean, and is the API's own when storage doesn't allow direct access.
+func (a *documentAccessor) uploadURL(document models.Document, upload *models.Upload) (string, error) {
+	if upload.ScanStatus != models.UploadScanStatusCLEAN {
+		return "", nil
+	}
+	url, err := a.presignedURL(document, upload, uploaderpkg.UploadKey(a.storer, upload), upload.ContentType, models.DocumentAccessPurposeUPLOADURL)
+	if err == storage.ErrNoPresignedURL {
+		return uploadContentURL(*upload), nil
+	}
+	return url, err
+}
+
+// previewURL returns the URL of an upload's preview, which is empty until there is one. Uploads
+// no preview can be made of get the API's URL, which serves a placeholder.
+func (a *documentAccessor) previewURL(document models.Document, upload *models.Upload) (string, error) {
+	if upload.ScanStatus != models.UploadScanStatusCLEAN || upload.PreviewStatus == models.UploadPreviewStatusPENDING {
+		return "", nil
+	}
+	if upload.PreviewStatus == models.UploadPreviewStatusUNAVAILABLE {
+		return uploadPreviewURL(*upload), nil
+	}
+	url, err := a.presignedURL(document, upload, uploaderpkg.PreviewKey(a.storer, upload), "image/jpeg", models.DocumentAccessPurposePREVIEWURL)
+	if err == storage.ErrNoPresignedURL {
+		return uploadPreviewURL(*upload), nil
+	}
+	return url, err
+}
+
+func payloadForDocumentAccessModel(access models.DocumentAccess) *internalmessages.DocumentAccessPayload {
+	role := internalmessages.DocumentAccessRole(access.Role)
+	purpose := internalmessages.DocumentAccessPurpose(access.Purpose)
+	return &internalmessages.DocumentAccessPayload{
+		ID:              fmtUUID(access.ID),
+		UserID:          fmtUUID(access.UserID),
+		Email:           fmtEmail(access.Email),
+		Role:            &role,
+		ServiceMemberID: fmtUUID(access.ServiceMemberID),
+		DocumentID:      fmtUUID(access.DocumentID),
+		UploadID:        fmtUUIDPtr(access.UploadID),
+		Purpose:         &purpose,
+		IPAddress:       fmtString(access.IPAddress),
+		ForwardedFor:    access.ForwardedFor,
+		CreatedAt:       fmtDateTime(access.CreatedAt),
+	}
+}
+
+// IndexDocumentAccessesHandler lists who accessed a service member's documents via GET /service_members/{serviceMemberId}/document_accesses
+type IndexDocumentAccessesHandler HandlerContext
+
+// Handle returns the document access log of a service member, most recent first, to office users
+func (h IndexDocumentAccessesHandler) Handle(params officeop.IndexDocumentAccessesParams) middleware.Responder {
+	session := auth.SessionFromRequestContext(params.HTTPRequest)
+
+	serviceMemberID, _ := uuid.FromString(params.ServiceMemberID.String())
+	accesses, err := models.FetchDocumentAccesses(h.db, session, serviceMemberID)
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+
+	payload := make(internalmessages.IndexDocumentAccessesPayload, len(accesses))
+	for i, access := range accesses {
+		payload[i] = payloadForDocumentAccessModel(access)
+	}
+	return officeop.NewIndexDocumentAccessesOK().WithPayload(payload)
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/strfmt"
+
+	documentop "github.com/transcom/mymove/pkg/gen/internalapi/internaloperations/documents"
+	officeop "github.com/transcom/mymove/pkg/gen/internalapi/internaloperations/office"
+	"github.com/transcom/mymove/pkg/gen/internalmessages"
+	"github.com/transcom/mymove/pkg/models"
+	storageTest "github.com/transcom/mymove/pkg/storage/test"
+	"github.com/transcom/mymove/pkg/testdatagen"
+)
+
+func (suite *HandlerSuite) TestIndexDocumentAccessesHandler() {
+	upload, err := testdatagen.MakeUpload(suite.db, nil)
+	suite.Nil(err)
+	var document models.Document
+	suite.Nil(suite.db.Eager("ServiceMember.User").Find(&document, upload.DocumentID))
+	officeUser, err := testdatagen.MakeOfficeUser(suite.db)
+	suite.Nil(err)
+
+	context := NewHandlerContext(suite.db, suite.logger)
+	context.SetFileStorer(storageTest.NewFakeS3Storage(true))
+
+	// The service member looks at their document, then an office user does
+	showParams := documentop.NewShowDocumentParams()
+	showParams.DocumentID = strfmt.UUID(document.ID.String())
+	showParams.HTTPRequest = suite.authenticateRequest(&http.Request{RemoteAddr: "10.0.0.1:1234"}, document.ServiceMember)
+	response := ShowDocumentHandler(context).Handle(showParams)
+	suite.IsType(&documentop.ShowDocumentOK{}, response)
+
+	req := &http.Request{RemoteAddr: "10.0.0.2:1234", Header: http.Header{}}
+	req.Header.Set("X-Forwarded-For", "192.0.2.1")
+	showParams.HTTPRequest = suite.authenticateOfficeRequest(req, officeUser)
+	response = ShowDocumentHandler(context).Handle(showParams)
+	suite.IsType(&documentop.ShowDocumentOK{}, response)
+
+	indexParams := officeop.NewIndexDocumentAccessesParams()
+	indexParams.ServiceMemberID = strfmt.UUID(document.ServiceMemberID.String())
+	indexParams.HTTPRequest = suite.authenticateOfficeRequest(&http.Request{}, officeUser)
+	response = IndexDocumentAccessesHandler(context).Handle(indexParams)
+	okResponse, ok := response.(*officeop.IndexDocumentAccessesOK)
+	suite.True(ok, "Request failed: %#v", response)
+	if !ok {
+		return
+	}
+
+	// Each look records the document being viewed and the URL of its upload being given out
+	payload := okResponse.Payload
+	suite.Len(payload, 4)
+	roles := map[internalmessages.DocumentAccessRole]int{}
+	purposes := map[internalmessages.DocumentAccessPurpose]int{}
+	for _, access := range payload {
+		suite.Equal(document.ID.String(), access.DocumentID.String())
+		roles[*access.Role]++
+		purposes[*access.Purpose]++
+		if *access.Role == internalmessages.DocumentAccessRoleOFFICEUSER {
+			suite.Equal(officeUser.UserID.String(), access.UserID.String())
+			suite.Equal("10.0.0.2", *access.IPAddress)
+			suite.Equal("192.0.2.1", *access.ForwardedFor)
+		} else {
+			suite.Equal("10.0.0.1", *access.IPAddress)
+			suite.Nil(access.ForwardedFor)
+		}
+		if *access.Purpose == internalmessages.DocumentAccessPurposeUPLOADURL {
+			suite.Equal(upload.ID.String(), access.UploadID.String())
+		} else {
+			suite.Nil(access.UploadID)
+		}
+	}
+	suite.Equal(2, roles[internalmessages.DocumentAccessRoleSERVICEMEMBER])
+	suite.Equal(2, roles[internalmessages.DocumentAccessRoleOFFICEUSER])
+	suite.Equal(2, purposes[internalmessages.DocumentAccessPurposeVIEWDOCUMENT])
+	suite.Equal(2, purposes[internalmessages.DocumentAccessPurposeUPLOADURL])
+
+	// Service members can't see who has looked at their documents
+	indexParams.HTTPRequest = suite.authenticateRequest(&http.Request{}, document.ServiceMember)
+	response = IndexDocumentAccessesHandler(context).Handle(indexParams)
+	suite.IsType(&errResponse{}, response)
+	suite.Equal(http.StatusForbidden, response.(*errResponse).code)
+}
@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"io"
+
 	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
 	"github.com/go-openapi/swag"
 	"github.com/gobuffalo/uuid"
 	"go.uber.org/zap"
@@ -12,30 +15,50 @@ import (
 	"github.com/transcom/mymove/pkg/gen/restapi/apioperations"
 	"github.com/transcom/mymove/pkg/models"
 	"github.com/transcom/mymove/pkg/storage"
+	uploaderpkg "github.com/transcom/mymove/pkg/uploader"
 )
 
-func payloadForDocumentModel(storer storage.FileStorer, document models.Document) (*internalmessages.DocumentPayload, error) {
+func payloadForDocumentModel(accessor *documentAccessor, document models.Document) (*internalmessages.DocumentPayload, error) {
 	uploads := make([]*internalmessages.UploadPayload, len(document.Uploads))
 	for i, upload := range document.Uploads {
-		key := storer.Key("documents", document.ID.String(), "uploads", upload.ID.String())
-		url, err := storer.PresignedURL(key, upload.ContentType)
+		// Uploads can't be opened until they have been scanned clean
+		url, err := accessor.uploadURL(document, &upload)
+		if err != nil {
+			return nil, err
+		}
+		previewURL, err := accessor.previewURL(document, &upload)
 		if err != nil {
 			return nil, err
 		}
 
-		uploadPayload := payloadForUploadModel(upload, url)
+		uploadPayload := payloadForUploadModel(upload, url, previewURL)
+		if duplicate := document.Uploads.Duplicate(&upload); duplicate != nil {
+			uploadPayload.DuplicateOf = fmtUUID(duplicate.ID)
+		}
 		uploads[i] = uploadPayload
 	}
 
+	documentType := internalmessages.DocumentType(document.DocumentType)
 	documentPayload := &internalmessages.DocumentPayload{
 		ID:              fmtUUID(document.ID),
 		ServiceMemberID: fmtUUID(document.ServiceMemberID),
 		Name:            swag.String(document.Name),
+		DocumentType:    &documentType,
+		Policy:          payloadForDocumentPolicy(document.DocumentType.Policy()),
 		Uploads:         uploads,
 	}
 	return documentPayload, nil
 }
 
+func payloadForDocumentPolicy(policy models.DocumentPolicy) *internalmessages.DocumentPolicyPayload {
+	return &internalmessages.DocumentPolicyPayload{
+		AllowedContentTypes:  policy.AllowedContentTypes,
+		MaxBytes:             swag.Int64(policy.MaxBytes),
+		MaxUploads:           swag.Int64(int64(policy.MaxUploads)),
+		RequiresOfficeReview: swag.Bool(policy.RequiresOfficeReview),
+	}
+}
+
 // CreateDocumentHandler creates a new document via POST /documents/
 type CreateDocumentHandler HandlerContext
 
@@ -54,9 +77,14 @@ func (h CreateDocumentHandler) Handle(params documentop.CreateDocumentParams) mi
 		return responseForError(h.logger, err)
 	}
 
+	documentType := models.DocumentTypeOTHER
+	if params.DocumentPayload.DocumentType != "" {
+		documentType = models.DocumentType(params.DocumentPayload.DocumentType)
+	}
 	newDocument := models.Document{
 		ServiceMemberID: serviceMember.ID,
 		Name:            params.DocumentPayload.Name,
+		DocumentType:    documentType,
 	}
 
 	verrs, err := h.db.ValidateAndCreate(&newDocument)
@@ -69,7 +97,7 @@ func (h CreateDocumentHandler) Handle(params documentop.CreateDocumentParams) mi
 	}
 
 	h.logger.Info("created a document with id: ", zap.Any("new_document_id", newDocument.ID))
-	documentPayload, err := payloadForDocumentModel(h.storage, newDocument)
+	documentPayload, err := payloadForDocumentModel(newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest), newDocument)
 	if err != nil {
 		return responseForError(h.logger, err)
 	}
@@ -93,7 +121,11 @@ func (h ShowDocumentHandler) Handle(params documentop.ShowDocumentParams) middle
 		return responseForError(h.logger, err)
 	}
 
-	documentPayload, err := payloadForDocumentModel(h.storage, document)
+	accessor := newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest)
+	if err := accessor.record(document, nil, models.DocumentAccessPurposeVIEWDOCUMENT); err != nil {
+		return responseForError(h.logger, err)
+	}
+	documentPayload, err := payloadForDocumentModel(accessor, document)
 	if err != nil {
 		return responseForError(h.logger, err)
 	}
@@ -101,6 +133,51 @@ func (h ShowDocumentHandler) Handle(params documentop.ShowDocumentParams) middle
 	return documentop.NewShowDocumentOK().WithPayload(documentPayload)
 }
 
+// ShowDocumentPDFHandler downloads a document as one PDF via GET /documents/:document_id/pdf
+type ShowDocumentPDFHandler HandlerContext
+
+// Handle redirects to a PDF combining a Document's uploads, or streams it if storage doesn't
+// allow direct access
+func (h ShowDocumentPDFHandler) Handle(params documentop.ShowDocumentPDFParams) middleware.Responder {
+	session := auth.SessionFromRequestContext(params.HTTPRequest)
+
+	documentID, err := uuid.FromString(params.DocumentID.String())
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+
+	document, err := models.FetchDocument(h.db, session, documentID)
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+
+	accessor := newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest)
+	uploader := uploaderpkg.NewUploader(h.db, h.logger, h.storage, h.scanner)
+	url, err := uploader.CombinedPDFURL(&document)
+	if err == storage.ErrNoPresignedURL {
+		var content io.ReadCloser
+		if content, err = uploader.FetchCombinedPDF(&document); err == nil {
+			if err := accessor.record(document, nil, models.DocumentAccessPurposeDOWNLOADPDF); err != nil {
+				content.Close()
+				return responseForError(h.logger, err)
+			}
+			return contentResponder(h.logger, content, "application/pdf")
+		}
+	}
+	switch err {
+	case nil:
+		if err := accessor.record(document, nil, models.DocumentAccessPurposePDFURL); err != nil {
+			return responseForError(h.logger, err)
+		}
+		return documentop.NewShowDocumentPDFSeeOther().WithLocation(strfmt.URI(url))
+	case uploaderpkg.ErrNoUploads:
+		return documentop.NewShowDocumentPDFNotFound()
+	case uploaderpkg.ErrUploadNotClean:
+		return documentop.NewShowDocumentPDFConflict()
+	}
+	return responseForError(h.logger, err)
+}
+
 /* NOTE - The code above is for the INTERNAL API. The code below is for the public API. These will, obviously,
 need to be reconciled. This will be done when the NotImplemented code below is Implemented
 */
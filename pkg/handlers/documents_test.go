@@ -59,6 +59,10 @@ func (suite *HandlerSuite) TestCreateDocumentsHandler() {
 		t.Errorf("wrong number of uploads, expected 0, got %d", len(documentPayload.Uploads))
 	}
 
+	// Documents are OTHER unless given a type
+	suite.Equal(internalmessages.DocumentTypeOTHER, *documentPayload.DocumentType)
+	suite.False(*documentPayload.Policy.RequiresOfficeReview)
+
 	document := models.Document{}
 	err = suite.db.Find(&document, documentPayload.ID)
 	if err != nil {
@@ -117,3 +121,42 @@ func (suite *HandlerSuite) TestShowDocumentHandler() {
 		t.Errorf("wrong URL for upload, expected %s, got %s", expectedURL, uploadPayload.URL)
 	}
 }
+
+func (suite *HandlerSuite) TestShowDocumentPDFHandler() {
+	upload, err := testdatagen.MakeUpload(suite.db, nil)
+	suite.Nil(err)
+	var document models.Document
+	suite.Nil(suite.db.Eager("ServiceMember.User").Find(&document, upload.DocumentID))
+
+	fakeS3 := storageTest.NewFakeS3Storage(true)
+	key := fakeS3.Key("documents", upload.DocumentID.String(), "uploads", upload.ID.String())
+	fakeS3.Store(key, suite.fixture("test.pdf").Data, "somehash")
+
+	params := documentop.NewShowDocumentPDFParams()
+	params.DocumentID = strfmt.UUID(document.ID.String())
+	params.HTTPRequest = suite.authenticateRequest(&http.Request{}, document.ServiceMember)
+
+	context := NewHandlerContext(suite.db, suite.logger)
+	context.SetFileStorer(fakeS3)
+	handler := ShowDocumentPDFHandler(context)
+	response := handler.Handle(params)
+
+	suite.Assertions.IsType(&documentop.ShowDocumentPDFSeeOther{}, response)
+	location := response.(*documentop.ShowDocumentPDFSeeOther).Location.String()
+	suite.Contains(location, fmt.Sprintf("documents/%s/combined/", document.ID))
+	suite.Len(fakeS3.PutFiles, 2)
+
+	// The combined PDF is only rendered once
+	response = handler.Handle(params)
+	suite.Assertions.IsType(&documentop.ShowDocumentPDFSeeOther{}, response)
+	suite.Equal(location, response.(*documentop.ShowDocumentPDFSeeOther).Location.String())
+	suite.Len(fakeS3.PutFiles, 2)
+
+	// Until every upload has been scanned, there is nothing to download
+	pending, err := testdatagen.MakeUpload(suite.db, &document)
+	suite.Nil(err)
+	pending.ScanStatus = models.UploadScanStatusPENDING
+	suite.mustSave(&pending)
+	response = handler.Handle(params)
+	suite.Assertions.IsType(&documentop.ShowDocumentPDFConflict{}, response)
+}
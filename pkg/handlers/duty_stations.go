@@ -30,21 +30,36 @@ func payloadForDutyStationModel(station models.DutyStation) *internalmessages.Du
 // SearchDutyStationsHandler returns a list of all issues
 type SearchDutyStationsHandler HandlerContext
 
-// Handle returns a list of stations based on the search query
+// Handle returns a list of stations based on the search query, best match first. Stations near the
+// postal code, if one is given, rank higher.
 func (h SearchDutyStationsHandler) Handle(params stationop.SearchDutyStationsParams) middleware.Responder {
-	var stations models.DutyStations
-	var err error
+	limit := models.DefaultSearchLimit
+	if params.Limit != nil {
+		limit = int(*params.Limit)
+	}
+
+	var near *models.GeoPoint
+	if params.PostalCode != nil {
+		point, err := geoPointForPostalCode(h.db, *params.PostalCode)
+		if err == nil {
+			near = &point
+		} else if err != models.ErrFetchNotFound {
+			h.logger.Error("Finding duty stations", zap.Error(err))
+			return stationop.NewSearchDutyStationsInternalServerError()
+		}
+		// An unknown postal code only loses the proximity ranking
+	}
 
-	stations, err = models.FindDutyStations(h.db, params.Search)
+	results, err := models.SearchDutyStations(h.db, params.Search, near, limit)
 	if err != nil {
 		h.logger.Error("Finding duty stations", zap.Error(err))
 		return stationop.NewSearchDutyStationsInternalServerError()
 
 	}
 
-	stationPayloads := make(internalmessages.DutyStationsPayload, len(stations))
-	for i, station := range stations {
-		stationPayload := payloadForDutyStationModel(station)
+	stationPayloads := make(internalmessages.DutyStationsPayload, len(results))
+	for i, result := range results {
+		stationPayload := payloadForDutyStationModel(result.DutyStation)
 		stationPayloads[i] = stationPayload
 	}
 	return stationop.NewSearchDutyStationsOK().WithPayload(stationPayloads)
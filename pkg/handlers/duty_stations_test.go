@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http/httptest"
 
+	"github.com/go-openapi/swag"
 	"github.com/gobuffalo/uuid"
 
 	"github.com/transcom/mymove/pkg/auth"
@@ -77,3 +78,36 @@ func (suite *HandlerSuite) TestSearchDutyStationHandler() {
 		t.Error("Address should have been loaded")
 	}
 }
+
+func (suite *HandlerSuite) TestSearchDutyStationHandlerRanksNearbyStations() {
+	for _, station := range []struct {
+		name       string
+		postalCode string
+		latitude   float32
+		longitude  float32
+	}{
+		{"Fort Bliss", "79916", 31.8134, -106.4215},
+		{"Fort Bragg", "28310", 35.1409, -79.0061},
+	} {
+		suite.Nil(models.SaveZip5Location(suite.db, models.Zip5Location{Zip5: station.postalCode, Latitude: station.latitude, Longitude: station.longitude}))
+		address := models.Address{StreetAddress1: "duty station", City: "city", State: "state", PostalCode: station.postalCode}
+		suite.mustSave(&address)
+		suite.mustSave(&models.DutyStation{Name: station.name, Affiliation: internalmessages.AffiliationARMY, AddressID: address.ID})
+	}
+	handler := SearchDutyStationsHandler(NewHandlerContext(suite.db, suite.logger))
+
+	for _, postalCode := range []string{"79916", "28310"} {
+		params := stationop.SearchDutyStationsParams{
+			HTTPRequest: httptest.NewRequest("GET", "/duty_stations", nil),
+			Search:      "fort",
+			PostalCode:  swag.String(postalCode),
+		}
+		response := handler.Handle(params)
+
+		suite.Assertions.IsType(&stationop.SearchDutyStationsOK{}, response)
+		payload := response.(*stationop.SearchDutyStationsOK).Payload
+		if suite.Len(payload, 2) {
+			suite.Equal(postalCode, *payload[0].Address.PostalCode)
+		}
+	}
+}
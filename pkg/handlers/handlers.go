@@ -13,6 +13,7 @@ import (
 
 	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/runtime/middleware"
+	"github.com/transcom/mymove/pkg/addressvalidation"
 	"github.com/transcom/mymove/pkg/auth"
 	"github.com/transcom/mymove/pkg/gen/internalapi"
 	internalops "github.com/transcom/mymove/pkg/gen/internalapi/internaloperations"
@@ -20,7 +21,9 @@ import (
 	"github.com/transcom/mymove/pkg/gen/restapi"
 	publicops "github.com/transcom/mymove/pkg/gen/restapi/apioperations"
 	"github.com/transcom/mymove/pkg/route"
+	"github.com/transcom/mymove/pkg/scanner"
 	"github.com/transcom/mymove/pkg/storage"
+	uploaderpkg "github.com/transcom/mymove/pkg/uploader"
 )
 
 // HandlerContext contains dependencies that are shared between all handlers.
@@ -32,7 +35,11 @@ type HandlerContext struct {
 	cookieSecret     string
 	noSessionTimeout bool
 	planner          route.Planner
+	addressValidator *addressvalidation.Validator
 	storage          storage.FileStorer
+	contentAddressed bool
+	scanner          scanner.Scanner
+	previewWorker    *uploaderpkg.PreviewWorker
 	sesService       sesiface.SESAPI
 }
 
@@ -49,6 +56,24 @@ func (context *HandlerContext) SetFileStorer(storer storage.FileStorer) {
 	context.storage = storer
 }
 
+// SetContentAddressedStorage makes new uploads store each file once under its SHA-256, shared
+// by every upload of the same content
+func (context *HandlerContext) SetContentAddressedStorage() {
+	context.contentAddressed = true
+}
+
+// SetScanner is a simple setter for the scanner private field. Without a scanner, new uploads
+// can't be opened until they are scanned later.
+func (context *HandlerContext) SetScanner(scanner scanner.Scanner) {
+	context.scanner = scanner
+}
+
+// SetPreviewWorker is a simple setter for the previewWorker private field. New uploads only get
+// previews if it has been set, or if something else makes them.
+func (context *HandlerContext) SetPreviewWorker(worker *uploaderpkg.PreviewWorker) {
+	context.previewWorker = worker
+}
+
 // SetSesService is a simple setter for AWS SES private field
 func (context *HandlerContext) SetSesService(sesService sesiface.SESAPI) {
 	context.sesService = sesService
@@ -59,6 +84,12 @@ func (context *HandlerContext) SetPlanner(planner route.Planner) {
 	context.planner = planner
 }
 
+// SetAddressValidator is a simple setter for the addressValidator private field. Addresses are only
+// normalized and checked when service members are created or updated if it has been set.
+func (context *HandlerContext) SetAddressValidator(validator *addressvalidation.Validator) {
+	context.addressValidator = validator
+}
+
 // SetCookieSecret is a simple setter for the cookieSeecret private Field
 func (context *HandlerContext) SetCookieSecret(cookieSecret string) {
 	context.cookieSecret = cookieSecret
@@ -133,10 +164,18 @@ func NewInternalAPIHandler(context HandlerContext) http.Handler {
 	internalAPI.PpmPatchPersonallyProcuredMoveHandler = PatchPersonallyProcuredMoveHandler(context)
 	internalAPI.PpmShowPPMEstimateHandler = ShowPPMEstimateHandler(context)
 	internalAPI.PpmShowPPMSitEstimateHandler = ShowPPMSitEstimateHandler(context)
+	internalAPI.PpmShowPPMMoveDateEstimatesHandler = ShowPPMMoveDateEstimatesHandler(context)
 
 	internalAPI.DutyStationsSearchDutyStationsHandler = SearchDutyStationsHandler(context)
 
+	internalAPI.AddressesValidateAddressHandler = ValidateAddressHandler(context)
+
+	internalAPI.PostalCodesShowPostalCodeHandler = ShowPostalCodeHandler(context)
+	internalAPI.PostalCodesSearchPostalCodesHandler = SearchPostalCodesHandler(context)
+
 	internalAPI.TransportationOfficesShowDutyStationTransportationOfficeHandler = ShowDutyStationTransportationOfficeHandler(context)
+	internalAPI.TransportationOfficesSearchTransportationOfficesHandler = SearchTransportationOfficesHandler(context)
+	internalAPI.TransportationOfficesShowNearestTransportationOfficesHandler = ShowNearestTransportationOfficesHandler(context)
 
 	internalAPI.ShipmentsIndexShipmentsHandler = IndexShipmentsHandler(context)
 
@@ -161,7 +200,15 @@ func NewInternalAPIHandler(context HandlerContext) http.Handler {
 
 	internalAPI.DocumentsCreateDocumentHandler = CreateDocumentHandler(context)
 	internalAPI.DocumentsShowDocumentHandler = ShowDocumentHandler(context)
+	internalAPI.DocumentsShowDocumentPDFHandler = ShowDocumentPDFHandler(context)
 	internalAPI.UploadsCreateUploadHandler = CreateUploadHandler(context)
+	internalAPI.UploadsShowUploadContentHandler = ShowUploadContentHandler(context)
+	internalAPI.UploadsShowUploadPreviewHandler = ShowUploadPreviewHandler(context)
+	internalAPI.UploadsCreateUploadSessionHandler = CreateUploadSessionHandler(context)
+	internalAPI.UploadsShowUploadSessionHandler = ShowUploadSessionHandler(context)
+	internalAPI.UploadsAppendUploadSessionChunkHandler = AppendUploadSessionChunkHandler(context)
+	internalAPI.UploadsFinalizeUploadSessionHandler = FinalizeUploadSessionHandler(context)
+	internalAPI.UploadsDeleteUploadSessionHandler = DeleteUploadSessionHandler(context)
 	internalAPI.UploadsDeleteUploadHandler = DeleteUploadHandler(context)
 	internalAPI.UploadsDeleteUploadsHandler = DeleteUploadsHandler(context)
 
@@ -171,6 +218,9 @@ func NewInternalAPIHandler(context HandlerContext) http.Handler {
 	internalAPI.OfficeApprovePPMHandler = ApprovePPMHandler(context)
 	internalAPI.OfficeApproveReimbursementHandler = ApproveReimbursementHandler(context)
 	internalAPI.OfficeCancelMoveHandler = CancelMoveHandler(context)
+	internalAPI.OfficeIndexDocumentAccessesHandler = IndexDocumentAccessesHandler(context)
+	internalAPI.OfficeReviewUploadHandler = ReviewUploadHandler(context)
+	internalAPI.OfficeShowZip3MapHandler = ShowZip3MapHandler(context)
 
 	internalAPI.EntitlementsValidateEntitlementHandler = ValidateEntitlementHandler(context)
 
@@ -10,14 +10,13 @@ import (
 	moveop "github.com/transcom/mymove/pkg/gen/internalapi/internaloperations/moves"
 	"github.com/transcom/mymove/pkg/gen/internalmessages"
 	"github.com/transcom/mymove/pkg/models"
-	"github.com/transcom/mymove/pkg/storage"
 )
 
-func payloadForMoveModel(storer storage.FileStorer, order models.Order, move models.Move) (*internalmessages.MovePayload, error) {
+func payloadForMoveModel(accessor *documentAccessor, order models.Order, move models.Move) (*internalmessages.MovePayload, error) {
 
 	var ppmPayloads internalmessages.IndexPersonallyProcuredMovePayload
 	for _, ppm := range move.PersonallyProcuredMoves {
-		payload, err := payloadForPPMModel(storer, ppm)
+		payload, err := payloadForPPMModel(accessor, ppm)
 		if err != nil {
 			return nil, err
 		}
@@ -58,7 +57,7 @@ func (h CreateMoveHandler) Handle(params moveop.CreateMoveParams) middleware.Res
 		}
 		return responseForVErrors(h.logger, verrs, err)
 	}
-	movePayload, err := payloadForMoveModel(h.storage, orders, *move)
+	movePayload, err := payloadForMoveModel(newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest), orders, *move)
 	if err != nil {
 		return responseForError(h.logger, err)
 	}
@@ -86,7 +85,7 @@ func (h ShowMoveHandler) Handle(params moveop.ShowMoveParams) middleware.Respond
 		return responseForError(h.logger, err)
 	}
 
-	movePayload, err := payloadForMoveModel(h.storage, orders, *move)
+	movePayload, err := payloadForMoveModel(newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest), orders, *move)
 	if err != nil {
 		return responseForError(h.logger, err)
 	}
@@ -123,7 +122,7 @@ func (h PatchMoveHandler) Handle(params moveop.PatchMoveParams) middleware.Respo
 	if err != nil || verrs.HasAny() {
 		return responseForVErrors(h.logger, verrs, err)
 	}
-	movePayload, err := payloadForMoveModel(h.storage, orders, *move)
+	movePayload, err := payloadForMoveModel(newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest), orders, *move)
 	if err != nil {
 		return responseForError(h.logger, err)
 	}
@@ -157,7 +156,7 @@ func (h SubmitMoveHandler) Handle(params moveop.SubmitMoveForApprovalParams) mid
 		return responseForVErrors(h.logger, verrs, err)
 	}
 
-	movePayload, err := payloadForMoveModel(h.storage, move.Orders, *move)
+	movePayload, err := payloadForMoveModel(newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest), move.Orders, *move)
 	if err != nil {
 		return responseForError(h.logger, err)
 	}
@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"time"
+
 	"github.com/go-openapi/runtime/middleware"
+	"github.com/gobuffalo/pop"
 	"github.com/gobuffalo/uuid"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
 	"github.com/transcom/mymove/pkg/auth"
@@ -25,6 +29,14 @@ func (h ApproveMoveHandler) Handle(params officeop.ApproveMoveParams) middleware
 		return responseForError(h.logger, err)
 	}
 
+	unreviewed, err := countUnreviewedUploads(h.db, move.Orders.UploadedOrdersID)
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+	if unreviewed > 0 {
+		return responseForConflictErrors(h.logger, errors.Errorf("%d uploaded files of the move's orders must be reviewed before it is approved", unreviewed))
+	}
+
 	move.Status = models.MoveStatusAPPROVED
 
 	verrs, err := h.db.ValidateAndUpdate(move)
@@ -32,7 +44,7 @@ func (h ApproveMoveHandler) Handle(params officeop.ApproveMoveParams) middleware
 		return responseForVErrors(h.logger, verrs, err)
 	}
 
-	movePayload, err := payloadForMoveModel(h.storage, move.Orders, *move)
+	movePayload, err := payloadForMoveModel(newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest), move.Orders, *move)
 	if err != nil {
 		return responseForError(h.logger, err)
 	}
@@ -77,7 +89,7 @@ func (h CancelMoveHandler) Handle(params officeop.CancelMoveParams) middleware.R
 		}
 	}
 
-	movePayload, err := payloadForMoveModel(h.storage, move.Orders, *move)
+	movePayload, err := payloadForMoveModel(newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest), move.Orders, *move)
 	if err != nil {
 		return responseForError(h.logger, err)
 	}
@@ -99,6 +111,18 @@ func (h ApprovePPMHandler) Handle(params officeop.ApprovePPMParams) middleware.R
 		return responseForError(h.logger, err)
 	}
 
+	documentIDs := []uuid.UUID{ppm.Move.Orders.UploadedOrdersID}
+	if ppm.AdvanceWorksheetID != nil {
+		documentIDs = append(documentIDs, *ppm.AdvanceWorksheetID)
+	}
+	unreviewed, err := countUnreviewedUploads(h.db, documentIDs...)
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+	if unreviewed > 0 {
+		return responseForConflictErrors(h.logger, errors.Errorf("%d uploaded files of the PPM's documents must be reviewed before it is approved", unreviewed))
+	}
+
 	moveID := ppm.MoveID
 	ppm.Status = models.PPMStatusAPPROVED
 
@@ -117,13 +141,73 @@ func (h ApprovePPMHandler) Handle(params officeop.ApprovePPMParams) middleware.R
 		// return newErrResponse(500)
 	}
 
-	ppmPayload, err := payloadForPPMModel(h.storage, *ppm)
+	ppmPayload, err := payloadForPPMModel(newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest), *ppm)
 	if err != nil {
 		return responseForError(h.logger, err)
 	}
 	return officeop.NewApprovePPMOK().WithPayload(ppmPayload)
 }
 
+// countUnreviewedUploads returns how many uploads of the documents with the given IDs an office
+// user must still review before the move or PPM they belong to can be approved
+func countUnreviewedUploads(db *pop.Connection, documentIDs ...uuid.UUID) (int, error) {
+	count := 0
+	for _, documentID := range documentIDs {
+		if documentID == uuid.Nil {
+			continue
+		}
+		uploads, err := models.FetchUnreviewedUploads(db, documentID)
+		if err != nil {
+			return 0, err
+		}
+		count += len(uploads)
+	}
+	return count, nil
+}
+
+// ReviewUploadHandler records that an office user has looked over an upload via POST /uploads/{uploadId}/review
+type ReviewUploadHandler HandlerContext
+
+// Handle marks an upload as reviewed by the office user making the request
+func (h ReviewUploadHandler) Handle(params officeop.ReviewUploadParams) middleware.Responder {
+	session := auth.SessionFromRequestContext(params.HTTPRequest)
+	if !session.IsOfficeApp() || !session.IsOfficeUser() {
+		return officeop.NewReviewUploadForbidden()
+	}
+
+	// #nosec UUID is pattern matched by swagger and will be ok
+	uploadID, _ := uuid.FromString(params.UploadID.String())
+	upload, err := models.FetchUpload(h.db, session, uploadID)
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+	// Only files which have been scanned clean can be opened to look over
+	if upload.ScanStatus != models.UploadScanStatusCLEAN {
+		return officeop.NewReviewUploadConflict()
+	}
+
+	if upload.ReviewedAt == nil {
+		reviewedAt := time.Now()
+		upload.ReviewedAt = &reviewedAt
+		upload.ReviewerID = &session.OfficeUserID
+		verrs, err := h.db.ValidateAndUpdate(&upload)
+		if err != nil || verrs.HasAny() {
+			return responseForVErrors(h.logger, verrs, err)
+		}
+	}
+
+	accessor := newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest)
+	url, err := accessor.uploadURL(upload.Document, &upload)
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+	previewURL, err := accessor.previewURL(upload.Document, &upload)
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+	return officeop.NewReviewUploadOK().WithPayload(payloadForUploadModel(upload, url, previewURL))
+}
+
 // ApproveReimbursementHandler approves a move via POST /reimbursement/{reimbursementId}/approve
 type ApproveReimbursementHandler HandlerContext
 
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"net/http"
 	"net/http/httptest"
 
 	"github.com/go-openapi/strfmt"
@@ -8,6 +9,7 @@ import (
 	officeop "github.com/transcom/mymove/pkg/gen/internalapi/internaloperations/office"
 	"github.com/transcom/mymove/pkg/gen/internalmessages"
 	"github.com/transcom/mymove/pkg/models"
+	storageTest "github.com/transcom/mymove/pkg/storage/test"
 	"github.com/transcom/mymove/pkg/testdatagen"
 )
 
@@ -37,6 +39,44 @@ func (suite *HandlerSuite) TestApproveMoveHandler() {
 	suite.Assertions.Equal(internalmessages.MoveStatusAPPROVED, okResponse.Payload.Status)
 }
 
+func (suite *HandlerSuite) TestApproveMoveRequiresReviewedUploads() {
+	move, _ := testdatagen.MakeMove(suite.db)
+	orders := move.Orders.UploadedOrders
+	orders.DocumentType = models.DocumentTypeORDERS
+	suite.mustSave(&orders)
+	upload, err := testdatagen.MakeUpload(suite.db, &orders)
+	suite.Nil(err)
+	officeUser, _ := testdatagen.MakeOfficeUser(suite.db)
+	context := NewHandlerContext(suite.db, suite.logger)
+	context.SetFileStorer(storageTest.NewFakeS3Storage(true))
+
+	// The orders haven't been reviewed, so the move can't be approved
+	approveParams := officeop.ApproveMoveParams{
+		HTTPRequest: suite.authenticateOfficeRequest(httptest.NewRequest("POST", "/moves/some_id/approve", nil), officeUser),
+		MoveID:      strfmt.UUID(move.ID.String()),
+	}
+	response := ApproveMoveHandler(context).Handle(approveParams)
+	suite.checkErrorResponse(response, http.StatusConflict, "Conflict")
+
+	// Service members can't review their own uploads
+	reviewParams := officeop.ReviewUploadParams{
+		HTTPRequest: suite.authenticateRequest(httptest.NewRequest("POST", "/uploads/some_id/review", nil), orders.ServiceMember),
+		UploadID:    strfmt.UUID(upload.ID.String()),
+	}
+	response = ReviewUploadHandler(context).Handle(reviewParams)
+	suite.Assertions.IsType(&officeop.ReviewUploadForbidden{}, response)
+
+	reviewParams.HTTPRequest = suite.authenticateOfficeRequest(httptest.NewRequest("POST", "/uploads/some_id/review", nil), officeUser)
+	response = ReviewUploadHandler(context).Handle(reviewParams)
+	suite.Assertions.IsType(&officeop.ReviewUploadOK{}, response)
+	suite.NotNil(response.(*officeop.ReviewUploadOK).Payload.ReviewedAt)
+	suite.Nil(suite.db.Find(&upload, upload.ID))
+	suite.Equal(officeUser.ID, *upload.ReviewerID)
+
+	response = ApproveMoveHandler(context).Handle(approveParams)
+	suite.Assertions.IsType(&officeop.ApproveMoveOK{}, response)
+}
+
 func (suite *HandlerSuite) TestCancelMoveHandler() {
 	// Given: a set of orders, a move, and office user
 	orders, err := testdatagen.MakeOrder(suite.db)
@@ -9,18 +9,17 @@ import (
 	ordersop "github.com/transcom/mymove/pkg/gen/internalapi/internaloperations/orders"
 	"github.com/transcom/mymove/pkg/gen/internalmessages"
 	"github.com/transcom/mymove/pkg/models"
-	"github.com/transcom/mymove/pkg/storage"
 )
 
-func payloadForOrdersModel(storer storage.FileStorer, order models.Order) (*internalmessages.Orders, error) {
-	documentPayload, err := payloadForDocumentModel(storer, order.UploadedOrders)
+func payloadForOrdersModel(accessor *documentAccessor, order models.Order) (*internalmessages.Orders, error) {
+	documentPayload, err := payloadForDocumentModel(accessor, order.UploadedOrders)
 	if err != nil {
 		return nil, err
 	}
 
 	var moves internalmessages.IndexMovesPayload
 	for _, move := range order.Moves {
-		payload, err := payloadForMoveModel(storer, order, move)
+		payload, err := payloadForMoveModel(accessor, order, move)
 		if err != nil {
 			return nil, err
 		}
@@ -97,7 +96,7 @@ func (h CreateOrdersHandler) Handle(params ordersop.CreateOrdersParams) middlewa
 	}
 	newOrder.Moves = append(newOrder.Moves, *newMove)
 
-	orderPayload, err := payloadForOrdersModel(h.storage, newOrder)
+	orderPayload, err := payloadForOrdersModel(newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest), newOrder)
 	if err != nil {
 		return responseForError(h.logger, err)
 	}
@@ -117,7 +116,7 @@ func (h ShowOrdersHandler) Handle(params ordersop.ShowOrdersParams) middleware.R
 		return responseForError(h.logger, err)
 	}
 
-	orderPayload, err := payloadForOrdersModel(h.storage, order)
+	orderPayload, err := payloadForOrdersModel(newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest), order)
 	if err != nil {
 		return responseForError(h.logger, err)
 	}
@@ -170,7 +169,7 @@ func (h UpdateOrdersHandler) Handle(params ordersop.UpdateOrdersParams) middlewa
 		return responseForVErrors(h.logger, verrs, err)
 	}
 
-	orderPayload, err := payloadForOrdersModel(h.storage, order)
+	orderPayload, err := payloadForOrdersModel(newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest), order)
 	if err != nil {
 		return responseForError(h.logger, err)
 	}
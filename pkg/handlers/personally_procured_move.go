@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/swag"
 	"github.com/gobuffalo/uuid"
 	"go.uber.org/zap"
 
@@ -12,13 +13,13 @@ import (
 	"github.com/transcom/mymove/pkg/gen/internalmessages"
 	"github.com/transcom/mymove/pkg/models"
 	"github.com/transcom/mymove/pkg/rateengine"
-	"github.com/transcom/mymove/pkg/storage"
+	"github.com/transcom/mymove/pkg/route"
 	"github.com/transcom/mymove/pkg/unit"
 )
 
-func payloadForPPMModel(storer storage.FileStorer, personallyProcuredMove models.PersonallyProcuredMove) (*internalmessages.PersonallyProcuredMovePayload, error) {
+func payloadForPPMModel(accessor *documentAccessor, personallyProcuredMove models.PersonallyProcuredMove) (*internalmessages.PersonallyProcuredMovePayload, error) {
 
-	documentPayload, err := payloadForDocumentModel(storer, personallyProcuredMove.AdvanceWorksheet)
+	documentPayload, err := payloadForDocumentModel(accessor, personallyProcuredMove.AdvanceWorksheet)
 	if err != nil {
 		return nil, err
 	}
@@ -42,6 +43,9 @@ func payloadForPPMModel(storer storage.FileStorer, personallyProcuredMove models
 		Advance:             payloadForReimbursementModel(personallyProcuredMove.Advance),
 		AdvanceWorksheet:    documentPayload,
 		Mileage:             personallyProcuredMove.Mileage,
+		MileageProvider:     personallyProcuredMove.MileageProvider,
+		MileageProfile:      personallyProcuredMove.MileageProfile,
+		VehicleType:         personallyProcuredMove.VehicleType,
 	}
 	if personallyProcuredMove.IncentiveEstimateMin != nil {
 		min := (*personallyProcuredMove.IncentiveEstimateMin).Int64()
@@ -103,7 +107,7 @@ func (h CreatePersonallyProcuredMoveHandler) Handle(params ppmop.CreatePersonall
 		return responseForVErrors(h.logger, verrs, err)
 	}
 
-	ppmPayload, err := payloadForPPMModel(h.storage, *newPPM)
+	ppmPayload, err := payloadForPPMModel(newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest), *newPPM)
 	if err != nil {
 		return responseForError(h.logger, err)
 	}
@@ -129,8 +133,9 @@ func (h IndexPersonallyProcuredMovesHandler) Handle(params ppmop.IndexPersonally
 	// The given move does belong to the current user.
 	ppms := move.PersonallyProcuredMoves
 	ppmsPayload := make(internalmessages.IndexPersonallyProcuredMovePayload, len(ppms))
+	accessor := newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest)
 	for i, ppm := range ppms {
-		ppmPayload, err := payloadForPPMModel(h.storage, ppm)
+		ppmPayload, err := payloadForPPMModel(accessor, ppm)
 		if err != nil {
 			return responseForError(h.logger, err)
 		}
@@ -145,6 +150,9 @@ func patchPPMWithPayload(ppm *models.PersonallyProcuredMove, payload *internalme
 	if payload.Size != nil {
 		ppm.Size = payload.Size
 	}
+	if payload.VehicleType != nil {
+		ppm.VehicleType = payload.VehicleType
+	}
 	if payload.WeightEstimate != nil {
 		ppm.WeightEstimate = payload.WeightEstimate
 	}
@@ -227,16 +235,20 @@ func (h PatchPersonallyProcuredMoveHandler) Handle(params ppmop.PatchPersonallyP
 	originPtr := params.PatchPersonallyProcuredMovePayload.PickupPostalCode
 	destinationPtr := params.PatchPersonallyProcuredMovePayload.DestinationPostalCode
 	weightPtr := params.PatchPersonallyProcuredMovePayload.WeightEstimate
+	vehicleTypePtr := params.PatchPersonallyProcuredMovePayload.VehicleType
 
 	// Figure out if we have values to compare and, if so, whether the new or old value
 	// should be used in the calculation
 	origin, originChanged, originOK := stringForComparison(ppm.PickupPostalCode, originPtr)
 	destination, destinationChanged, destinationOK := stringForComparison(ppm.DestinationPostalCode, destinationPtr)
 	weight, weightChanged, weightOK := int64ForComparison(ppm.WeightEstimate, weightPtr)
+	vehicleTypeChanged := vehicleTypePtr != nil && (ppm.VehicleType == nil || *ppm.VehicleType != *vehicleTypePtr)
+	previousAdditionalPickup := swag.StringValue(ppm.AdditionalPickupPostalCode)
 
 	patchPPMWithPayload(ppm, params.PatchPersonallyProcuredMovePayload)
 
-	if originOK && destinationOK && weightOK && (originChanged || destinationChanged || weightChanged) {
+	additionalPickupChanged := swag.StringValue(ppm.AdditionalPickupPostalCode) != previousAdditionalPickup
+	if originOK && destinationOK && weightOK && (originChanged || destinationChanged || weightChanged || vehicleTypeChanged || additionalPickupChanged) {
 		h.logger.Info("updating PPM calculated fields",
 			zap.String("originZip", origin),
 			zap.String("destinationZip", destination),
@@ -258,7 +270,7 @@ func (h PatchPersonallyProcuredMoveHandler) Handle(params ppmop.PatchPersonallyP
 		return responseForVErrors(h.logger, verrs, err)
 	}
 
-	ppmPayload, err := payloadForPPMModel(h.storage, *ppm)
+	ppmPayload, err := payloadForPPMModel(newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest), *ppm)
 	if err != nil {
 		return responseForError(h.logger, err)
 	}
@@ -294,29 +306,38 @@ func int64ForComparison(previousValue, newValue *int64) (value int64, valueChang
 	return 0, false, false
 }
 
+// plannerForVehicleType returns a planner which routes the vehicle a PPM is moved in. PPMs without
+// a vehicle type are moved by truck, which is what planner routes.
+func plannerForVehicleType(planner route.Planner, vehicleType *internalmessages.PPMVehicleType) route.Planner {
+	if vehicleType != nil && *vehicleType == internalmessages.PPMVehicleTypeCAR {
+		return route.PlannerForVehicle(planner, route.CarProfile)
+	}
+	return planner
+}
+
 func (h PatchPersonallyProcuredMoveHandler) updateCalculatedFields(ppm *models.PersonallyProcuredMove, newOrigin string, newDestination string) error {
-	re := rateengine.NewRateEngine(h.db, h.logger, h.planner)
+	re := rateengine.NewRateEngine(h.db, h.logger, plannerForVehicleType(h.planner, ppm.VehicleType))
 	daysInSIT := 0
 	if ppm.HasSit != nil && *ppm.HasSit && ppm.DaysInStorage != nil {
 		daysInSIT = int(*ppm.DaysInStorage)
 	}
 
-	lhDiscount, sitDiscount, err := PPMDiscountFetch(h.db, h.logger, newOrigin, newDestination, *ppm.PlannedMoveDate)
+	lhDiscount, sitDiscount, err := rateengine.PPMDiscountFetch(h.db, h.logger, newOrigin, newDestination, *ppm.PlannedMoveDate)
 	if err != nil {
 		return err
 	}
 
-	cost, err := re.ComputePPM(unit.Pound(*ppm.WeightEstimate), newOrigin, newDestination, *ppm.PlannedMoveDate, daysInSIT, lhDiscount, sitDiscount)
+	stops := rateengine.PPMRoute(newOrigin, ppm.AdditionalPickupPostalCode, newDestination)
+	cost, err := re.ComputePPMRoute(unit.Pound(*ppm.WeightEstimate), stops, *ppm.PlannedMoveDate, daysInSIT, lhDiscount, sitDiscount)
 	if err != nil {
 		return err
 	}
 
-	mileage := int64(cost.LinehaulCostComputation.Mileage)
-	ppm.Mileage = &mileage
+	ppm.SetMileage(int64(cost.Mileage), cost.MileageProvider, cost.MileageProfile)
 	ppm.PlannedSITMax = &cost.SITFee
 	ppm.SITMax = &cost.SITMax
-	min := cost.GCC.MultiplyFloat64(0.95)
-	max := cost.GCC.MultiplyFloat64(1.05)
+	min := cost.GCC.MultiplyRate(unit.NewRate(95, 100), unit.RoundHalfUp)
+	max := cost.GCC.MultiplyRate(unit.NewRate(105, 100), unit.RoundHalfUp)
 	ppm.IncentiveEstimateMin = &min
 	ppm.IncentiveEstimateMax = &max
 
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/swag"
+
+	ppmop "github.com/transcom/mymove/pkg/gen/internalapi/internaloperations/ppm"
+	"github.com/transcom/mymove/pkg/gen/internalmessages"
+	"github.com/transcom/mymove/pkg/rateengine"
+	"github.com/transcom/mymove/pkg/unit"
+)
+
+// ShowPPMMoveDateEstimatesHandler returns PPM estimates for the days around a planned move date
+type ShowPPMMoveDateEstimatesHandler HandlerContext
+
+// Handle calculates a PPM reimbursement range for each candidate move date.
+func (h ShowPPMMoveDateEstimatesHandler) Handle(params ppmop.ShowPPMMoveDateEstimatesParams) middleware.Responder {
+	engine := rateengine.NewRateEngine(h.db, h.logger, h.planner)
+
+	windowDays := 7
+	if params.WindowDays != nil {
+		windowDays = int(*params.WindowDays)
+	}
+
+	estimates, err := engine.ComputePPMMoveDates(unit.Pound(params.WeightEstimate),
+		params.OriginZip,
+		params.DestinationZip,
+		time.Time(params.PlannedMoveDate),
+		windowDays,
+	)
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+
+	payload := internalmessages.PPMMoveDateEstimates{
+		Estimates: []*internalmessages.PPMMoveDateEstimate{},
+	}
+	for _, estimate := range estimates {
+		payload.Estimates = append(payload.Estimates, &internalmessages.PPMMoveDateEstimate{
+			MoveDate:         fmtDate(estimate.MoveDate),
+			IsPeak:           swag.Bool(estimate.PeakRateCycle),
+			CodeOfService:    swag.String(estimate.CodeOfService),
+			LinehaulDiscount: swag.Float64(estimate.LinehaulDiscount.Float64()),
+			RangeMin:         swag.Int64(estimate.IncentiveEstimateMin.Int64()),
+			RangeMax:         swag.Int64(estimate.IncentiveEstimateMax.Int64()),
+		})
+	}
+	return ppmop.NewShowPPMMoveDateEstimatesOK().WithPayload(&payload)
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"time"
+
+	ppmop "github.com/transcom/mymove/pkg/gen/internalapi/internaloperations/ppm"
+	"github.com/transcom/mymove/pkg/route"
+	"github.com/transcom/mymove/pkg/testdatagen"
+	"github.com/transcom/mymove/pkg/testdatagen/scenario"
+)
+
+func (suite *HandlerSuite) TestShowPPMMoveDateEstimatesHandler() {
+	if err := scenario.RunRateEngineScenario1(suite.db); err != nil {
+		suite.FailNow("failed to run scenario 1: %+v", err)
+	}
+
+	user, _ := testdatagen.MakeServiceMember(suite.db)
+
+	req := httptest.NewRequest("GET", "/estimates/ppm_move_dates", nil)
+	req = suite.authenticateRequest(req, user)
+
+	windowDays := int64(1)
+	params := ppmop.ShowPPMMoveDateEstimatesParams{
+		HTTPRequest:     req,
+		PlannedMoveDate: *fmtDate(time.Date(2018, time.September, 30, 0, 0, 0, 0, time.UTC)),
+		OriginZip:       "32168",
+		DestinationZip:  "29429",
+		WeightEstimate:  4000,
+		WindowDays:      &windowDays,
+	}
+
+	context := NewHandlerContext(suite.db, suite.logger)
+	context.SetPlanner(route.NewTestingPlanner(362))
+	showHandler := ShowPPMMoveDateEstimatesHandler(context)
+	showResponse := showHandler.Handle(params)
+
+	okResponse := showResponse.(*ppmop.ShowPPMMoveDateEstimatesOK)
+	estimates := okResponse.Payload.Estimates
+
+	suite.Len(estimates, 3)
+	suite.True(*estimates[1].IsPeak, "September 30th should be in the peak rate cycle")
+	suite.False(*estimates[2].IsPeak, "October 1st should be in the non-peak rate cycle")
+	suite.Equal("2", *estimates[2].CodeOfService)
+	suite.Equal(int64(250135), *estimates[2].RangeMin, "RangeMin was not equal")
+	suite.Equal(int64(276465), *estimates[2].RangeMax, "RangeMax was not equal")
+}
+
+func (suite *HandlerSuite) TestShowPPMMoveDateEstimatesHandlerNoRates() {
+	if err := scenario.RunRateEngineScenario1(suite.db); err != nil {
+		suite.FailNow("failed to run scenario 1: %+v", err)
+	}
+
+	// Scenario 1 has no discounts in the 2019 peak rate cycle
+	user, _ := testdatagen.MakeServiceMember(suite.db)
+
+	req := httptest.NewRequest("GET", "/estimates/ppm_move_dates", nil)
+	req = suite.authenticateRequest(req, user)
+
+	params := ppmop.ShowPPMMoveDateEstimatesParams{
+		HTTPRequest:     req,
+		PlannedMoveDate: *fmtDate(scenario.May15_2019),
+		OriginZip:       "32168",
+		DestinationZip:  "29429",
+		WeightEstimate:  4000,
+	}
+
+	context := NewHandlerContext(suite.db, suite.logger)
+	context.SetPlanner(route.NewTestingPlanner(362))
+	showHandler := ShowPPMMoveDateEstimatesHandler(context)
+	showResponse := showHandler.Handle(params)
+
+	suite.checkResponseNotFound(showResponse)
+}
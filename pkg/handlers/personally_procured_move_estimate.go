@@ -16,9 +16,10 @@ type ShowPPMEstimateHandler HandlerContext
 
 // Handle calculates a PPM reimbursement range.
 func (h ShowPPMEstimateHandler) Handle(params ppmop.ShowPPMEstimateParams) middleware.Responder {
-	engine := rateengine.NewRateEngine(h.db, h.logger, h.planner)
+	engine := rateengine.NewRateEngine(h.db, h.logger,
+		plannerForVehicleType(h.planner, (*internalmessages.PPMVehicleType)(params.VehicleType)))
 
-	lhDiscount, _, err := PPMDiscountFetch(h.db,
+	lhDiscount, _, err := rateengine.PPMDiscountFetch(h.db,
 		h.logger,
 		params.OriginZip,
 		params.DestinationZip,
@@ -28,9 +29,8 @@ func (h ShowPPMEstimateHandler) Handle(params ppmop.ShowPPMEstimateParams) middl
 		return responseForError(h.logger, err)
 	}
 
-	cost, err := engine.ComputePPM(unit.Pound(params.WeightEstimate),
-		params.OriginZip,
-		params.DestinationZip,
+	cost, err := engine.ComputePPMRoute(unit.Pound(params.WeightEstimate),
+		rateengine.PPMRoute(params.OriginZip, params.AdditionalPickupZip, params.DestinationZip),
 		time.Time(params.PlannedMoveDate),
 		0, // We don't want any SIT charges
 		lhDiscount,
@@ -41,8 +41,8 @@ func (h ShowPPMEstimateHandler) Handle(params ppmop.ShowPPMEstimateParams) middl
 		return responseForError(h.logger, err)
 	}
 
-	min := cost.GCC.MultiplyFloat64(0.95)
-	max := cost.GCC.MultiplyFloat64(1.05)
+	min := cost.GCC.MultiplyRate(unit.NewRate(95, 100), unit.RoundHalfUp)
+	max := cost.GCC.MultiplyRate(unit.NewRate(105, 100), unit.RoundHalfUp)
 
 	ppmEstimate := internalmessages.PPMEstimateRange{
 		RangeMin: swag.Int64(min.Int64()),
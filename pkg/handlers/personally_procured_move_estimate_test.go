@@ -9,6 +9,7 @@ import (
 	"github.com/transcom/mymove/pkg/route"
 	"github.com/transcom/mymove/pkg/testdatagen"
 	"github.com/transcom/mymove/pkg/testdatagen/scenario"
+	"github.com/transcom/mymove/pkg/unit"
 )
 
 func (suite *HandlerSuite) TestShowPPMEstimateHandler() {
@@ -74,3 +75,63 @@ func (suite *HandlerSuite) TestShowPPMEstimateHandlerLowWeight() {
 	suite.Equal(int64(256739), *cost.RangeMin, "RangeMin was not equal")
 	suite.Equal(int64(283765), *cost.RangeMax, "RangeMax was not equal")
 }
+
+// additionalPickupPlanner routes directly with its Planner, but says routes through waypoints are
+// longer, as they would be with a stop on the way
+type additionalPickupPlanner struct {
+	route.Planner
+	legs []int
+}
+
+func (p additionalPickupPlanner) WaypointsTransitDistance(waypoints []*models.Address) (route.RouteDistance, error) {
+	distance := route.RouteDistance{Legs: p.legs}
+	for _, leg := range p.legs {
+		distance.Total += leg
+	}
+	return distance, nil
+}
+
+func (suite *HandlerSuite) TestShowPPMEstimateHandlerAdditionalPickup() {
+	if err := scenario.RunRateEngineScenario2(suite.db); err != nil {
+		suite.FailNow("failed to run scenario 2: %+v", err)
+	}
+	// The route with the additional pickup is long enough to be in the next mileage band
+	suite.mustSave(&models.Tariff400ngLinehaulRate{
+		DistanceMilesLower: 1801,
+		DistanceMilesUpper: 2001,
+		Type:               "ConusLinehaul",
+		WeightLbsLower:     7400,
+		WeightLbsUpper:     7600,
+		RateCents:          unit.Cents(1377900),
+		EffectiveDateLower: scenario.May15_2018,
+		EffectiveDateUpper: scenario.May15_2019,
+	})
+
+	user, _ := testdatagen.MakeServiceMember(suite.db)
+	req := httptest.NewRequest("GET", "/estimates/ppm", nil)
+	req = suite.authenticateRequest(req, user)
+
+	additionalPickup := "94608"
+	params := ppmop.ShowPPMEstimateParams{
+		HTTPRequest:         req,
+		PlannedMoveDate:     *fmtDate(scenario.May15_2018),
+		OriginZip:           "94540",
+		AdditionalPickupZip: &additionalPickup,
+		DestinationZip:      "78626",
+		WeightEstimate:      7500,
+	}
+
+	context := NewHandlerContext(suite.db, suite.logger)
+	context.SetPlanner(additionalPickupPlanner{Planner: route.NewTestingPlanner(1693), legs: []int{20, 1880}})
+	showResponse := ShowPPMEstimateHandler(context).Handle(params)
+
+	okResponse, ok := showResponse.(*ppmop.ShowPPMEstimateOK)
+	if !ok {
+		suite.FailNow("Request failed", "%#v", showResponse)
+	}
+	cost := okResponse.Payload
+
+	// The same move without the additional pickup is estimated at 605203 to 668909
+	suite.True(*cost.RangeMin > int64(605203), "RangeMin %d doesn't include the additional pickup", *cost.RangeMin)
+	suite.True(*cost.RangeMax > int64(668909), "RangeMax %d doesn't include the additional pickup", *cost.RangeMax)
+}
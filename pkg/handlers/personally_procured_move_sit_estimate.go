@@ -22,7 +22,7 @@ func (h ShowPPMSitEstimateHandler) Handle(params ppmop.ShowPPMSitEstimateParams)
 	cwtWeight := unit.Pound(params.WeightEstimate).ToCWT()
 	plannedMoveDateTime := time.Time(params.PlannedMoveDate)
 
-	_, sitDiscount, err := PPMDiscountFetch(h.db,
+	_, sitDiscount, err := rateengine.PPMDiscountFetch(h.db,
 		h.logger,
 		params.OriginZip,
 		params.DestinationZip,
@@ -157,6 +157,7 @@ func (suite *HandlerSuite) TestPatchPPMHandler() {
 		ServiceMember:   move.Orders.ServiceMember,
 		ServiceMemberID: move.Orders.ServiceMemberID,
 		Name:            "uploaded_document",
+		DocumentType:    models.DocumentTypeADVANCEWORKSHEET,
 	}
 	suite.mustSave(&newAdvanceWorksheet)
 
@@ -196,7 +197,9 @@ func (suite *HandlerSuite) TestPatchPPMHandler() {
 	}
 
 	handler := PatchPersonallyProcuredMoveHandler(NewHandlerContext(suite.db, suite.logger))
-	handler.planner = route.NewTestingPlanner(900)
+	handler.planner = route.NewFallbackPlanner(suite.logger, []route.PlannerProvider{
+		{Name: "offline", Planner: route.NewTestingPlanner(900)},
+	}, route.DefaultCircuitFailureThreshold, route.DefaultCircuitCooldown, route.DefaultDisagreementTolerance)
 	response := handler.Handle(patchPPMParams)
 
 	// assert we got back the 201 response
@@ -212,6 +215,7 @@ func (suite *HandlerSuite) TestPatchPPMHandler() {
 	suite.Equal(*(*time.Time)(patchPPMPayload.PlannedMoveDate), newMoveDate, "MoveDate should have been updated.")
 	suite.Nil(patchPPMPayload.DaysInStorage, "AdditionalPostalCode should have been updated to nil.")
 	suite.Equal(*patchPPMPayload.Mileage, int64(900), "Mileage should have been set to 900")
+	suite.Equal("offline", *patchPPMPayload.MileageProvider, "The planner which answered should have been recorded")
 }
 
 func (suite *HandlerSuite) TestPatchPPMHandlerSetWeightLater() {
@@ -274,6 +278,62 @@ func (suite *HandlerSuite) TestPatchPPMHandlerSetWeightLater() {
 	suite.Assertions.Equal(int64(97785), *patchPPMPayload.SitMax)
 }
 
+// carAwarePlanner routes trucks with its embedded Planner and cars with car
+type carAwarePlanner struct {
+	route.Planner
+	car route.Planner
+}
+
+func (p carAwarePlanner) Profile() route.VehicleProfile {
+	return route.DefaultTruckProfile
+}
+
+func (p carAwarePlanner) ForVehicle(profile route.VehicleProfile) route.Planner {
+	if profile.Type == route.VehicleCar {
+		return p.car
+	}
+	return p
+}
+
+func (suite *HandlerSuite) TestPatchPPMHandlerCarRouting() {
+	scenario.RunRateEngineScenario1(suite.db)
+
+	moveDate := time.Now()
+	move, _ := testdatagen.MakeMove(suite.db)
+	ppm1 := models.PersonallyProcuredMove{
+		MoveID:                move.ID,
+		Move:                  move,
+		PlannedMoveDate:       &moveDate,
+		WeightEstimate:        swag.Int64(4100),
+		PickupPostalCode:      swag.String("32168"),
+		DestinationPostalCode: swag.String("29400"),
+		Status:                models.PPMStatusDRAFT,
+	}
+	suite.mustSave(&ppm1)
+
+	req := httptest.NewRequest("GET", "/fake/path", nil)
+	req = suite.authenticateRequest(req, move.Orders.ServiceMember)
+
+	car := internalmessages.PPMVehicleTypeCAR
+	patchPPMParams := ppmop.PatchPersonallyProcuredMoveParams{
+		HTTPRequest:              req,
+		MoveID:                   strfmt.UUID(move.ID.String()),
+		PersonallyProcuredMoveID: strfmt.UUID(ppm1.ID.String()),
+		PatchPersonallyProcuredMovePayload: &internalmessages.PatchPersonallyProcuredMovePayload{
+			VehicleType: &car,
+		},
+	}
+
+	handler := PatchPersonallyProcuredMoveHandler(NewHandlerContext(suite.db, suite.logger))
+	handler.planner = carAwarePlanner{Planner: route.NewTestingPlanner(900), car: route.NewTestingPlanner(850)}
+	response := handler.Handle(patchPPMParams)
+
+	// Changing only the vehicle type recalculates the mileage with car routing
+	okResponse := response.(*ppmop.PatchPersonallyProcuredMoveCreated)
+	suite.Equal(car, *okResponse.Payload.VehicleType)
+	suite.Equal(int64(850), *okResponse.Payload.Mileage)
+}
+
 func (suite *HandlerSuite) TestPatchPPMHandlerWrongUser() {
 	initialSize := internalmessages.TShirtSize("S")
 	newSize := internalmessages.TShirtSize("L")
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/swag"
+	"github.com/gobuffalo/pop"
+
+	postalcodeop "github.com/transcom/mymove/pkg/gen/internalapi/internaloperations/postal_codes"
+	"github.com/transcom/mymove/pkg/gen/internalmessages"
+	"github.com/transcom/mymove/pkg/models"
+)
+
+func payloadForZip5LocationModel(location models.Zip5Location) *internalmessages.PostalCodeLocation {
+	return &internalmessages.PostalCodeLocation{
+		PostalCode: swag.String(location.Zip5),
+		City:       swag.String(location.City),
+		State:      swag.String(location.State),
+		County:     swag.String(location.County),
+		Latitude:   swag.Float32(location.Latitude),
+		Longitude:  swag.Float32(location.Longitude),
+	}
+}
+
+// geoPointForPostalCode returns the location of a ZIP5, or models.ErrFetchNotFound if it doesn't exist
+func geoPointForPostalCode(db *pop.Connection, postalCode string) (models.GeoPoint, error) {
+	location, err := models.FetchZip5Location(db, postalCode)
+	if err != nil {
+		return models.GeoPoint{}, err
+	}
+	return models.GeoPoint{Latitude: float64(location.Latitude), Longitude: float64(location.Longitude)}, nil
+}
+
+// ShowPostalCodeHandler returns the location of a ZIP5
+type ShowPostalCodeHandler HandlerContext
+
+// Handle looks up a ZIP5, returning a 404 if it doesn't exist
+func (h ShowPostalCodeHandler) Handle(params postalcodeop.ShowPostalCodeParams) middleware.Responder {
+	location, err := models.FetchZip5Location(h.db, params.PostalCode)
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+	return postalcodeop.NewShowPostalCodeOK().WithPayload(payloadForZip5LocationModel(location))
+}
+
+// SearchPostalCodesHandler returns the ZIP5s starting with a prefix
+type SearchPostalCodesHandler HandlerContext
+
+// Handle returns the matching ZIP5s, in order
+func (h SearchPostalCodesHandler) Handle(params postalcodeop.SearchPostalCodesParams) middleware.Responder {
+	limit := 10
+	if params.Limit != nil {
+		limit = int(*params.Limit)
+	}
+
+	locations, err := models.SearchZip5Locations(h.db, params.Search, limit)
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+
+	payload := make(internalmessages.PostalCodeLocations, len(locations))
+	for i, location := range locations {
+		payload[i] = payloadForZip5LocationModel(location)
+	}
+	return postalcodeop.NewSearchPostalCodesOK().WithPayload(payload)
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	postalcodeop "github.com/transcom/mymove/pkg/gen/internalapi/internaloperations/postal_codes"
+	"github.com/transcom/mymove/pkg/models"
+)
+
+func (suite *HandlerSuite) TestShowPostalCodeHandler() {
+	suite.Nil(models.SaveZip5Location(suite.db, models.Zip5Location{
+		Zip5: "94103", City: "San Francisco", State: "CA", County: "San Francisco", Latitude: 37.775678, Longitude: -122.412131,
+	}))
+	handler := ShowPostalCodeHandler(NewHandlerContext(suite.db, suite.logger))
+
+	params := postalcodeop.ShowPostalCodeParams{
+		HTTPRequest: httptest.NewRequest("GET", "/postal_codes/94103", nil),
+		PostalCode:  "94103",
+	}
+	response := handler.Handle(params)
+
+	suite.Assertions.IsType(&postalcodeop.ShowPostalCodeOK{}, response)
+	payload := response.(*postalcodeop.ShowPostalCodeOK).Payload
+	suite.Equal("94103", *payload.PostalCode)
+	suite.Equal("San Francisco", *payload.City)
+	suite.Equal("CA", *payload.State)
+	suite.Equal(float32(37.775678), *payload.Latitude)
+
+	params = postalcodeop.ShowPostalCodeParams{
+		HTTPRequest: httptest.NewRequest("GET", "/postal_codes/00001", nil),
+		PostalCode:  "00001",
+	}
+	response = handler.Handle(params)
+	errResponse := response.(*errResponse)
+	suite.Equal(http.StatusNotFound, errResponse.code)
+}
+
+func (suite *HandlerSuite) TestSearchPostalCodesHandler() {
+	for _, zip5 := range []string{"94107", "94103", "20301"} {
+		suite.Nil(models.SaveZip5Location(suite.db, models.Zip5Location{Zip5: zip5}))
+	}
+	handler := SearchPostalCodesHandler(NewHandlerContext(suite.db, suite.logger))
+
+	params := postalcodeop.SearchPostalCodesParams{
+		HTTPRequest: httptest.NewRequest("GET", "/postal_codes?search=941", nil),
+		Search:      "941",
+	}
+	response := handler.Handle(params)
+
+	suite.Assertions.IsType(&postalcodeop.SearchPostalCodesOK{}, response)
+	payload := response.(*postalcodeop.SearchPostalCodesOK).Payload
+	if suite.Len(payload, 2) {
+		suite.Equal("94103", *payload[0].PostalCode)
+		suite.Equal("94107", *payload[1].PostalCode)
+	}
+
+	limit := int64(1)
+	params.Limit = &limit
+	response = handler.Handle(params)
+	suite.Len(response.(*postalcodeop.SearchPostalCodesOK).Payload, 1)
+}
@@ -4,21 +4,21 @@ import (
 	"github.com/go-openapi/runtime/middleware"
 	"github.com/gobuffalo/uuid"
 	"github.com/gobuffalo/validate"
+	"github.com/pkg/errors"
 
 	"github.com/transcom/mymove/pkg/auth"
 	servicememberop "github.com/transcom/mymove/pkg/gen/internalapi/internaloperations/service_members"
 	"github.com/transcom/mymove/pkg/gen/internalmessages"
 	"github.com/transcom/mymove/pkg/models"
-	"github.com/transcom/mymove/pkg/storage"
 )
 
-func payloadForServiceMemberModel(storer storage.FileStorer, serviceMember models.ServiceMember) *internalmessages.ServiceMemberPayload {
+func payloadForServiceMemberModel(accessor *documentAccessor, serviceMember models.ServiceMember) *internalmessages.ServiceMemberPayload {
 
 	var dutyStationPayload *internalmessages.DutyStationPayload
 	dutyStationPayload = payloadForDutyStationModel(serviceMember.DutyStation)
 	orders := make([]*internalmessages.Orders, len(serviceMember.Orders))
 	for i, order := range serviceMember.Orders {
-		orderPayload, _ := payloadForOrdersModel(storer, order)
+		orderPayload, _ := payloadForOrdersModel(accessor, order)
 		orders[i] = orderPayload
 	}
 
@@ -62,8 +62,18 @@ type CreateServiceMemberHandler HandlerContext
 
 // Handle ... creates a new ServiceMember from a request payload
 func (h CreateServiceMemberHandler) Handle(params servicememberop.CreateServiceMemberParams) middleware.Responder {
-	residentialAddress := addressModelFromPayload(params.CreateServiceMemberPayload.ResidentialAddress)
-	backupMailingAddress := addressModelFromPayload(params.CreateServiceMemberPayload.BackupMailingAddress)
+	addressVerrs := validate.NewErrors()
+	residentialAddress, err := addressFromPayload(h.addressValidator, "residential_address", nil, params.CreateServiceMemberPayload.ResidentialAddress, addressVerrs)
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+	backupMailingAddress, err := addressFromPayload(h.addressValidator, "backup_mailing_address", nil, params.CreateServiceMemberPayload.BackupMailingAddress, addressVerrs)
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+	if addressVerrs.HasAny() {
+		return responseForVErrors(h.logger, addressVerrs, errors.Errorf("invalid address: %s", addressVerrs))
+	}
 
 	ssnString := params.CreateServiceMemberPayload.SocialSecurityNumber
 	var ssn *models.SocialSecurityNumber
@@ -134,7 +144,7 @@ func (h CreateServiceMemberHandler) Handle(params servicememberop.CreateServiceM
 		session.LastName = *(newServiceMember.LastName)
 	}
 	// And return
-	serviceMemberPayload := payloadForServiceMemberModel(h.storage, newServiceMember)
+	serviceMemberPayload := payloadForServiceMemberModel(newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest), newServiceMember)
 	responder := servicememberop.NewCreateServiceMemberCreated().WithPayload(serviceMemberPayload)
 	return NewCookieUpdateResponder(params.HTTPRequest, h.cookieSecret, h.noSessionTimeout, h.logger, responder)
 }
@@ -152,7 +162,7 @@ func (h ShowServiceMemberHandler) Handle(params servicememberop.ShowServiceMembe
 		return responseForError(h.logger, err)
 	}
 
-	serviceMemberPayload := payloadForServiceMemberModel(h.storage, serviceMember)
+	serviceMemberPayload := payloadForServiceMemberModel(newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest), serviceMember)
 	return servicememberop.NewShowServiceMemberOK().WithPayload(serviceMemberPayload)
 }
 
@@ -177,7 +187,7 @@ func (h PatchServiceMemberHandler) Handle(params servicememberop.PatchServiceMem
 		return responseForVErrors(h.logger, verrs, err)
 	}
 
-	serviceMemberPayload := payloadForServiceMemberModel(h.storage, serviceMember)
+	serviceMemberPayload := payloadForServiceMemberModel(newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest), serviceMember)
 	return servicememberop.NewPatchServiceMemberOK().WithPayload(serviceMemberPayload)
 }
 
@@ -244,19 +254,19 @@ func (h PatchServiceMemberHandler) patchServiceMemberWithPayload(serviceMember *
 			return verrs, err
 		}
 	}
-	if payload.ResidentialAddress != nil {
-		if serviceMember.ResidentialAddress == nil {
-			serviceMember.ResidentialAddress = addressModelFromPayload(payload.ResidentialAddress)
-		} else {
-			updateAddressWithPayload(serviceMember.ResidentialAddress, payload.ResidentialAddress)
-		}
+	addressVerrs := validate.NewErrors()
+	residentialAddress, err := addressFromPayload(h.addressValidator, "residential_address", serviceMember.ResidentialAddress, payload.ResidentialAddress, addressVerrs)
+	if err != nil {
+		return addressVerrs, err
 	}
-	if payload.BackupMailingAddress != nil {
-		if serviceMember.BackupMailingAddress == nil {
-			serviceMember.BackupMailingAddress = addressModelFromPayload(payload.BackupMailingAddress)
-		} else {
-			updateAddressWithPayload(serviceMember.BackupMailingAddress, payload.BackupMailingAddress)
-		}
+	serviceMember.ResidentialAddress = residentialAddress
+	backupMailingAddress, err := addressFromPayload(h.addressValidator, "backup_mailing_address", serviceMember.BackupMailingAddress, payload.BackupMailingAddress, addressVerrs)
+	if err != nil {
+		return addressVerrs, err
+	}
+	serviceMember.BackupMailingAddress = backupMailingAddress
+	if addressVerrs.HasAny() {
+		return addressVerrs, errors.Errorf("invalid address: %s", addressVerrs)
 	}
 
 	return validate.NewErrors(), nil
@@ -280,7 +290,7 @@ func (h ShowServiceMemberOrdersHandler) Handle(params servicememberop.ShowServic
 		return responseForError(h.logger, err)
 	}
 
-	orderPayload, err := payloadForOrdersModel(h.storage, order)
+	orderPayload, err := payloadForOrdersModel(newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest), order)
 	if err != nil {
 		return responseForError(h.logger, err)
 	}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/go-openapi/runtime/middleware"
+
+	"github.com/transcom/mymove/pkg/auth"
+	officeop "github.com/transcom/mymove/pkg/gen/internalapi/internaloperations/office"
+	"github.com/transcom/mymove/pkg/tariffmap"
+)
+
+// ShowZip3MapHandler returns the ZIP3 tariff areas as GeoJSON via GET /tariff/zip3_map
+type ShowZip3MapHandler HandlerContext
+
+// Handle returns a GeoJSON FeatureCollection of ZIP3 centroids grouped by rate area, region or service area
+func (h ShowZip3MapHandler) Handle(params officeop.ShowZip3MapParams) middleware.Responder {
+	session := auth.SessionFromRequestContext(params.HTTPRequest)
+
+	if !session.IsOfficeUser() {
+		return officeop.NewShowZip3MapUnauthorized()
+	}
+
+	groupBy := tariffmap.GroupByRateArea
+	if params.GroupBy != nil {
+		groupBy = tariffmap.GroupBy(*params.GroupBy)
+	}
+	date := time.Now()
+	if params.Date != nil {
+		date = time.Time(*params.Date)
+	}
+
+	collection, err := tariffmap.Fetch(h.db, groupBy, date)
+	if err == tariffmap.ErrInvalidGroupBy {
+		return officeop.NewShowZip3MapBadRequest()
+	} else if err != nil {
+		return responseForError(h.logger, err)
+	}
+
+	return officeop.NewShowZip3MapOK().WithPayload(collection)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http/httptest"
+
+	"github.com/go-openapi/swag"
+
+	officeop "github.com/transcom/mymove/pkg/gen/internalapi/internaloperations/office"
+	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/tariffmap"
+	"github.com/transcom/mymove/pkg/testdatagen"
+)
+
+func (suite *HandlerSuite) TestShowZip3MapHandler() {
+	suite.mustSave(&models.Tariff400ngZip3{Zip3: "941", BasepointCity: "San Francisco", State: "CA", ServiceArea: "80", RateArea: "US87", Region: "2"})
+	suite.Nil(models.SaveZip5Location(suite.db, models.Zip5Location{Zip5: "94103", Latitude: 37.77, Longitude: -122.41}))
+	officeUser, _ := testdatagen.MakeOfficeUser(suite.db)
+
+	req := httptest.NewRequest("GET", "/tariff/zip3_map", nil)
+	req = suite.authenticateOfficeRequest(req, officeUser)
+	params := officeop.ShowZip3MapParams{
+		HTTPRequest: req,
+		GroupBy:     swag.String("region"),
+	}
+	handler := ShowZip3MapHandler(NewHandlerContext(suite.db, suite.logger))
+	response := handler.Handle(params)
+
+	suite.Assertions.IsType(&officeop.ShowZip3MapOK{}, response)
+	collection, ok := response.(*officeop.ShowZip3MapOK).Payload.(tariffmap.FeatureCollection)
+	suite.True(ok)
+	suite.Len(collection.Features, 1)
+	suite.Equal("2", collection.Features[0].Properties["group"])
+	suite.Equal([2]float64{-122.41, 37.77}, collection.Features[0].Geometry.Coordinates)
+}
+
+func (suite *HandlerSuite) TestShowZip3MapHandlerRequiresOfficeUser() {
+	serviceMember, _ := testdatagen.MakeServiceMember(suite.db)
+
+	req := httptest.NewRequest("GET", "/tariff/zip3_map", nil)
+	req = suite.authenticateRequest(req, serviceMember)
+	params := officeop.ShowZip3MapParams{HTTPRequest: req}
+	handler := ShowZip3MapHandler(NewHandlerContext(suite.db, suite.logger))
+	response := handler.Handle(params)
+
+	suite.Assertions.IsType(&officeop.ShowZip3MapUnauthorized{}, response)
+}
@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"net/http"
+
 	"github.com/go-openapi/runtime/middleware"
 	"github.com/go-openapi/swag"
 	"github.com/gobuffalo/uuid"
+	"github.com/pkg/errors"
 
 	transportationofficeop "github.com/transcom/mymove/pkg/gen/internalapi/internaloperations/transportation_offices"
 	"github.com/transcom/mymove/pkg/gen/internalmessages"
@@ -25,6 +28,19 @@ func payloadForTransportationOfficeModel(office models.TransportationOffice) *in
 		Name:       swag.String(office.Name),
 		Address:    payloadForAddressModel(&office.Address),
 		PhoneLines: phoneLines,
+		Latitude:   office.Latitude,
+		Longitude:  office.Longitude,
+	}
+	return payload
+}
+
+func payloadForTransportationOfficeSearchResults(results []models.TransportationOfficeSearchResult) internalmessages.TransportationOfficeDistances {
+	payload := make(internalmessages.TransportationOfficeDistances, len(results))
+	for i, result := range results {
+		payload[i] = &internalmessages.TransportationOfficeDistance{
+			TransportationOffice: payloadForTransportationOfficeModel(result.TransportationOffice),
+			DistanceMiles:        result.Miles,
+		}
 	}
 	return payload
 }
@@ -43,3 +59,70 @@ func (h ShowDutyStationTransportationOfficeHandler) Handle(params transportation
 
 	return transportationofficeop.NewShowDutyStationTransportationOfficeOK().WithPayload(transportationOfficePayload)
 }
+
+// SearchTransportationOfficesHandler returns the transportation offices matching a search query
+type SearchTransportationOfficesHandler HandlerContext
+
+// Handle returns the matching transportation offices, best match first. Offices near the postal code,
+// if one is given, rank higher.
+func (h SearchTransportationOfficesHandler) Handle(params transportationofficeop.SearchTransportationOfficesParams) middleware.Responder {
+	limit := models.DefaultSearchLimit
+	if params.Limit != nil {
+		limit = int(*params.Limit)
+	}
+
+	var near *models.GeoPoint
+	if params.PostalCode != nil {
+		point, err := geoPointForPostalCode(h.db, *params.PostalCode)
+		if err == nil {
+			near = &point
+		} else if err != models.ErrFetchNotFound {
+			return responseForError(h.logger, err)
+		}
+		// An unknown postal code only loses the proximity ranking
+	}
+
+	results, err := models.SearchTransportationOffices(h.db, params.Search, near, limit)
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+	return transportationofficeop.NewSearchTransportationOfficesOK().WithPayload(payloadForTransportationOfficeSearchResults(results))
+}
+
+// ShowNearestTransportationOfficesHandler returns the transportation offices nearest to a ZIP or duty station
+type ShowNearestTransportationOfficesHandler HandlerContext
+
+// Handle returns the nearest transportation offices, nearest first
+func (h ShowNearestTransportationOfficesHandler) Handle(params transportationofficeop.ShowNearestTransportationOfficesParams) middleware.Responder {
+	if (params.PostalCode == nil) == (params.DutyStationID == nil) {
+		return newErrResponse(http.StatusBadRequest, errors.New("exactly one of postal_code and duty_station_id is required"))
+	}
+	limit := 5
+	if params.Limit != nil {
+		limit = int(*params.Limit)
+	}
+
+	postalCode := params.PostalCode
+	if params.DutyStationID != nil {
+		dutyStationID, _ := uuid.FromString(params.DutyStationID.String())
+		station, err := models.FetchDutyStation(h.db, dutyStationID)
+		if err != nil {
+			return responseForError(h.logger, err)
+		}
+		zip5 := station.Address.PostalCode
+		if len(zip5) > 5 {
+			zip5 = zip5[:5]
+		}
+		postalCode = &zip5
+	}
+
+	near, err := geoPointForPostalCode(h.db, *postalCode)
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+	results, err := models.FetchNearestTransportationOffices(h.db, near, limit)
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+	return transportationofficeop.NewShowNearestTransportationOfficesOK().WithPayload(payloadForTransportationOfficeSearchResults(results))
+}
@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 
+	"github.com/go-openapi/swag"
+
 	transportationofficeop "github.com/transcom/mymove/pkg/gen/internalapi/internaloperations/transportation_offices"
 	"github.com/transcom/mymove/pkg/gen/internalmessages"
 	"github.com/transcom/mymove/pkg/models"
@@ -53,3 +55,76 @@ func (suite *HandlerSuite) TestShowDutyStationTransportationOfficeHandlerNoOffic
 
 	suite.Assertions.Equal(http.StatusNotFound, errResponse.code)
 }
+
+func (suite *HandlerSuite) TestShowNearestTransportationOfficesHandler() {
+	suite.Nil(models.SaveZip5Location(suite.db, models.Zip5Location{Zip5: "28310", Latitude: 35.1409, Longitude: -79.0061}))
+	station, _ := testdatagen.MakeDutyStation(suite.db, "Fort Bragg", internalmessages.AffiliationARMY,
+		models.Address{StreetAddress1: "duty station", City: "Fort Bragg", State: "NC", PostalCode: "28310-1234"})
+	for _, office := range []models.TransportationOffice{
+		{Name: "PPPO Fort Bliss", Latitude: 31.8134, Longitude: -106.4215},
+		{Name: "PPPO Fort Bragg", Latitude: 35.1409, Longitude: -79.0061},
+	} {
+		address, _ := testdatagen.MakeAddress(suite.db)
+		office.AddressID = address.ID
+		suite.mustSave(&office)
+	}
+	handler := ShowNearestTransportationOfficesHandler(NewHandlerContext(suite.db, suite.logger))
+
+	params := transportationofficeop.ShowNearestTransportationOfficesParams{
+		HTTPRequest: httptest.NewRequest("GET", "/transportation_offices/nearest?postal_code=28310", nil),
+		PostalCode:  swag.String("28310"),
+	}
+	response := handler.Handle(params)
+	suite.Assertions.IsType(&transportationofficeop.ShowNearestTransportationOfficesOK{}, response)
+	payload := response.(*transportationofficeop.ShowNearestTransportationOfficesOK).Payload
+	// The office made for the duty station has no real location, so it comes after Fort Bliss
+	if suite.Len(payload, 3) {
+		suite.Equal("PPPO Fort Bragg", *payload[0].TransportationOffice.Name)
+		suite.InDelta(0, *payload[0].DistanceMiles, 0.1)
+		suite.Equal("PPPO Fort Bliss", *payload[1].TransportationOffice.Name)
+	}
+
+	limit := int64(1)
+	params = transportationofficeop.ShowNearestTransportationOfficesParams{
+		HTTPRequest:   httptest.NewRequest("GET", "/transportation_offices/nearest", nil),
+		DutyStationID: fmtUUID(station.ID),
+		Limit:         &limit,
+	}
+	response = handler.Handle(params)
+	suite.Assertions.IsType(&transportationofficeop.ShowNearestTransportationOfficesOK{}, response)
+	payload = response.(*transportationofficeop.ShowNearestTransportationOfficesOK).Payload
+	if suite.Len(payload, 1) {
+		suite.Equal("PPPO Fort Bragg", *payload[0].TransportationOffice.Name)
+	}
+
+	// Exactly one of the postal code and the duty station is needed
+	params = transportationofficeop.ShowNearestTransportationOfficesParams{
+		HTTPRequest: httptest.NewRequest("GET", "/transportation_offices/nearest", nil),
+	}
+	response = handler.Handle(params)
+	suite.Equal(http.StatusBadRequest, response.(*errResponse).code)
+
+	params.PostalCode = swag.String("00001")
+	response = handler.Handle(params)
+	suite.Equal(http.StatusNotFound, response.(*errResponse).code)
+}
+
+func (suite *HandlerSuite) TestSearchTransportationOfficesHandler() {
+	for _, name := range []string{"PPPO Fort Bliss", "PPPO Fort Bragg"} {
+		address, _ := testdatagen.MakeAddress(suite.db)
+		suite.mustSave(&models.TransportationOffice{Name: name, AddressID: address.ID})
+	}
+	handler := SearchTransportationOfficesHandler(NewHandlerContext(suite.db, suite.logger))
+
+	params := transportationofficeop.SearchTransportationOfficesParams{
+		HTTPRequest: httptest.NewRequest("GET", "/transportation_offices?search=bliss", nil),
+		Search:      "bliss",
+	}
+	response := handler.Handle(params)
+	suite.Assertions.IsType(&transportationofficeop.SearchTransportationOfficesOK{}, response)
+	payload := response.(*transportationofficeop.SearchTransportationOfficesOK).Payload
+	if suite.Len(payload, 1) {
+		suite.Equal("PPPO Fort Bliss", *payload[0].TransportationOffice.Name)
+		suite.Nil(payload[0].DistanceMiles)
+	}
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/swag"
+	"github.com/gobuffalo/uuid"
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/auth"
+	uploadop "github.com/transcom/mymove/pkg/gen/internalapi/internaloperations/uploads"
+	"github.com/transcom/mymove/pkg/gen/internalmessages"
+	"github.com/transcom/mymove/pkg/models"
+	uploaderpkg "github.com/transcom/mymove/pkg/uploader"
+)
+
+func payloadForUploadSessionModel(session models.UploadSession) *internalmessages.UploadSessionPayload {
+	return &internalmessages.UploadSessionPayload{
+		ID:            fmtUUID(session.ID),
+		DocumentID:    fmtUUID(session.DocumentID),
+		Filename:      swag.String(session.Filename),
+		Bytes:         swag.Int64(session.Bytes),
+		Checksum:      swag.String(session.Checksum),
+		ReceivedBytes: swag.Int64(session.ReceivedBytes),
+		MaxChunkBytes: swag.Int64(uploaderpkg.MaxChunkBytes),
+		ExpiresAt:     fmtDateTime(session.ExpiresAt),
+	}
+}
+
+// CreateUploadSessionHandler starts a resumable upload via POST /documents/{documentId}/upload_sessions
+type CreateUploadSessionHandler HandlerContext
+
+// Handle creates a new UploadSession from a request payload
+func (h CreateUploadSessionHandler) Handle(params uploadop.CreateUploadSessionParams) middleware.Responder {
+	session := auth.SessionFromRequestContext(params.HTTPRequest)
+
+	documentID, err := uuid.FromString(params.DocumentID.String())
+	if err != nil {
+		h.logger.Info("Badly formed UUID for document", zap.String("document_id", params.DocumentID.String()), zap.Error(err))
+		return uploadop.NewCreateUploadSessionBadRequest()
+	}
+
+	// Fetch document to ensure user has access to it
+	document, err := models.FetchDocument(h.db, session, documentID)
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+
+	payload := params.CreateUploadSessionPayload
+	uploader := uploaderpkg.NewUploader(h.db, h.logger, h.storage, h.scanner)
+	uploadSession, verrs, err := uploader.CreateUploadSession(document.ID, session.UserID, *payload.Filename, *payload.Bytes, *payload.Checksum)
+	if err != nil {
+		return responseForError(h.logger, err)
+	} else if verrs != nil {
+		return uploadop.NewCreateUploadSessionBadRequest().WithPayload(createFailedValidationPayload(verrs))
+	}
+
+	return uploadop.NewCreateUploadSessionCreated().WithPayload(payloadForUploadSessionModel(*uploadSession))
+}
+
+// ShowUploadSessionHandler returns an upload session via GET /upload_sessions/{uploadSessionId}
+type ShowUploadSessionHandler HandlerContext
+
+// Handle returns an upload session the user started
+func (h ShowUploadSessionHandler) Handle(params uploadop.ShowUploadSessionParams) middleware.Responder {
+	session := auth.SessionFromRequestContext(params.HTTPRequest)
+
+	uploadSessionID, _ := uuid.FromString(params.UploadSessionID.String())
+	uploadSession, err := models.FetchUploadSession(h.db, session, uploadSessionID)
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+
+	return uploadop.NewShowUploadSessionOK().WithPayload(payloadForUploadSessionModel(uploadSession))
+}
+
+// AppendUploadSessionChunkHandler adds a chunk to an upload session via PATCH /upload_sessions/{uploadSessionId}
+type AppendUploadSessionChunkHandler HandlerContext
+
+// Handle stores a chunk of an upload session's file
+func (h AppendUploadSessionChunkHandler) Handle(params uploadop.AppendUploadSessionChunkParams) middleware.Responder {
+	session := auth.SessionFromRequestContext(params.HTTPRequest)
+	defer params.Chunk.Close()
+
+	uploadSessionID, _ := uuid.FromString(params.UploadSessionID.String())
+	uploadSession, err := models.FetchUploadSession(h.db, session, uploadSessionID)
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+
+	uploader := uploaderpkg.NewUploader(h.db, h.logger, h.storage, h.scanner)
+	switch err := uploader.AppendChunk(&uploadSession, params.Offset, params.Chunk); err {
+	case nil:
+		return uploadop.NewAppendUploadSessionChunkOK().WithPayload(payloadForUploadSessionModel(uploadSession))
+	case uploaderpkg.ErrChunkOffset:
+		return uploadop.NewAppendUploadSessionChunkConflict().WithPayload(payloadForUploadSessionModel(uploadSession))
+	case uploaderpkg.ErrChunkTooLarge:
+		return uploadop.NewAppendUploadSessionChunkRequestEntityTooLarge()
+	default:
+		h.logger.Error("Failed to store chunk", zap.String("upload_session_id", uploadSession.ID.String()), zap.Error(err))
+		return uploadop.NewAppendUploadSessionChunkInternalServerError()
+	}
+}
+
+// FinalizeUploadSessionHandler creates an upload from an upload session via POST /upload_sessions/{uploadSessionId}/finalize
+type FinalizeUploadSessionHandler HandlerContext
+
+// Handle creates an Upload from the file received by an upload session
+func (h FinalizeUploadSessionHandler) Handle(params uploadop.FinalizeUploadSessionParams) middleware.Responder {
+	session := auth.SessionFromRequestContext(params.HTTPRequest)
+
+	uploadSessionID, _ := uuid.FromString(params.UploadSessionID.String())
+	uploadSession, err := models.FetchUploadSession(h.db, session, uploadSessionID)
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+
+	uploader := uploaderpkg.NewUploader(h.db, h.logger, h.storage, h.scanner)
+	if h.contentAddressed {
+		uploader.SetContentAddressed()
+	}
+	newUpload, verrs, err := uploader.FinalizeUploadSession(&uploadSession)
+	if err == uploaderpkg.ErrUploadSessionIncomplete {
+		return uploadop.NewFinalizeUploadSessionConflict().WithPayload(payloadForUploadSessionModel(uploadSession))
+	} else if err == uploaderpkg.ErrUploadSessionNotFound {
+		return responseForError(h.logger, models.ErrFetchNotFound)
+	} else if err != nil {
+		h.logger.Error("Failed to finalize upload session", zap.String("upload_session_id", uploadSession.ID.String()), zap.Error(err))
+		return uploadop.NewFinalizeUploadSessionInternalServerError()
+	} else if verrs != nil {
+		return uploadop.NewFinalizeUploadSessionBadRequest().WithPayload(createFailedValidationPayload(verrs))
+	}
+
+	if newUpload.ScanStatus == models.UploadScanStatusINFECTED {
+		return uploadop.NewFinalizeUploadSessionBadRequest()
+	}
+
+	document, err := models.FetchDocument(h.db, session, newUpload.DocumentID)
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+	url, err := newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest).uploadURL(document, newUpload)
+	if err != nil {
+		h.logger.Error("failed to get presigned url", zap.Error(err))
+		return uploadop.NewFinalizeUploadSessionInternalServerError()
+	}
+	if h.previewWorker != nil {
+		h.previewWorker.Notify()
+	}
+	uploadPayload := payloadForUploadModel(*newUpload, url, "")
+	reportDuplicate(h.db, h.logger, newUpload, uploadPayload)
+	return uploadop.NewFinalizeUploadSessionCreated().WithPayload(uploadPayload)
+}
+
+// DeleteUploadSessionHandler abandons an upload session via DELETE /upload_sessions/{uploadSessionId}
+type DeleteUploadSessionHandler HandlerContext
+
+// Handle deletes an upload session and its chunks
+func (h DeleteUploadSessionHandler) Handle(params uploadop.DeleteUploadSessionParams) middleware.Responder {
+	session := auth.SessionFromRequestContext(params.HTTPRequest)
+
+	uploadSessionID, _ := uuid.FromString(params.UploadSessionID.String())
+	uploadSession, err := models.FetchUploadSession(h.db, session, uploadSessionID)
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+
+	uploader := uploaderpkg.NewUploader(h.db, h.logger, h.storage, h.scanner)
+	if err := uploader.AbortUploadSession(&uploadSession); err != nil {
+		return responseForError(h.logger, err)
+	}
+	return uploadop.NewDeleteUploadSessionNoContent()
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+
+	uploadop "github.com/transcom/mymove/pkg/gen/internalapi/internaloperations/uploads"
+	"github.com/transcom/mymove/pkg/gen/internalmessages"
+	"github.com/transcom/mymove/pkg/models"
+	scannerTest "github.com/transcom/mymove/pkg/scanner/test"
+	"github.com/transcom/mymove/pkg/storage"
+	storageTest "github.com/transcom/mymove/pkg/storage/test"
+	"github.com/transcom/mymove/pkg/testdatagen"
+)
+
+func (suite *HandlerSuite) TestUploadSessionHandlers() {
+	document, err := testdatagen.MakeDocument(suite.db, nil, "")
+	suite.Nil(err)
+	content, err := ioutil.ReadAll(suite.fixture("test.pdf").Data)
+	suite.Nil(err)
+	checksum, err := storage.ComputeChecksum(bytes.NewReader(content))
+	suite.Nil(err)
+
+	context := NewHandlerContext(suite.db, suite.logger)
+	context.SetFileStorer(storageTest.NewFakeS3Storage(true))
+	context.SetScanner(scannerTest.NewFakeScanner(true))
+	request := func() *http.Request {
+		return suite.authenticateRequest(&http.Request{}, document.ServiceMember)
+	}
+
+	createParams := uploadop.NewCreateUploadSessionParams()
+	createParams.HTTPRequest = request()
+	createParams.DocumentID = strfmt.UUID(document.ID.String())
+	createParams.CreateUploadSessionPayload = &internalmessages.CreateUploadSessionPayload{
+		Filename: swag.String("test.pdf"),
+		Bytes:    swag.Int64(int64(len(content))),
+		Checksum: swag.String(checksum),
+	}
+	response := CreateUploadSessionHandler(context).Handle(createParams)
+	suite.Assertions.IsType(&uploadop.CreateUploadSessionCreated{}, response)
+	sessionPayload := response.(*uploadop.CreateUploadSessionCreated).Payload
+	suite.Equal(int64(0), *sessionPayload.ReceivedBytes)
+
+	appendChunk := func(offset int, chunk []byte) middleware.Responder {
+		params := uploadop.NewAppendUploadSessionChunkParams()
+		params.HTTPRequest = request()
+		params.UploadSessionID = *sessionPayload.ID
+		params.Offset = int64(offset)
+		params.Chunk = ioutil.NopCloser(bytes.NewReader(chunk))
+		return AppendUploadSessionChunkHandler(context).Handle(params)
+	}
+	half := len(content) / 2
+	suite.Assertions.IsType(&uploadop.AppendUploadSessionChunkOK{}, appendChunk(0, content[:half]))
+
+	// A client which lost track of where it was is told where to resume
+	response = appendChunk(0, content[:half])
+	suite.Assertions.IsType(&uploadop.AppendUploadSessionChunkConflict{}, response)
+	suite.Equal(int64(half), *response.(*uploadop.AppendUploadSessionChunkConflict).Payload.ReceivedBytes)
+
+	showParams := uploadop.NewShowUploadSessionParams()
+	showParams.HTTPRequest = request()
+	showParams.UploadSessionID = *sessionPayload.ID
+	response = ShowUploadSessionHandler(context).Handle(showParams)
+	suite.Assertions.IsType(&uploadop.ShowUploadSessionOK{}, response)
+	suite.Equal(int64(half), *response.(*uploadop.ShowUploadSessionOK).Payload.ReceivedBytes)
+
+	finalizeParams := uploadop.NewFinalizeUploadSessionParams()
+	finalizeParams.HTTPRequest = request()
+	finalizeParams.UploadSessionID = *sessionPayload.ID
+	suite.Assertions.IsType(&uploadop.FinalizeUploadSessionConflict{}, FinalizeUploadSessionHandler(context).Handle(finalizeParams))
+
+	suite.Assertions.IsType(&uploadop.AppendUploadSessionChunkOK{}, appendChunk(half, content[half:]))
+	response = FinalizeUploadSessionHandler(context).Handle(finalizeParams)
+	suite.Assertions.IsType(&uploadop.FinalizeUploadSessionCreated{}, response)
+	uploadPayload := response.(*uploadop.FinalizeUploadSessionCreated).Payload
+	suite.Equal("application/pdf", *uploadPayload.ContentType)
+	suite.NotNil(uploadPayload.URL)
+
+	var upload models.Upload
+	suite.Nil(suite.db.Find(&upload, uploadPayload.ID))
+	suite.Equal(checksum, upload.Checksum)
+
+	// Once finalized, the session is gone
+	response = ShowUploadSessionHandler(context).Handle(showParams)
+	suite.checkErrorResponse(response, http.StatusNotFound, "Not Found")
+}
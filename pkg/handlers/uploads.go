@@ -1,9 +1,14 @@
 package handlers
 
 import (
+	"fmt"
+	"io"
+	"net/http"
+
 	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/runtime/middleware"
 	"github.com/go-openapi/swag"
+	"github.com/gobuffalo/pop"
 	"github.com/gobuffalo/uuid"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -15,16 +20,56 @@ import (
 	uploaderpkg "github.com/transcom/mymove/pkg/uploader"
 )
 
-func payloadForUploadModel(upload models.Upload, url string) *internalmessages.UploadPayload {
-	return &internalmessages.UploadPayload{
-		ID:          fmtUUID(upload.ID),
-		Filename:    swag.String(upload.Filename),
-		ContentType: swag.String(upload.ContentType),
-		URL:         fmtURI(url),
-		Bytes:       &upload.Bytes,
-		CreatedAt:   fmtDateTime(upload.CreatedAt),
-		UpdatedAt:   fmtDateTime(upload.UpdatedAt),
+// payloadForUploadModel returns the payload for an upload. url is empty for uploads which haven't
+// been scanned clean, and previewURL for uploads whose preview hasn't been made yet.
+func payloadForUploadModel(upload models.Upload, url string, previewURL string) *internalmessages.UploadPayload {
+	payload := &internalmessages.UploadPayload{
+		ID:            fmtUUID(upload.ID),
+		Filename:      swag.String(upload.Filename),
+		ContentType:   swag.String(upload.ContentType),
+		ScanStatus:    internalmessages.UploadScanStatus(upload.ScanStatus),
+		PreviewStatus: internalmessages.UploadPreviewStatus(upload.PreviewStatus),
+		Bytes:         &upload.Bytes,
+		CreatedAt:     fmtDateTime(upload.CreatedAt),
+		UpdatedAt:     fmtDateTime(upload.UpdatedAt),
+	}
+	if url != "" {
+		payload.URL = fmtURI(url)
+	}
+	if previewURL != "" {
+		payload.PreviewURL = fmtURI(previewURL)
+	}
+	if upload.ReviewedAt != nil {
+		payload.ReviewedAt = fmtDateTime(*upload.ReviewedAt)
 	}
+	return payload
+}
+
+// uploadContentURL returns the URL of the endpoint which streams an upload's file, for storage
+// which doesn't allow direct access
+func uploadContentURL(upload models.Upload) string {
+	return fmt.Sprintf("/internal/uploads/%s/content", upload.ID)
+}
+
+// uploadPreviewURL returns the URL of the endpoint which streams an upload's preview, for storage
+// which doesn't allow direct access
+func uploadPreviewURL(upload models.Upload) string {
+	return fmt.Sprintf("/internal/uploads/%s/preview", upload.ID)
+}
+
+// contentResponder streams content as a response with the given Content-Type, and closes it.
+// Responses are kept out of shared caches, as files may hold personal information.
+func contentResponder(logger *zap.Logger, content io.ReadCloser, contentType string) middleware.Responder {
+	return middleware.ResponderFunc(func(rw http.ResponseWriter, _ runtime.Producer) {
+		defer content.Close()
+		rw.Header().Set("Content-Type", contentType)
+		rw.Header().Set("X-Content-Type-Options", "nosniff")
+		rw.Header().Set("Cache-Control", "private, no-store")
+		rw.WriteHeader(http.StatusOK)
+		if _, err := io.Copy(rw, content); err != nil {
+			logger.Error("failed to stream file", zap.Error(err))
+		}
+	})
 }
 
 // CreateUploadHandler creates a new upload via POST /documents/{documentID}/uploads
@@ -54,7 +99,10 @@ func (h CreateUploadHandler) Handle(params uploadop.CreateUploadParams) middlewa
 		return responseForError(h.logger, docErr)
 	}
 
-	uploader := uploaderpkg.NewUploader(h.db, h.logger, h.storage)
+	uploader := uploaderpkg.NewUploader(h.db, h.logger, h.storage, h.scanner)
+	if h.contentAddressed {
+		uploader.SetContentAddressed()
+	}
 	newUpload, verrs, err := uploader.CreateUpload(document.ID, session.UserID, file)
 	if err != nil {
 		if cause := errors.Cause(err); cause == uploaderpkg.ErrZeroLengthFile {
@@ -67,15 +115,104 @@ func (h CreateUploadHandler) Handle(params uploadop.CreateUploadParams) middlewa
 		return uploadop.NewCreateUploadBadRequest().WithPayload(payload)
 	}
 
-	url, err := uploader.PresignedURL(newUpload)
+	if newUpload.ScanStatus == models.UploadScanStatusINFECTED {
+		return uploadop.NewCreateUploadBadRequest()
+	}
+
+	accessor := newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest)
+	url, err := accessor.uploadURL(document, newUpload)
 	if err != nil {
 		h.logger.Error("failed to get presigned url", zap.Error(err))
 		return uploadop.NewCreateUploadInternalServerError()
 	}
-	uploadPayload := payloadForUploadModel(*newUpload, url)
+	if h.previewWorker != nil {
+		h.previewWorker.Notify()
+	}
+
+	// Previews are made in the background, so a new upload never has one yet
+	uploadPayload := payloadForUploadModel(*newUpload, url, "")
+	reportDuplicate(h.db, h.logger, newUpload, uploadPayload)
 	return uploadop.NewCreateUploadCreated().WithPayload(uploadPayload)
 }
 
+// reportDuplicate tells the client if a new upload has the same file as another upload of its
+// document, which is usually a mistake. The upload has been made either way, so failing to
+// check is only logged.
+func reportDuplicate(db *pop.Connection, logger *zap.Logger, upload *models.Upload, payload *internalmessages.UploadPayload) {
+	duplicate, err := models.FetchDuplicateUpload(db, upload)
+	if err != nil {
+		logger.Error("failed to check for duplicate uploads", zap.String("upload_id", upload.ID.String()), zap.Error(err))
+		return
+	}
+	if duplicate != nil {
+		payload.DuplicateOf = fmtUUID(duplicate.ID)
+	}
+}
+
+// ShowUploadContentHandler streams an upload's file via GET /uploads/{uploadId}/content
+type ShowUploadContentHandler HandlerContext
+
+// Handle streams the file of an upload the user has access to
+func (h ShowUploadContentHandler) Handle(params uploadop.ShowUploadContentParams) middleware.Responder {
+	session := auth.SessionFromRequestContext(params.HTTPRequest)
+
+	uploadID, err := uuid.FromString(params.UploadID.String())
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+	upload, err := models.FetchUpload(h.db, session, uploadID)
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+
+	uploader := uploaderpkg.NewUploader(h.db, h.logger, h.storage, h.scanner)
+	content, err := uploader.Fetch(&upload)
+	if err == uploaderpkg.ErrUploadNotClean {
+		return uploadop.NewShowUploadContentConflict()
+	} else if err != nil {
+		h.logger.Error("failed to fetch upload", zap.String("upload_id", upload.ID.String()), zap.Error(err))
+		return uploadop.NewShowUploadContentInternalServerError()
+	}
+	accessor := newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest)
+	if err := accessor.record(upload.Document, &upload, models.DocumentAccessPurposeDOWNLOADUPLOAD); err != nil {
+		content.Close()
+		return uploadop.NewShowUploadContentInternalServerError()
+	}
+	return contentResponder(h.logger, content, upload.ContentType)
+}
+
+// ShowUploadPreviewHandler streams an upload's preview via GET /uploads/{uploadId}/preview
+type ShowUploadPreviewHandler HandlerContext
+
+// Handle streams the preview of an upload the user has access to
+func (h ShowUploadPreviewHandler) Handle(params uploadop.ShowUploadPreviewParams) middleware.Responder {
+	session := auth.SessionFromRequestContext(params.HTTPRequest)
+
+	uploadID, err := uuid.FromString(params.UploadID.String())
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+	upload, err := models.FetchUpload(h.db, session, uploadID)
+	if err != nil {
+		return responseForError(h.logger, err)
+	}
+
+	uploader := uploaderpkg.NewUploader(h.db, h.logger, h.storage, h.scanner)
+	content, err := uploader.FetchPreview(&upload)
+	if err == uploaderpkg.ErrUploadNotClean || err == uploaderpkg.ErrNoPreview {
+		return uploadop.NewShowUploadPreviewNotFound()
+	} else if err != nil {
+		h.logger.Error("failed to fetch upload preview", zap.String("upload_id", upload.ID.String()), zap.Error(err))
+		return uploadop.NewShowUploadPreviewInternalServerError()
+	}
+	accessor := newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest)
+	if err := accessor.record(upload.Document, &upload, models.DocumentAccessPurposeDOWNLOADPREVIEW); err != nil {
+		content.Close()
+		return uploadop.NewShowUploadPreviewInternalServerError()
+	}
+	return contentResponder(h.logger, content, "image/jpeg")
+}
+
 // DeleteUploadHandler deletes an upload
 type DeleteUploadHandler HandlerContext
 
@@ -89,7 +226,7 @@ func (h DeleteUploadHandler) Handle(params uploadop.DeleteUploadParams) middlewa
 		return responseForError(h.logger, err)
 	}
 
-	uploader := uploaderpkg.NewUploader(h.db, h.logger, h.storage)
+	uploader := uploaderpkg.NewUploader(h.db, h.logger, h.storage, h.scanner)
 	if err = uploader.DeleteUpload(&upload); err != nil {
 		return responseForError(h.logger, err)
 	}
@@ -104,7 +241,7 @@ type DeleteUploadsHandler HandlerContext
 func (h DeleteUploadsHandler) Handle(params uploadop.DeleteUploadsParams) middleware.Responder {
 	// User should always be populated by middleware
 	session := auth.SessionFromRequestContext(params.HTTPRequest)
-	uploader := uploaderpkg.NewUploader(h.db, h.logger, h.storage)
+	uploader := uploaderpkg.NewUploader(h.db, h.logger, h.storage, h.scanner)
 
 	for _, uploadID := range params.UploadIds {
 		uuid, _ := uuid.FromString(uploadID.String())
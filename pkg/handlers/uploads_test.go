@@ -1,18 +1,28 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"time"
 
+	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/runtime/middleware"
 	"github.com/go-openapi/strfmt"
 	"github.com/gobuffalo/uuid"
 
 	uploadop "github.com/transcom/mymove/pkg/gen/internalapi/internaloperations/uploads"
+	"github.com/transcom/mymove/pkg/gen/internalmessages"
 	"github.com/transcom/mymove/pkg/models"
+	scannerTest "github.com/transcom/mymove/pkg/scanner/test"
+	"github.com/transcom/mymove/pkg/storage"
 	storageTest "github.com/transcom/mymove/pkg/storage/test"
 	"github.com/transcom/mymove/pkg/testdatagen"
+	uploaderpkg "github.com/transcom/mymove/pkg/uploader"
 )
 
 func createPrereqs(suite *HandlerSuite) (models.Document, uploadop.CreateUploadParams) {
@@ -31,6 +41,10 @@ func createPrereqs(suite *HandlerSuite) (models.Document, uploadop.CreateUploadP
 }
 
 func makeRequest(suite *HandlerSuite, params uploadop.CreateUploadParams, serviceMember models.ServiceMember, fakeS3 *storageTest.FakeS3Storage) middleware.Responder {
+	return makeScannedRequest(suite, params, serviceMember, fakeS3, scannerTest.NewFakeScanner(true))
+}
+
+func makeScannedRequest(suite *HandlerSuite, params uploadop.CreateUploadParams, serviceMember models.ServiceMember, fakeS3 *storageTest.FakeS3Storage, fakeScanner *scannerTest.FakeScanner) middleware.Responder {
 	req := &http.Request{}
 	req = suite.authenticateRequest(req, serviceMember)
 
@@ -38,6 +52,7 @@ func makeRequest(suite *HandlerSuite, params uploadop.CreateUploadParams, servic
 
 	context := NewHandlerContext(suite.db, suite.logger)
 	context.SetFileStorer(fakeS3)
+	context.SetScanner(fakeScanner)
 	handler := CreateUploadHandler(context)
 	response := handler.Handle(params)
 
@@ -85,9 +100,60 @@ func (suite *HandlerSuite) TestCreateUploadsHandlerSuccess() {
 		t.Errorf("Wrong file position: expected 0, got %d", pos)
 	}
 
+	suite.Equal(models.UploadScanStatusCLEAN, upload.ScanStatus)
+	suite.NotNil(uploadPayload.URL)
+
 	// TODO verify Body
 }
 
+func (suite *HandlerSuite) TestCreateUploadsHandlerEnforcesDocumentPolicy() {
+	fakeS3 := storageTest.NewFakeS3Storage(true)
+	document, params := createPrereqs(suite)
+	document.DocumentType = models.DocumentTypeADVANCEWORKSHEET
+	suite.mustSave(&document)
+
+	for i := 0; i < models.DocumentTypeADVANCEWORKSHEET.Policy().MaxUploads; i++ {
+		params.File = suite.fixture("test.pdf")
+		response := makeRequest(suite, params, document.ServiceMember, fakeS3)
+		suite.Assertions.IsType(&uploadop.CreateUploadCreated{}, response)
+	}
+
+	params.File = suite.fixture("test.pdf")
+	response := makeRequest(suite, params, document.ServiceMember, fakeS3)
+	suite.Assertions.IsType(&uploadop.CreateUploadBadRequest{}, response)
+	payload := response.(*uploadop.CreateUploadBadRequest).Payload
+	suite.Equal("No more than 2 files can be uploaded to advance worksheet documents.", payload.Errors["uploads"])
+}
+
+func (suite *HandlerSuite) TestCreateUploadsHandlerQuarantinesInfectedFile() {
+	fakeS3 := storageTest.NewFakeS3Storage(true)
+	document, params := createPrereqs(suite)
+	params.File = suite.fixture("infected.pdf")
+
+	response := makeRequest(suite, params, document.ServiceMember, fakeS3)
+	suite.Assertions.IsType(&uploadop.CreateUploadBadRequest{}, response)
+
+	// The upload is kept, but its file is only stored in quarantine
+	upload := models.Upload{}
+	suite.Nil(suite.db.First(&upload))
+	suite.Equal(models.UploadScanStatusINFECTED, upload.ScanStatus)
+	suite.Len(fakeS3.PutFiles, 1)
+	suite.Equal(fmt.Sprintf("quarantine/documents/%s/uploads/%s", document.ID, upload.ID), fakeS3.PutFiles[0].Key)
+}
+
+func (suite *HandlerSuite) TestCreateUploadsHandlerScanFailure() {
+	fakeS3 := storageTest.NewFakeS3Storage(true)
+	document, params := createPrereqs(suite)
+
+	response := makeScannedRequest(suite, params, document.ServiceMember, fakeS3, scannerTest.NewFakeScanner(false))
+	suite.Assertions.IsType(&uploadop.CreateUploadCreated{}, response)
+	uploadPayload := response.(*uploadop.CreateUploadCreated).Payload
+
+	// Until it is scanned the file can't be opened
+	suite.Nil(uploadPayload.URL)
+	suite.Equal(internalmessages.UploadScanStatusPENDING, uploadPayload.ScanStatus)
+}
+
 func (suite *HandlerSuite) TestCreateUploadsHandlerFailsWithWrongUser() {
 	t := suite.T()
 	fakeS3 := storageTest.NewFakeS3Storage(true)
@@ -253,3 +319,97 @@ func (suite *HandlerSuite) TestDeleteUploadsHandlerSuccess() {
 	err = suite.db.Find(&queriedUpload, upload1.ID)
 	suite.NotNil(err)
 }
+
+func (suite *HandlerSuite) TestShowUploadContentHandlerWithEncryptedStorage() {
+	fakeS3 := storageTest.NewFakeS3Storage(true)
+	keyring, err := storage.NewKeyring("test:" + base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("k"), 32)))
+	suite.Nil(err)
+	encrypted := storage.NewEncrypted(fakeS3, keyring, suite.logger)
+	document, params := createPrereqs(suite)
+
+	params.HTTPRequest = suite.authenticateRequest(&http.Request{}, document.ServiceMember)
+	context := NewHandlerContext(suite.db, suite.logger)
+	context.SetFileStorer(encrypted)
+	context.SetScanner(scannerTest.NewFakeScanner(true))
+	response := CreateUploadHandler(context).Handle(params)
+	suite.Assertions.IsType(&uploadop.CreateUploadCreated{}, response)
+	uploadPayload := response.(*uploadop.CreateUploadCreated).Payload
+
+	// Encrypted files can't be reached directly, so they are streamed by the API
+	suite.Equal(fmt.Sprintf("/internal/uploads/%s/content", uploadPayload.ID), uploadPayload.URL.String())
+
+	showParams := uploadop.NewShowUploadContentParams()
+	showParams.UploadID = *uploadPayload.ID
+	showParams.HTTPRequest = suite.authenticateRequest(&http.Request{}, document.ServiceMember)
+	response = ShowUploadContentHandler(context).Handle(showParams)
+
+	rr := httptest.NewRecorder()
+	response.WriteResponse(rr, runtime.JSONProducer())
+	suite.Equal(http.StatusOK, rr.Code)
+	suite.Equal("application/pdf", rr.Header().Get("Content-Type"))
+	fixture, err := ioutil.ReadAll(suite.fixture("test.pdf").Data)
+	suite.Nil(err)
+	suite.Equal(fixture, rr.Body.Bytes())
+
+	// Only the uploader's service member may download it
+	otherServiceMember, err := testdatagen.MakeServiceMember(suite.db)
+	suite.Nil(err)
+	showParams.HTTPRequest = suite.authenticateRequest(&http.Request{}, otherServiceMember)
+	response = ShowUploadContentHandler(context).Handle(showParams)
+	suite.checkResponseForbidden(response)
+}
+
+func (suite *HandlerSuite) TestShowUploadPreviewHandler() {
+	fakeS3 := storageTest.NewFakeS3Storage(true)
+	document, params := createPrereqs(suite)
+	params.File = suite.fixture("test.png")
+
+	params.HTTPRequest = suite.authenticateRequest(&http.Request{}, document.ServiceMember)
+	context := NewHandlerContext(suite.db, suite.logger)
+	context.SetFileStorer(fakeS3)
+	context.SetScanner(scannerTest.NewFakeScanner(true))
+	worker := uploaderpkg.NewPreviewWorker(suite.db, suite.logger, fakeS3, time.Minute)
+	context.SetPreviewWorker(worker)
+	response := CreateUploadHandler(context).Handle(params)
+	suite.Assertions.IsType(&uploadop.CreateUploadCreated{}, response)
+	uploadPayload := response.(*uploadop.CreateUploadCreated).Payload
+	suite.Nil(uploadPayload.PreviewURL)
+
+	showParams := uploadop.NewShowUploadPreviewParams()
+	showParams.UploadID = *uploadPayload.ID
+	showParams.HTTPRequest = suite.authenticateRequest(&http.Request{}, document.ServiceMember)
+	response = ShowUploadPreviewHandler(context).Handle(showParams)
+	suite.Assertions.IsType(&uploadop.ShowUploadPreviewNotFound{}, response)
+
+	// Once the worker has made the preview it can be downloaded
+	worker.RunOnce()
+	response = ShowUploadPreviewHandler(context).Handle(showParams)
+	rr := httptest.NewRecorder()
+	response.WriteResponse(rr, runtime.JSONProducer())
+	suite.Equal(http.StatusOK, rr.Code)
+	suite.Equal("image/jpeg", rr.Header().Get("Content-Type"))
+	suite.Equal("image/jpeg", http.DetectContentType(rr.Body.Bytes()))
+
+	// Only the uploader's service member may download it
+	otherServiceMember, err := testdatagen.MakeServiceMember(suite.db)
+	suite.Nil(err)
+	showParams.HTTPRequest = suite.authenticateRequest(&http.Request{}, otherServiceMember)
+	response = ShowUploadPreviewHandler(context).Handle(showParams)
+	suite.checkResponseForbidden(response)
+}
+
+func (suite *HandlerSuite) TestCreateUploadsHandlerReportsDuplicate() {
+	fakeS3 := storageTest.NewFakeS3Storage(true)
+	document, params := createPrereqs(suite)
+
+	response := makeRequest(suite, params, document.ServiceMember, fakeS3)
+	suite.Assertions.IsType(&uploadop.CreateUploadCreated{}, response)
+	first := response.(*uploadop.CreateUploadCreated).Payload
+	suite.Nil(first.DuplicateOf)
+
+	params.File = suite.fixture("test.pdf")
+	response = makeRequest(suite, params, document.ServiceMember, fakeS3)
+	suite.Assertions.IsType(&uploadop.CreateUploadCreated{}, response)
+	second := response.(*uploadop.CreateUploadCreated).Payload
+	suite.Equal(*first.ID, *second.DuplicateOf)
+}
@@ -8,14 +8,13 @@ import (
 	userop "github.com/transcom/mymove/pkg/gen/internalapi/internaloperations/users"
 	"github.com/transcom/mymove/pkg/gen/internalmessages"
 	"github.com/transcom/mymove/pkg/models"
-	"github.com/transcom/mymove/pkg/storage"
 )
 
-func payloadForUserModel(storer storage.FileStorer, user *models.User, serviceMember *models.ServiceMember) *internalmessages.LoggedInUserPayload {
+func payloadForUserModel(accessor *documentAccessor, user *models.User, serviceMember *models.ServiceMember) *internalmessages.LoggedInUserPayload {
 	var smPayload *internalmessages.ServiceMemberPayload
 
 	if serviceMember != nil {
-		smPayload = payloadForServiceMemberModel(storer, *serviceMember)
+		smPayload = payloadForServiceMemberModel(accessor, *serviceMember)
 	}
 
 	userPayload := internalmessages.LoggedInUserPayload{
@@ -49,7 +48,7 @@ func (h ShowLoggedInUserHandler) Handle(params userop.ShowLoggedInUserParams) mi
 		h.logger.Error("Error retrieving service_member", zap.Error(err))
 		response = userop.NewShowLoggedInUserUnauthorized()
 	} else {
-		userPayload := payloadForUserModel(h.storage, user, serviceMember)
+		userPayload := payloadForUserModel(newDocumentAccessor(h.db, h.logger, h.storage, params.HTTPRequest), user, serviceMember)
 		response = userop.NewShowLoggedInUserOK().WithPayload(userPayload)
 	}
 	return response
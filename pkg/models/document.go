@@ -19,6 +19,7 @@ type Document struct {
 	ServiceMemberID uuid.UUID     `db:"service_member_id"`
 	ServiceMember   ServiceMember `belongs_to:"service_members"`
 	Name            string        `db:"name"`
+	DocumentType    DocumentType  `db:"document_type"`
 	CreatedAt       time.Time     `db:"created_at"`
 	UpdatedAt       time.Time     `db:"updated_at"`
 	Uploads         Uploads       `has_many:"uploads" order_by:"created_at asc"`
@@ -31,6 +32,7 @@ type Documents []Document
 func (d *Document) Validate(tx *pop.Connection) (*validate.Errors, error) {
 	return validate.Validate(
 		&validators.UUIDIsPresent{Field: d.ServiceMemberID, Name: "ServiceMemberID"},
+		&validators.StringInclusion{Field: string(d.DocumentType), Name: "DocumentType", List: validDocumentTypes()},
 	), nil
 }
 
@@ -52,3 +54,16 @@ func FetchDocument(db *pop.Connection, session *auth.Session, id uuid.UUID) (Doc
 	}
 	return document, nil
 }
+
+// LockDocument returns the document with the given ID, locking it until the end of the
+// transaction db is in, so that uploads are added to it one at a time
+func LockDocument(db *pop.Connection, id uuid.UUID) (Document, error) {
+	var documents Documents
+	if err := db.RawQuery("SELECT * FROM documents WHERE id = ? FOR UPDATE", id).All(&documents); err != nil {
+		return Document{}, errors.Wrap(err, "locking document")
+	}
+	if len(documents) == 0 {
+		return Document{}, ErrFetchNotFound
+	}
+	return documents[0], nil
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/pop"
+	"github.com/gobuffalo/uuid"
+	"github.com/gobuffalo/validate"
+	"github.com/gobuffalo/validate/validators"
+	"github.com/pkg/errors"
+
+	"github.com/transcom/mymove/pkg/auth"
+)
+
+// DocumentAccessRole is the app a user accessed a document through
+type DocumentAccessRole string
+
+const (
+	// DocumentAccessRoleSERVICEMEMBER captures enum value "SERVICE_MEMBER"
+	DocumentAccessRoleSERVICEMEMBER DocumentAccessRole = "SERVICE_MEMBER"
+	// DocumentAccessRoleOFFICEUSER captures enum value "OFFICE_USER"
+	DocumentAccessRoleOFFICEUSER DocumentAccessRole = "OFFICE_USER"
+)
+
+// DocumentAccessPurpose is what a user was given by an access to a document
+type DocumentAccessPurpose string
+
+const (
+	// DocumentAccessPurposeVIEWDOCUMENT captures enum value "VIEW_DOCUMENT"
+	DocumentAccessPurposeVIEWDOCUMENT DocumentAccessPurpose = "VIEW_DOCUMENT"
+	// DocumentAccessPurposeUPLOADURL captures enum value "UPLOAD_URL"
+	DocumentAccessPurposeUPLOADURL DocumentAccessPurpose = "UPLOAD_URL"
+	// DocumentAccessPurposePREVIEWURL captures enum value "PREVIEW_URL"
+	DocumentAccessPurposePREVIEWURL DocumentAccessPurpose = "PREVIEW_URL"
+	// DocumentAccessPurposePDFURL captures enum value "PDF_URL"
+	DocumentAccessPurposePDFURL DocumentAccessPurpose = "PDF_URL"
+	// DocumentAccessPurposeDOWNLOADUPLOAD captures enum value "DOWNLOAD_UPLOAD"
+	DocumentAccessPurposeDOWNLOADUPLOAD DocumentAccessPurpose = "DOWNLOAD_UPLOAD"
+	// DocumentAccessPurposeDOWNLOADPREVIEW captures enum value "DOWNLOAD_PREVIEW"
+	DocumentAccessPurposeDOWNLOADPREVIEW DocumentAccessPurpose = "DOWNLOAD_PREVIEW"
+	// DocumentAccessPurposeDOWNLOADPDF captures enum value "DOWNLOAD_PDF"
+	DocumentAccessPurposeDOWNLOADPDF DocumentAccessPurpose = "DOWNLOAD_PDF"
+)
+
+// A DocumentAccess records a user being shown a service member's document, being given a URL to
+// one of its files, or downloading one. The table is append only; rows are never changed or
+// deleted.
+type DocumentAccess struct {
+	ID     uuid.UUID `db:"id"`
+	UserID uuid.UUID `db:"user_id"`
+	// The user's email when they accessed the document
+	Email string             `db:"email"`
+	Role  DocumentAccessRole `db:"role"`
+	// The service member whose document was accessed
+	ServiceMemberID uuid.UUID             `db:"service_member_id"`
+	DocumentID      uuid.UUID             `db:"document_id"`
+	UploadID        *uuid.UUID            `db:"upload_id"`
+	Purpose         DocumentAccessPurpose `db:"purpose"`
+	// The address the request came from, if known, and the X-Forwarded-For header the load
+	// balancer added
+	IPAddress    string    `db:"ip_address"`
+	ForwardedFor *string   `db:"forwarded_for"`
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+// DocumentAccesses is not required by pop and may be deleted
+type DocumentAccesses []DocumentAccess
+
+// Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
+func (a *DocumentAccess) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	return validate.Validate(
+		&validators.UUIDIsPresent{Field: a.UserID, Name: "UserID"},
+		&validators.StringIsPresent{Field: string(a.Role), Name: "Role"},
+		&validators.UUIDIsPresent{Field: a.ServiceMemberID, Name: "ServiceMemberID"},
+		&validators.UUIDIsPresent{Field: a.DocumentID, Name: "DocumentID"},
+		&validators.StringIsPresent{Field: string(a.Purpose), Name: "Purpose"},
+	), nil
+}
+
+// NewDocumentAccess builds a record of the session's user accessing a document, or one of its
+// uploads if upload isn't nil
+func NewDocumentAccess(session *auth.Session, document Document, upload *Upload, purpose DocumentAccessPurpose) DocumentAccess {
+	access := DocumentAccess{
+		UserID:          session.UserID,
+		Email:           session.Email,
+		Role:            DocumentAccessRoleSERVICEMEMBER,
+		ServiceMemberID: document.ServiceMemberID,
+		DocumentID:      document.ID,
+		Purpose:         purpose,
+	}
+	if session.IsOfficeApp() {
+		access.Role = DocumentAccessRoleOFFICEUSER
+	}
+	if upload != nil {
+		access.UploadID = &upload.ID
+	}
+	return access
+}
+
+// RecordDocumentAccess saves a new DocumentAccess
+func RecordDocumentAccess(db *pop.Connection, access *DocumentAccess) error {
+	verrs, err := db.ValidateAndCreate(access)
+	if err != nil {
+		return errors.Wrap(err, "recording document access")
+	} else if verrs.HasAny() {
+		return errors.Errorf("recording document access: %s", verrs)
+	}
+	return nil
+}
+
+// FetchDocumentAccesses returns the accesses to a service member's documents, most recent first.
+// Only office users may see them.
+func FetchDocumentAccesses(db *pop.Connection, session *auth.Session, serviceMemberID uuid.UUID) (DocumentAccesses, error) {
+	if !session.IsOfficeApp() || !session.IsOfficeUser() {
+		return nil, ErrFetchForbidden
+	}
+	accesses := DocumentAccesses{}
+	err := db.Where("service_member_id = ?", serviceMemberID).Order("created_at desc").All(&accesses)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching document accesses")
+	}
+	return accesses, nil
+}
@@ -0,0 +1,57 @@
+package models_test
+
+import (
+	"github.com/transcom/mymove/pkg/auth"
+	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/testdatagen"
+)
+
+func (suite *ModelSuite) Test_DocumentAccessValidations() {
+	access := &models.DocumentAccess{}
+
+	expErrors := map[string][]string{
+		"user_id":           {"UserID can not be blank."},
+		"role":              {"Role can not be blank."},
+		"service_member_id": {"ServiceMemberID can not be blank."},
+		"document_id":       {"DocumentID can not be blank."},
+		"purpose":           {"Purpose can not be blank."},
+	}
+
+	suite.verifyValidationErrors(access, expErrors)
+}
+
+func (suite *ModelSuite) Test_DocumentAccessesAreAppendOnly() {
+	upload, err := testdatagen.MakeUpload(suite.db, nil)
+	suite.Nil(err)
+	document := upload.Document
+
+	session := &auth.Session{
+		ApplicationName: auth.MyApp,
+		UserID:          document.ServiceMember.UserID,
+		ServiceMemberID: document.ServiceMemberID,
+	}
+	access := models.NewDocumentAccess(session, document, &upload, models.DocumentAccessPurposeUPLOADURL)
+	suite.Nil(models.RecordDocumentAccess(suite.db, &access))
+	suite.Equal(models.DocumentAccessRoleSERVICEMEMBER, access.Role)
+	suite.Equal(upload.ID, *access.UploadID)
+
+	access.Purpose = models.DocumentAccessPurposeDOWNLOADUPLOAD
+	suite.NotNil(suite.db.Update(&access))
+	suite.NotNil(suite.db.Destroy(&access))
+
+	officeUser, err := testdatagen.MakeOfficeUser(suite.db)
+	suite.Nil(err)
+	officeSession := &auth.Session{
+		ApplicationName: auth.OfficeApp,
+		UserID:          *officeUser.UserID,
+		OfficeUserID:    officeUser.ID,
+	}
+	accesses, err := models.FetchDocumentAccesses(suite.db, officeSession, document.ServiceMemberID)
+	suite.Nil(err)
+	if suite.Len(accesses, 1) {
+		suite.Equal(models.DocumentAccessPurposeUPLOADURL, accesses[0].Purpose)
+	}
+
+	_, err = models.FetchDocumentAccesses(suite.db, session, document.ServiceMemberID)
+	suite.Equal(models.ErrFetchForbidden, err)
+}
@@ -15,6 +15,7 @@ func (suite *ModelSuite) Test_DocumentCreate() {
 
 	document := models.Document{
 		ServiceMemberID: serviceMember.ID,
+		DocumentType:    models.DocumentTypeWEIGHTTICKET,
 	}
 
 	verrs, err := suite.db.ValidateAndSave(&document)
@@ -33,7 +34,23 @@ func (suite *ModelSuite) Test_DocumentValidations() {
 
 	var expErrors = map[string][]string{
 		"service_member_id": {"ServiceMemberID can not be blank."},
+		"document_type":     {"DocumentType is not in the list [ORDERS, AMENDMENT, WEIGHT_TICKET, EXPENSE_RECEIPT, ADVANCE_WORKSHEET, POWER_OF_ATTORNEY, OTHER]."},
 	}
 
 	suite.verifyValidationErrors(document, expErrors)
 }
+
+func (suite *ModelSuite) Test_DocumentPolicyValidateUpload() {
+	policy := models.DocumentTypeADVANCEWORKSHEET.Policy()
+	upload := &models.Upload{ContentType: "application/pdf", Bytes: 1000}
+	suite.False(policy.ValidateUpload(models.DocumentTypeADVANCEWORKSHEET, upload, 0).HasAny())
+
+	upload = &models.Upload{ContentType: "image/png", Bytes: policy.MaxBytes + 1}
+	verrs := policy.ValidateUpload(models.DocumentTypeADVANCEWORKSHEET, upload, policy.MaxUploads)
+	suite.Equal([]string{"image/png files can't be uploaded to advance worksheet documents, only application/pdf."}, verrs.Get("content_type"))
+	suite.Equal([]string{"Files uploaded to advance worksheet documents must be at most 10 MB."}, verrs.Get("bytes"))
+	suite.Equal([]string{"No more than 2 files can be uploaded to advance worksheet documents."}, verrs.Get("uploads"))
+
+	// Documents without a known type get the least restrictive policy
+	suite.Equal(models.DocumentPolicies[models.DocumentTypeOTHER], models.DocumentType("").Policy())
+}
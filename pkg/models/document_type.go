@@ -0,0 +1,137 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobuffalo/validate"
+	"github.com/gobuffalo/validate/validators"
+)
+
+// DocumentType is the kind of paperwork a Document holds
+type DocumentType string
+
+const (
+	// DocumentTypeORDERS captures enum value "ORDERS"
+	DocumentTypeORDERS DocumentType = "ORDERS"
+	// DocumentTypeAMENDMENT captures enum value "AMENDMENT"
+	DocumentTypeAMENDMENT DocumentType = "AMENDMENT"
+	// DocumentTypeWEIGHTTICKET captures enum value "WEIGHT_TICKET"
+	DocumentTypeWEIGHTTICKET DocumentType = "WEIGHT_TICKET"
+	// DocumentTypeEXPENSERECEIPT captures enum value "EXPENSE_RECEIPT"
+	DocumentTypeEXPENSERECEIPT DocumentType = "EXPENSE_RECEIPT"
+	// DocumentTypeADVANCEWORKSHEET captures enum value "ADVANCE_WORKSHEET"
+	DocumentTypeADVANCEWORKSHEET DocumentType = "ADVANCE_WORKSHEET"
+	// DocumentTypePOWEROFATTORNEY captures enum value "POWER_OF_ATTORNEY"
+	DocumentTypePOWEROFATTORNEY DocumentType = "POWER_OF_ATTORNEY"
+	// DocumentTypeOTHER captures enum value "OTHER"
+	DocumentTypeOTHER DocumentType = "OTHER"
+)
+
+// megabyte is the number of bytes in a megabyte, for upload size limits
+const megabyte = 1000 * 1000
+
+// DocumentPolicy limits the uploads a type of document may have
+type DocumentPolicy struct {
+	// AllowedContentTypes are the MIME types of files that may be uploaded
+	AllowedContentTypes []string
+	// MaxBytes is the largest file that may be uploaded
+	MaxBytes int64
+	// MaxUploads is the most files a document may have
+	MaxUploads int
+	// RequiresOfficeReview is whether an office user must review each upload before the move or
+	// PPM the document belongs to can be approved
+	RequiresOfficeReview bool
+}
+
+var scannedPaperwork = []string{"application/pdf", "image/jpeg", "image/png"}
+
+// DocumentPolicies holds the policy of each DocumentType
+var DocumentPolicies = map[DocumentType]DocumentPolicy{
+	DocumentTypeORDERS:           {AllowedContentTypes: scannedPaperwork, MaxBytes: 25 * megabyte, MaxUploads: 10, RequiresOfficeReview: true},
+	DocumentTypeAMENDMENT:        {AllowedContentTypes: scannedPaperwork, MaxBytes: 25 * megabyte, MaxUploads: 10, RequiresOfficeReview: true},
+	DocumentTypeWEIGHTTICKET:     {AllowedContentTypes: scannedPaperwork, MaxBytes: 10 * megabyte, MaxUploads: 4, RequiresOfficeReview: true},
+	DocumentTypeEXPENSERECEIPT:   {AllowedContentTypes: scannedPaperwork, MaxBytes: 10 * megabyte, MaxUploads: 20, RequiresOfficeReview: true},
+	DocumentTypeADVANCEWORKSHEET: {AllowedContentTypes: []string{"application/pdf"}, MaxBytes: 10 * megabyte, MaxUploads: 2, RequiresOfficeReview: true},
+	DocumentTypePOWEROFATTORNEY:  {AllowedContentTypes: scannedPaperwork, MaxBytes: 10 * megabyte, MaxUploads: 5, RequiresOfficeReview: true},
+	DocumentTypeOTHER:            {AllowedContentTypes: scannedPaperwork, MaxBytes: 25 * megabyte, MaxUploads: 20, RequiresOfficeReview: false},
+}
+
+// DocumentTypes lists every DocumentType, in the order they are offered to service members
+var DocumentTypes = []DocumentType{
+	DocumentTypeORDERS,
+	DocumentTypeAMENDMENT,
+	DocumentTypeWEIGHTTICKET,
+	DocumentTypeEXPENSERECEIPT,
+	DocumentTypeADVANCEWORKSHEET,
+	DocumentTypePOWEROFATTORNEY,
+	DocumentTypeOTHER,
+}
+
+// validDocumentTypes lists the DocumentTypes a Document may have
+func validDocumentTypes() []string {
+	types := make([]string, len(DocumentTypes))
+	for i, documentType := range DocumentTypes {
+		types[i] = string(documentType)
+	}
+	return types
+}
+
+// Policy returns the upload policy of a DocumentType
+func (t DocumentType) Policy() DocumentPolicy {
+	if policy, ok := DocumentPolicies[t]; ok {
+		return policy
+	}
+	return DocumentPolicies[DocumentTypeOTHER]
+}
+
+// Describe returns the document type in words, e.g. "weight ticket"
+func (t DocumentType) Describe() string {
+	return strings.ToLower(strings.Replace(string(t), "_", " ", -1))
+}
+
+// ValidateUpload checks that upload may be added to a document of type documentType which already
+// has existingUploads uploads
+func (p DocumentPolicy) ValidateUpload(documentType DocumentType, upload *Upload, existingUploads int) *validate.Errors {
+	return validate.Validate(&uploadPolicyValidator{
+		policy:          p,
+		documentType:    documentType,
+		upload:          upload,
+		existingUploads: existingUploads,
+	})
+}
+
+// uploadPolicyValidator validates an upload against the policy of the document it is added to
+type uploadPolicyValidator struct {
+	policy          DocumentPolicy
+	documentType    DocumentType
+	upload          *Upload
+	existingUploads int
+}
+
+// IsValid adds an error for each limit of the policy the upload exceeds
+func (v *uploadPolicyValidator) IsValid(errors *validate.Errors) {
+	description := v.documentType.Describe()
+
+	allowed := false
+	for _, contentType := range v.policy.AllowedContentTypes {
+		if contentType == v.upload.ContentType {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		errors.Add(validators.GenerateKey("ContentType"), fmt.Sprintf("%s files can't be uploaded to %s documents, only %s.",
+			v.upload.ContentType, description, strings.Join(v.policy.AllowedContentTypes, ", ")))
+	}
+
+	if v.upload.Bytes > v.policy.MaxBytes {
+		errors.Add(validators.GenerateKey("Bytes"), fmt.Sprintf("Files uploaded to %s documents must be at most %d MB.",
+			description, v.policy.MaxBytes/megabyte))
+	}
+
+	if v.existingUploads >= v.policy.MaxUploads {
+		errors.Add(validators.GenerateKey("Uploads"), fmt.Sprintf("No more than %d files can be uploaded to %s documents.",
+			v.policy.MaxUploads, description))
+	}
+}
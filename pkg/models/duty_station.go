@@ -57,31 +57,68 @@ func FetchDutyStation(tx *pop.Connection, id uuid.UUID) (DutyStation, error) {
 	return station, err
 }
 
-// FindDutyStations returns all duty stations matching a search query and military affiliation
+// FindDutyStations returns the duty stations best matching a search query, best match first
 func FindDutyStations(tx *pop.Connection, search string) (DutyStations, error) {
-	var stations DutyStations
+	results, err := SearchDutyStations(tx, search, nil, DefaultSearchLimit)
+	stations := make(DutyStations, len(results))
+	for i, result := range results {
+		stations[i] = result.DutyStation
+	}
+	return stations, err
+}
 
-	// ILIKE does case-insensitive pattern matching, "%" matches any string
-	// We build a query by inserting '%' between each letter in the search string.
-	// This allows matching substrings as well as abbreviations.
-	// It would probably be worth ordering the results by similarity to the search string, one day.
-	searchQuery := []rune("%")
+// DutyStationSearchResult is a duty station found by SearchDutyStations
+type DutyStationSearchResult struct {
+	DutyStation
+	// Score combines the similarity of the name to the search string with the proximity to the search point
+	Score float64
+	// Miles is the distance from the search point to the station's ZIP, if both are known
+	Miles *float64
+}
 
-	for _, runeChar := range search {
-		searchQuery = append(searchQuery, runeChar)
-		searchQuery = append(searchQuery, '%')
+// SearchDutyStations returns up to limit duty stations whose names are similar to search, or which
+// match it as an abbreviation, best match first. If near is given, stations closer to it rank higher.
+// The location of a station is the location of its ZIP.
+func SearchDutyStations(tx *pop.Connection, search string, near *GeoPoint, limit int) ([]DutyStationSearchResult, error) {
+	from := `duty_stations
+		JOIN addresses ON duty_stations.address_id = addresses.id
+		LEFT JOIN zip5_locations ON zip5_locations.zip5 = LEFT(addresses.postal_code, 5)`
+	sql := rankedSearchSQL("duty_stations", from, "zip5_locations.latitude", "zip5_locations.longitude", near != nil)
+
+	params := []interface{}{search, abbreviationPattern(search)}
+	if near != nil {
+		params = append(params, near.Latitude, near.Longitude)
 	}
-	queryString := string(searchQuery)
+	params = append(params, limit)
 
-	query := tx.Q().Eager().Where("name ILIKE $1", queryString)
+	matches := []rankedMatch{}
+	if err := tx.RawQuery(sql, params...).All(&matches); err != nil {
+		return nil, errors.Wrap(err, "searching duty stations")
+	}
+	if len(matches) == 0 {
+		return []DutyStationSearchResult{}, nil
+	}
 
-	if err := query.All(&stations); err != nil {
-		if errors.Cause(err).Error() != recordNotFoundErrorString {
-			return stations, err
-		}
+	ids := make([]interface{}, len(matches))
+	for i, match := range matches {
+		ids[i] = match.ID
+	}
+	var stations DutyStations
+	if err := tx.Eager().Where("id in (?)", ids...).All(&stations); err != nil {
+		return nil, errors.Wrap(err, "fetching matching duty stations")
+	}
+	byID := make(map[uuid.UUID]DutyStation, len(stations))
+	for _, station := range stations {
+		byID[station.ID] = station
 	}
 
-	return stations, nil
+	results := make([]DutyStationSearchResult, 0, len(matches))
+	for _, match := range matches {
+		if station, ok := byID[match.ID]; ok {
+			results = append(results, DutyStationSearchResult{DutyStation: station, Score: match.Score, Miles: match.Miles})
+		}
+	}
+	return results, nil
 }
 
 // FetchDutyStationTransportationOffice returns a transportation office for a duty station
@@ -99,3 +136,32 @@ func FetchDutyStationTransportationOffice(db *pop.Connection, dutyStationID uuid
 
 	return dutyStation.TransportationOffice, nil
 }
+
+// DutyStationPostalCodePair is an origin and destination ZIP5 for which orders have been issued
+type DutyStationPostalCodePair struct {
+	OriginPostalCode      string `db:"origin_postal_code"`
+	DestinationPostalCode string `db:"destination_postal_code"`
+	Orders                int    `db:"orders"`
+}
+
+// FetchCommonDutyStationPostalCodePairs returns up to limit of the most frequent pairs of current
+// and new duty station ZIP5s across all orders, most frequent first.
+func FetchCommonDutyStationPostalCodePairs(db *pop.Connection, limit int) ([]DutyStationPostalCodePair, error) {
+	sql := `SELECT
+			LEFT(origin_address.postal_code, 5) AS origin_postal_code,
+			LEFT(destination_address.postal_code, 5) AS destination_postal_code,
+			COUNT(*) AS orders
+		FROM orders
+		JOIN service_members ON orders.service_member_id = service_members.id
+		JOIN duty_stations AS origin_station ON service_members.duty_station_id = origin_station.id
+		JOIN addresses AS origin_address ON origin_station.address_id = origin_address.id
+		JOIN duty_stations AS destination_station ON orders.new_duty_station_id = destination_station.id
+		JOIN addresses AS destination_address ON destination_station.address_id = destination_address.id
+		GROUP BY 1, 2
+		ORDER BY orders DESC, origin_postal_code, destination_postal_code
+		LIMIT $1`
+
+	pairs := []DutyStationPostalCodePair{}
+	err := db.RawQuery(sql, limit).All(&pairs)
+	return pairs, err
+}
@@ -75,3 +75,94 @@ func (suite *ModelSuite) Test_FetchDutyStationTransportationOffice() {
 	}
 
 }
+
+func (suite *ModelSuite) Test_FetchCommonDutyStationPostalCodePairs() {
+	makeOrderFrom := func(station models.DutyStation) {
+		sm, err := testdatagen.MakeExtendedServiceMember(suite.db)
+		suite.Nil(err)
+		sm.DutyStationID = &station.ID
+		suite.mustSave(&sm)
+		// New duty station is always Air Station Yuma, 85364
+		_, err = testdatagen.MakeOrderForServiceMember(suite.db, sm)
+		suite.Nil(err)
+	}
+
+	pendleton, _ := testdatagen.MakeDutyStation(suite.db, "Camp Pendleton", internalmessages.AffiliationMARINES,
+		models.Address{StreetAddress1: "duty station", City: "Oceanside", State: "CA", PostalCode: "92055-1234"})
+	lejeune, _ := testdatagen.MakeDutyStation(suite.db, "Camp Lejeune", internalmessages.AffiliationMARINES,
+		models.Address{StreetAddress1: "duty station", City: "Jacksonville", State: "NC", PostalCode: "28542"})
+
+	makeOrderFrom(lejeune)
+	makeOrderFrom(pendleton)
+	makeOrderFrom(pendleton)
+
+	pairs, err := models.FetchCommonDutyStationPostalCodePairs(suite.db, 10)
+	suite.Nil(err)
+	if suite.Len(pairs, 2) {
+		suite.Equal(models.DutyStationPostalCodePair{OriginPostalCode: "92055", DestinationPostalCode: "85364", Orders: 2}, pairs[0])
+		suite.Equal(models.DutyStationPostalCodePair{OriginPostalCode: "28542", DestinationPostalCode: "85364", Orders: 1}, pairs[1])
+	}
+
+	pairs, err = models.FetchCommonDutyStationPostalCodePairs(suite.db, 1)
+	suite.Nil(err)
+	suite.Len(pairs, 1)
+}
+
+func (suite *ModelSuite) makeSearchDutyStation(name string, postalCode string, latitude float32, longitude float32) models.DutyStation {
+	suite.Nil(models.SaveZip5Location(suite.db, models.Zip5Location{Zip5: postalCode, Latitude: latitude, Longitude: longitude}))
+	address := models.Address{
+		StreetAddress1: "duty station",
+		City:           "city",
+		State:          "state",
+		PostalCode:     postalCode,
+	}
+	suite.mustSave(&address)
+	station := models.DutyStation{
+		Name:        name,
+		Affiliation: internalmessages.AffiliationARMY,
+		AddressID:   address.ID,
+	}
+	suite.mustSave(&station)
+	return station
+}
+
+func (suite *ModelSuite) TestSearchDutyStations() {
+	suite.makeSearchDutyStation("Fort Bliss", "79916", 31.8134, -106.4215)
+	suite.makeSearchDutyStation("Fort Bragg", "28310", 35.1409, -79.0061)
+	suite.makeSearchDutyStation("Fort Benning", "31905", 32.3643, -84.9510)
+
+	results, err := models.SearchDutyStations(suite.db, "fort", nil, 10)
+	suite.Nil(err)
+	suite.Len(results, 3)
+	for _, result := range results {
+		suite.Nil(result.Miles)
+	}
+
+	// Abbreviations still match
+	results, err = models.SearchDutyStations(suite.db, "ftbragg", nil, 10)
+	suite.Nil(err)
+	if suite.Len(results, 1) {
+		suite.Equal("Fort Bragg", results[0].Name)
+		suite.Equal("duty station", results[0].Address.StreetAddress1)
+	}
+
+	// Near Fayetteville, NC, Fort Bragg comes first
+	fayetteville := models.GeoPoint{Latitude: 35.0527, Longitude: -78.8784}
+	results, err = models.SearchDutyStations(suite.db, "fort", &fayetteville, 10)
+	suite.Nil(err)
+	if suite.Len(results, 3) {
+		suite.Equal("Fort Bragg", results[0].Name)
+		if suite.NotNil(results[0].Miles) {
+			suite.InDelta(9, *results[0].Miles, 3)
+		}
+		suite.True(results[0].Score > results[1].Score)
+	}
+
+	results, err = models.SearchDutyStations(suite.db, "fort", &fayetteville, 1)
+	suite.Nil(err)
+	suite.Len(results, 1)
+
+	results, err = models.SearchDutyStations(suite.db, "camp lejeune", &fayetteville, 10)
+	suite.Nil(err)
+	suite.Len(results, 0)
+}
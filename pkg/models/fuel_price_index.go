@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/pop"
+	"github.com/gobuffalo/uuid"
+	"github.com/gobuffalo/validate"
+	"github.com/gobuffalo/validate/validators"
+	"github.com/pkg/errors"
+
+	"github.com/transcom/mymove/pkg/unit"
+)
+
+// FuelPriceIndex is the DOE national average diesel price per gallon that applies to
+// shipments picked up during its effective dates.
+type FuelPriceIndex struct {
+	ID                    uuid.UUID       `json:"id" db:"id"`
+	CreatedAt             time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt             time.Time       `json:"updated_at" db:"updated_at"`
+	DieselPriceMillicents unit.Millicents `json:"diesel_price_millicents" db:"diesel_price_millicents"`
+	EffectiveDateLower    time.Time       `json:"effective_date_lower" db:"effective_date_lower"`
+	EffectiveDateUpper    time.Time       `json:"effective_date_upper" db:"effective_date_upper"`
+}
+
+// FuelPriceIndices is a list of FuelPriceIndex records
+type FuelPriceIndices []FuelPriceIndex
+
+// Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
+func (f *FuelPriceIndex) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	return validate.Validate(
+		&validators.IntIsPresent{Field: f.DieselPriceMillicents.Int(), Name: "DieselPriceMillicents"},
+		&validators.IntIsGreaterThan{Field: f.DieselPriceMillicents.Int(), Name: "DieselPriceMillicents", Compared: 0},
+		&validators.TimeAfterTime{
+			FirstTime: f.EffectiveDateUpper, FirstName: "EffectiveDateUpper",
+			SecondTime: f.EffectiveDateLower, SecondName: "EffectiveDateLower"},
+	), nil
+}
+
+// FetchFuelPriceIndex returns the fuel price index in effect on the given date.
+func FetchFuelPriceIndex(tx *pop.Connection, date time.Time) (FuelPriceIndex, error) {
+	index := FuelPriceIndex{}
+
+	sql := `SELECT
+			*
+		FROM
+			fuel_price_indices
+		WHERE
+			effective_date_lower <= $1 AND $1 < effective_date_upper
+		ORDER BY
+			effective_date_lower DESC`
+
+	err := tx.RawQuery(sql, date).First(&index)
+	if err != nil {
+		if errors.Cause(err).Error() == recordNotFoundErrorString {
+			return index, ErrFetchNotFound
+		}
+		return index, errors.Wrapf(err, "error fetching fuel price index for %s", date)
+	}
+	return index, nil
+}
@@ -0,0 +1,72 @@
+package models_test
+
+import (
+	"time"
+
+	. "github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/testdatagen"
+)
+
+func (suite *ModelSuite) Test_FuelPriceIndexValidation() {
+	now := time.Now()
+
+	validIndex := FuelPriceIndex{
+		DieselPriceMillicents: 325000,
+		EffectiveDateLower:    now,
+		EffectiveDateUpper:    now.AddDate(0, 0, 7),
+	}
+
+	expErrors := map[string][]string{}
+	suite.verifyValidationErrors(&validIndex, expErrors)
+
+	invalidIndex := FuelPriceIndex{
+		DieselPriceMillicents: 0,
+		EffectiveDateLower:    now,
+		EffectiveDateUpper:    now.AddDate(0, 0, -7),
+	}
+
+	expErrors = map[string][]string{
+		"diesel_price_millicents": []string{"DieselPriceMillicents can not be blank.", "0 is not greater than 0."},
+		"effective_date_upper":    []string{"EffectiveDateUpper must be after EffectiveDateLower."},
+	}
+	suite.verifyValidationErrors(&invalidIndex, expErrors)
+}
+
+func (suite *ModelSuite) Test_FetchFuelPriceIndex() {
+	t := suite.T()
+
+	weekStart := testdatagen.PeakRateCycleStart
+	index1 := FuelPriceIndex{
+		DieselPriceMillicents: 325000,
+		EffectiveDateLower:    weekStart,
+		EffectiveDateUpper:    weekStart.AddDate(0, 0, 7),
+	}
+	suite.mustSave(&index1)
+	index2 := FuelPriceIndex{
+		DieselPriceMillicents: 331000,
+		EffectiveDateLower:    weekStart.AddDate(0, 0, 7),
+		EffectiveDateUpper:    weekStart.AddDate(0, 0, 14),
+	}
+	suite.mustSave(&index2)
+
+	// Test inclusivity of EffectiveDateLower
+	index, err := FetchFuelPriceIndex(suite.db, weekStart)
+	if err != nil {
+		t.Fatalf("Unable to query fuel price index: %s", err)
+	}
+	if index.DieselPriceMillicents != 325000 {
+		t.Errorf("Incorrect fuel price. Got: %d, expected %d", index.DieselPriceMillicents, 325000)
+	}
+
+	// Test exclusivity of EffectiveDateUpper
+	index, err = FetchFuelPriceIndex(suite.db, weekStart.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatalf("Unable to query fuel price index: %s", err)
+	}
+	if index.DieselPriceMillicents != 331000 {
+		t.Errorf("Incorrect fuel price. Got: %d, expected %d", index.DieselPriceMillicents, 331000)
+	}
+
+	_, err = FetchFuelPriceIndex(suite.db, weekStart.AddDate(0, 0, 14))
+	suite.Equal(ErrFetchNotFound, err)
+}
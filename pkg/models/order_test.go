@@ -42,6 +42,7 @@ func (suite *ModelSuite) TestFetchOrder() {
 		ServiceMember:   serviceMember1,
 		ServiceMemberID: serviceMember1.ID,
 		Name:            UploadedOrdersDocumentName,
+		DocumentType:    DocumentTypeORDERS,
 	}
 	deptIndicator := testdatagen.DefaultDepartmentIndicator
 	TAC := testdatagen.DefaultTransportationAccountingCode
@@ -109,6 +110,7 @@ func (suite *ModelSuite) TestOrderStateMachine() {
 		ServiceMember:   serviceMember1,
 		ServiceMemberID: serviceMember1.ID,
 		Name:            UploadedOrdersDocumentName,
+		DocumentType:    DocumentTypeORDERS,
 	}
 	deptIndicator := testdatagen.DefaultDepartmentIndicator
 	TAC := testdatagen.DefaultTransportationAccountingCode
@@ -159,6 +161,7 @@ func (suite *ModelSuite) TestCanceledMoveCancelsOrder() {
 		ServiceMember:   serviceMember1,
 		ServiceMemberID: serviceMember1.ID,
 		Name:            UploadedOrdersDocumentName,
+		DocumentType:    DocumentTypeORDERS,
 	}
 	deptIndicator := testdatagen.DefaultDepartmentIndicator
 	TAC := testdatagen.DefaultTransportationAccountingCode
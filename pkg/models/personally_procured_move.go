@@ -32,32 +32,35 @@ const (
 
 // PersonallyProcuredMove is the portion of a move that a service member performs themselves
 type PersonallyProcuredMove struct {
-	ID                            uuid.UUID                    `json:"id" db:"id"`
-	MoveID                        uuid.UUID                    `json:"move_id" db:"move_id"`
-	Move                          Move                         `belongs_to:"move"`
-	CreatedAt                     time.Time                    `json:"created_at" db:"created_at"`
-	UpdatedAt                     time.Time                    `json:"updated_at" db:"updated_at"`
-	Size                          *internalmessages.TShirtSize `json:"size" db:"size"`
-	WeightEstimate                *int64                       `json:"weight_estimate" db:"weight_estimate"`
-	PlannedMoveDate               *time.Time                   `json:"planned_move_date" db:"planned_move_date"`
-	PickupPostalCode              *string                      `json:"pickup_postal_code" db:"pickup_postal_code"`
-	HasAdditionalPostalCode       *bool                        `json:"has_additional_postal_code" db:"has_additional_postal_code"`
-	AdditionalPickupPostalCode    *string                      `json:"additional_pickup_postal_code" db:"additional_pickup_postal_code"`
-	DestinationPostalCode         *string                      `json:"destination_postal_code" db:"destination_postal_code"`
-	HasSit                        *bool                        `json:"has_sit" db:"has_sit"`
-	DaysInStorage                 *int64                       `json:"days_in_storage" db:"days_in_storage"`
-	EstimatedStorageReimbursement *string                      `json:"estimated_storage_reimbursement" db:"estimated_storage_reimbursement"`
-	Mileage                       *int64                       `json:"mileage" db:"mileage"`
-	PlannedSITMax                 *unit.Cents                  `json:"planned_sit_max" db:"planned_sit_max"`
-	SITMax                        *unit.Cents                  `json:"sit_max" db:"sit_max"`
-	IncentiveEstimateMin          *unit.Cents                  `json:"incentive_estimate_min" db:"incentive_estimate_min"`
-	IncentiveEstimateMax          *unit.Cents                  `json:"incentive_estimate_max" db:"incentive_estimate_max"`
-	Status                        PPMStatus                    `json:"status" db:"status"`
-	HasRequestedAdvance           bool                         `json:"has_requested_advance" db:"has_requested_advance"`
-	AdvanceID                     *uuid.UUID                   `json:"advance_id" db:"advance_id"`
-	Advance                       *Reimbursement               `belongs_to:"reimbursements"`
-	AdvanceWorksheet              Document                     `belongs_to:"documents"`
-	AdvanceWorksheetID            *uuid.UUID                   `json:"advance_worksheet_id" db:"advance_worksheet_id"`
+	ID                            uuid.UUID                        `json:"id" db:"id"`
+	MoveID                        uuid.UUID                        `json:"move_id" db:"move_id"`
+	Move                          Move                             `belongs_to:"move"`
+	CreatedAt                     time.Time                        `json:"created_at" db:"created_at"`
+	UpdatedAt                     time.Time                        `json:"updated_at" db:"updated_at"`
+	Size                          *internalmessages.TShirtSize     `json:"size" db:"size"`
+	WeightEstimate                *int64                           `json:"weight_estimate" db:"weight_estimate"`
+	PlannedMoveDate               *time.Time                       `json:"planned_move_date" db:"planned_move_date"`
+	PickupPostalCode              *string                          `json:"pickup_postal_code" db:"pickup_postal_code"`
+	HasAdditionalPostalCode       *bool                            `json:"has_additional_postal_code" db:"has_additional_postal_code"`
+	AdditionalPickupPostalCode    *string                          `json:"additional_pickup_postal_code" db:"additional_pickup_postal_code"`
+	DestinationPostalCode         *string                          `json:"destination_postal_code" db:"destination_postal_code"`
+	HasSit                        *bool                            `json:"has_sit" db:"has_sit"`
+	DaysInStorage                 *int64                           `json:"days_in_storage" db:"days_in_storage"`
+	EstimatedStorageReimbursement *string                          `json:"estimated_storage_reimbursement" db:"estimated_storage_reimbursement"`
+	Mileage                       *int64                           `json:"mileage" db:"mileage"`
+	MileageProvider               *string                          `json:"mileage_provider" db:"mileage_provider"`
+	MileageProfile                *string                          `json:"mileage_profile" db:"mileage_profile"`
+	VehicleType                   *internalmessages.PPMVehicleType `json:"vehicle_type" db:"vehicle_type"`
+	PlannedSITMax                 *unit.Cents                      `json:"planned_sit_max" db:"planned_sit_max"`
+	SITMax                        *unit.Cents                      `json:"sit_max" db:"sit_max"`
+	IncentiveEstimateMin          *unit.Cents                      `json:"incentive_estimate_min" db:"incentive_estimate_min"`
+	IncentiveEstimateMax          *unit.Cents                      `json:"incentive_estimate_max" db:"incentive_estimate_max"`
+	Status                        PPMStatus                        `json:"status" db:"status"`
+	HasRequestedAdvance           bool                             `json:"has_requested_advance" db:"has_requested_advance"`
+	AdvanceID                     *uuid.UUID                       `json:"advance_id" db:"advance_id"`
+	Advance                       *Reimbursement                   `belongs_to:"reimbursements"`
+	AdvanceWorksheet              Document                         `belongs_to:"documents"`
+	AdvanceWorksheetID            *uuid.UUID                       `json:"advance_worksheet_id" db:"advance_worksheet_id"`
 }
 
 // PersonallyProcuredMoves is a list of PPMs
@@ -83,6 +86,20 @@ func (p *PersonallyProcuredMove) ValidateUpdate(tx *pop.Connection) (*validate.E
 	return validate.NewErrors(), nil
 }
 
+// SetMileage records the mileage of a PPM, along with the route planner which answered it and
+// the vehicle profile the planner routed. Either may be empty if the planner didn't say.
+func (p *PersonallyProcuredMove) SetMileage(mileage int64, provider string, profile string) {
+	p.Mileage = &mileage
+	p.MileageProvider = nil
+	if provider != "" {
+		p.MileageProvider = &provider
+	}
+	p.MileageProfile = nil
+	if profile != "" {
+		p.MileageProfile = &profile
+	}
+}
+
 // State Machine
 // Avoid calling PersonallyProcuredMove.Status = ... ever. Use these methods to change the state.
 
@@ -115,6 +132,29 @@ func FetchPersonallyProcuredMove(db *pop.Connection, session *auth.Session, id u
 	return &ppm, nil
 }
 
+// FetchPPMsForReestimate returns the DRAFT and SUBMITTED PPMs with a planned move date in
+// [start, end) that have enough information to be priced by the rate engine.
+func FetchPPMsForReestimate(db *pop.Connection, start time.Time, end time.Time) (PersonallyProcuredMoves, error) {
+	ppms := PersonallyProcuredMoves{}
+
+	sql := `SELECT
+			*
+		FROM
+			personally_procured_moves
+		WHERE
+			status IN ($1, $2)
+			AND planned_move_date >= $3 AND planned_move_date < $4
+			AND weight_estimate IS NOT NULL
+			AND pickup_postal_code IS NOT NULL
+			AND destination_postal_code IS NOT NULL
+		ORDER BY
+			planned_move_date ASC
+		`
+
+	err := db.RawQuery(sql, PPMStatusDRAFT, PPMStatusSUBMITTED, start, end).All(&ppms)
+	return ppms, err
+}
+
 // SavePersonallyProcuredMove Safely saves a PPM and it's associated Advance.
 func SavePersonallyProcuredMove(db *pop.Connection, ppm *PersonallyProcuredMove) (*validate.Errors, error) {
 	responseVErrors := validate.NewErrors()
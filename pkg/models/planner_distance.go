@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/pop"
+	"github.com/gobuffalo/uuid"
+	"github.com/gobuffalo/validate"
+	"github.com/gobuffalo/validate/validators"
+	"github.com/pkg/errors"
+)
+
+// PlannerDistance is a distance returned by a route planner provider, cached so that the
+// provider doesn't need to be asked again for the same source and destination. Profile is the key
+// of the vehicle profile the distance was routed for, or empty if the provider doesn't route by vehicle.
+type PlannerDistance struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	Source      string    `json:"source" db:"source"`
+	Destination string    `json:"destination" db:"destination"`
+	Provider    string    `json:"provider" db:"provider"`
+	Profile     string    `json:"profile" db:"profile"`
+	Distance    int       `json:"distance" db:"distance"`
+	FetchedAt   time.Time `json:"fetched_at" db:"fetched_at"`
+}
+
+// PlannerDistances is a list of PlannerDistance records
+type PlannerDistances []PlannerDistance
+
+// Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
+func (p *PlannerDistance) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	return validate.Validate(
+		&validators.StringIsPresent{Field: p.Source, Name: "Source"},
+		&validators.StringIsPresent{Field: p.Destination, Name: "Destination"},
+		&validators.StringIsPresent{Field: p.Provider, Name: "Provider"},
+		&validators.IntIsGreaterThan{Field: p.Distance, Name: "Distance", Compared: -1},
+		&validators.TimeIsPresent{Field: p.FetchedAt, Name: "FetchedAt"},
+	), nil
+}
+
+// FetchPlannerDistance returns the cached distance from a provider for a vehicle profile between
+// source and destination
+func FetchPlannerDistance(db *pop.Connection, source string, destination string, provider string, profile string) (PlannerDistance, error) {
+	distance := PlannerDistance{}
+	err := db.Where("source = ? AND destination = ? AND provider = ? AND profile = ?", source, destination, provider, profile).First(&distance)
+	if err != nil {
+		if errors.Cause(err).Error() == recordNotFoundErrorString {
+			return distance, ErrFetchNotFound
+		}
+		return distance, errors.Wrap(err, "fetching planner distance")
+	}
+	return distance, nil
+}
+
+// SavePlannerDistance creates or replaces the cached distance from a provider for a vehicle profile
+// between source and destination
+func SavePlannerDistance(db *pop.Connection, source string, destination string, provider string, profile string, distance int, fetchedAt time.Time) error {
+	plannerDistance := PlannerDistance{
+		ID:          uuid.Must(uuid.NewV4()),
+		Source:      source,
+		Destination: destination,
+		Provider:    provider,
+		Profile:     profile,
+		Distance:    distance,
+		FetchedAt:   fetchedAt,
+	}
+	verrs, err := plannerDistance.Validate(db)
+	if err != nil {
+		return err
+	}
+	if verrs.HasAny() {
+		return errors.Errorf("invalid planner distance: %v", verrs)
+	}
+
+	now := time.Now()
+	sql := `INSERT INTO planner_distances
+			(id, created_at, updated_at, source, destination, provider, profile, distance, fetched_at)
+		VALUES
+			($1, $2, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (source, destination, provider, profile) DO UPDATE SET
+			updated_at = EXCLUDED.updated_at,
+			distance = EXCLUDED.distance,
+			fetched_at = EXCLUDED.fetched_at`
+	err = db.RawQuery(sql, plannerDistance.ID, now, source, destination, provider, profile, distance, fetchedAt).Exec()
+	return errors.Wrap(err, "saving planner distance")
+}
@@ -0,0 +1,57 @@
+package models_test
+
+import (
+	"time"
+
+	. "github.com/transcom/mymove/pkg/models"
+)
+
+func (suite *ModelSuite) Test_PlannerDistanceValidation() {
+	plannerDistance := &PlannerDistance{Distance: -1}
+
+	expErrors := map[string][]string{
+		"source":      []string{"Source can not be blank."},
+		"destination": []string{"Destination can not be blank."},
+		"provider":    []string{"Provider can not be blank."},
+		"distance":    []string{"-1 is not greater than -1."},
+		"fetched_at":  []string{"FetchedAt can not be blank."},
+	}
+	suite.verifyValidationErrors(plannerDistance, expErrors)
+}
+
+func (suite *ModelSuite) Test_SaveAndFetchPlannerDistance() {
+	fetchedAt := time.Date(2018, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := FetchPlannerDistance(suite.db, "zip5:94103", "zip5:20301", "here", "truck")
+	suite.Equal(ErrFetchNotFound, err)
+
+	suite.Nil(SavePlannerDistance(suite.db, "zip5:94103", "zip5:20301", "here", "truck", 2800, fetchedAt))
+	suite.Nil(SavePlannerDistance(suite.db, "zip5:94103", "zip5:20301", "bing", "truck", 2810, fetchedAt))
+
+	distance, err := FetchPlannerDistance(suite.db, "zip5:94103", "zip5:20301", "here", "truck")
+	suite.Nil(err)
+	suite.Equal(2800, distance.Distance)
+	suite.True(fetchedAt.Equal(distance.FetchedAt))
+
+	// Saving again replaces the cached distance
+	refetchedAt := fetchedAt.AddDate(0, 1, 0)
+	suite.Nil(SavePlannerDistance(suite.db, "zip5:94103", "zip5:20301", "here", "truck", 2790, refetchedAt))
+	distance, err = FetchPlannerDistance(suite.db, "zip5:94103", "zip5:20301", "here", "truck")
+	suite.Nil(err)
+	suite.Equal(2790, distance.Distance)
+	suite.True(refetchedAt.Equal(distance.FetchedAt))
+
+	// Each vehicle profile has its own entry
+	_, err = FetchPlannerDistance(suite.db, "zip5:94103", "zip5:20301", "here", "car")
+	suite.Equal(ErrFetchNotFound, err)
+	suite.Nil(SavePlannerDistance(suite.db, "zip5:94103", "zip5:20301", "here", "car", 2750, refetchedAt))
+	distance, err = FetchPlannerDistance(suite.db, "zip5:94103", "zip5:20301", "here", "truck")
+	suite.Nil(err)
+	suite.Equal(2790, distance.Distance)
+
+	count, err := suite.db.Count(&PlannerDistance{})
+	suite.Nil(err)
+	suite.Equal(3, count)
+
+	suite.NotNil(SavePlannerDistance(suite.db, "", "zip5:20301", "here", "truck", 2790, refetchedAt))
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/pop"
+	"github.com/gobuffalo/uuid"
+	"github.com/gobuffalo/validate"
+	"github.com/gobuffalo/validate/validators"
+
+	"github.com/transcom/mymove/pkg/unit"
+)
+
+// PPMEstimateAudit records a change made to the cached estimate fields of a PPM
+// by a batch re-estimation, along with the values that were replaced.
+type PPMEstimateAudit struct {
+	ID                           uuid.UUID              `json:"id" db:"id"`
+	CreatedAt                    time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt                    time.Time              `json:"updated_at" db:"updated_at"`
+	PersonallyProcuredMoveID     uuid.UUID              `json:"personally_procured_move_id" db:"personally_procured_move_id"`
+	PersonallyProcuredMove       PersonallyProcuredMove `belongs_to:"personally_procured_moves"`
+	Reason                       string                 `json:"reason" db:"reason"`
+	PreviousIncentiveEstimateMin *unit.Cents            `json:"previous_incentive_estimate_min" db:"previous_incentive_estimate_min"`
+	PreviousIncentiveEstimateMax *unit.Cents            `json:"previous_incentive_estimate_max" db:"previous_incentive_estimate_max"`
+	PreviousPlannedSITMax        *unit.Cents            `json:"previous_planned_sit_max" db:"previous_planned_sit_max"`
+	PreviousMileage              *int64                 `json:"previous_mileage" db:"previous_mileage"`
+	IncentiveEstimateMin         unit.Cents             `json:"incentive_estimate_min" db:"incentive_estimate_min"`
+	IncentiveEstimateMax         unit.Cents             `json:"incentive_estimate_max" db:"incentive_estimate_max"`
+	PlannedSITMax                unit.Cents             `json:"planned_sit_max" db:"planned_sit_max"`
+	Mileage                      int64                  `json:"mileage" db:"mileage"`
+}
+
+// PPMEstimateAudits is a list of PPMEstimateAudit records
+type PPMEstimateAudits []PPMEstimateAudit
+
+// Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
+func (a *PPMEstimateAudit) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	return validate.Validate(
+		&validators.UUIDIsPresent{Field: a.PersonallyProcuredMoveID, Name: "PersonallyProcuredMoveID"},
+		&validators.StringIsPresent{Field: a.Reason, Name: "Reason"},
+		&validators.IntIsGreaterThan{Field: a.IncentiveEstimateMin.Int(), Name: "IncentiveEstimateMin", Compared: -1},
+		&validators.IntIsGreaterThan{Field: a.IncentiveEstimateMax.Int(), Name: "IncentiveEstimateMax", Compared: -1},
+	), nil
+}
+
+// NewPPMEstimateAudit builds an audit record capturing the current estimate fields of
+// the PPM before they are replaced with the supplied values.
+func NewPPMEstimateAudit(ppm PersonallyProcuredMove, reason string, min unit.Cents, max unit.Cents, plannedSITMax unit.Cents, mileage int64) PPMEstimateAudit {
+	return PPMEstimateAudit{
+		PersonallyProcuredMoveID:     ppm.ID,
+		Reason:                       reason,
+		PreviousIncentiveEstimateMin: ppm.IncentiveEstimateMin,
+		PreviousIncentiveEstimateMax: ppm.IncentiveEstimateMax,
+		PreviousPlannedSITMax:        ppm.PlannedSITMax,
+		PreviousMileage:              ppm.Mileage,
+		IncentiveEstimateMin:         min,
+		IncentiveEstimateMax:         max,
+		PlannedSITMax:                plannedSITMax,
+		Mileage:                      mileage,
+	}
+}
+
+// FetchPPMEstimateAudits returns the estimate audit trail for a PPM, most recent first.
+func FetchPPMEstimateAudits(db *pop.Connection, ppmID uuid.UUID) (PPMEstimateAudits, error) {
+	audits := PPMEstimateAudits{}
+	err := db.Where("personally_procured_move_id = $1", ppmID).Order("created_at desc").All(&audits)
+	return audits, err
+}
@@ -0,0 +1,37 @@
+package models_test
+
+import (
+	. "github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/testdatagen"
+	"github.com/transcom/mymove/pkg/unit"
+)
+
+func (suite *ModelSuite) Test_PPMEstimateAuditValidation() {
+	audit := &PPMEstimateAudit{}
+
+	expErrors := map[string][]string{
+		"personally_procured_move_id": {"PersonallyProcuredMoveID can not be blank."},
+		"reason":                      {"Reason can not be blank."},
+	}
+
+	suite.verifyValidationErrors(audit, expErrors)
+}
+
+func (suite *ModelSuite) Test_FetchPPMEstimateAudits() {
+	ppm, err := testdatagen.MakePPM(suite.db)
+	suite.Nil(err)
+
+	previous := unit.Cents(1000)
+	ppm.IncentiveEstimateMax = &previous
+
+	audit := NewPPMEstimateAudit(ppm, "tariff load", unit.Cents(1900), unit.Cents(2100), unit.Cents(0), 100)
+	suite.mustSave(&audit)
+
+	audits, err := FetchPPMEstimateAudits(suite.db, ppm.ID)
+	suite.Nil(err)
+	if suite.Len(audits, 1) {
+		suite.Equal(previous, *audits[0].PreviousIncentiveEstimateMax)
+		suite.Equal(unit.Cents(2100), audits[0].IncentiveEstimateMax)
+		suite.Nil(audits[0].PreviousIncentiveEstimateMin)
+	}
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobuffalo/uuid"
+)
+
+// GeoPoint is a location in degrees of latitude and longitude
+type GeoPoint struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// earthRadiusMiles is the mean radius of the Earth
+const earthRadiusMiles = 3958.8
+
+// proximityWeight is how much being right next to the search point adds to a search score. Trigram
+// similarity, the rest of the score, is between 0 and 1.
+const proximityWeight = 0.5
+
+// proximityScaleMiles is the distance at which being near the search point adds half of proximityWeight
+const proximityScaleMiles = 50.0
+
+// DefaultSearchLimit is how many results the ranked searches return when no limit is given
+const DefaultSearchLimit = 20
+
+// greatCircleMilesSQL returns a SQL expression for the distance in miles between the latitude and
+// longitude columns and the point given by the latitude and longitude parameters, e.g. "$2" and "$3".
+// It is NULL where the columns are.
+func greatCircleMilesSQL(latitudeColumn string, longitudeColumn string, latitudeParam string, longitudeParam string) string {
+	// The spherical law of cosines. LEAST guards acos against rounding just above 1 for identical points.
+	return fmt.Sprintf(`(%f * acos(LEAST(1.0,
+		cos(radians(%s)) * cos(radians(%s)) * cos(radians(%s) - radians(%s))
+		+ sin(radians(%s)) * sin(radians(%s)))))`,
+		earthRadiusMiles,
+		latitudeParam, latitudeColumn, longitudeColumn, longitudeParam,
+		latitudeParam, latitudeColumn)
+}
+
+// proximityScoreSQL returns a SQL expression for the part of a search score given by a distance in miles.
+// It falls from proximityWeight at no distance towards 0 far away, and is 0 when the distance is NULL.
+func proximityScoreSQL(milesSQL string) string {
+	return fmt.Sprintf("COALESCE(%f / (1 + %s / %f), 0)", proximityWeight, milesSQL, proximityScaleMiles)
+}
+
+// abbreviationPattern returns an ILIKE pattern with '%' around each letter of search, so that
+// abbreviations such as "ftbragg" match "Fort Bragg". Wildcards in search are escaped.
+func abbreviationPattern(search string) string {
+	pattern := []rune("%")
+	for _, runeChar := range search {
+		switch runeChar {
+		case '%', '_', '\\':
+			pattern = append(pattern, '\\')
+		case ' ':
+			continue
+		}
+		pattern = append(pattern, runeChar, '%')
+	}
+	return string(pattern)
+}
+
+// rankedSearchSQL returns the query shared by the ranked searches of duty stations and transportation
+// offices. The name is compared with search, $1, and the ILIKE pattern, $2. If near is set, the
+// proximity of the latitude and longitude columns to $3, $4 is added to the score. The limit is the
+// last parameter. It selects the id, score and miles of each match, best first.
+func rankedSearchSQL(table string, from string, latitudeColumn string, longitudeColumn string, near bool) string {
+	milesSQL := "NULL::float"
+	limitParam := "$3"
+	if near {
+		milesSQL = greatCircleMilesSQL(latitudeColumn, longitudeColumn, "$3", "$4")
+		limitParam = "$5"
+	}
+	sql := `SELECT id, score, miles FROM (
+			SELECT
+				{table}.id AS id,
+				{table}.name AS name,
+				similarity({table}.name, $1) + {proximity} AS score,
+				{miles} AS miles
+			FROM {from}
+			WHERE {table}.name ILIKE $2 OR {table}.name % $1
+		) AS matches
+		ORDER BY score DESC, name
+		LIMIT {limit}`
+	return strings.NewReplacer(
+		"{table}", table,
+		"{from}", from,
+		"{proximity}", proximityScoreSQL(milesSQL),
+		"{miles}", milesSQL,
+		"{limit}", limitParam,
+	).Replace(sql)
+}
+
+// rankedMatch is one row of a ranked search
+type rankedMatch struct {
+	ID    uuid.UUID `db:"id"`
+	Score float64   `db:"score"`
+	Miles *float64  `db:"miles"`
+}
@@ -185,6 +185,7 @@ func (s ServiceMember) CreateOrder(db *pop.Connection,
 			ServiceMemberID: s.ID,
 			ServiceMember:   s,
 			Name:            UploadedOrdersDocumentName,
+			DocumentType:    DocumentTypeORDERS,
 		}
 		verrs, err := db.ValidateAndCreate(&uploadedOrders)
 		if err != nil || verrs.HasAny() {
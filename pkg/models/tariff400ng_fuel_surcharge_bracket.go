@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/pop"
+	"github.com/gobuffalo/uuid"
+	"github.com/gobuffalo/validate"
+	"github.com/gobuffalo/validate/validators"
+	"github.com/pkg/errors"
+
+	"github.com/transcom/mymove/pkg/unit"
+)
+
+// Tariff400ngFuelSurchargeBracket is the percentage of the linehaul charge added as a
+// fuel surcharge when the diesel price falls within the bracket. The percentage is kept in
+// basis points so that it is exact.
+type Tariff400ngFuelSurchargeBracket struct {
+	ID                   uuid.UUID        `json:"id" db:"id"`
+	CreatedAt            time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time        `json:"updated_at" db:"updated_at"`
+	PriceMillicentsLower unit.Millicents  `json:"price_millicents_lower" db:"price_millicents_lower"`
+	PriceMillicentsUpper unit.Millicents  `json:"price_millicents_upper" db:"price_millicents_upper"`
+	SurchargeBasisPoints unit.BasisPoints `json:"surcharge_basis_points" db:"surcharge_basis_points"`
+	EffectiveDateLower   time.Time        `json:"effective_date_lower" db:"effective_date_lower"`
+	EffectiveDateUpper   time.Time        `json:"effective_date_upper" db:"effective_date_upper"`
+}
+
+// Tariff400ngFuelSurchargeBrackets is a list of Tariff400ngFuelSurchargeBracket records
+type Tariff400ngFuelSurchargeBrackets []Tariff400ngFuelSurchargeBracket
+
+// Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
+func (t *Tariff400ngFuelSurchargeBracket) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	return validate.Validate(
+		&validators.IntIsGreaterThan{Field: t.PriceMillicentsLower.Int(), Name: "PriceMillicentsLower", Compared: -1},
+		&validators.IntIsLessThan{Field: t.PriceMillicentsLower.Int(), Name: "PriceMillicentsLower",
+			Compared: t.PriceMillicentsUpper.Int()},
+		&IntIsNotNegative{Field: t.SurchargeBasisPoints.Int(), Name: "SurchargeBasisPoints"},
+		&validators.TimeAfterTime{
+			FirstTime: t.EffectiveDateUpper, FirstName: "EffectiveDateUpper",
+			SecondTime: t.EffectiveDateLower, SecondName: "EffectiveDateLower"},
+	), nil
+}
+
+// FetchFuelSurchargeBasisPoints returns the fuel surcharge, in basis points of linehaul, for a
+// diesel price on the given date.
+func FetchFuelSurchargeBasisPoints(tx *pop.Connection, priceMillicents unit.Millicents, date time.Time) (unit.BasisPoints, error) {
+	brackets := Tariff400ngFuelSurchargeBrackets{}
+
+	sql := `SELECT
+			*
+		FROM
+			tariff400ng_fuel_surcharge_brackets
+		WHERE
+			price_millicents_lower <= $1 AND $1 < price_millicents_upper
+		AND
+			effective_date_lower <= $2 AND $2 < effective_date_upper`
+
+	err := tx.RawQuery(sql, priceMillicents, date).All(&brackets)
+	if err != nil {
+		return 0, errors.Wrapf(err, "error fetching fuel surcharge bracket for %d millicents on %s", priceMillicents, date)
+	}
+	if len(brackets) == 0 {
+		return 0, ErrFetchNotFound
+	}
+	if len(brackets) != 1 {
+		return 0, errors.Errorf("Wanted 1 fuel surcharge bracket, found %d brackets for parameters: %v millicents, %v",
+			len(brackets), priceMillicents, date)
+	}
+
+	return brackets[0].SurchargeBasisPoints, nil
+}
@@ -0,0 +1,80 @@
+package models_test
+
+import (
+	"time"
+
+	. "github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/testdatagen"
+)
+
+func (suite *ModelSuite) Test_FuelSurchargeBracketValidation() {
+	now := time.Now()
+
+	validBracket := Tariff400ngFuelSurchargeBracket{
+		PriceMillicentsLower: 300000,
+		PriceMillicentsUpper: 305000,
+		SurchargeBasisPoints: 1050,
+		EffectiveDateLower:   now,
+		EffectiveDateUpper:   now.AddDate(1, 0, 0),
+	}
+
+	expErrors := map[string][]string{}
+	suite.verifyValidationErrors(&validBracket, expErrors)
+
+	invalidBracket := Tariff400ngFuelSurchargeBracket{
+		PriceMillicentsLower: 305000,
+		PriceMillicentsUpper: 300000,
+		SurchargeBasisPoints: -1,
+		EffectiveDateLower:   now,
+		EffectiveDateUpper:   now.AddDate(-1, 0, 0),
+	}
+
+	expErrors = map[string][]string{
+		"price_millicents_lower": []string{"305000 is not less than 300000."},
+		"surcharge_basis_points": []string{"SurchargeBasisPoints must not be negative"},
+		"effective_date_upper":   []string{"EffectiveDateUpper must be after EffectiveDateLower."},
+	}
+	suite.verifyValidationErrors(&invalidBracket, expErrors)
+}
+
+func (suite *ModelSuite) Test_FetchFuelSurchargeBasisPoints() {
+	t := suite.T()
+
+	bracket1 := Tariff400ngFuelSurchargeBracket{
+		PriceMillicentsLower: 300000,
+		PriceMillicentsUpper: 305000,
+		SurchargeBasisPoints: 1050,
+		EffectiveDateLower:   testdatagen.PeakRateCycleStart,
+		EffectiveDateUpper:   testdatagen.PeakRateCycleEnd,
+	}
+	suite.mustSave(&bracket1)
+	bracket2 := Tariff400ngFuelSurchargeBracket{
+		PriceMillicentsLower: 305000,
+		PriceMillicentsUpper: 310000,
+		SurchargeBasisPoints: 1100,
+		EffectiveDateLower:   testdatagen.PeakRateCycleStart,
+		EffectiveDateUpper:   testdatagen.PeakRateCycleEnd,
+	}
+	suite.mustSave(&bracket2)
+
+	// Test inclusivity of PriceMillicentsLower
+	surcharge, err := FetchFuelSurchargeBasisPoints(suite.db, 300000, testdatagen.DateInsidePeakRateCycle)
+	if err != nil {
+		t.Fatalf("Unable to query fuel surcharge: %s", err)
+	}
+	if surcharge != 1050 {
+		t.Errorf("Incorrect fuel surcharge. Got: %d, expected %d", surcharge, 1050)
+	}
+
+	// Test exclusivity of PriceMillicentsUpper
+	surcharge, err = FetchFuelSurchargeBasisPoints(suite.db, 305000, testdatagen.DateInsidePeakRateCycle)
+	if err != nil {
+		t.Fatalf("Unable to query fuel surcharge: %s", err)
+	}
+	if surcharge != 1100 {
+		t.Errorf("Incorrect fuel surcharge. Got: %d, expected %d", surcharge, 1100)
+	}
+
+	_, err = FetchFuelSurchargeBasisPoints(suite.db, 300000, testdatagen.DateOutsidePeakRateCycle)
+	suite.Equal(ErrFetchNotFound, err)
+}
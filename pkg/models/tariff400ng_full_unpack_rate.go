@@ -8,17 +8,19 @@ import (
 	"github.com/gobuffalo/validate"
 	"github.com/gobuffalo/validate/validators"
 	"github.com/pkg/errors"
+
+	"github.com/transcom/mymove/pkg/unit"
 )
 
 // Tariff400ngFullUnpackRate describes the rates paid to unpack various weights of goods
 type Tariff400ngFullUnpackRate struct {
-	ID                 uuid.UUID `json:"id" db:"id"`
-	CreatedAt          time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
-	Schedule           int       `json:"schedule" db:"schedule"`
-	RateMillicents     int       `json:"rate_millicents" db:"rate_millicents"`
-	EffectiveDateLower time.Time `json:"effective_date_lower" db:"effective_date_lower"`
-	EffectiveDateUpper time.Time `json:"effective_date_upper" db:"effective_date_upper"`
+	ID                 uuid.UUID       `json:"id" db:"id"`
+	CreatedAt          time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at" db:"updated_at"`
+	Schedule           int             `json:"schedule" db:"schedule"`
+	RateMillicents     unit.Millicents `json:"rate_millicents" db:"rate_millicents"`
+	EffectiveDateLower time.Time       `json:"effective_date_lower" db:"effective_date_lower"`
+	EffectiveDateUpper time.Time       `json:"effective_date_upper" db:"effective_date_upper"`
 }
 
 // Tariff400ngFullUnpackRates is not required by pop and may be deleted
@@ -28,7 +30,7 @@ type Tariff400ngFullUnpackRates []Tariff400ngFullUnpackRate
 // This method is not required and may be deleted.
 func (t *Tariff400ngFullUnpackRate) Validate(tx *pop.Connection) (*validate.Errors, error) {
 	return validate.Validate(
-		&validators.IntIsGreaterThan{Field: t.RateMillicents, Name: "RateMillicents", Compared: -1},
+		&validators.IntIsGreaterThan{Field: t.RateMillicents.Int(), Name: "RateMillicents", Compared: -1},
 		&validators.TimeAfterTime{
 			FirstTime: t.EffectiveDateUpper, FirstName: "EffectiveDateUpper",
 			SecondTime: t.EffectiveDateLower, SecondName: "EffectiveDateLower"},
@@ -37,7 +39,7 @@ func (t *Tariff400ngFullUnpackRate) Validate(tx *pop.Connection) (*validate.Erro
 
 // FetchTariff400ngFullUnpackRateMillicents returns the full unpack rate for a service
 // schedule.
-func FetchTariff400ngFullUnpackRateMillicents(tx *pop.Connection, serviceSchedule int, date time.Time) (int, error) {
+func FetchTariff400ngFullUnpackRateMillicents(tx *pop.Connection, serviceSchedule int, date time.Time) (unit.Millicents, error) {
 	rate := Tariff400ngFullUnpackRate{}
 
 	sql := `SELECT *
@@ -5,6 +5,7 @@ import (
 
 	. "github.com/transcom/mymove/pkg/models"
 	"github.com/transcom/mymove/pkg/testdatagen"
+	"github.com/transcom/mymove/pkg/unit"
 )
 
 func (suite *ModelSuite) Test_UnpackEffectiveDateValidation() {
@@ -63,7 +64,7 @@ func (suite *ModelSuite) Test_UnpackCreateAndSave() {
 func (suite *ModelSuite) Test_FetchFullUnPackRateCents() {
 	t := suite.T()
 
-	rateExpected := 100
+	rateExpected := unit.Millicents(100)
 	schedule := 1
 
 	fupr := Tariff400ngFullUnpackRate{
@@ -1,11 +1,14 @@
 package models
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/gobuffalo/pop"
 	"github.com/gobuffalo/uuid"
 	"github.com/gobuffalo/validate"
 	"github.com/gobuffalo/validate/validators"
-	"time"
+	"github.com/pkg/errors"
 )
 
 // TransportationOffice is a PPPO, PPSO or JPPSO. If it is its own shipping office, ShippingOffice will be nil,
@@ -52,3 +55,82 @@ func (t *TransportationOffice) ValidateCreate(tx *pop.Connection) (*validate.Err
 func (t *TransportationOffice) ValidateUpdate(tx *pop.Connection) (*validate.Errors, error) {
 	return validate.NewErrors(), nil
 }
+
+// TransportationOfficeSearchResult is a transportation office found by SearchTransportationOffices or
+// FetchNearestTransportationOffices
+type TransportationOfficeSearchResult struct {
+	TransportationOffice
+	// Score combines the similarity of the name to the search string with the proximity to the search point
+	Score float64
+	// Miles is the distance from the search point to the office, if both are known
+	Miles *float64
+}
+
+// officeLatitudeSQL is the latitude of an office, or NULL if it was never located. No office is on the equator.
+const officeLatitudeSQL = "NULLIF(transportation_offices.latitude, 0)"
+
+// SearchTransportationOffices returns up to limit transportation offices whose names are similar to search,
+// or which match it as an abbreviation, best match first. If near is given, offices closer to it rank higher.
+func SearchTransportationOffices(tx *pop.Connection, search string, near *GeoPoint, limit int) ([]TransportationOfficeSearchResult, error) {
+	sql := rankedSearchSQL("transportation_offices", "transportation_offices", officeLatitudeSQL, "transportation_offices.longitude", near != nil)
+
+	params := []interface{}{search, abbreviationPattern(search)}
+	if near != nil {
+		params = append(params, near.Latitude, near.Longitude)
+	}
+	params = append(params, limit)
+
+	matches := []rankedMatch{}
+	if err := tx.RawQuery(sql, params...).All(&matches); err != nil {
+		return nil, errors.Wrap(err, "searching transportation offices")
+	}
+	return fetchTransportationOfficeMatches(tx, matches)
+}
+
+// FetchNearestTransportationOffices returns up to limit of the transportation offices nearest to a point,
+// nearest first. Offices without a location are left out.
+func FetchNearestTransportationOffices(tx *pop.Connection, near GeoPoint, limit int) ([]TransportationOfficeSearchResult, error) {
+	milesSQL := greatCircleMilesSQL(officeLatitudeSQL, "transportation_offices.longitude", "$1", "$2")
+	sql := fmt.Sprintf(`SELECT id, 0.0::float AS score, miles FROM (
+			SELECT transportation_offices.id AS id, transportation_offices.name AS name, %s AS miles
+			FROM transportation_offices
+		) AS offices
+		WHERE miles IS NOT NULL
+		ORDER BY miles, name
+		LIMIT $3`, milesSQL)
+
+	matches := []rankedMatch{}
+	if err := tx.RawQuery(sql, near.Latitude, near.Longitude, limit).All(&matches); err != nil {
+		return nil, errors.Wrap(err, "fetching nearest transportation offices")
+	}
+	return fetchTransportationOfficeMatches(tx, matches)
+}
+
+// fetchTransportationOfficeMatches loads the offices found by a search, along with their addresses and
+// phone lines, keeping the order of the matches
+func fetchTransportationOfficeMatches(tx *pop.Connection, matches []rankedMatch) ([]TransportationOfficeSearchResult, error) {
+	results := make([]TransportationOfficeSearchResult, 0, len(matches))
+	if len(matches) == 0 {
+		return results, nil
+	}
+
+	ids := make([]interface{}, len(matches))
+	for i, match := range matches {
+		ids[i] = match.ID
+	}
+	var offices TransportationOffices
+	if err := tx.Eager("Address", "PhoneLines").Where("id in (?)", ids...).All(&offices); err != nil {
+		return nil, errors.Wrap(err, "fetching matching transportation offices")
+	}
+	byID := make(map[uuid.UUID]TransportationOffice, len(offices))
+	for _, office := range offices {
+		byID[office.ID] = office
+	}
+
+	for _, match := range matches {
+		if office, ok := byID[match.ID]; ok {
+			results = append(results, TransportationOfficeSearchResult{TransportationOffice: office, Score: match.Score, Miles: match.Miles})
+		}
+	}
+	return results, nil
+}
@@ -61,3 +61,62 @@ func (suite *ModelSuite) Test_TransportationOffice() {
 	suite.Equal(ppo.ID, loadedOffice.ID)
 	suite.Equal(jppso.ID, loadedOffice.ShippingOffice.ID)
 }
+
+func (suite *ModelSuite) makeLocatedOffice(name string, latitude float32, longitude float32) TransportationOffice {
+	address := Address{
+		StreetAddress1: "1 Office Rd",
+		City:           "city",
+		State:          "state",
+		PostalCode:     "12345",
+	}
+	suite.mustSave(&address)
+	office := TransportationOffice{
+		Name:      name,
+		AddressID: address.ID,
+		Latitude:  latitude,
+		Longitude: longitude,
+	}
+	suite.mustSave(&office)
+	return office
+}
+
+func (suite *ModelSuite) Test_FetchNearestTransportationOffices() {
+	suite.makeLocatedOffice("PPPO Fort Bliss", 31.8134, -106.4215)
+	suite.makeLocatedOffice("PPPO Fort Bragg", 35.1409, -79.0061)
+	suite.makeLocatedOffice("PPPO Nowhere", 0, 0)
+
+	fayetteville := GeoPoint{Latitude: 35.0527, Longitude: -78.8784}
+	results, err := FetchNearestTransportationOffices(suite.db, fayetteville, 10)
+	suite.Nil(err)
+	// The office without a location is left out
+	if suite.Len(results, 2) {
+		suite.Equal("PPPO Fort Bragg", results[0].Name)
+		suite.Equal("1 Office Rd", results[0].Address.StreetAddress1)
+		suite.InDelta(9, *results[0].Miles, 3)
+		suite.Equal("PPPO Fort Bliss", results[1].Name)
+		suite.InDelta(1600, *results[1].Miles, 50)
+	}
+
+	results, err = FetchNearestTransportationOffices(suite.db, fayetteville, 1)
+	suite.Nil(err)
+	suite.Len(results, 1)
+}
+
+func (suite *ModelSuite) Test_SearchTransportationOffices() {
+	suite.makeLocatedOffice("PPPO Fort Bliss", 31.8134, -106.4215)
+	suite.makeLocatedOffice("PPPO Fort Bragg", 35.1409, -79.0061)
+
+	elPaso := GeoPoint{Latitude: 31.7619, Longitude: -106.4850}
+	results, err := SearchTransportationOffices(suite.db, "pppo fort", &elPaso, 10)
+	suite.Nil(err)
+	if suite.Len(results, 2) {
+		suite.Equal("PPPO Fort Bliss", results[0].Name)
+	}
+
+	results, err = SearchTransportationOffices(suite.db, "bragg", nil, 10)
+	suite.Nil(err)
+	if suite.Len(results, 1) {
+		suite.Equal("PPPO Fort Bragg", results[0].Name)
+		suite.Nil(results[0].Miles)
+	}
+}
@@ -238,6 +238,23 @@ func GetRateCycle(year int, peak bool) (start time.Time, end time.Time) {
 	return start, end
 }
 
+// GetRateCycleForDate returns the start and end dates of the rate cycle that contains
+// the given date, and whether that cycle is the peak season.
+func GetRateCycleForDate(date time.Time) (start time.Time, end time.Time, peak bool) {
+	year := date.Year()
+	start, end = GetRateCycle(year, true)
+	if !date.Before(start) && date.Before(end) {
+		return start, end, true
+	}
+	if date.Before(start) {
+		// Early in the year, before the peak season starts, belongs to the non-peak
+		// cycle that began the previous October.
+		year--
+	}
+	start, end = GetRateCycle(year, false)
+	return start, end, false
+}
+
 // FetchDiscountRates returns the discount linehaul and SIT rates for the TSP with the highest
 // BVS during the specified data, limited to those TSPs in the channel defined by the
 // originZip and destinationZip.
@@ -89,6 +89,29 @@ func (suite *ModelSuite) Test_GetRateCycle() {
 	}
 }
 
+func (suite *ModelSuite) Test_GetRateCycleForDate() {
+	start, end, peak := GetRateCycleForDate(testdatagen.DateInsidePeakRateCycle)
+	suite.True(peak)
+	suite.Equal(testdatagen.PeakRateCycleStart, start)
+	suite.Equal(testdatagen.PeakRateCycleEnd, end)
+
+	start, end, peak = GetRateCycleForDate(testdatagen.DateInsideNonPeakRateCycle)
+	suite.False(peak)
+	suite.Equal(testdatagen.NonPeakRateCycleStart, start)
+	suite.Equal(testdatagen.NonPeakRateCycleEnd, end)
+
+	// The first instant of a cycle belongs to that cycle
+	_, _, peak = GetRateCycleForDate(testdatagen.PeakRateCycleEnd)
+	suite.False(peak)
+
+	// January belongs to the non-peak cycle that started the previous October
+	january := time.Date(testdatagen.TestYear, time.January, 10, 0, 0, 0, 0, time.UTC)
+	start, end, peak = GetRateCycleForDate(january)
+	suite.False(peak)
+	suite.Equal(time.Date(testdatagen.TestYear-1, time.October, 1, 0, 0, 0, 0, time.UTC), start)
+	suite.Equal(testdatagen.PeakRateCycleStart, end)
+}
+
 func (suite *ModelSuite) Test_IncrementTSPPerformanceOfferCount() {
 	t := suite.T()
 
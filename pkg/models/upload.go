@@ -11,6 +11,30 @@ import (
 	"github.com/transcom/mymove/pkg/auth"
 )
 
+// UploadScanStatus is the outcome of scanning an upload's file for malware
+type UploadScanStatus string
+
+const (
+	// UploadScanStatusPENDING captures enum value "PENDING"
+	UploadScanStatusPENDING UploadScanStatus = "PENDING"
+	// UploadScanStatusCLEAN captures enum value "CLEAN"
+	UploadScanStatusCLEAN UploadScanStatus = "CLEAN"
+	// UploadScanStatusINFECTED captures enum value "INFECTED"
+	UploadScanStatusINFECTED UploadScanStatus = "INFECTED"
+)
+
+// UploadPreviewStatus is the state of the small image shown in place of an upload's file
+type UploadPreviewStatus string
+
+const (
+	// UploadPreviewStatusPENDING captures enum value "PENDING"
+	UploadPreviewStatusPENDING UploadPreviewStatus = "PENDING"
+	// UploadPreviewStatusREADY captures enum value "READY"
+	UploadPreviewStatusREADY UploadPreviewStatus = "READY"
+	// UploadPreviewStatusUNAVAILABLE captures enum value "UNAVAILABLE"
+	UploadPreviewStatusUNAVAILABLE UploadPreviewStatus = "UNAVAILABLE"
+)
+
 // An Upload represents an uploaded file, such as an image or PDF.
 type Upload struct {
 	ID          uuid.UUID `db:"id"`
@@ -21,8 +45,24 @@ type Upload struct {
 	Bytes       int64     `db:"bytes"`
 	ContentType string    `db:"content_type"`
 	Checksum    string    `db:"checksum"`
-	CreatedAt   time.Time `db:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at"`
+	// Only CLEAN uploads may be opened. Uploads which haven't been scanned yet have no ScannedAt,
+	// nor do those made before scanning, which are CLEAN so that they can still be opened.
+	ScanStatus UploadScanStatus `db:"scan_status"`
+	ScannedAt  *time.Time       `db:"scanned_at"`
+	// The ID of the master key the file was encrypted with, if it was encrypted
+	EncryptionKeyID *string             `db:"encryption_key_id"`
+	PreviewStatus   UploadPreviewStatus `db:"preview_status"`
+	// The hex encoded SHA-256 of the file, which uploads made before it was recorded lack
+	ContentSHA256 *string `db:"content_sha256"`
+	// Whether the file is stored once under its SHA-256, shared with every other upload of
+	// the same content, rather than under the upload's own key
+	ContentAddressed bool `db:"content_addressed"`
+	// When, and by which office user, the upload was reviewed, for documents whose type
+	// requires it
+	ReviewedAt *time.Time `db:"reviewed_at"`
+	ReviewerID *uuid.UUID `db:"reviewer_id"`
+	CreatedAt  time.Time  `db:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at"`
 }
 
 // Uploads is not required by pop and may be deleted
@@ -59,6 +99,121 @@ func FetchUpload(db *pop.Connection, session *auth.Session, id uuid.UUID) (Uploa
 	return upload, nil
 }
 
+// FetchUnreviewedUploads returns the uploads of the document with the given ID which no office
+// user has reviewed yet, if its type requires office review. Infected uploads are never shown,
+// so they are never reviewed either.
+func FetchUnreviewedUploads(db *pop.Connection, documentID uuid.UUID) (Uploads, error) {
+	uploads := Uploads{}
+	var document Document
+	if err := db.Find(&document, documentID); err != nil {
+		return uploads, errors.Wrap(err, "fetching document")
+	}
+	if !document.DocumentType.Policy().RequiresOfficeReview {
+		return uploads, nil
+	}
+	err := db.Where("document_id = ? AND reviewed_at IS NULL AND scan_status != ?", documentID, UploadScanStatusINFECTED).Order("created_at asc").All(&uploads)
+	if err != nil {
+		return uploads, errors.Wrap(err, "fetching unreviewed uploads")
+	}
+	return uploads, nil
+}
+
+// FetchPendingUploads returns up to limit uploads which have not been scanned yet, oldest first
+func FetchPendingUploads(db *pop.Connection, limit int) (Uploads, error) {
+	uploads := Uploads{}
+	err := db.Where("scan_status = ?", UploadScanStatusPENDING).Order("created_at asc").Limit(limit).All(&uploads)
+	if err != nil {
+		return uploads, errors.Wrap(err, "fetching pending uploads")
+	}
+	return uploads, nil
+}
+
+// FetchUploadsToPreview returns up to limit clean uploads which have no preview yet, oldest first
+func FetchUploadsToPreview(db *pop.Connection, limit int) (Uploads, error) {
+	uploads := Uploads{}
+	err := db.Where("scan_status = ? AND preview_status = ?", UploadScanStatusCLEAN, UploadPreviewStatusPENDING).Order("created_at asc").Limit(limit).All(&uploads)
+	if err != nil {
+		return uploads, errors.Wrap(err, "fetching uploads to preview")
+	}
+	return uploads, nil
+}
+
+// FetchUploadsToRewrap returns up to limit uploads whose files weren't encrypted with the master key
+// keyID, oldest first
+func FetchUploadsToRewrap(db *pop.Connection, keyID string, limit int) (Uploads, error) {
+	uploads := Uploads{}
+	err := db.Where("encryption_key_id IS NULL OR encryption_key_id != ?", keyID).Order("created_at asc").Limit(limit).All(&uploads)
+	if err != nil {
+		return uploads, errors.Wrap(err, "fetching uploads to rewrap")
+	}
+	return uploads, nil
+}
+
+// FetchDuplicateUpload returns the earliest other upload of the same document with the same
+// content as upload, or nil if there is none
+func FetchDuplicateUpload(db *pop.Connection, upload *Upload) (*Upload, error) {
+	if upload.ContentSHA256 == nil {
+		return nil, nil
+	}
+	uploads := Uploads{}
+	err := db.Where("document_id = ? AND content_sha256 = ? AND id != ?", upload.DocumentID, *upload.ContentSHA256, upload.ID).Order("created_at asc").Limit(1).All(&uploads)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching duplicate upload")
+	}
+	if len(uploads) == 0 {
+		return nil, nil
+	}
+	return &uploads[0], nil
+}
+
+// Duplicate returns the earliest other upload in uploads with the same content as upload, or nil
+// if there is none. It answers FetchDuplicateUpload for uploads already loaded.
+func (uploads Uploads) Duplicate(upload *Upload) *Upload {
+	if upload.ContentSHA256 == nil {
+		return nil
+	}
+	var duplicate *Upload
+	for i := range uploads {
+		other := &uploads[i]
+		if other.ID == upload.ID || other.ContentSHA256 == nil || *other.ContentSHA256 != *upload.ContentSHA256 {
+			continue
+		}
+		if duplicate == nil || other.CreatedAt.Before(duplicate.CreatedAt) {
+			duplicate = other
+		}
+	}
+	return duplicate
+}
+
+// FetchContentReference returns an upload which refers to the content-addressed file with the
+// given SHA-256, or nil if none does any more. Quarantined uploads never refer to one.
+func FetchContentReference(db *pop.Connection, contentSHA256 string) (*Upload, error) {
+	uploads := Uploads{}
+	err := db.Where("content_addressed = true AND content_sha256 = ? AND scan_status != ?", contentSHA256, UploadScanStatusINFECTED).Limit(1).All(&uploads)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching content reference")
+	}
+	if len(uploads) == 0 {
+		return nil, nil
+	}
+	return &uploads[0], nil
+}
+
+// LockUploadContent locks the content-addressed file with the given SHA-256 until the end of
+// the transaction db is in, so that no upload starts or stops referring to it meanwhile.
+func LockUploadContent(db *pop.Connection, contentSHA256 string) error {
+	err := db.RawQuery("SELECT pg_advisory_xact_lock(hashtext(?))", contentSHA256).Exec()
+	return errors.Wrap(err, "locking upload content")
+}
+
+// UpdateContentEncryptionKeyID records that the content-addressed file with the given SHA-256
+// was encrypted with the master key keyID, on every upload which refers to it
+func UpdateContentEncryptionKeyID(db *pop.Connection, contentSHA256 string, keyID *string) error {
+	err := db.RawQuery("UPDATE uploads SET encryption_key_id = ?, updated_at = now() WHERE content_addressed = true AND content_sha256 = ? AND scan_status != ?",
+		keyID, contentSHA256, UploadScanStatusINFECTED).Exec()
+	return errors.Wrap(err, "updating content encryption key")
+}
+
 // DeleteUpload deletes an upload from the database
 func DeleteUpload(db *pop.Connection, upload *Upload) error {
 	return db.Destroy(upload)
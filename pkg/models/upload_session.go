@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/pop"
+	"github.com/gobuffalo/uuid"
+	"github.com/gobuffalo/validate"
+	"github.com/gobuffalo/validate/validators"
+	"github.com/pkg/errors"
+	"github.com/transcom/mymove/pkg/auth"
+)
+
+// An UploadSession collects a file which is uploaded in chunks, so that an interrupted upload
+// can be resumed from where it stopped. Once every byte has been received the session is
+// finalized into an Upload.
+type UploadSession struct {
+	ID         uuid.UUID `db:"id"`
+	DocumentID uuid.UUID `db:"document_id"`
+	UploaderID uuid.UUID `db:"uploader_id"`
+	Filename   string    `db:"filename"`
+	// Bytes and Checksum describe the whole file, and are checked when the session is finalized
+	Bytes    int64  `db:"bytes"`
+	Checksum string `db:"checksum"`
+	// ReceivedBytes is the offset the next chunk must start at
+	ReceivedBytes int64     `db:"received_bytes"`
+	Chunks        int       `db:"chunks"`
+	ExpiresAt     time.Time `db:"expires_at"`
+	CreatedAt     time.Time `db:"created_at"`
+	UpdatedAt     time.Time `db:"updated_at"`
+}
+
+// UploadSessions is not required by pop and may be deleted
+type UploadSessions []UploadSession
+
+// Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
+func (s *UploadSession) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	return validate.Validate(
+		&validators.UUIDIsPresent{Field: s.DocumentID, Name: "DocumentID"},
+		&validators.UUIDIsPresent{Field: s.UploaderID, Name: "UploaderID"},
+		&validators.StringIsPresent{Field: s.Filename, Name: "Filename"},
+		&validators.StringIsPresent{Field: s.Checksum, Name: "Checksum"},
+		&validators.IntIsGreaterThan{Field: int(s.Bytes), Name: "Bytes", Compared: 0},
+		&validators.IntIsLessThan{Field: int(s.ReceivedBytes), Name: "ReceivedBytes", Compared: int(s.Bytes) + 1},
+		&validators.TimeIsPresent{Field: s.ExpiresAt, Name: "ExpiresAt"},
+	), nil
+}
+
+// Complete returns true once every byte of the file has been received
+func (s *UploadSession) Complete() bool {
+	return s.ReceivedBytes == s.Bytes
+}
+
+// FetchUploadSession returns an unexpired UploadSession if the user started it
+func FetchUploadSession(db *pop.Connection, session *auth.Session, id uuid.UUID) (UploadSession, error) {
+	var uploadSession UploadSession
+	err := db.Find(&uploadSession, id)
+	if err != nil {
+		if errors.Cause(err).Error() == recordNotFoundErrorString {
+			return UploadSession{}, ErrFetchNotFound
+		}
+		// Otherwise, it's an unexpected err so we return that.
+		return UploadSession{}, err
+	}
+
+	if time.Now().After(uploadSession.ExpiresAt) {
+		return UploadSession{}, ErrFetchNotFound
+	}
+	if uploadSession.UploaderID != session.UserID {
+		return UploadSession{}, ErrFetchForbidden
+	}
+	return uploadSession, nil
+}
+
+// FetchExpiredUploadSessions returns up to limit UploadSessions which expired before they were
+// finalized, oldest first
+func FetchExpiredUploadSessions(db *pop.Connection, limit int) (UploadSessions, error) {
+	uploadSessions := UploadSessions{}
+	err := db.Where("expires_at < ?", time.Now()).Order("expires_at asc").Limit(limit).All(&uploadSessions)
+	if err != nil {
+		return uploadSessions, errors.Wrap(err, "fetching expired upload sessions")
+	}
+	return uploadSessions, nil
+}
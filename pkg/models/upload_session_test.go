@@ -0,0 +1,56 @@
+package models_test
+
+import (
+	"time"
+
+	"github.com/transcom/mymove/pkg/auth"
+	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/testdatagen"
+)
+
+func (suite *ModelSuite) Test_UploadSessionValidations() {
+	session := &models.UploadSession{Bytes: 10, ReceivedBytes: 11}
+
+	expErrors := map[string][]string{
+		"document_id":    {"DocumentID can not be blank."},
+		"uploader_id":    {"UploaderID can not be blank."},
+		"filename":       {"Filename can not be blank."},
+		"checksum":       {"Checksum can not be blank."},
+		"received_bytes": {"11 is not less than 11."},
+		"expires_at":     {"ExpiresAt can not be blank."},
+	}
+	suite.verifyValidationErrors(session, expErrors)
+}
+
+func (suite *ModelSuite) Test_FetchUploadSession() {
+	document, err := testdatagen.MakeDocument(suite.db, nil, "")
+	suite.Nil(err)
+	session := models.UploadSession{
+		DocumentID: document.ID,
+		UploaderID: document.ServiceMember.UserID,
+		Filename:   "test.pdf",
+		Bytes:      10,
+		Checksum:   "ImGQ2Ush0bDHsaQthV5BnQ==",
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}
+	suite.mustSave(&session)
+
+	owner := &auth.Session{ApplicationName: auth.MyApp, UserID: document.ServiceMember.UserID}
+	fetched, err := models.FetchUploadSession(suite.db, owner, session.ID)
+	suite.Nil(err)
+	suite.Equal(session.ID, fetched.ID)
+
+	other, err := testdatagen.MakeServiceMember(suite.db)
+	suite.Nil(err)
+	_, err = models.FetchUploadSession(suite.db, &auth.Session{ApplicationName: auth.MyApp, UserID: other.UserID}, session.ID)
+	suite.Equal(models.ErrFetchForbidden, err)
+
+	// Expired sessions can't be resumed, and are left for clean up
+	session.ExpiresAt = time.Now().Add(-time.Hour)
+	suite.mustSave(&session)
+	_, err = models.FetchUploadSession(suite.db, owner, session.ID)
+	suite.Equal(models.ErrFetchNotFound, err)
+	expired, err := models.FetchExpiredUploadSessions(suite.db, 10)
+	suite.Nil(err)
+	suite.Len(expired, 1)
+}
@@ -72,6 +72,34 @@ func (v *DiscountRateIsValid) IsValid(errors *validate.Errors) {
 	}
 }
 
+// IntIsNotNegative validates that an int is zero or greater.
+type IntIsNotNegative struct {
+	Name  string
+	Field int
+}
+
+// IsValid adds an error if the value is less than zero.
+func (v *IntIsNotNegative) IsValid(errors *validate.Errors) {
+	if v.Field < 0 {
+		errors.Add(validators.GenerateKey(v.Name), fmt.Sprintf("%s must not be negative", v.Name))
+	}
+}
+
+// Float64IsInRange validates that a float64 is between Min and Max inclusive.
+type Float64IsInRange struct {
+	Name  string
+	Field float64
+	Min   float64
+	Max   float64
+}
+
+// IsValid adds an error if the value is outside the range.
+func (v *Float64IsInRange) IsValid(errors *validate.Errors) {
+	if v.Field < v.Min || v.Field > v.Max {
+		errors.Add(validators.GenerateKey(v.Name), fmt.Sprintf("%s must be between %v and %v", v.Name, v.Min, v.Max))
+	}
+}
+
 // AllowedFileType validates that a content-type is contained in our list of accepted types.
 type AllowedFileType struct {
 	validators.StringInclusion
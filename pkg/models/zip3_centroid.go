@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/pop"
+	"github.com/pkg/errors"
+
+	"github.com/transcom/mymove/pkg/unit"
+)
+
+// Zip3Centroid is the center of the ZIP5s in a ZIP3, along with the tariff areas the ZIP3 is in.
+// A ZIP3 split between rate areas has a centroid for the ZIP5s in each of its rate areas.
+type Zip3Centroid struct {
+	Zip3          string  `db:"zip3"`
+	BasepointCity string  `db:"basepoint_city"`
+	State         string  `db:"state"`
+	RateArea      string  `db:"rate_area"`
+	Region        string  `db:"region"`
+	ServiceArea   string  `db:"service_area"`
+	Split         bool    `db:"split"`
+	Latitude      float64 `db:"latitude"`
+	Longitude     float64 `db:"longitude"`
+	Zip5Count     int     `db:"zip5_count"`
+	// The service area fields are only set if the service area has rates effective on the date fetched
+	ServiceAreaName    *string     `db:"service_area_name"`
+	ServicesSchedule   *int        `db:"services_schedule"`
+	ServiceChargeCents *unit.Cents `db:"service_charge_cents"`
+}
+
+// Zip3Centroids is a list of Zip3Centroid
+type Zip3Centroids []Zip3Centroid
+
+// FetchZip3Centroids returns the centroid of each ZIP3 with known ZIP5 locations, in ZIP3 order, with
+// the service area rates effective on date
+func FetchZip3Centroids(db *pop.Connection, date time.Time) (Zip3Centroids, error) {
+	centroids := Zip3Centroids{}
+	sql := `SELECT
+			z3.zip3,
+			z3.basepoint_city,
+			z3.state,
+			COALESCE(r5.rate_area, z3.rate_area) AS rate_area,
+			z3.region,
+			z3.service_area,
+			z3.rate_area = 'ZIP' AS split,
+			AVG(l.latitude) AS latitude,
+			AVG(l.longitude) AS longitude,
+			COUNT(l.zip5) AS zip5_count,
+			sa.name AS service_area_name,
+			sa.services_schedule,
+			sa.service_charge_cents
+		FROM
+			tariff400ng_zip3s AS z3
+		JOIN
+			zip5_locations AS l ON LEFT(l.zip5, 3) = z3.zip3
+		LEFT JOIN
+			tariff400ng_zip5_rate_areas AS r5 ON z3.rate_area = 'ZIP' AND r5.zip5 = l.zip5
+		LEFT JOIN
+			tariff400ng_service_areas AS sa ON sa.service_area = z3.service_area
+				AND sa.effective_date_lower <= $1
+				AND sa.effective_date_upper > $1
+		GROUP BY
+			z3.zip3, z3.basepoint_city, z3.state, COALESCE(r5.rate_area, z3.rate_area), z3.region,
+			z3.service_area, z3.rate_area, sa.name, sa.services_schedule, sa.service_charge_cents
+		ORDER BY
+			z3.zip3, rate_area`
+
+	err := db.RawQuery(sql, date).All(&centroids)
+	if err != nil {
+		return centroids, errors.Wrap(err, "fetching ZIP3 centroids")
+	}
+	return centroids, nil
+}
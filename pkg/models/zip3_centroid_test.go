@@ -0,0 +1,68 @@
+package models_test
+
+import (
+	"time"
+
+	. "github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/unit"
+)
+
+func (suite *ModelSuite) Test_FetchZip3Centroids() {
+	now := time.Now()
+	suite.mustSave(&Tariff400ngZip3{Zip3: "941", BasepointCity: "San Francisco", State: "CA", ServiceArea: "80", RateArea: "US87", Region: "2"})
+	suite.mustSave(&Tariff400ngZip3{Zip3: "322", BasepointCity: "Jacksonville", State: "FL", ServiceArea: "184", RateArea: "ZIP", Region: "13"})
+	// Without ZIP5 locations there is nothing to place
+	suite.mustSave(&Tariff400ngZip3{Zip3: "999", BasepointCity: "Ketchikan", State: "AK", ServiceArea: "1", RateArea: "US8190100", Region: "1"})
+	suite.mustSave(&Tariff400ngZip5RateArea{Zip5: "32207", RateArea: "US4964400"})
+	suite.mustSave(&Tariff400ngZip5RateArea{Zip5: "32210", RateArea: "US4964400"})
+	suite.mustSave(&Tariff400ngZip5RateArea{Zip5: "32226", RateArea: "US49"})
+	suite.mustSave(&Tariff400ngServiceArea{
+		Name:               "San Francisco, CA",
+		ServiceArea:        "80",
+		ServicesSchedule:   3,
+		ServiceChargeCents: unit.Cents(350),
+		EffectiveDateLower: now.AddDate(0, -1, 0),
+		EffectiveDateUpper: now.AddDate(0, 1, 0),
+		SIT185ARateCents:   unit.Cents(50),
+		SIT185BRateCents:   unit.Cents(50),
+		SITPDSchedule:      1,
+	})
+
+	for _, location := range []Zip5Location{
+		{Zip5: "94103", Latitude: 37.0, Longitude: -122.0},
+		{Zip5: "94107", Latitude: 38.0, Longitude: -123.0},
+		{Zip5: "32207", Latitude: 30.0, Longitude: -81.0},
+		{Zip5: "32210", Latitude: 31.0, Longitude: -82.0},
+		{Zip5: "32226", Latitude: 30.5, Longitude: -81.5},
+	} {
+		suite.Nil(SaveZip5Location(suite.db, location))
+	}
+
+	centroids, err := FetchZip3Centroids(suite.db, now)
+	suite.Nil(err)
+	suite.Len(centroids, 3)
+
+	// The split ZIP3 has a centroid for each of its rate areas
+	suite.Equal("322", centroids[0].Zip3)
+	suite.Equal("US49", centroids[0].RateArea)
+	suite.True(centroids[0].Split)
+	suite.Equal(1, centroids[0].Zip5Count)
+	suite.Equal("322", centroids[1].Zip3)
+	suite.Equal("US4964400", centroids[1].RateArea)
+	suite.Equal(2, centroids[1].Zip5Count)
+	suite.InDelta(30.5, centroids[1].Latitude, 0.001)
+	suite.Nil(centroids[1].ServiceChargeCents)
+
+	sanFrancisco := centroids[2]
+	suite.Equal("941", sanFrancisco.Zip3)
+	suite.False(sanFrancisco.Split)
+	suite.InDelta(37.5, sanFrancisco.Latitude, 0.001)
+	suite.InDelta(-122.5, sanFrancisco.Longitude, 0.001)
+	suite.Equal(3, *sanFrancisco.ServicesSchedule)
+	suite.Equal(unit.Cents(350), *sanFrancisco.ServiceChargeCents)
+
+	// Rates which aren't in effect are left out
+	centroids, err = FetchZip3Centroids(suite.db, now.AddDate(1, 0, 0))
+	suite.Nil(err)
+	suite.Nil(centroids[2].ServiceAreaName)
+}
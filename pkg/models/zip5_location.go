@@ -0,0 +1,116 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/pop"
+	"github.com/gobuffalo/uuid"
+	"github.com/gobuffalo/validate"
+	"github.com/gobuffalo/validate/validators"
+	"github.com/pkg/errors"
+)
+
+// Zip5Location is the location of the center of a ZIP5, along with the city, state and county it is in
+type Zip5Location struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	Zip5      string    `json:"zip5" db:"zip5"`
+	Latitude  float32   `json:"latitude" db:"latitude"`
+	Longitude float32   `json:"longitude" db:"longitude"`
+	City      string    `json:"city" db:"city"`
+	State     string    `json:"state" db:"state"`
+	County    string    `json:"county" db:"county"`
+}
+
+// Zip5Locations is a list of Zip5Location records
+type Zip5Locations []Zip5Location
+
+// Validate gets run every time you call a "pop.Validate*" (pop.ValidateAndSave, pop.ValidateAndCreate, pop.ValidateAndUpdate) method.
+func (z *Zip5Location) Validate(tx *pop.Connection) (*validate.Errors, error) {
+	return validate.Validate(
+		&validators.RegexMatch{Field: z.Zip5, Name: "Zip5", Expr: "^[0-9]{5}$"},
+		&Float64IsInRange{Field: float64(z.Latitude), Name: "Latitude", Min: -90, Max: 90},
+		&Float64IsInRange{Field: float64(z.Longitude), Name: "Longitude", Min: -180, Max: 180},
+	), nil
+}
+
+// FetchZip5Location returns the location of a ZIP5
+func FetchZip5Location(db *pop.Connection, zip5 string) (Zip5Location, error) {
+	location := Zip5Location{}
+	err := db.Where("zip5 = ?", zip5).First(&location)
+	if err != nil {
+		if errors.Cause(err).Error() == recordNotFoundErrorString {
+			return location, ErrFetchNotFound
+		}
+		return location, errors.Wrap(err, "fetching ZIP5 location")
+	}
+	return location, nil
+}
+
+// SearchZip5Locations returns up to limit ZIP5s starting with prefix, in order
+func SearchZip5Locations(db *pop.Connection, prefix string, limit int) (Zip5Locations, error) {
+	locations := Zip5Locations{}
+	err := db.Where("zip5 LIKE ?", prefix+"%").Order("zip5").Limit(limit).All(&locations)
+	if err != nil {
+		return locations, errors.Wrap(err, "searching ZIP5 locations")
+	}
+	return locations, nil
+}
+
+// FetchZip5LocationsByCity returns up to limit ZIP5s in a city, ignoring case, in order
+func FetchZip5LocationsByCity(db *pop.Connection, city string, state string, limit int) (Zip5Locations, error) {
+	locations := Zip5Locations{}
+	err := db.Where("upper(city) = upper(?) AND upper(state) = upper(?)", city, state).Order("zip5").Limit(limit).All(&locations)
+	if err != nil {
+		return locations, errors.Wrap(err, "fetching ZIP5 locations by city")
+	}
+	return locations, nil
+}
+
+// FetchAllZip5Locations returns the location of every ZIP5
+func FetchAllZip5Locations(db *pop.Connection) (Zip5Locations, error) {
+	locations := Zip5Locations{}
+	err := db.All(&locations)
+	if err != nil {
+		return locations, errors.Wrap(err, "fetching ZIP5 locations")
+	}
+	return locations, nil
+}
+
+// CountZip5LocationsWithoutCity returns how many ZIP5s have no city or state, as they do until
+// they are loaded with cmd/load_zip_locations
+func CountZip5LocationsWithoutCity(db *pop.Connection) (int, error) {
+	count, err := db.Where("city = '' OR state = ''").Count(&Zip5Location{})
+	if err != nil {
+		return 0, errors.Wrap(err, "counting ZIP5 locations without a city")
+	}
+	return count, nil
+}
+
+// SaveZip5Location creates or replaces the location of a ZIP5
+func SaveZip5Location(db *pop.Connection, location Zip5Location) error {
+	verrs, err := location.Validate(db)
+	if err != nil {
+		return err
+	}
+	if verrs.HasAny() {
+		return errors.Errorf("invalid ZIP5 location: %v", verrs)
+	}
+
+	now := time.Now()
+	sql := `INSERT INTO zip5_locations
+			(id, created_at, updated_at, zip5, latitude, longitude, city, state, county)
+		VALUES
+			($1, $2, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (zip5) DO UPDATE SET
+			updated_at = EXCLUDED.updated_at,
+			latitude = EXCLUDED.latitude,
+			longitude = EXCLUDED.longitude,
+			city = EXCLUDED.city,
+			state = EXCLUDED.state,
+			county = EXCLUDED.county`
+	err = db.RawQuery(sql, uuid.Must(uuid.NewV4()), now, location.Zip5, location.Latitude, location.Longitude,
+		location.City, location.State, location.County).Exec()
+	return errors.Wrap(err, "saving ZIP5 location")
+}
@@ -0,0 +1,55 @@
+package models_test
+
+import (
+	. "github.com/transcom/mymove/pkg/models"
+)
+
+func (suite *ModelSuite) Test_Zip5LocationValidation() {
+	location := &Zip5Location{Zip5: "9410", Latitude: 91, Longitude: -181}
+
+	expErrors := map[string][]string{
+		"zip5":      []string{"Zip5 does not match the expected format."},
+		"latitude":  []string{"Latitude must be between -90 and 90"},
+		"longitude": []string{"Longitude must be between -180 and 180"},
+	}
+	suite.verifyValidationErrors(location, expErrors)
+}
+
+func (suite *ModelSuite) Test_SaveFetchAndSearchZip5Locations() {
+	_, err := FetchZip5Location(suite.db, "94103")
+	suite.Equal(ErrFetchNotFound, err)
+
+	suite.Nil(SaveZip5Location(suite.db, Zip5Location{Zip5: "94103", Latitude: 37.775678, Longitude: -122.412131}))
+	suite.Nil(SaveZip5Location(suite.db, Zip5Location{Zip5: "94107", Latitude: 37.766529, Longitude: -122.39577, City: "San Francisco", State: "CA", County: "San Francisco"}))
+	suite.Nil(SaveZip5Location(suite.db, Zip5Location{Zip5: "20301", Latitude: 38.8933, Longitude: -77.0146, City: "Washington", State: "DC"}))
+
+	// Saving again fills in the city, state and county
+	suite.Nil(SaveZip5Location(suite.db, Zip5Location{Zip5: "94103", Latitude: 37.775678, Longitude: -122.412131, City: "San Francisco", State: "CA", County: "San Francisco"}))
+	location, err := FetchZip5Location(suite.db, "94103")
+	suite.Nil(err)
+	suite.Equal("San Francisco", location.City)
+	suite.Equal("CA", location.State)
+	suite.Equal(float32(37.775678), location.Latitude)
+
+	locations, err := SearchZip5Locations(suite.db, "941", 10)
+	suite.Nil(err)
+	if suite.Len(locations, 2) {
+		suite.Equal("94103", locations[0].Zip5)
+		suite.Equal("94107", locations[1].Zip5)
+	}
+
+	locations, err = FetchZip5LocationsByCity(suite.db, "SAN FRANCISCO", "ca", 10)
+	suite.Nil(err)
+	suite.Len(locations, 2)
+
+	locations, err = FetchAllZip5Locations(suite.db)
+	suite.Nil(err)
+	suite.Len(locations, 3)
+
+	suite.Nil(SaveZip5Location(suite.db, Zip5Location{Zip5: "00501", Latitude: 40.922326, Longitude: -72.637078}))
+	count, err := CountZip5LocationsWithoutCity(suite.db)
+	suite.Nil(err)
+	suite.Equal(1, count)
+
+	suite.NotNil(SaveZip5Location(suite.db, Zip5Location{Zip5: "charleston"}))
+}
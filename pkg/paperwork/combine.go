@@ -0,0 +1,104 @@
+package paperwork
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hhrutter/pdfcpu/pkg/api"
+	"github.com/hhrutter/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+// File is the content of an upload to be combined into a PDF
+type File struct {
+	ContentType string
+	Data        []byte
+}
+
+// pdfConfiguration returns the pdfcpu configuration used to read and write PDFs. Uploaded PDFs come
+// from all kinds of scanners and phone apps, so they are validated leniently.
+func pdfConfiguration() *pdfcpu.Configuration {
+	conf := pdfcpu.NewDefaultConfiguration()
+	conf.ValidationMode = pdfcpu.ValidationRelaxed
+	return conf
+}
+
+// ImageToPDF returns a single letter size page showing a JPEG or PNG, after it has been made
+// upright and scaled down by NormalizeImage
+func ImageToPDF(data []byte) ([]byte, error) {
+	normalized, err := NormalizeImage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := pdfcpu.DefaultImportConfig()
+	imp.PageSize = "Letter"
+	imp.PageDim = pdfcpu.PaperSize["Letter"]
+
+	// pdfcpu only imports images from files
+	dir, err := ioutil.TempDir("", "paperwork")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create temporary directory")
+	}
+	defer os.RemoveAll(dir)
+	imagePath := filepath.Join(dir, "image.jpg")
+	pdfPath := filepath.Join(dir, "image.pdf")
+	if err := ioutil.WriteFile(imagePath, normalized, 0600); err != nil {
+		return nil, errors.Wrap(err, "could not write image")
+	}
+
+	if _, err := api.ImportImages(api.ImportImagesCommand([]string{imagePath}, pdfPath, imp, pdfConfiguration())); err != nil {
+		return nil, errors.Wrap(err, "could not convert image to PDF")
+	}
+	pdf, err := ioutil.ReadFile(pdfPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read PDF of image")
+	}
+	return pdf, nil
+}
+
+// pdfReader adapts a PDF in memory to the readers pdfcpu merges
+type pdfReader struct {
+	*bytes.Reader
+}
+
+// Close does nothing, as there is nothing to release
+func (pdfReader) Close() error {
+	return nil
+}
+
+// CombineFiles returns a PDF of files in order. Images become a page each and the pages of PDFs
+// are included as they are.
+func CombineFiles(files []File) ([]byte, error) {
+	if len(files) == 0 {
+		return nil, errors.New("no files to combine")
+	}
+
+	pdfs := make([]pdfcpu.ReadSeekerCloser, len(files))
+	for i, file := range files {
+		switch file.ContentType {
+		case "application/pdf":
+			pdfs[i] = pdfReader{bytes.NewReader(file.Data)}
+		case "image/jpeg", "image/png":
+			page, err := ImageToPDF(file.Data)
+			if err != nil {
+				return nil, err
+			}
+			pdfs[i] = pdfReader{bytes.NewReader(page)}
+		default:
+			return nil, errors.Errorf("can't combine files of type %s", file.ContentType)
+		}
+	}
+
+	ctx, err := api.MergeContexts(pdfs, pdfConfiguration())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not combine PDFs")
+	}
+	var buf bytes.Buffer
+	if err := api.WriteContext(ctx, &buf); err != nil {
+		return nil, errors.Wrap(err, "could not write combined PDF")
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,180 @@
+package paperwork
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"testing"
+
+	"github.com/hhrutter/pdfcpu/pkg/api"
+)
+
+// testImage returns a width x height image which is red in its top left corner and white elsewhere
+func testImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.White)
+		}
+	}
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	return img
+}
+
+// withOrientation returns a JPEG with an EXIF APP1 segment recording orientation
+func withOrientation(t *testing.T, img image.Image, orientation uint16) []byte {
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, img, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// A little endian TIFF header followed by an IFD holding only the orientation tag
+	tiff := []byte("II*\x00\x08\x00\x00\x00\x01\x00\x12\x01\x03\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00")
+	binary.LittleEndian.PutUint16(tiff[18:], orientation)
+	app1 := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(app1)+2))
+	segment = append(segment, app1...)
+
+	data := encoded.Bytes()
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func TestExifOrientation(t *testing.T) {
+	img := testImage(4, 2)
+	if orientation := exifOrientation(withOrientation(t, img, 6)); orientation != 6 {
+		t.Errorf("expected orientation 6, got %d", orientation)
+	}
+
+	var plain bytes.Buffer
+	jpeg.Encode(&plain, img, nil)
+	if orientation := exifOrientation(plain.Bytes()); orientation != 1 {
+		t.Errorf("expected orientation 1 without EXIF, got %d", orientation)
+	}
+	if orientation := exifOrientation([]byte("%PDF-1.4")); orientation != 1 {
+		t.Errorf("expected orientation 1 for a non-JPEG, got %d", orientation)
+	}
+}
+
+func TestOrient(t *testing.T) {
+	img := testImage(4, 2)
+	red := color.RGBA{255, 0, 0, 255}
+	// Where the top left pixel ends up once the image is made upright
+	corners := map[int]image.Point{
+		1: {0, 0},
+		2: {3, 0},
+		3: {3, 1},
+		4: {0, 1},
+		5: {0, 0},
+		6: {1, 0},
+		7: {1, 3},
+		8: {0, 3},
+	}
+	for orientation, corner := range corners {
+		upright := orient(img, orientation)
+		if orientation >= 5 && upright.Bounds().Dx() != 2 {
+			t.Errorf("orientation %d: expected width and height to swap, got %v", orientation, upright.Bounds())
+		}
+		if color.RGBAModel.Convert(upright.At(corner.X, corner.Y)) != red {
+			t.Errorf("orientation %d: expected red pixel at %v", orientation, corner)
+		}
+	}
+}
+
+func TestNormalizeImageDownscales(t *testing.T) {
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, testImage(MaxImageDimension*2, MaxImageDimension)); err != nil {
+		t.Fatal(err)
+	}
+	normalized, err := NormalizeImage(encoded.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(normalized))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "jpeg" || config.Width != MaxImageDimension || config.Height != MaxImageDimension/2 {
+		t.Errorf("expected a %dx%d jpeg, got a %dx%d %s", MaxImageDimension, MaxImageDimension/2, config.Width, config.Height, format)
+	}
+
+	if _, err := NormalizeImage([]byte("not an image")); err == nil {
+		t.Error("expected an error for data which isn't an image")
+	}
+}
+
+func TestNormalizeImageRejectsHugeImages(t *testing.T) {
+	// Just a PNG header, declaring an image of 50000x50000 pixels
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], 50000)
+	binary.BigEndian.PutUint32(ihdr[4:], 50000)
+	ihdr[8], ihdr[9] = 8, 2 // 8 bit RGB
+	chunk := append([]byte("IHDR"), ihdr...)
+	var bomb bytes.Buffer
+	bomb.WriteString("\x89PNG\r\n\x1a\n")
+	binary.Write(&bomb, binary.BigEndian, uint32(len(ihdr)))
+	bomb.Write(chunk)
+	binary.Write(&bomb, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+
+	if _, err := NormalizeImage(bomb.Bytes()); err != ErrImageTooLarge {
+		t.Errorf("expected ErrImageTooLarge, got %v", err)
+	}
+	if _, err := Preview(File{ContentType: "image/png", Data: bomb.Bytes()}); err != ErrNoPreview {
+		t.Errorf("expected ErrNoPreview, got %v", err)
+	}
+}
+
+// pageCount returns the number of pages in a PDF, which pdfcpu counts while validating it
+func pageCount(pdf []byte) (int, error) {
+	ctx, err := api.ReadContext(bytes.NewReader(pdf), "", int64(len(pdf)), pdfConfiguration())
+	if err != nil {
+		return 0, err
+	}
+	if err := api.ValidateContext(ctx); err != nil {
+		return 0, err
+	}
+	return ctx.PageCount, nil
+}
+
+func TestCombineFiles(t *testing.T) {
+	pdf, err := ioutil.ReadFile("testdata/test.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pdfPages, err := pageCount(pdf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var photo bytes.Buffer
+	if err := png.Encode(&photo, testImage(40, 30)); err != nil {
+		t.Fatal(err)
+	}
+
+	combined, err := CombineFiles([]File{
+		{ContentType: "image/jpeg", Data: withOrientation(t, testImage(40, 30), 6)},
+		{ContentType: "application/pdf", Data: pdf},
+		{ContentType: "image/png", Data: photo.Bytes()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages, err := pageCount(combined)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pages != pdfPages+2 {
+		t.Errorf("expected %d pages, got %d", pdfPages+2, pages)
+	}
+
+	if _, err := CombineFiles(nil); err == nil {
+		t.Error("expected an error combining no files")
+	}
+	if _, err := CombineFiles([]File{{ContentType: "text/plain", Data: []byte("text")}}); err == nil {
+		t.Error("expected an error combining a text file")
+	}
+}
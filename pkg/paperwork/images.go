@@ -0,0 +1,198 @@
+package paperwork
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	// Registers the PNG decoder with image.Decode
+	_ "image/png"
+
+	"github.com/pkg/errors"
+)
+
+// MaxImageDimension is the longest side, in pixels, of images placed in PDFs. Larger photos are
+// scaled down to it, which is about 200 DPI on a letter page.
+const MaxImageDimension = 2200
+
+// imageQuality is the JPEG quality images are re-encoded at
+const imageQuality = 85
+
+// maxImagePixels is the largest image, in pixels, which is decoded. A small file can declare a
+// far larger image, which would take too much memory to decode.
+const maxImagePixels = 100 * 1000 * 1000
+
+// ErrImageTooLarge is returned for images with more than maxImagePixels pixels
+var ErrImageTooLarge = errors.New("image is too large to decode")
+
+// checkImageSize returns an error unless data is an image small enough to decode
+func checkImageSize(data []byte) error {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrap(err, "could not decode image")
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return ErrImageTooLarge
+	}
+	return nil
+}
+
+// NormalizeImage decodes a JPEG or PNG, turns it upright according to its EXIF orientation, scales
+// it down to fit within MaxImageDimension and returns it encoded as a JPEG.
+func NormalizeImage(data []byte) ([]byte, error) {
+	if err := checkImageSize(data); err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode image")
+	}
+
+	img = downscale(orient(img, exifOrientation(data)), MaxImageDimension)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: imageQuality}); err != nil {
+		return nil, errors.Wrap(err, "could not encode image")
+	}
+	return buf.Bytes(), nil
+}
+
+// downscale returns img scaled so that neither side is longer than max, or img itself if it
+// already fits. Each pixel is the average of the pixels it covers, which keeps text legible.
+func downscale(img image.Image, max int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= max && height <= max {
+		return img
+	}
+	scaledWidth, scaledHeight := max, height*max/width
+	if height > width {
+		scaledWidth, scaledHeight = width*max/height, max
+	}
+	if scaledWidth < 1 {
+		scaledWidth = 1
+	}
+	if scaledHeight < 1 {
+		scaledHeight = 1
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, scaledWidth, scaledHeight))
+	for y := 0; y < scaledHeight; y++ {
+		top, bottom := y*height/scaledHeight, (y+1)*height/scaledHeight
+		for x := 0; x < scaledWidth; x++ {
+			left, right := x*width/scaledWidth, (x+1)*width/scaledWidth
+			var r, g, b, a, n uint64
+			for sy := top; sy < bottom; sy++ {
+				for sx := left; sx < right; sx++ {
+					pr, pg, pb, pa := img.At(bounds.Min.X+sx, bounds.Min.Y+sy).RGBA()
+					r, g, b, a, n = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa), n+1
+				}
+			}
+			scaled.SetRGBA(x, y, color.RGBA{uint8(r / n >> 8), uint8(g / n >> 8), uint8(b / n >> 8), uint8(a / n >> 8)})
+		}
+	}
+	return scaled
+}
+
+// orient returns img turned upright, given its EXIF orientation. Orientations 2 to 8 are the
+// combinations of mirroring and rotating by 90 degree steps a camera may record instead of
+// turning the pixels themselves.
+func orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	// Orientations 5 to 8 swap width and height
+	transposed := orientation >= 5
+	out := image.NewRGBA(image.Rect(0, 0, width, height))
+	if transposed {
+		out = image.NewRGBA(image.Rect(0, 0, height, width))
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored
+				dx, dy = width-1-x, y
+			case 3: // rotated 180
+				dx, dy = width-1-x, height-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, height-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90 clockwise to display
+				dx, dy = height-1-y, x
+			case 7: // transversed
+				dx, dy = height-1-y, width-1-x
+			case 8: // rotated 90 counterclockwise to display
+				dx, dy = y, width-1-x
+			}
+			out.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return out
+}
+
+// exifOrientation returns the EXIF orientation (1 to 8) recorded in a JPEG, or 1 if there is none
+func exifOrientation(data []byte) int {
+	// A JPEG starts with SOI, followed by segments of a marker and a big endian length
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		// EXIF is in an APP1 segment, which comes before the image data starts at SOS
+		if marker == 0xDA || length < 2 || i+2+length > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+// tiffOrientation returns the orientation tag of the first IFD of TIFF formatted EXIF data
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for e := 0; e < entries; e++ {
+		entry := ifd + 2 + e*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		// The orientation tag is a SHORT, stored at the start of the entry's value
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+	return 1
+}
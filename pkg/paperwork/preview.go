@@ -0,0 +1,147 @@
+package paperwork
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hhrutter/pdfcpu/pkg/api"
+	// Registers the TIFF decoder, as scanners often embed TIFFs in PDFs
+	_ "github.com/hhrutter/pdfcpu/tiff"
+	"github.com/pkg/errors"
+)
+
+// PreviewDimension is the longest side, in pixels, of the previews of uploads
+const PreviewDimension = 400
+
+// previewQuality is the JPEG quality previews are encoded at
+const previewQuality = 75
+
+// ErrNoPreview is returned for files no preview can be made of, such as PDFs whose first page
+// has no images, or files which can't be read at all. Trying again won't help.
+var ErrNoPreview = errors.New("no preview can be made of this file")
+
+// Preview returns a small JPEG showing a file. Images are turned upright and scaled down. PDFs
+// aren't rendered: they are shown by the largest image on their first page, which for scanned
+// documents is the page itself. PDFs of text, and files which can't be decoded, return
+// ErrNoPreview, and are shown by PlaceholderPreview instead.
+func Preview(file File) ([]byte, error) {
+	var data []byte
+	switch file.ContentType {
+	case "image/jpeg", "image/png":
+		data = file.Data
+	case "application/pdf":
+		var err error
+		if data, err = largestFirstPageImage(file.Data); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrNoPreview
+	}
+
+	if err := checkImageSize(data); err != nil {
+		return nil, ErrNoPreview
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrNoPreview
+	}
+	img = downscale(orient(img, exifOrientation(data)), PreviewDimension)
+
+	// JPEGs have no transparency, so transparent PNGs are shown on white like a page
+	bounds := img.Bounds()
+	flattened := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flattened, flattened.Bounds(), image.NewUniform(color.White), image.ZP, draw.Src)
+	draw.Draw(flattened, flattened.Bounds(), img, bounds.Min, draw.Over)
+	return encodePreview(flattened)
+}
+
+// PlaceholderPreview returns a JPEG of a generic page, the same size as previews, to show in place
+// of files no preview can be made of
+func PlaceholderPreview() ([]byte, error) {
+	// A US letter page, with grey bars standing in for lines of text
+	width := PreviewDimension * 85 / 110
+	margin := width / 8
+	page := image.NewRGBA(image.Rect(0, 0, width, PreviewDimension))
+	draw.Draw(page, page.Bounds(), image.NewUniform(color.White), image.ZP, draw.Src)
+	border := image.NewUniform(color.Gray{Y: 0xa0})
+	for _, edge := range []image.Rectangle{
+		image.Rect(0, 0, width, 2),
+		image.Rect(0, PreviewDimension-2, width, PreviewDimension),
+		image.Rect(0, 0, 2, PreviewDimension),
+		image.Rect(width-2, 0, width, PreviewDimension),
+	} {
+		draw.Draw(page, edge, border, image.ZP, draw.Src)
+	}
+	text := image.NewUniform(color.Gray{Y: 0xd8})
+	for i, y := 0, margin; y+6 < PreviewDimension-margin; i, y = i+1, y+16 {
+		right := width - margin
+		// Paragraphs end with a short line
+		if i%5 == 4 {
+			right = width / 2
+		}
+		draw.Draw(page, image.Rect(margin, y, right, y+6), text, image.ZP, draw.Src)
+	}
+	return encodePreview(page)
+}
+
+// encodePreview encodes img as a preview JPEG
+func encodePreview(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: previewQuality}); err != nil {
+		return nil, errors.Wrap(err, "could not encode preview")
+	}
+	return buf.Bytes(), nil
+}
+
+// largestFirstPageImage returns the encoded image with the most pixels on the first page of a PDF
+func largestFirstPageImage(pdf []byte) ([]byte, error) {
+	// pdfcpu only extracts images from files to files
+	dir, err := ioutil.TempDir("", "preview")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create temporary directory")
+	}
+	defer os.RemoveAll(dir)
+	pdfPath := filepath.Join(dir, "file.pdf")
+	imageDir := filepath.Join(dir, "images")
+	if err := ioutil.WriteFile(pdfPath, pdf, 0600); err != nil {
+		return nil, errors.Wrap(err, "could not write PDF")
+	}
+	if err := os.Mkdir(imageDir, 0700); err != nil {
+		return nil, errors.Wrap(err, "could not create image directory")
+	}
+
+	// A PDF which can't be parsed now never will be, so it has no preview rather than failing
+	if _, err := api.ExtractImages(api.ExtractImagesCommand(pdfPath, imageDir, []string{"1"}, pdfConfiguration())); err != nil {
+		return nil, ErrNoPreview
+	}
+	images, err := ioutil.ReadDir(imageDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list extracted images")
+	}
+
+	var largest []byte
+	largestPixels := 0
+	for _, img := range images {
+		data, err := ioutil.ReadFile(filepath.Join(imageDir, img.Name()))
+		if err != nil {
+			continue
+		}
+		config, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			continue
+		}
+		if pixels := config.Width * config.Height; pixels > largestPixels {
+			largest, largestPixels = data, pixels
+		}
+	}
+	if largest == nil {
+		return nil, ErrNoPreview
+	}
+	return largest, nil
+}
@@ -0,0 +1,101 @@
+package paperwork
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"testing"
+)
+
+func TestPreviewImage(t *testing.T) {
+	data := withOrientation(t, testImage(3000, 1000), 6)
+
+	preview, err := Preview(File{ContentType: "image/jpeg", Data: data})
+	if err != nil {
+		t.Fatalf("could not preview image: %s", err)
+	}
+	img, format, err := image.Decode(bytes.NewReader(preview))
+	if err != nil || format != "jpeg" {
+		t.Fatalf("preview isn't a JPEG: %s %v", format, err)
+	}
+	// Turned upright, so taller than wide, and scaled down
+	if bounds := img.Bounds(); bounds.Dx() != 133 || bounds.Dy() != PreviewDimension {
+		t.Errorf("wrong preview size: %v", bounds)
+	}
+}
+
+func TestPreviewTransparentPNG(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	preview, err := Preview(File{ContentType: "image/png", Data: buf.Bytes()})
+	if err != nil {
+		t.Fatalf("could not preview image: %s", err)
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(preview))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, g, b, _ := decoded.At(5, 5).RGBA()
+	if r < 0xf000 || g < 0xf000 || b < 0xf000 {
+		t.Errorf("expected transparency to be white, got %v", color.RGBA64{uint16(r), uint16(g), uint16(b), 0xffff})
+	}
+}
+
+func TestPreviewPDF(t *testing.T) {
+	scan, err := ImageToPDF(withOrientation(t, testImage(1000, 1300), 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	preview, err := Preview(File{ContentType: "application/pdf", Data: scan})
+	if err != nil {
+		t.Fatalf("could not preview PDF: %s", err)
+	}
+	img, _, err := image.Decode(bytes.NewReader(preview))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bounds := img.Bounds(); bounds.Dy() != PreviewDimension {
+		t.Errorf("wrong preview size: %v", bounds)
+	}
+
+	// A PDF of text alone has nothing to show
+	text, err := ioutil.ReadFile("testdata/test.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Preview(File{ContentType: "application/pdf", Data: text}); err != ErrNoPreview {
+		t.Errorf("expected ErrNoPreview, got %v", err)
+	}
+}
+
+func TestPreviewCorruptFiles(t *testing.T) {
+	corrupt := []File{
+		{ContentType: "application/pdf", Data: []byte("%PDF-1.4\nnot really a PDF")},
+		{ContentType: "image/png", Data: []byte("\x89PNG\r\n\x1a\nnot really a PNG")},
+	}
+	for _, file := range corrupt {
+		if _, err := Preview(file); err != ErrNoPreview {
+			t.Errorf("expected ErrNoPreview for a corrupt %s, got %v", file.ContentType, err)
+		}
+	}
+}
+
+func TestPlaceholderPreview(t *testing.T) {
+	placeholder, err := PlaceholderPreview()
+	if err != nil {
+		t.Fatalf("could not make placeholder: %s", err)
+	}
+	img, format, err := image.Decode(bytes.NewReader(placeholder))
+	if err != nil || format != "jpeg" {
+		t.Fatalf("placeholder isn't a JPEG: %s %v", format, err)
+	}
+	if bounds := img.Bounds(); bounds.Dx() != 309 || bounds.Dy() != PreviewDimension {
+		t.Errorf("wrong placeholder size: %v", bounds)
+	}
+}
@@ -1,4 +1,4 @@
-package handlers
+package rateengine
 
 import (
 	"time"
@@ -10,9 +10,23 @@ import (
 	"github.com/transcom/mymove/pkg/unit"
 )
 
-// PPMDiscountFetch attempts to fetch the discount rates first for COS D, then 2
+// PPMDiscount is the set of discount rates used to price a PPM, along with the
+// Code of Service and rate cycle they were taken from.
+type PPMDiscount struct {
+	LinehaulDiscount unit.DiscountRate
+	SITDiscount      unit.DiscountRate
+	CodeOfService    string
+	RateCycleStart   time.Time
+	RateCycleEnd     time.Time
+	PeakRateCycle    bool
+}
+
+// FetchPPMDiscount attempts to fetch the discount rates first for COS D, then 2
 // Most PPMs use COS D, but when there is no COS D rate, the calculation is based on Code 2
-func PPMDiscountFetch(db *pop.Connection, logger *zap.Logger, originZip string, destZip string, moveDate time.Time) (unit.DiscountRate, unit.DiscountRate, error) {
+func FetchPPMDiscount(db *pop.Connection, logger *zap.Logger, originZip string, destZip string, moveDate time.Time) (PPMDiscount, error) {
+	discount := PPMDiscount{}
+	discount.RateCycleStart, discount.RateCycleEnd, discount.PeakRateCycle = models.GetRateCycleForDate(moveDate)
+
 	// Try to fetch with COS D.
 	lhDiscount, sitDiscount, err := models.FetchDiscountRates(db,
 		originZip,
@@ -26,11 +40,14 @@ func PPMDiscountFetch(db *pop.Connection, logger *zap.Logger, originZip string,
 			zap.String("destination_zip", destZip),
 			zap.Time("move_date", moveDate),
 		)
-		return lhDiscount, sitDiscount, err
+		discount.LinehaulDiscount = lhDiscount
+		discount.SITDiscount = sitDiscount
+		discount.CodeOfService = "D"
+		return discount, err
 	}
 
 	if err != models.ErrFetchNotFound {
-		return 0, 0, err
+		return PPMDiscount{}, err
 	}
 	// When COS D not found, COS 2 may have rates.
 	lhDiscount, sitDiscount, err = models.FetchDiscountRates(db,
@@ -45,7 +62,10 @@ func PPMDiscountFetch(db *pop.Connection, logger *zap.Logger, originZip string,
 			zap.String("destination_zip", destZip),
 			zap.Time("move_date", moveDate),
 		)
-		return lhDiscount, sitDiscount, err
+		discount.LinehaulDiscount = lhDiscount
+		discount.SITDiscount = sitDiscount
+		discount.CodeOfService = "2"
+		return discount, err
 	}
 
 	logger.Info("Couldn't find Discount for COS D or 2.",
@@ -54,5 +74,14 @@ func PPMDiscountFetch(db *pop.Connection, logger *zap.Logger, originZip string,
 		zap.Time("move_date", moveDate),
 		zap.Error(err),
 	)
-	return 0, 0, err
+	return PPMDiscount{}, err
+}
+
+// PPMDiscountFetch returns the linehaul and SIT discount rates found by FetchPPMDiscount.
+func PPMDiscountFetch(db *pop.Connection, logger *zap.Logger, originZip string, destZip string, moveDate time.Time) (unit.DiscountRate, unit.DiscountRate, error) {
+	discount, err := FetchPPMDiscount(db, logger, originZip, destZip, moveDate)
+	if err != nil {
+		return 0, 0, err
+	}
+	return discount.LinehaulDiscount, discount.SITDiscount, nil
 }
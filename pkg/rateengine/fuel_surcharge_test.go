@@ -0,0 +1,80 @@
+package rateengine
+
+import (
+	"time"
+
+	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/route"
+	"github.com/transcom/mymove/pkg/testdatagen"
+	"github.com/transcom/mymove/pkg/testdatagen/scenario"
+	"github.com/transcom/mymove/pkg/unit"
+)
+
+func (suite *RateEngineSuite) setupFuelSurcharge(priceMillicents unit.Millicents, surcharge unit.BasisPoints) {
+	index := models.FuelPriceIndex{
+		DieselPriceMillicents: priceMillicents,
+		EffectiveDateLower:    testdatagen.PeakRateCycleStart,
+		EffectiveDateUpper:    testdatagen.PeakRateCycleEnd,
+	}
+	suite.mustSave(&index)
+
+	bracket := models.Tariff400ngFuelSurchargeBracket{
+		PriceMillicentsLower: priceMillicents - 2500,
+		PriceMillicentsUpper: priceMillicents + 2500,
+		SurchargeBasisPoints: surcharge,
+		EffectiveDateLower:   testdatagen.PeakRateCycleStart,
+		EffectiveDateUpper:   testdatagen.PeakRateCycleEnd,
+	}
+	suite.mustSave(&bracket)
+}
+
+func (suite *RateEngineSuite) Test_FuelSurcharge() {
+	engine := NewRateEngine(suite.db, suite.logger, suite.planner)
+
+	// No fuel data loaded means no surcharge, flagged as missing
+	rate, missing, err := engine.fuelSurchargeRate(testdatagen.DateInsidePeakRateCycle)
+	suite.Nil(err)
+	suite.Equal(unit.Cents(0), unit.Cents(100000).MultiplyRate(rate, unit.RoundHalfUp))
+	suite.True(missing)
+
+	suite.setupFuelSurcharge(325000, 1050)
+
+	rate, missing, err = engine.fuelSurchargeRate(testdatagen.DateInsidePeakRateCycle)
+	suite.Nil(err)
+	suite.Equal(unit.Cents(10500), unit.Cents(100000).MultiplyRate(rate, unit.RoundHalfUp))
+	suite.False(missing)
+
+	rate, missing, err = engine.fuelSurchargeRate(testdatagen.DateOutsidePeakRateCycle)
+	suite.Nil(err)
+	suite.Equal(unit.Cents(0), unit.Cents(100000).MultiplyRate(rate, unit.RoundHalfUp))
+	suite.True(missing)
+}
+
+func (suite *RateEngineSuite) Test_ComputePPMIncludesFuelSurcharge() {
+	if err := scenario.RunRateEngineScenario1(suite.db); err != nil {
+		suite.FailNow("failed to run scenario 1: %+v", err)
+	}
+	fuelIndex := models.FuelPriceIndex{
+		DieselPriceMillicents: 325000,
+		EffectiveDateLower:    scenario.May15_2018,
+		EffectiveDateUpper:    scenario.May15_2019,
+	}
+	suite.mustSave(&fuelIndex)
+	bracket := models.Tariff400ngFuelSurchargeBracket{
+		PriceMillicentsLower: 320000,
+		PriceMillicentsUpper: 330000,
+		SurchargeBasisPoints: 1000,
+		EffectiveDateLower:   scenario.May15_2018,
+		EffectiveDateUpper:   scenario.May15_2019,
+	}
+	suite.mustSave(&bracket)
+
+	engine := NewRateEngine(suite.db, suite.logger, route.NewTestingPlanner(362))
+	cost, err := engine.ComputePPM(unit.Pound(4000), "32168", "29429", time.Date(2018, time.June, 18, 0, 0, 0, 0, time.UTC), 0, unit.DiscountRate(0.67), 0)
+	suite.Nil(err)
+
+	undiscountedLinehaul := cost.BaseLinehaul + cost.OriginLinehaulFactor + cost.DestinationLinehaulFactor + cost.ShorthaulCharge
+	suite.Equal(undiscountedLinehaul.MultiplyRate(unit.NewRate(1, 10), unit.RoundHalfUp), cost.FuelSurcharge)
+	suite.False(cost.FuelSurchargeMissing)
+	suite.Equal(unit.Cents(263300)+cost.FuelSurcharge, cost.GCC)
+}
@@ -8,6 +8,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/route"
 	"github.com/transcom/mymove/pkg/unit"
 )
 
@@ -18,19 +19,43 @@ type LinehaulCostComputation struct {
 	DestinationLinehaulFactor unit.Cents
 	ShorthaulCharge           unit.Cents
 	LinehaulChargeTotal       unit.Cents
-	Mileage                   int
+	FuelSurcharge             unit.Cents
+	// FuelSurchargeRate is the share of the undiscounted linehaul charge the fuel surcharge is
+	FuelSurchargeRate unit.Rate
+	// FuelSurchargeMissing is set when no fuel price index or surcharge bracket covers the move
+	// date, so the linehaul charge has no fuel surcharge when it should have one
+	FuelSurchargeMissing bool
+	Mileage              int
+	// MileageProvider is the route planner which answered the mileage, if the planner says, and
+	// MileageProfile the vehicle profile it routed
+	MileageProvider string
+	MileageProfile  string
 }
 
-// Scale scales a cost computation by a multiplicative factor
-func (c *LinehaulCostComputation) Scale(factor float64) {
-	c.BaseLinehaul = c.BaseLinehaul.MultiplyFloat64(factor)
-	c.OriginLinehaulFactor = c.OriginLinehaulFactor.MultiplyFloat64(factor)
-	c.DestinationLinehaulFactor = c.DestinationLinehaulFactor.MultiplyFloat64(factor)
-	c.ShorthaulCharge = c.ShorthaulCharge.MultiplyFloat64(factor)
-	c.LinehaulChargeTotal = c.LinehaulChargeTotal.MultiplyFloat64(factor)
+// LinehaulLeg is the part of a route's linehaul charges attributed to one leg of the route,
+// in proportion to the leg's share of the total mileage
+type LinehaulLeg struct {
+	OriginZip5          string
+	DestinationZip5     string
+	Mileage             int
+	LinehaulChargeTotal unit.Cents
+	FuelSurcharge       unit.Cents
 }
 
-func (re *RateEngine) determineMileage(originZip5 string, destinationZip5 string) (mileage int, err error) {
+// Scale scales a cost computation by an exact multiplicative factor, rounding each charge once.
+// The fuel surcharge is worked out again from the unscaled linehaul charge, rather than scaling
+// the rounded surcharge.
+func (c *LinehaulCostComputation) Scale(factor unit.Rate) {
+	c.FuelSurcharge = c.LinehaulChargeTotal.MultiplyRate(c.FuelSurchargeRate.Multiply(factor), unit.RoundHalfUp)
+	c.BaseLinehaul = c.BaseLinehaul.MultiplyRate(factor, unit.RoundHalfUp)
+	c.OriginLinehaulFactor = c.OriginLinehaulFactor.MultiplyRate(factor, unit.RoundHalfUp)
+	c.DestinationLinehaulFactor = c.DestinationLinehaulFactor.MultiplyRate(factor, unit.RoundHalfUp)
+	c.ShorthaulCharge = c.ShorthaulCharge.MultiplyRate(factor, unit.RoundHalfUp)
+	c.LinehaulChargeTotal = c.LinehaulChargeTotal.MultiplyRate(factor, unit.RoundHalfUp)
+}
+
+// determineMileage finds the mileage between two ZIP5s, along with the provider which answered it
+func (re *RateEngine) determineMileage(originZip5 string, destinationZip5 string) (distance route.DistanceResult, err error) {
 	sourceAddress := models.Address{
 		StreetAddress1: "",
 		StreetAddress2: swag.String(""),
@@ -48,11 +73,63 @@ func (re *RateEngine) determineMileage(originZip5 string, destinationZip5 string
 		PostalCode:     destinationZip5,
 	}
 
-	mileage, err = re.planner.TransitDistance(&sourceAddress, &destinationAddress)
+	distance, err = route.TransitDistanceResult(re.planner, &sourceAddress, &destinationAddress)
 	if err != nil {
 		re.logger.Error("Failed to get distance from planner - %v", zap.Error(err))
 	}
-	return mileage, err
+	return distance, err
+}
+
+// determineRouteMileage finds the mileage of a route through each of the ZIP5s in turn, along
+// with the provider which answered it
+func (re *RateEngine) determineRouteMileage(zip5s []string) (route.DistanceResult, error) {
+	var waypoints []*models.Address
+	for _, zip5 := range zip5s {
+		waypoints = append(waypoints, &models.Address{
+			StreetAddress1: "",
+			StreetAddress2: swag.String(""),
+			StreetAddress3: swag.String(""),
+			City:           "",
+			State:          "",
+			PostalCode:     zip5,
+		})
+	}
+
+	distance, err := route.WaypointsTransitDistanceResult(re.planner, waypoints)
+	if err != nil {
+		re.logger.Error("Failed to get route distance from planner", zap.Error(err))
+	}
+	return distance, err
+}
+
+// attributeLinehaul splits the linehaul charge total and fuel surcharge between the legs of a
+// route by mileage. Each share is rounded and the last leg takes what is left, so that the legs
+// always add up to the totals.
+func attributeLinehaul(cost LinehaulCostComputation, zip5s []string, distance route.RouteDistance) []LinehaulLeg {
+	legs := make([]LinehaulLeg, len(distance.Legs))
+	remainingLinehaul := cost.LinehaulChargeTotal
+	remainingFuelSurcharge := cost.FuelSurcharge
+	for i, mileage := range distance.Legs {
+		legs[i] = LinehaulLeg{
+			OriginZip5:      zip5s[i],
+			DestinationZip5: zip5s[i+1],
+			Mileage:         mileage,
+		}
+		if i == len(legs)-1 {
+			legs[i].LinehaulChargeTotal = remainingLinehaul
+			legs[i].FuelSurcharge = remainingFuelSurcharge
+			break
+		}
+		if distance.Total == 0 {
+			continue
+		}
+		share := unit.NewRate(int64(mileage), int64(distance.Total))
+		legs[i].LinehaulChargeTotal = cost.LinehaulChargeTotal.MultiplyRate(share, unit.RoundHalfUp)
+		legs[i].FuelSurcharge = cost.FuelSurcharge.MultiplyRate(share, unit.RoundHalfUp)
+		remainingLinehaul -= legs[i].LinehaulChargeTotal
+		remainingFuelSurcharge -= legs[i].FuelSurcharge
+	}
+	return legs
 }
 
 // Determine the Base Linehaul (BLH)
@@ -88,16 +165,39 @@ func (re *RateEngine) shorthaulCharge(mileage int, cwt unit.CWT, date time.Time)
 	return shorthaulChargeCents, err
 }
 
+// Determine the Fuel Surcharge (FS) rate from the diesel price index in effect on the move date.
+// The surcharge is this share of the undiscounted linehaul charge. When no price index or
+// bracket has been loaded for the date, the rate is 0 and missing is set, so that the estimate
+// can be flagged as incomplete.
+func (re *RateEngine) fuelSurchargeRate(date time.Time) (rate unit.Rate, missing bool, err error) {
+	index, err := models.FetchFuelPriceIndex(re.db, date)
+	if err == models.ErrFetchNotFound {
+		re.logger.Warn("No fuel price index for date, skipping fuel surcharge", zap.Time("date", date))
+		return unit.Rate{}, true, nil
+	} else if err != nil {
+		return unit.Rate{}, false, err
+	}
+
+	surcharge, err := models.FetchFuelSurchargeBasisPoints(re.db, index.DieselPriceMillicents, date)
+	if err == models.ErrFetchNotFound {
+		re.logger.Warn("No fuel surcharge bracket for diesel price, skipping fuel surcharge",
+			zap.Int("diesel_price_millicents", index.DieselPriceMillicents.Int()),
+			zap.Time("date", date))
+		return unit.Rate{}, true, nil
+	} else if err != nil {
+		return unit.Rate{}, false, err
+	}
+
+	return surcharge.Rate(), false, nil
+}
+
 // Determine Linehaul Charge (LC) TOTAL
 // Formula: LC= [BLH + OLF + DLF + [SH]
-func (re *RateEngine) linehaulChargeComputation(weight unit.Pound, originZip5 string, destinationZip5 string, date time.Time) (cost LinehaulCostComputation, err error) {
+// The Fuel Surcharge is computed on LC but kept separate from it, as it is not discounted.
+func (re *RateEngine) linehaulChargeComputation(weight unit.Pound, originZip5 string, destinationZip5 string, mileage int, date time.Time) (cost LinehaulCostComputation, err error) {
 	cwt := weight.ToCWT()
 	originZip3 := Zip5ToZip3(originZip5)
 	destinationZip3 := Zip5ToZip3(destinationZip5)
-	mileage, err := re.determineMileage(originZip5, destinationZip5)
-	if err != nil {
-		return cost, errors.Wrap(err, "Failed to determine mileage")
-	}
 	cost.Mileage = mileage
 
 	cost.BaseLinehaul, err = re.baseLinehaul(mileage, weight, date)
@@ -122,12 +222,19 @@ func (re *RateEngine) linehaulChargeComputation(weight unit.Pound, originZip5 st
 		cost.DestinationLinehaulFactor +
 		cost.ShorthaulCharge
 
+	cost.FuelSurchargeRate, cost.FuelSurchargeMissing, err = re.fuelSurchargeRate(date)
+	if err != nil {
+		return cost, errors.Wrap(err, "Failed to determine fuel surcharge")
+	}
+	cost.FuelSurcharge = cost.LinehaulChargeTotal.MultiplyRate(cost.FuelSurchargeRate, unit.RoundHalfUp)
+
 	re.logger.Info("Linehaul charge total calculated",
 		zap.Int("linehaul total", cost.LinehaulChargeTotal.Int()),
 		zap.Int("linehaul", cost.BaseLinehaul.Int()),
 		zap.Int("origin lh factor", cost.OriginLinehaulFactor.Int()),
 		zap.Int("destination lh factor", cost.DestinationLinehaulFactor.Int()),
-		zap.Int("shorthaul", cost.ShorthaulCharge.Int()))
+		zap.Int("shorthaul", cost.ShorthaulCharge.Int()),
+		zap.Int("fuel surcharge", cost.FuelSurcharge.Int()))
 
 	return cost, err
 }
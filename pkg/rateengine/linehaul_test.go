@@ -1,7 +1,10 @@
 package rateengine
 
 import (
+	"time"
+
 	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/route"
 	"github.com/transcom/mymove/pkg/testdatagen"
 	"github.com/transcom/mymove/pkg/unit"
 )
@@ -9,14 +12,65 @@ import (
 func (suite *RateEngineSuite) Test_CheckDetermineMileage() {
 	t := suite.T()
 	engine := NewRateEngine(suite.db, suite.logger, suite.planner)
-	mileage, err := engine.determineMileage("39574", "33633")
+	distance, err := engine.determineMileage("39574", "33633")
 	if err != nil {
 		t.Error("Unable to determine mileage: ", err)
 	}
 	expected := 1234
-	if mileage != expected {
-		t.Errorf("Determined mileage incorrectly. Expected %d, got %d", expected, mileage)
+	if distance.Distance != expected {
+		t.Errorf("Determined mileage incorrectly. Expected %d, got %d", expected, distance.Distance)
 	}
+	// The testing planner doesn't say who answered
+	suite.Equal("", distance.Provider)
+}
+
+func (suite *RateEngineSuite) Test_ComputePPMRecordsMileageProvider() {
+	suite.setupPPMTotalRates()
+	planner := route.NewFallbackPlanner(suite.logger, []route.PlannerProvider{
+		{Name: "offline", Planner: suite.planner},
+	}, route.DefaultCircuitFailureThreshold, route.DefaultCircuitCooldown, route.DefaultDisagreementTolerance)
+	engine := NewRateEngine(suite.db, suite.logger, planner)
+
+	cost, err := engine.ComputePPM(2000, "39574", "33633", testdatagen.RateEngineDate,
+		1, unit.DiscountRate(.6), unit.DiscountRate(.5))
+	suite.Nil(err)
+	suite.Equal(1234, cost.Mileage)
+	suite.Equal("offline", cost.MileageProvider)
+}
+
+// countingPlanner counts the lookups which reach the planner it wraps
+type countingPlanner struct {
+	route.Planner
+	calls int
+}
+
+func (cp *countingPlanner) TransitDistance(source *models.Address, destination *models.Address) (int, error) {
+	cp.calls++
+	return cp.Planner.TransitDistance(source, destination)
+}
+
+func (cp *countingPlanner) WaypointsTransitDistance(waypoints []*models.Address) (route.RouteDistance, error) {
+	cp.calls++
+	return cp.Planner.WaypointsTransitDistance(waypoints)
+}
+
+func (suite *RateEngineSuite) Test_ComputePPMUsesWarmedDistances() {
+	suite.setupPPMTotalRates()
+	underlying := &countingPlanner{Planner: suite.planner}
+	planner := route.NewCachingPlanner(suite.logger, suite.db, underlying, "here", time.Hour)
+
+	failures := route.WarmDistanceCache(planner, []models.DutyStationPostalCodePair{
+		{OriginPostalCode: "39574", DestinationPostalCode: "33633"},
+	})
+	suite.Empty(failures)
+	suite.Equal(1, underlying.calls)
+
+	engine := NewRateEngine(suite.db, suite.logger, planner)
+	cost, err := engine.ComputePPM(2000, "39574", "33633", testdatagen.RateEngineDate,
+		1, unit.DiscountRate(.6), unit.DiscountRate(.5))
+	suite.Nil(err)
+	suite.Equal(1234, cost.Mileage)
+	suite.Equal(1, underlying.calls, "expected the warmed distance to be used")
 }
 
 func (suite *RateEngineSuite) Test_CheckBaseLinehaul() {
@@ -194,8 +248,12 @@ func (suite *RateEngineSuite) Test_CheckLinehaulChargeTotal() {
 	}
 	suite.mustSave(&sa2)
 
+	distance, err := engine.determineMileage(zip5Austin, zip5SanFrancisco)
+	if err != nil {
+		t.Error("Unable to determine mileage: ", err)
+	}
 	cost, err := engine.linehaulChargeComputation(
-		weight, zip5Austin, zip5SanFrancisco, testdatagen.DateInsidePeakRateCycle)
+		weight, zip5Austin, zip5SanFrancisco, distance.Distance, testdatagen.DateInsidePeakRateCycle)
 	if err != nil {
 		t.Error("Unable to determine linehaulChargeTotal: ", err)
 	}
@@ -0,0 +1,78 @@
+package rateengine
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/unit"
+)
+
+// MaxMoveDateWindowDays is the largest number of days either side of the planned move
+// date that ComputePPMMoveDates will price.
+const MaxMoveDateWindowDays = 21
+
+// MoveDateEstimate is the PPM incentive for a single candidate move date, along with the
+// rate cycle and discount set used to price it.
+type MoveDateEstimate struct {
+	MoveDate time.Time
+	PPMDiscount
+	GCC                  unit.Cents
+	IncentiveEstimateMin unit.Cents
+	IncentiveEstimateMax unit.Cents
+}
+
+// ComputePPMMoveDates prices a PPM (excluding SIT) for every day from windowDays before to
+// windowDays after the planned move date, so the member can see how moving across the
+// peak/non-peak boundary changes the incentive. The distance is only determined once.
+// Dates for which no tariff or discount is available are left out of the results.
+func (re *RateEngine) ComputePPMMoveDates(
+	weight unit.Pound,
+	originZip5 string,
+	destinationZip5 string,
+	plannedMoveDate time.Time,
+	windowDays int) ([]MoveDateEstimate, error) {
+
+	if windowDays < 0 || windowDays > MaxMoveDateWindowDays {
+		return nil, errors.Errorf("window must be between 0 and %d days, got %d", MaxMoveDateWindowDays, windowDays)
+	}
+
+	distance, err := re.determineMileage(originZip5, destinationZip5)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to determine mileage")
+	}
+
+	var estimates []MoveDateEstimate
+	var lastErr error
+	for offset := -windowDays; offset <= windowDays; offset++ {
+		moveDate := plannedMoveDate.AddDate(0, 0, offset)
+
+		discount, err := FetchPPMDiscount(re.db, re.logger, originZip5, destinationZip5, moveDate)
+		if err != nil {
+			re.logger.Info("No discount for candidate move date", zap.Time("move_date", moveDate), zap.Error(err))
+			lastErr = err
+			continue
+		}
+
+		cost, err := re.computePPMForMileage(weight, originZip5, destinationZip5, distance, moveDate, 0, discount.LinehaulDiscount, 0)
+		if err != nil {
+			re.logger.Info("Could not price candidate move date", zap.Time("move_date", moveDate), zap.Error(err))
+			lastErr = err
+			continue
+		}
+
+		estimates = append(estimates, MoveDateEstimate{
+			MoveDate:             moveDate,
+			PPMDiscount:          discount,
+			GCC:                  cost.GCC,
+			IncentiveEstimateMin: cost.GCC.MultiplyRate(unit.NewRate(95, 100), unit.RoundHalfUp),
+			IncentiveEstimateMax: cost.GCC.MultiplyRate(unit.NewRate(105, 100), unit.RoundHalfUp),
+		})
+	}
+
+	if len(estimates) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return estimates, nil
+}
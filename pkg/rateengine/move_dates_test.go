@@ -0,0 +1,58 @@
+package rateengine
+
+import (
+	"time"
+
+	"github.com/transcom/mymove/pkg/route"
+	"github.com/transcom/mymove/pkg/testdatagen/scenario"
+	"github.com/transcom/mymove/pkg/unit"
+)
+
+func (suite *RateEngineSuite) Test_ComputePPMMoveDatesAcrossPeakBoundary() {
+	if err := scenario.RunRateEngineScenario1(suite.db); err != nil {
+		suite.FailNow("failed to run scenario 1: %+v", err)
+	}
+
+	engine := NewRateEngine(suite.db, suite.logger, route.NewTestingPlanner(362))
+	lastPeakDay := time.Date(2018, time.September, 30, 0, 0, 0, 0, time.UTC)
+
+	estimates, err := engine.ComputePPMMoveDates(unit.Pound(4000), "32168", "29429", lastPeakDay, 1)
+	suite.Nil(err)
+
+	if suite.Len(estimates, 3) {
+		suite.Equal(lastPeakDay.AddDate(0, 0, -1), estimates[0].MoveDate)
+		suite.True(estimates[0].PeakRateCycle)
+		suite.True(estimates[1].PeakRateCycle)
+		suite.False(estimates[2].PeakRateCycle)
+		suite.Equal(time.Date(2018, time.October, 1, 0, 0, 0, 0, time.UTC), estimates[2].RateCycleStart)
+
+		for _, estimate := range estimates {
+			suite.Equal("2", estimate.CodeOfService)
+			suite.Equal(unit.DiscountRate(0.67), estimate.LinehaulDiscount)
+			suite.Equal(unit.Cents(263300), estimate.GCC)
+			suite.Equal(unit.Cents(276465), estimate.IncentiveEstimateMax)
+		}
+	}
+}
+
+func (suite *RateEngineSuite) Test_ComputePPMMoveDatesSkipsUnpricedDates() {
+	if err := scenario.RunRateEngineScenario1(suite.db); err != nil {
+		suite.FailNow("failed to run scenario 1: %+v", err)
+	}
+
+	engine := NewRateEngine(suite.db, suite.logger, route.NewTestingPlanner(362))
+
+	// Discounts in scenario 1 stop at Oct 15th 2018
+	estimates, err := engine.ComputePPMMoveDates(unit.Pound(4000), "32168", "29429", scenario.Oct15_2018, 2)
+	suite.Nil(err)
+	if suite.Len(estimates, 2) {
+		suite.Equal(scenario.Oct15_2018.AddDate(0, 0, -1), estimates[1].MoveDate)
+	}
+
+	// No dates in the window can be priced
+	_, err = engine.ComputePPMMoveDates(unit.Pound(4000), "32168", "29429", scenario.May15_2019, 2)
+	suite.NotNil(err)
+
+	_, err = engine.ComputePPMMoveDates(unit.Pound(4000), "32168", "29429", scenario.May15_2018, MaxMoveDateWindowDays+1)
+	suite.NotNil(err)
+}
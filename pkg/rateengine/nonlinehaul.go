@@ -1,7 +1,6 @@
 package rateengine
 
 import (
-	"math"
 	"time"
 
 	"github.com/pkg/errors"
@@ -19,12 +18,12 @@ type NonLinehaulCostComputation struct {
 	UnpackFee             unit.Cents
 }
 
-// Scale scales a cost computation by a multiplicative factor
-func (c *NonLinehaulCostComputation) Scale(factor float64) {
-	c.OriginServiceFee = c.OriginServiceFee.MultiplyFloat64(factor)
-	c.DestinationServiceFee = c.DestinationServiceFee.MultiplyFloat64(factor)
-	c.PackFee = c.PackFee.MultiplyFloat64(factor)
-	c.UnpackFee = c.UnpackFee.MultiplyFloat64(factor)
+// Scale scales a cost computation by an exact multiplicative factor, rounding each charge once
+func (c *NonLinehaulCostComputation) Scale(factor unit.Rate) {
+	c.OriginServiceFee = c.OriginServiceFee.MultiplyRate(factor, unit.RoundHalfUp)
+	c.DestinationServiceFee = c.DestinationServiceFee.MultiplyRate(factor, unit.RoundHalfUp)
+	c.PackFee = c.PackFee.MultiplyRate(factor, unit.RoundHalfUp)
+	c.UnpackFee = c.UnpackFee.MultiplyRate(factor, unit.RoundHalfUp)
 }
 
 func (re *RateEngine) serviceFeeCents(cwt unit.CWT, zip3 string, date time.Time) (unit.Cents, error) {
@@ -60,7 +59,9 @@ func (re *RateEngine) fullUnpackCents(cwt unit.CWT, zip3 string, date time.Time)
 		return 0, err
 	}
 
-	return unit.Cents(math.Round(float64(cwt.Int()*fullUnpackRate) / 1000.0)), nil
+	// The unpack rate is published in millicents, so the charge is only rounded to cents once
+	// it has been multiplied out
+	return fullUnpackRate.Multiply(cwt.Int()).ToCents(unit.RoundHalfUp), nil
 }
 
 // SitCharge calculates the SIT charge based on various factors.
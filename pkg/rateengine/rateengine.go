@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/gobuffalo/pop"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
@@ -30,16 +31,6 @@ type CostComputation struct {
 	GCC    unit.Cents
 }
 
-// Scale scales a cost computation by a multiplicative factor
-func (c *CostComputation) Scale(factor float64) {
-	c.LinehaulCostComputation.Scale(factor)
-	c.NonLinehaulCostComputation.Scale(factor)
-
-	c.SITFee = c.SITFee.MultiplyFloat64(factor)
-	c.SITMax = c.SITMax.MultiplyFloat64(factor)
-	c.GCC = c.GCC.MultiplyFloat64(factor)
-}
-
 // MarshalLogObject allows CostComputation to be logged by Zap.
 func (c CostComputation) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
 	encoder.AddInt("BaseLinehaul", c.BaseLinehaul.Int())
@@ -47,6 +38,8 @@ func (c CostComputation) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
 	encoder.AddInt("DestinationLinehaulFactor", c.DestinationLinehaulFactor.Int())
 	encoder.AddInt("ShorthaulCharge", c.ShorthaulCharge.Int())
 	encoder.AddInt("LinehaulChargeTotal", c.LinehaulChargeTotal.Int())
+	encoder.AddInt("FuelSurcharge", c.FuelSurcharge.Int())
+	encoder.AddBool("FuelSurchargeMissing", c.FuelSurchargeMissing)
 
 	encoder.AddInt("OriginServiceFee", c.OriginServiceFee.Int())
 	encoder.AddInt("DestinationServiceFee", c.DestinationServiceFee.Int())
@@ -75,19 +68,92 @@ func (re *RateEngine) ComputePPM(
 	lhDiscount unit.DiscountRate,
 	sitDiscount unit.DiscountRate) (cost CostComputation, err error) {
 
+	distance, err := re.determineMileage(originZip5, destinationZip5)
+	if err != nil {
+		re.logger.Error("Failed to compute linehaul cost", zap.Error(err))
+		return cost, errors.Wrap(err, "Failed to determine mileage")
+	}
+
+	return re.computePPMForMileage(weight, originZip5, destinationZip5, distance, date, daysInSIT, lhDiscount, sitDiscount)
+}
+
+// RouteCostComputation is the cost of a move through several stops, along with the part of the
+// linehaul charges attributed to each leg of the route.
+type RouteCostComputation struct {
+	CostComputation
+	Legs []LinehaulLeg
+}
+
+// PPMRoute returns the ZIP5s a PPM stops at: its origin, its additional pickup if it has one,
+// and its destination
+func PPMRoute(originZip5 string, additionalPickupZip5 *string, destinationZip5 string) []string {
+	if additionalPickupZip5 == nil || *additionalPickupZip5 == "" {
+		return []string{originZip5, destinationZip5}
+	}
+	return []string{originZip5, *additionalPickupZip5, destinationZip5}
+}
+
+// ComputePPMRoute calculates the cost of a PPM which stops at each of the ZIP5s in turn, e.g. to
+// make an additional pickup. The move is priced from the first to the last ZIP5 over the total
+// mileage of the route. A route of two ZIP5s costs the same as ComputePPM.
+func (re *RateEngine) ComputePPMRoute(
+	weight unit.Pound,
+	zip5s []string,
+	date time.Time,
+	daysInSIT int,
+	lhDiscount unit.DiscountRate,
+	sitDiscount unit.DiscountRate) (cost RouteCostComputation, err error) {
+
+	if len(zip5s) < 2 {
+		return cost, route.ErrTooFewWaypoints
+	}
+
+	var distance route.DistanceResult
+	if len(zip5s) == 2 {
+		distance, err = re.determineMileage(zip5s[0], zip5s[1])
+	} else {
+		distance, err = re.determineRouteMileage(zip5s)
+	}
+	if err != nil {
+		return cost, errors.Wrap(err, "Failed to determine mileage")
+	}
+
+	originZip5 := zip5s[0]
+	destinationZip5 := zip5s[len(zip5s)-1]
+	cost.CostComputation, err = re.computePPMForMileage(weight, originZip5, destinationZip5, distance, date, daysInSIT, lhDiscount, sitDiscount)
+	if err != nil {
+		return cost, err
+	}
+	cost.Legs = attributeLinehaul(cost.LinehaulCostComputation, zip5s, route.RouteDistance{Total: distance.Distance, Legs: distance.Legs})
+	return cost, nil
+}
+
+// computePPMForMileage calculates the cost of a PPM move over an already determined distance.
+func (re *RateEngine) computePPMForMileage(
+	weight unit.Pound,
+	originZip5 string,
+	destinationZip5 string,
+	distance route.DistanceResult,
+	date time.Time,
+	daysInSIT int,
+	lhDiscount unit.DiscountRate,
+	sitDiscount unit.DiscountRate) (cost CostComputation, err error) {
+
 	// Weights below 1000lbs are prorated to the 1000lb rate
-	prorateFactor := 1.0
+	prorateFactor := unit.NewRate(1, 1)
 	if weight.Int() < 1000 {
-		prorateFactor = weight.Float64() / 1000.0
+		prorateFactor = unit.NewRate(int64(weight.Int()), 1000)
 		weight = unit.Pound(1000)
 	}
 
 	// Linehaul charges
-	linehaulCostComputation, err := re.linehaulChargeComputation(weight, originZip5, destinationZip5, date)
+	linehaulCostComputation, err := re.linehaulChargeComputation(weight, originZip5, destinationZip5, distance.Distance, date)
 	if err != nil {
 		re.logger.Error("Failed to compute linehaul cost", zap.Error(err))
 		return
 	}
+	linehaulCostComputation.MileageProvider = distance.Provider
+	linehaulCostComputation.MileageProfile = distance.Profile
 
 	// Non linehaul charges
 	nonLinehaulCostComputation, err := re.nonLinehaulChargeComputation(weight, originZip5, destinationZip5, date)
@@ -96,12 +162,14 @@ func (re *RateEngine) ComputePPM(
 		return
 	}
 
-	// Apply linehaul discounts
-	linehaulCostComputation.LinehaulChargeTotal = lhDiscount.Apply(linehaulCostComputation.LinehaulChargeTotal)
-	nonLinehaulCostComputation.OriginServiceFee = lhDiscount.Apply(nonLinehaulCostComputation.OriginServiceFee)
-	nonLinehaulCostComputation.DestinationServiceFee = lhDiscount.Apply(nonLinehaulCostComputation.DestinationServiceFee)
-	nonLinehaulCostComputation.PackFee = lhDiscount.Apply(nonLinehaulCostComputation.PackFee)
-	nonLinehaulCostComputation.UnpackFee = lhDiscount.Apply(nonLinehaulCostComputation.UnpackFee)
+	// Apply linehaul discounts and the prorate factor together, so that each discounted
+	// charge is only rounded once. The fuel surcharge and the linehaul breakdown are prorated
+	// but not discounted.
+	linehaulFactor := lhDiscount.PercentOfTariff().Multiply(prorateFactor)
+	linehaulChargeTotal := linehaulCostComputation.LinehaulChargeTotal.MultiplyRate(linehaulFactor, unit.RoundHalfUp)
+	linehaulCostComputation.Scale(prorateFactor)
+	linehaulCostComputation.LinehaulChargeTotal = linehaulChargeTotal
+	nonLinehaulCostComputation.Scale(linehaulFactor)
 
 	// SIT
 	// Note that SIT has a different discount rate than [non]linehaul charges
@@ -111,7 +179,8 @@ func (re *RateEngine) ComputePPM(
 		re.logger.Info("Can't calculate sit")
 		return
 	}
-	sitFee := sitDiscount.Apply(sit)
+	sitFactor := sitDiscount.PercentOfTariff().Multiply(prorateFactor)
+	sitFee := sit.MultiplyRate(sitFactor, unit.RoundHalfUp)
 
 	/// Max SIT
 	maxSIT, err := re.SitCharge(weight.ToCWT(), MaxSITDays, destinationZip3, date, true)
@@ -120,10 +189,12 @@ func (re *RateEngine) ComputePPM(
 		return
 	}
 	// Note that SIT has a different discount rate than [non]linehaul charges
-	maxSITFee := sitDiscount.Apply(maxSIT)
+	maxSITFee := maxSIT.MultiplyRate(sitFactor, unit.RoundHalfUp)
 
 	// Totals
+	// The GCC is the sum of the already rounded charges, so it matches the breakdown
 	gcc := linehaulCostComputation.LinehaulChargeTotal +
+		linehaulCostComputation.FuelSurcharge +
 		nonLinehaulCostComputation.OriginServiceFee +
 		nonLinehaulCostComputation.DestinationServiceFee +
 		nonLinehaulCostComputation.PackFee +
@@ -137,9 +208,6 @@ func (re *RateEngine) ComputePPM(
 		GCC:    gcc,
 	}
 
-	// Finaly, scale by prorate factor
-	cost.Scale(prorateFactor)
-
 	re.logger.Info("PPM cost computation", zap.Object("cost", cost))
 
 	return cost, nil
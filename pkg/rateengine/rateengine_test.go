@@ -14,9 +14,7 @@ import (
 	"github.com/transcom/mymove/pkg/unit"
 )
 
-func (suite *RateEngineSuite) Test_CheckPPMTotal() {
-	t := suite.T()
-	engine := NewRateEngine(suite.db, suite.logger, suite.planner)
+func (suite *RateEngineSuite) setupPPMTotalRates() {
 	originZip3 := models.Tariff400ngZip3{
 		Zip3:          "395",
 		BasepointCity: "Saucier",
@@ -103,6 +101,12 @@ func (suite *RateEngineSuite) Test_CheckPPMTotal() {
 		EffectiveDateUpper: testdatagen.PeakRateCycleEnd,
 	}
 	suite.mustSave(&shorthaul)
+}
+
+func (suite *RateEngineSuite) Test_CheckPPMTotal() {
+	t := suite.T()
+	engine := NewRateEngine(suite.db, suite.logger, suite.planner)
+	suite.setupPPMTotalRates()
 
 	// 139698 +20000
 	cost, err := engine.ComputePPM(2000, "39574", "33633", testdatagen.RateEngineDate,
@@ -118,6 +122,98 @@ func (suite *RateEngineSuite) Test_CheckPPMTotal() {
 	}
 }
 
+func (suite *RateEngineSuite) Test_CheckProratedPPMTotal() {
+	engine := NewRateEngine(suite.db, suite.logger, suite.planner)
+	suite.setupPPMTotalRates()
+
+	// 850lbs is priced at the 1000lb (10 CWT) rate and prorated by 0.85. With a 60% discount
+	// each discounted charge is 0.4 * 0.85 = 0.34 of tariff, rounded once, half up:
+	// linehaul (20000 + 57*10 + 69*10) * 0.34 = 7228.4
+	// origin service fee 350*10 * 0.34 = 1190
+	// destination service fee 663*10 * 0.34 = 2254.2
+	// full pack 5429*10 * 0.34 = 18458.6
+	// full unpack 5429 * 0.34 = 1845.86
+	// SIT 222*1*10 at 50% discount * 0.85 = 943.5
+	cost, err := engine.ComputePPM(850, "39574", "33633", testdatagen.RateEngineDate,
+		1, unit.DiscountRate(.6), unit.DiscountRate(.5))
+	suite.Nil(err)
+
+	suite.Equal(unit.Cents(7228), cost.LinehaulChargeTotal)
+	suite.Equal(unit.Cents(1190), cost.OriginServiceFee)
+	suite.Equal(unit.Cents(2254), cost.DestinationServiceFee)
+	suite.Equal(unit.Cents(18459), cost.PackFee)
+	suite.Equal(unit.Cents(1846), cost.UnpackFee)
+	suite.Equal(unit.Cents(944), cost.SITFee)
+	suite.Equal(unit.Cents(17000), cost.BaseLinehaul)
+
+	// The GCC matches the sum of the line items
+	suite.Equal(unit.Cents(30977), cost.GCC)
+}
+
+func (suite *RateEngineSuite) Test_ComputePPMRoute() {
+	engine := NewRateEngine(suite.db, suite.logger, suite.planner)
+	suite.setupPPMTotalRates()
+
+	// An additional pickup in Gulfport on the way from Saucier to Tampa. Each leg is 1234 miles,
+	// priced as a 2468 mile move from 395 to 336 with the same rates as Test_CheckPPMTotal
+	cost, err := engine.ComputePPMRoute(2000, []string{"39574", "39501", "33633"}, testdatagen.RateEngineDate,
+		1, unit.DiscountRate(.6), unit.DiscountRate(.5))
+	suite.Nil(err)
+
+	suite.Equal(2468, cost.Mileage)
+	suite.Equal(unit.Cents(64887), cost.GCC)
+	// (20000 + 57*20 + 69*20) * 0.4 = 9008, split evenly between the legs
+	if suite.Len(cost.Legs, 2) {
+		suite.Equal(LinehaulLeg{OriginZip5: "39574", DestinationZip5: "39501", Mileage: 1234, LinehaulChargeTotal: 4504}, cost.Legs[0])
+		suite.Equal(LinehaulLeg{OriginZip5: "39501", DestinationZip5: "33633", Mileage: 1234, LinehaulChargeTotal: 4504}, cost.Legs[1])
+	}
+
+	// Without an additional pickup, the route is the direct move
+	direct, err := engine.ComputePPMRoute(2000, PPMRoute("39574", nil, "33633"), testdatagen.RateEngineDate,
+		1, unit.DiscountRate(.6), unit.DiscountRate(.5))
+	suite.Nil(err)
+	expected, err := engine.ComputePPM(2000, "39574", "33633", testdatagen.RateEngineDate,
+		1, unit.DiscountRate(.6), unit.DiscountRate(.5))
+	suite.Nil(err)
+	suite.Equal(expected, direct.CostComputation)
+	suite.Len(direct.Legs, 1)
+
+	_, err = engine.ComputePPMRoute(2000, []string{"39574"}, testdatagen.RateEngineDate,
+		1, unit.DiscountRate(.6), unit.DiscountRate(.5))
+	suite.Equal(route.ErrTooFewWaypoints, err)
+}
+
+func (suite *RateEngineSuite) Test_PPMRoute() {
+	suite.Equal([]string{"39574", "33633"}, PPMRoute("39574", nil, "33633"))
+	empty := ""
+	suite.Equal([]string{"39574", "33633"}, PPMRoute("39574", &empty, "33633"))
+	pickup := "39501"
+	suite.Equal([]string{"39574", "39501", "33633"}, PPMRoute("39574", &pickup, "33633"))
+}
+
+func (suite *RateEngineSuite) Test_AttributeLinehaulAddsUp() {
+	cost := LinehaulCostComputation{LinehaulChargeTotal: 1000, FuelSurcharge: 101}
+	legs := attributeLinehaul(cost, []string{"39574", "39501", "33633"}, route.RouteDistance{Total: 300, Legs: []int{100, 200}})
+
+	if suite.Len(legs, 2) {
+		suite.Equal(unit.Cents(333), legs[0].LinehaulChargeTotal)
+		suite.Equal(unit.Cents(34), legs[0].FuelSurcharge)
+		suite.Equal(unit.Cents(667), legs[1].LinehaulChargeTotal)
+		suite.Equal(unit.Cents(67), legs[1].FuelSurcharge)
+	}
+}
+
+func (suite *RateEngineSuite) Test_ScaleRoundsFuelSurchargeOnce() {
+	// 10% of 1005 is 100.5, which would round up to 101 and then halve to 51
+	cost := LinehaulCostComputation{LinehaulChargeTotal: 1005, FuelSurchargeRate: unit.NewRate(1, 10)}
+	cost.FuelSurcharge = cost.LinehaulChargeTotal.MultiplyRate(cost.FuelSurchargeRate, unit.RoundHalfUp)
+	suite.Equal(unit.Cents(101), cost.FuelSurcharge)
+
+	cost.Scale(unit.NewRate(1, 2))
+	suite.Equal(unit.Cents(50), cost.FuelSurcharge)
+	suite.Equal(unit.Cents(503), cost.LinehaulChargeTotal)
+}
+
 type RateEngineSuite struct {
 	suite.Suite
 	db      *pop.Connection
@@ -0,0 +1,231 @@
+// Package reestimate recomputes the cached estimate fields on DRAFT and SUBMITTED
+// PPMs after a tariff load or a refresh of TSP performance discounts, both of
+// which leave the stored incentive, SIT and mileage values stale.
+package reestimate
+
+import (
+	"time"
+
+	"github.com/gobuffalo/pop"
+	"github.com/gobuffalo/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/gen/internalmessages"
+	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/rateengine"
+	"github.com/transcom/mymove/pkg/route"
+	"github.com/transcom/mymove/pkg/unit"
+)
+
+// Criteria selects the PPMs to re-estimate and controls how changes are recorded.
+type Criteria struct {
+	// MoveDateStart and MoveDateEnd bound the planned move date, [start, end).
+	MoveDateStart time.Time
+	MoveDateEnd   time.Time
+	// OriginZip3s and DestinationZip3s restrict the PPMs by pickup and destination
+	// ZIP3. An empty list matches every ZIP.
+	OriginZip3s      []string
+	DestinationZip3s []string
+	// Reason is recorded on every audit record, e.g. "2018 tariff load".
+	Reason string
+	// Threshold is the change in the maximum incentive estimate above which a
+	// member is included in the report's Notable list.
+	Threshold unit.Cents
+	// DryRun computes and reports changes without writing them.
+	DryRun bool
+}
+
+// Change describes the estimate of a single PPM before and after re-estimation.
+type Change struct {
+	PPMID                uuid.UUID
+	MoveID               uuid.UUID
+	ServiceMemberID      uuid.UUID
+	PreviousEstimateMax  *unit.Cents
+	IncentiveEstimateMin unit.Cents
+	IncentiveEstimateMax unit.Cents
+	Delta                unit.Cents
+}
+
+// Failure records a PPM that could not be re-estimated.
+type Failure struct {
+	PPMID uuid.UUID
+	Err   error
+}
+
+// Report summarizes a re-estimation run.
+type Report struct {
+	Examined int
+	Updated  int
+	Failures []Failure
+	// Notable holds the changes whose estimate moved by more than the threshold.
+	Notable []Change
+}
+
+// Reestimator recomputes PPM estimates using the rate engine.
+type Reestimator struct {
+	db      *pop.Connection
+	logger  *zap.Logger
+	planner route.Planner
+}
+
+// NewReestimator creates a new Reestimator
+func NewReestimator(db *pop.Connection, logger *zap.Logger, planner route.Planner) *Reestimator {
+	return &Reestimator{db: db, logger: logger, planner: planner}
+}
+
+func matchesZip3(zip5 string, zip3s []string) bool {
+	if len(zip3s) == 0 {
+		return true
+	}
+	if len(zip5) < 3 {
+		return false
+	}
+	zip3 := rateengine.Zip5ToZip3(zip5)
+	for _, z := range zip3s {
+		if z == zip3 {
+			return true
+		}
+	}
+	return false
+}
+
+func absCents(c unit.Cents) unit.Cents {
+	if c < 0 {
+		return -c
+	}
+	return c
+}
+
+// Run re-estimates every PPM matching the criteria. A failure to price an individual
+// PPM is recorded in the report rather than aborting the run.
+func (r *Reestimator) Run(criteria Criteria) (Report, error) {
+	var report Report
+
+	if criteria.Reason == "" {
+		return report, errors.New("a reason is required for the estimate audit trail")
+	}
+
+	ppms, err := models.FetchPPMsForReestimate(r.db, criteria.MoveDateStart, criteria.MoveDateEnd)
+	if err != nil {
+		return report, errors.Wrap(err, "Failed to fetch PPMs for re-estimation")
+	}
+
+	for _, ppm := range ppms {
+		if !matchesZip3(*ppm.PickupPostalCode, criteria.OriginZip3s) ||
+			!matchesZip3(*ppm.DestinationPostalCode, criteria.DestinationZip3s) {
+			continue
+		}
+		report.Examined++
+
+		change, changed, err := r.reestimatePPM(ppm, criteria)
+		if err != nil {
+			r.logger.Error("Failed to re-estimate PPM", zap.String("ppm_id", ppm.ID.String()), zap.Error(err))
+			report.Failures = append(report.Failures, Failure{PPMID: ppm.ID, Err: err})
+			continue
+		}
+		if !changed {
+			continue
+		}
+		report.Updated++
+
+		if change.PreviousEstimateMax != nil && change.Delta > criteria.Threshold {
+			report.Notable = append(report.Notable, change)
+		}
+	}
+
+	r.logger.Info("PPM re-estimation complete",
+		zap.Int("examined", report.Examined),
+		zap.Int("updated", report.Updated),
+		zap.Int("failed", len(report.Failures)),
+		zap.Int("notable", len(report.Notable)))
+
+	return report, nil
+}
+
+func (r *Reestimator) reestimatePPM(ppm models.PersonallyProcuredMove, criteria Criteria) (change Change, changed bool, err error) {
+	planner := r.planner
+	if ppm.VehicleType != nil && *ppm.VehicleType == internalmessages.PPMVehicleTypeCAR {
+		planner = route.PlannerForVehicle(planner, route.CarProfile)
+	}
+	engine := rateengine.NewRateEngine(r.db, r.logger, planner)
+
+	origin := *ppm.PickupPostalCode
+	destination := *ppm.DestinationPostalCode
+	daysInSIT := 0
+	if ppm.HasSit != nil && *ppm.HasSit && ppm.DaysInStorage != nil {
+		daysInSIT = int(*ppm.DaysInStorage)
+	}
+
+	lhDiscount, sitDiscount, err := rateengine.PPMDiscountFetch(r.db, r.logger, origin, destination, *ppm.PlannedMoveDate)
+	if err != nil {
+		return change, false, err
+	}
+
+	stops := rateengine.PPMRoute(origin, ppm.AdditionalPickupPostalCode, destination)
+	cost, err := engine.ComputePPMRoute(unit.Pound(*ppm.WeightEstimate), stops, *ppm.PlannedMoveDate, daysInSIT, lhDiscount, sitDiscount)
+	if err != nil {
+		return change, false, err
+	}
+
+	mileage := int64(cost.LinehaulCostComputation.Mileage)
+	min := cost.GCC.MultiplyRate(unit.NewRate(95, 100), unit.RoundHalfUp)
+	max := cost.GCC.MultiplyRate(unit.NewRate(105, 100), unit.RoundHalfUp)
+
+	if ppm.IncentiveEstimateMin != nil && *ppm.IncentiveEstimateMin == min &&
+		ppm.IncentiveEstimateMax != nil && *ppm.IncentiveEstimateMax == max &&
+		ppm.PlannedSITMax != nil && *ppm.PlannedSITMax == cost.SITFee &&
+		ppm.Mileage != nil && *ppm.Mileage == mileage {
+		return change, false, nil
+	}
+
+	move := models.Move{}
+	if err := r.db.Eager("Orders").Find(&move, ppm.MoveID); err != nil {
+		return change, false, errors.Wrap(err, "Failed to find move for PPM")
+	}
+
+	change = Change{
+		PPMID:                ppm.ID,
+		MoveID:               move.ID,
+		ServiceMemberID:      move.Orders.ServiceMemberID,
+		PreviousEstimateMax:  ppm.IncentiveEstimateMax,
+		IncentiveEstimateMin: min,
+		IncentiveEstimateMax: max,
+	}
+	if ppm.IncentiveEstimateMax != nil {
+		change.Delta = absCents(max - *ppm.IncentiveEstimateMax)
+	}
+
+	if criteria.DryRun {
+		return change, true, nil
+	}
+
+	audit := models.NewPPMEstimateAudit(ppm, criteria.Reason, min, max, cost.SITFee, mileage)
+
+	ppm.SetMileage(mileage, cost.MileageProvider, cost.MileageProfile)
+	ppm.PlannedSITMax = &cost.SITFee
+	ppm.SITMax = &cost.SITMax
+	ppm.IncentiveEstimateMin = &min
+	ppm.IncentiveEstimateMax = &max
+
+	err = r.db.Transaction(func(tx *pop.Connection) error {
+		if verrs, err := tx.ValidateAndUpdate(&ppm); verrs.HasAny() || err != nil {
+			if err == nil {
+				err = errors.Errorf("validation errors saving PPM: %v", verrs)
+			}
+			return errors.Wrap(err, "Failed to save PPM")
+		}
+		if verrs, err := tx.ValidateAndCreate(&audit); verrs.HasAny() || err != nil {
+			if err == nil {
+				err = errors.Errorf("validation errors saving estimate audit: %v", verrs)
+			}
+			return errors.Wrap(err, "Failed to save estimate audit")
+		}
+		return nil
+	})
+	if err != nil {
+		return change, false, err
+	}
+
+	return change, true, nil
+}
@@ -0,0 +1,197 @@
+package reestimate
+
+import (
+	"log"
+	"testing"
+	"time"
+
+	"github.com/gobuffalo/pop"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/route"
+	"github.com/transcom/mymove/pkg/testdatagen"
+	"github.com/transcom/mymove/pkg/testdatagen/scenario"
+	"github.com/transcom/mymove/pkg/unit"
+)
+
+var scenario1MoveDate = time.Date(2018, time.June, 18, 0, 0, 0, 0, time.UTC)
+
+func (suite *ReestimateSuite) makeScenario1PPM(moveDate time.Time, estimateMax *unit.Cents) models.PersonallyProcuredMove {
+	move, err := testdatagen.MakeMove(suite.db)
+	suite.Nil(err)
+
+	ppm, verrs, err := move.CreatePPM(suite.db,
+		nil,
+		models.Int64Pointer(4000),
+		models.TimePointer(moveDate),
+		models.StringPointer("32168"),
+		nil,
+		nil,
+		models.StringPointer("29429"),
+		nil,
+		nil,
+		nil,
+		false,
+		nil,
+	)
+	suite.Nil(err)
+	suite.False(verrs.HasAny())
+
+	if estimateMax != nil {
+		ppm.IncentiveEstimateMax = estimateMax
+		suite.mustSave(ppm)
+	}
+	return *ppm
+}
+
+func (suite *ReestimateSuite) Test_RunUpdatesStaleEstimates() {
+	if err := scenario.RunRateEngineScenario1(suite.db); err != nil {
+		suite.FailNow("failed to run scenario 1: %+v", err)
+	}
+
+	stale := unit.Cents(200000)
+	ppm := suite.makeScenario1PPM(scenario1MoveDate, &stale)
+	outOfRange := suite.makeScenario1PPM(scenario.May15_2019, nil)
+
+	reestimator := NewReestimator(suite.db, suite.logger, suite.planner)
+	report, err := reestimator.Run(Criteria{
+		MoveDateStart: scenario.May15_2018,
+		MoveDateEnd:   scenario.Oct15_2018,
+		Reason:        "test tariff load",
+		Threshold:     unit.Cents(1000),
+	})
+	suite.Nil(err)
+
+	suite.Equal(1, report.Examined)
+	suite.Equal(1, report.Updated)
+	suite.Empty(report.Failures)
+	if suite.Len(report.Notable, 1) {
+		suite.Equal(ppm.ID, report.Notable[0].PPMID)
+		suite.Equal(unit.Cents(276465), report.Notable[0].IncentiveEstimateMax)
+		suite.Equal(unit.Cents(76465), report.Notable[0].Delta)
+	}
+
+	updated := models.PersonallyProcuredMove{}
+	suite.Nil(suite.db.Find(&updated, ppm.ID))
+	suite.Equal(unit.Cents(250135), *updated.IncentiveEstimateMin)
+	suite.Equal(unit.Cents(276465), *updated.IncentiveEstimateMax)
+	suite.Equal(int64(362), *updated.Mileage)
+
+	audits, err := models.FetchPPMEstimateAudits(suite.db, ppm.ID)
+	suite.Nil(err)
+	if suite.Len(audits, 1) {
+		suite.Equal(stale, *audits[0].PreviousIncentiveEstimateMax)
+		suite.Equal(unit.Cents(276465), audits[0].IncentiveEstimateMax)
+		suite.Equal("test tariff load", audits[0].Reason)
+	}
+
+	untouched := models.PersonallyProcuredMove{}
+	suite.Nil(suite.db.Find(&untouched, outOfRange.ID))
+	suite.Nil(untouched.IncentiveEstimateMax)
+
+	// A second run finds nothing left to change
+	report, err = reestimator.Run(Criteria{
+		MoveDateStart: scenario.May15_2018,
+		MoveDateEnd:   scenario.Oct15_2018,
+		Reason:        "test tariff load",
+	})
+	suite.Nil(err)
+	suite.Equal(1, report.Examined)
+	suite.Equal(0, report.Updated)
+}
+
+func (suite *ReestimateSuite) Test_RunDryRunAndZipFilter() {
+	if err := scenario.RunRateEngineScenario1(suite.db); err != nil {
+		suite.FailNow("failed to run scenario 1: %+v", err)
+	}
+
+	stale := unit.Cents(200000)
+	ppm := suite.makeScenario1PPM(scenario1MoveDate, &stale)
+
+	reestimator := NewReestimator(suite.db, suite.logger, suite.planner)
+
+	report, err := reestimator.Run(Criteria{
+		MoveDateStart: scenario.May15_2018,
+		MoveDateEnd:   scenario.Oct15_2018,
+		OriginZip3s:   []string{"945"},
+		Reason:        "test tariff load",
+	})
+	suite.Nil(err)
+	suite.Equal(0, report.Examined)
+
+	report, err = reestimator.Run(Criteria{
+		MoveDateStart: scenario.May15_2018,
+		MoveDateEnd:   scenario.Oct15_2018,
+		OriginZip3s:   []string{"321"},
+		Reason:        "test tariff load",
+		DryRun:        true,
+	})
+	suite.Nil(err)
+	suite.Equal(1, report.Updated)
+	suite.Len(report.Notable, 1)
+
+	unchanged := models.PersonallyProcuredMove{}
+	suite.Nil(suite.db.Find(&unchanged, ppm.ID))
+	suite.Equal(stale, *unchanged.IncentiveEstimateMax)
+
+	audits, err := models.FetchPPMEstimateAudits(suite.db, ppm.ID)
+	suite.Nil(err)
+	suite.Empty(audits)
+}
+
+func (suite *ReestimateSuite) Test_RunRecordsFailures() {
+	// No tariff data has been loaded, so pricing must fail
+	ppm := suite.makeScenario1PPM(scenario1MoveDate, nil)
+
+	reestimator := NewReestimator(suite.db, suite.logger, suite.planner)
+	report, err := reestimator.Run(Criteria{
+		MoveDateStart: scenario.May15_2018,
+		MoveDateEnd:   scenario.Oct15_2018,
+		Reason:        "test tariff load",
+	})
+	suite.Nil(err)
+	suite.Equal(1, report.Examined)
+	if suite.Len(report.Failures, 1) {
+		suite.Equal(ppm.ID, report.Failures[0].PPMID)
+	}
+}
+
+type ReestimateSuite struct {
+	suite.Suite
+	db      *pop.Connection
+	logger  *zap.Logger
+	planner route.Planner
+}
+
+func (suite *ReestimateSuite) SetupTest() {
+	suite.db.TruncateAll()
+}
+
+func (suite *ReestimateSuite) mustSave(model interface{}) {
+	t := suite.T()
+
+	verrs, err := suite.db.ValidateAndSave(model)
+	if err != nil {
+		log.Panic(err)
+	}
+	if verrs.Count() > 0 {
+		t.Fatalf("errors encountered saving %v: %v", model, verrs)
+	}
+}
+
+func TestReestimateSuite(t *testing.T) {
+	configLocation := "../../config"
+	pop.AddLookupPaths(configLocation)
+	db, err := pop.Connect("test")
+	if err != nil {
+		log.Panic(err)
+	}
+
+	logger, _ := zap.NewDevelopment()
+	planner := route.NewTestingPlanner(362)
+
+	hs := &ReestimateSuite{db: db, logger: logger, planner: planner}
+	suite.Run(t, hs)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -17,14 +18,22 @@ const bingRequestTimeout = time.Duration(30) * time.Second
 
 // bingPlanner holds configuration information to make TransitDistance calls via Microsoft's BING maps API
 type bingPlanner struct {
-	logger          *zap.Logger
-	httpClient      http.Client
-	endPointWithKey string
+	logger                 *zap.Logger
+	httpClient             http.Client
+	truckEndPointWithKey   string
+	drivingEndPointWithKey string
+	profile                VehicleProfile
+}
+
+// RouteLeg is the part of a Bing route between two consecutive waypoints
+type RouteLeg struct {
+	TravelDistance float64 `json:"travelDistance"`
 }
 
 // Resource is the innermost object in the Bing response
 type Resource struct {
-	TravelDistance float64 `json:"travelDistance"`
+	TravelDistance float64    `json:"travelDistance"`
+	RouteLegs      []RouteLeg `json:"routeLegs"`
 }
 
 // ResourceSet is an object in the BING response
@@ -39,17 +48,34 @@ type BingResponse struct {
 
 // Uses the Microsoft Bing Maps API to calculate the trucking distance between two endpoints
 func (p *bingPlanner) wayPointsTransitDistance(wp1 string, wp2 string) (int, error) {
-	query := fmt.Sprintf("%s&wp.1=%s&wp.2=%s", p.endPointWithKey, wp1, wp2)
+	resource, err := p.route([]string{wp1, wp2})
+	if err != nil {
+		return 0, err
+	}
+	return int(math.Round(resource.TravelDistance)), nil
+}
+
+// route requests a route through the waypoints, wp.1 to wp.N, and returns the route resource
+func (p *bingPlanner) route(wayPoints []string) (Resource, error) {
+	query := p.drivingEndPointWithKey
+	if p.profile.Type != VehicleCar {
+		// Hazardous materials are left out, which Bing takes to mean none are carried
+		query = fmt.Sprintf("%s&vehicleHeight=%.2f&vehicleWeight=%d&dimensionUnit=m&weightUnit=kg",
+			p.truckEndPointWithKey, p.profile.HeightMeters, p.profile.WeightKilograms)
+	}
+	for i, wayPoint := range wayPoints {
+		query += fmt.Sprintf("&wp.%d=%s", i+1, wayPoint)
+	}
 
 	resp, err := p.httpClient.Get(query)
 	if err != nil {
 		p.logger.Error("Getting response from Bing.", zap.Error(err))
-		return 0, errors.Wrap(err, "calling Bing")
+		return Resource{}, errors.Wrap(err, "calling Bing")
 	}
 
 	if resp.StatusCode != 200 {
 		p.logger.Info("Got non-200 response from Bing.", zap.Int("http_status", resp.StatusCode))
-		return 0, errors.New("error response from bing")
+		return Resource{}, errors.New("error response from bing")
 	}
 
 	routeDecoder := json.NewDecoder(resp.Body)
@@ -57,19 +83,19 @@ func (p *bingPlanner) wayPointsTransitDistance(wp1 string, wp2 string) (int, err
 	err = routeDecoder.Decode(&response)
 	if err != nil {
 		p.logger.Error("Failed to decode response from Bing.", zap.Error(err))
-		return 0, errors.Wrap(err, "decoding response from Bing")
+		return Resource{}, errors.Wrap(err, "decoding response from Bing")
 	}
 
 	if len(response.ResourceSets) == 0 {
 		p.logger.Error("Expected at least one ResourceSet in response", zap.Any("response", response))
-		return 0, errors.New("malformed response from Bing")
+		return Resource{}, errors.New("malformed response from Bing")
 	}
 	resourceSet := response.ResourceSets[0]
 	if len(resourceSet.Resources) == 0 {
 		p.logger.Error("Expected at least one Resource in response", zap.Any("response", response))
-		return 0, errors.New("malformed response from Bing")
+		return Resource{}, errors.New("malformed response from Bing")
 	}
-	return int(math.Round(resourceSet.Resources[0].TravelDistance)), nil
+	return resourceSet.Resources[0], nil
 }
 
 func (p *bingPlanner) LatLongTransitDistance(source LatLong, dest LatLong) (int, error) {
@@ -84,13 +110,56 @@ func (p *bingPlanner) TransitDistance(source *models.Address, destination *model
 	return p.wayPointsTransitDistance(urlencodeAddress(source), urlencodeAddress(destination))
 }
 
-// NewBingPlanner constructs and returns a Planner which uses the Bing Map API to plan routes.
-// endpoint should be the full URL to the Truck route REST endpoint,
+func (p *bingPlanner) WaypointsTransitDistance(waypoints []*models.Address) (RouteDistance, error) {
+	if len(waypoints) < 2 {
+		return RouteDistance{}, ErrTooFewWaypoints
+	}
+	var wayPoints []string
+	for _, waypoint := range waypoints {
+		wayPoints = append(wayPoints, urlencodeAddress(waypoint))
+	}
+
+	resource, err := p.route(wayPoints)
+	if err != nil {
+		return RouteDistance{}, err
+	}
+	if len(resource.RouteLegs) != len(waypoints)-1 {
+		p.logger.Error("Expected a route leg between each pair of waypoints",
+			zap.Int("waypoints", len(waypoints)),
+			zap.Int("route_legs", len(resource.RouteLegs)))
+		return RouteDistance{}, errors.New("malformed response from Bing")
+	}
+
+	legs := make([]int, len(resource.RouteLegs))
+	for i, leg := range resource.RouteLegs {
+		legs[i] = int(math.Round(leg.TravelDistance))
+	}
+	return newRouteDistance(legs), nil
+}
+
+func (p *bingPlanner) Profile() VehicleProfile {
+	return p.profile
+}
+
+func (p *bingPlanner) ForVehicle(profile VehicleProfile) Planner {
+	planner := *p
+	planner.profile = profile
+	return &planner
+}
+
+// NewBingPlanner constructs and returns a Planner which uses the Bing Map API to plan routes for the
+// vehicle described by profile. endpoint should be the full URL to the Truck route REST endpoint,
 // e.g. https://dev.virtualearth.net/REST/v1/Routes/Truck and apiKey should be the Bing Maps API key associated with
-// the application/account used to access the API
-func NewBingPlanner(logger *zap.Logger, endpoint *string, apiKey *string) Planner {
+// the application/account used to access the API. Cars are routed with the Driving endpoint beside it.
+func NewBingPlanner(logger *zap.Logger, endpoint *string, apiKey *string, profile VehicleProfile) Planner {
+	drivingEndpoint := *endpoint
+	if strings.HasSuffix(drivingEndpoint, "/Truck") {
+		drivingEndpoint = strings.TrimSuffix(drivingEndpoint, "/Truck") + "/Driving"
+	}
 	return &bingPlanner{
-		logger:          logger,
-		httpClient:      http.Client{Timeout: bingRequestTimeout},
-		endPointWithKey: fmt.Sprintf("%s?key=%s", *endpoint, *apiKey)}
+		logger:                 logger,
+		httpClient:             http.Client{Timeout: bingRequestTimeout},
+		truckEndPointWithKey:   fmt.Sprintf("%s?key=%s", *endpoint, *apiKey),
+		drivingEndPointWithKey: fmt.Sprintf("%s?key=%s", drivingEndpoint, *apiKey),
+		profile:                profile}
 }
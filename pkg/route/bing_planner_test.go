@@ -2,6 +2,12 @@ package route
 
 import (
 	"os"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/route/fakemaps"
 )
 
 type BingFullSuite struct {
@@ -16,5 +22,48 @@ func (suite *BingFullSuite) SetupTest() {
 	if len(testEndpoint) == 0 || len(testKey) == 0 {
 		suite.T().Fatal("You must set BING_MAPS_ENDPOINT and BING_MAPS_KEY to run this test")
 	}
-	suite.planner = NewBingPlanner(suite.logger, &testEndpoint, &testKey)
+	suite.planner = NewBingPlanner(suite.logger, &testEndpoint, &testKey, DefaultTruckProfile)
+}
+
+func TestBingPlannerWithFakeMaps(t *testing.T) {
+	maps := fakemaps.NewServer(readTestZip5Locations())
+	defer maps.Close()
+	endpoint := maps.BingEndpoint()
+	key := "key"
+	planner := NewBingPlanner(zap.NewNop(), &endpoint, &key, DefaultTruckProfile)
+
+	origin := models.Address{StreetAddress1: "1 Main St", City: "New Smyrna Beach", State: "FL", PostalCode: "32168"}
+	pickup := models.Address{StreetAddress1: "2 Main St", City: "Palatka", State: "FL", PostalCode: "32177"}
+	destination := models.Address{StreetAddress1: "3 Main St", City: "Charleston", State: "SC", PostalCode: "29429"}
+
+	distance, err := planner.TransitDistance(&origin, &destination)
+	if err != nil {
+		t.Fatal(err)
+	}
+	latLongDistance, err := planner.LatLongTransitDistance(LatLong{Latitude: 28.951931, Longitude: -81.033705}, LatLong{Latitude: 33.006254, Longitude: -79.656119})
+	if err != nil || latLongDistance != distance {
+		t.Errorf("expected the ZIP locations to be %d miles apart, got %d, %v", distance, latLongDistance, err)
+	}
+	route, err := planner.WaypointsTransitDistance([]*models.Address{&origin, &pickup, &destination})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(route.Legs) != 2 || route.Total <= distance {
+		t.Errorf("expected two legs longer than the direct distance %d, got %v", distance, route)
+	}
+
+	unknown := models.Address{StreetAddress1: "1 Main St", City: "Nowhere", State: "FL", PostalCode: "00000"}
+	if _, err := planner.TransitDistance(&origin, &unknown); err == nil {
+		t.Error("expected an error for an address Bing can't find")
+	}
+
+	for _, failure := range []fakemaps.Failure{fakemaps.ServerError, fakemaps.MalformedJSON, fakemaps.EmptyResult} {
+		maps.Handler().FailNext(fakemaps.Bing, failure)
+		if _, err := planner.TransitDistance(&origin, &destination); err == nil {
+			t.Errorf("expected an error when Bing fails with %s", failure)
+		}
+	}
+	if recovered, err := planner.TransitDistance(&origin, &destination); err != nil || recovered != distance {
+		t.Errorf("expected %d after the failures, got %d, %v", distance, recovered, err)
+	}
 }
@@ -0,0 +1,241 @@
+package route
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gobuffalo/pop"
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/models"
+)
+
+// DefaultDistanceCacheTTL is how long a cached distance is used before the provider is asked again.
+const DefaultDistanceCacheTTL = 90 * 24 * time.Hour
+
+// distanceCall is a lookup in progress, which concurrent requests for the same route wait on
+type distanceCall struct {
+	done     sync.WaitGroup
+	distance RouteDistance
+	err      error
+}
+
+// cachingPlanner wraps another Planner, saving its distances in the planner_distances table
+// and reusing them until they are older than the TTL.
+type cachingPlanner struct {
+	logger   *zap.Logger
+	db       *pop.Connection
+	planner  Planner
+	provider string
+	// profile is the key of the vehicle profile the underlying planner routes, so that truck and
+	// car distances are cached separately
+	profile string
+	ttl     time.Duration
+
+	mutex sync.Mutex
+	calls map[string]*distanceCall
+}
+
+// NewCachingPlanner constructs and returns a Planner which caches the distances returned by
+// planner. provider names the underlying planner, e.g. "here" or "bing", so that distances from
+// different providers are cached separately. Distances are also cached separately for each vehicle
+// profile the planner routes. When the underlying planner fails, an expired
+// distance is returned if there is one.
+func NewCachingPlanner(logger *zap.Logger, db *pop.Connection, planner Planner, provider string, ttl time.Duration) Planner {
+	return &cachingPlanner{
+		logger:   logger,
+		db:       db,
+		planner:  planner,
+		provider: provider,
+		profile:  profileKey(planner),
+		ttl:      ttl,
+		calls:    map[string]*distanceCall{},
+	}
+}
+
+func (p *cachingPlanner) Profile() VehicleProfile {
+	if vehiclePlanner, ok := p.planner.(VehiclePlanner); ok {
+		return vehiclePlanner.Profile()
+	}
+	return DefaultTruckProfile
+}
+
+// ForVehicle returns a caching planner for the underlying planner's planner for profile. Lookups
+// already in progress are only coalesced with lookups for the same vehicle.
+func (p *cachingPlanner) ForVehicle(profile VehicleProfile) Planner {
+	return NewCachingPlanner(p.logger, p.db, PlannerForVehicle(p.planner, profile), p.provider, p.ttl)
+}
+
+// addressCacheKey is the form of an address stored in the planner_distances table. Blank second
+// and third street lines are left out, so an address has the same key whether they are nil or
+// empty.
+func addressCacheKey(address *models.Address) string {
+	s := []string{address.StreetAddress1}
+	if address.StreetAddress2 != nil && strings.TrimSpace(*address.StreetAddress2) != "" {
+		s = append(s, *address.StreetAddress2)
+	}
+	if address.StreetAddress3 != nil && strings.TrimSpace(*address.StreetAddress3) != "" {
+		s = append(s, *address.StreetAddress3)
+	}
+	s = append(s, address.City, address.State, address.PostalCode)
+	for i := range s {
+		s[i] = strings.ToUpper(strings.TrimSpace(s[i]))
+	}
+	return "address:" + strings.Join(s, ",")
+}
+
+// WarmDistanceCache looks up the distance between each pair of duty station ZIP5s the way the rate
+// engine does, by addresses with only a ZIP5, so that a caching planner has them cached when moves
+// are priced. It returns the error of each pair which couldn't be looked up.
+func WarmDistanceCache(planner Planner, pairs []models.DutyStationPostalCodePair) map[models.DutyStationPostalCodePair]error {
+	failures := map[models.DutyStationPostalCodePair]error{}
+	for _, pair := range pairs {
+		source := models.Address{PostalCode: pair.OriginPostalCode}
+		destination := models.Address{PostalCode: pair.DestinationPostalCode}
+		if _, err := planner.TransitDistance(&source, &destination); err != nil {
+			failures[pair] = err
+		}
+	}
+	return failures
+}
+
+func (p *cachingPlanner) TransitDistance(source *models.Address, destination *models.Address) (int, error) {
+	return p.distance(addressCacheKey(source), addressCacheKey(destination), func() (int, error) {
+		return p.planner.TransitDistance(source, destination)
+	})
+}
+
+func (p *cachingPlanner) LatLongTransitDistance(source LatLong, destination LatLong) (int, error) {
+	return p.distance("latlong:"+source.Coords(), "latlong:"+destination.Coords(), func() (int, error) {
+		return p.planner.LatLongTransitDistance(source, destination)
+	})
+}
+
+func (p *cachingPlanner) Zip5TransitDistance(source string, destination string) (int, error) {
+	return p.distance("zip5:"+source, "zip5:"+destination, func() (int, error) {
+		return p.planner.Zip5TransitDistance(source, destination)
+	})
+}
+
+// distance looks up the distance between source and destination, coalescing concurrent lookups
+func (p *cachingPlanner) distance(source string, destination string, fetch func() (int, error)) (int, error) {
+	distance, err := p.coalesce(source+"|"+destination, func() (RouteDistance, error) {
+		distance, err := p.lookup(source, destination, fetch)
+		return newRouteDistance([]int{distance}), err
+	})
+	return distance.Total, err
+}
+
+// coalesce runs lookup, unless a lookup with the same key is already in progress, in which case
+// it waits for that lookup and returns its result
+func (p *cachingPlanner) coalesce(key string, lookup func() (RouteDistance, error)) (RouteDistance, error) {
+	p.mutex.Lock()
+	if call, ok := p.calls[key]; ok {
+		p.mutex.Unlock()
+		call.done.Wait()
+		return call.distance, call.err
+	}
+	call := &distanceCall{}
+	call.done.Add(1)
+	p.calls[key] = call
+	p.mutex.Unlock()
+
+	call.distance, call.err = lookup()
+	call.done.Done()
+
+	p.mutex.Lock()
+	delete(p.calls, key)
+	p.mutex.Unlock()
+
+	return call.distance, call.err
+}
+
+func (p *cachingPlanner) lookup(source string, destination string, fetch func() (int, error)) (int, error) {
+	cached, err := models.FetchPlannerDistance(p.db, source, destination, p.provider, p.profile)
+	found := err == nil
+	if err != nil && err != models.ErrFetchNotFound {
+		p.logger.Error("Failed to read cached distance", zap.Error(err))
+	}
+	if found && time.Since(cached.FetchedAt) < p.ttl {
+		return cached.Distance, nil
+	}
+
+	distance, err := fetch()
+	if err != nil {
+		if found {
+			p.logger.Warn("Route planner failed, using expired cached distance",
+				zap.String("source", source),
+				zap.String("destination", destination),
+				zap.String("provider", p.provider),
+				zap.Time("fetched_at", cached.FetchedAt),
+				zap.Error(err))
+			return cached.Distance, nil
+		}
+		return 0, err
+	}
+
+	err = models.SavePlannerDistance(p.db, source, destination, p.provider, p.profile, distance, time.Now())
+	if err != nil {
+		// The distance is still good even though it couldn't be cached
+		p.logger.Error("Failed to cache distance", zap.Error(err))
+	}
+	return distance, nil
+}
+
+// WaypointsTransitDistance caches each leg of the route separately, so legs are shared with
+// point to point lookups and other routes. The underlying planner is asked for the whole route
+// if any leg is missing or expired.
+func (p *cachingPlanner) WaypointsTransitDistance(waypoints []*models.Address) (RouteDistance, error) {
+	if len(waypoints) < 2 {
+		return RouteDistance{}, ErrTooFewWaypoints
+	}
+	keys := make([]string, len(waypoints))
+	for i, waypoint := range waypoints {
+		keys[i] = addressCacheKey(waypoint)
+	}
+
+	return p.coalesce(strings.Join(keys, "|"), func() (RouteDistance, error) {
+		legs := make([]int, len(waypoints)-1)
+		found := true
+		fresh := true
+		for i := range legs {
+			cached, err := models.FetchPlannerDistance(p.db, keys[i], keys[i+1], p.provider, p.profile)
+			if err != nil {
+				if err != models.ErrFetchNotFound {
+					p.logger.Error("Failed to read cached distance", zap.Error(err))
+				}
+				found = false
+				break
+			}
+			legs[i] = cached.Distance
+			if time.Since(cached.FetchedAt) >= p.ttl {
+				fresh = false
+			}
+		}
+		if found && fresh {
+			return newRouteDistance(legs), nil
+		}
+
+		distance, err := p.planner.WaypointsTransitDistance(waypoints)
+		if err != nil {
+			if found {
+				p.logger.Warn("Route planner failed, using expired cached route legs",
+					zap.String("provider", p.provider),
+					zap.Int("waypoints", len(waypoints)),
+					zap.Error(err))
+				return newRouteDistance(legs), nil
+			}
+			return RouteDistance{}, err
+		}
+
+		now := time.Now()
+		for i, leg := range distance.Legs {
+			err = models.SavePlannerDistance(p.db, keys[i], keys[i+1], p.provider, p.profile, leg, now)
+			if err != nil {
+				p.logger.Error("Failed to cache distance", zap.Error(err))
+			}
+		}
+		return distance, nil
+	})
+}
@@ -0,0 +1,218 @@
+package route
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gobuffalo/pop"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/models"
+)
+
+// countingPlanner counts the lookups that reach it, optionally holding them until release
+// is closed and failing them when fail is set
+type countingPlanner struct {
+	mutex    sync.Mutex
+	calls    int
+	distance int
+	fail     bool
+	release  chan struct{}
+}
+
+func (cp *countingPlanner) lookup() (int, error) {
+	if cp.release != nil {
+		<-cp.release
+	}
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	cp.calls++
+	if cp.fail {
+		return 0, errors.New("planner is down")
+	}
+	return cp.distance, nil
+}
+
+func (cp *countingPlanner) TransitDistance(source *models.Address, destination *models.Address) (int, error) {
+	return cp.lookup()
+}
+
+func (cp *countingPlanner) LatLongTransitDistance(source LatLong, destination LatLong) (int, error) {
+	return cp.lookup()
+}
+
+func (cp *countingPlanner) Zip5TransitDistance(source string, destination string) (int, error) {
+	return cp.lookup()
+}
+
+func (cp *countingPlanner) WaypointsTransitDistance(waypoints []*models.Address) (RouteDistance, error) {
+	legs := make([]int, len(waypoints)-1)
+	for i := range legs {
+		distance, err := cp.lookup()
+		if err != nil {
+			return RouteDistance{}, err
+		}
+		legs[i] = distance
+	}
+	return newRouteDistance(legs), nil
+}
+
+func (suite *CachingPlannerSuite) TestCachesDistances() {
+	underlying := &countingPlanner{distance: 362}
+	planner := NewCachingPlanner(suite.logger, suite.db, underlying, "here", time.Hour)
+
+	for i := 0; i < 3; i++ {
+		distance, err := planner.Zip5TransitDistance("32168", "29429")
+		suite.Nil(err)
+		suite.Equal(362, distance)
+	}
+	suite.Equal(1, underlying.calls)
+
+	source := models.Address{PostalCode: "32168"}
+	destination := models.Address{PostalCode: "29429"}
+	for i := 0; i < 2; i++ {
+		distance, err := planner.TransitDistance(&source, &destination)
+		suite.Nil(err)
+		suite.Equal(362, distance)
+	}
+	suite.Equal(2, underlying.calls)
+
+	// A different provider has its own entries
+	other := NewCachingPlanner(suite.logger, suite.db, underlying, "bing", time.Hour)
+	_, err := other.Zip5TransitDistance("32168", "29429")
+	suite.Nil(err)
+	suite.Equal(3, underlying.calls)
+}
+
+func (suite *CachingPlannerSuite) TestRefreshesExpiredDistances() {
+	suite.Nil(models.SavePlannerDistance(suite.db, "zip5:32168", "zip5:29429", "here", "", 300, time.Now().Add(-2*time.Hour)))
+
+	underlying := &countingPlanner{distance: 362}
+	planner := NewCachingPlanner(suite.logger, suite.db, underlying, "here", time.Hour)
+
+	distance, err := planner.Zip5TransitDistance("32168", "29429")
+	suite.Nil(err)
+	suite.Equal(362, distance)
+	suite.Equal(1, underlying.calls)
+
+	cached, err := models.FetchPlannerDistance(suite.db, "zip5:32168", "zip5:29429", "here", "")
+	suite.Nil(err)
+	suite.Equal(362, cached.Distance)
+}
+
+func (suite *CachingPlannerSuite) TestUsesExpiredDistanceWhenProviderFails() {
+	suite.Nil(models.SavePlannerDistance(suite.db, "zip5:32168", "zip5:29429", "here", "", 300, time.Now().Add(-2*time.Hour)))
+
+	underlying := &countingPlanner{fail: true}
+	planner := NewCachingPlanner(suite.logger, suite.db, underlying, "here", time.Hour)
+
+	distance, err := planner.Zip5TransitDistance("32168", "29429")
+	suite.Nil(err)
+	suite.Equal(300, distance)
+
+	_, err = planner.Zip5TransitDistance("94103", "20301")
+	suite.NotNil(err)
+}
+
+func (suite *CachingPlannerSuite) TestCoalescesConcurrentLookups() {
+	underlying := &countingPlanner{distance: 362, release: make(chan struct{})}
+	planner := NewCachingPlanner(suite.logger, suite.db, underlying, "here", time.Hour)
+
+	var wg sync.WaitGroup
+	distances := make([]int, 5)
+	for i := range distances {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			distances[i], _ = planner.Zip5TransitDistance("32168", "29429")
+		}(i)
+	}
+
+	// Give the lookups time to queue up behind the first before letting it finish
+	time.Sleep(100 * time.Millisecond)
+	close(underlying.release)
+	wg.Wait()
+
+	suite.Equal(1, underlying.calls)
+	for _, distance := range distances {
+		suite.Equal(362, distance)
+	}
+}
+
+func (suite *CachingPlannerSuite) TestCachesRouteLegs() {
+	underlying := &countingPlanner{distance: 100}
+	planner := NewCachingPlanner(suite.logger, suite.db, underlying, "here", time.Hour)
+
+	origin := models.Address{PostalCode: "32168"}
+	pickup := models.Address{PostalCode: "32174"}
+	destination := models.Address{PostalCode: "29429"}
+
+	route, err := planner.WaypointsTransitDistance([]*models.Address{&origin, &pickup, &destination})
+	suite.Nil(err)
+	suite.Equal(RouteDistance{Total: 200, Legs: []int{100, 100}}, route)
+	suite.Equal(2, underlying.calls)
+
+	// Each leg is cached, and is shared with point to point lookups
+	_, err = planner.WaypointsTransitDistance([]*models.Address{&origin, &pickup, &destination})
+	suite.Nil(err)
+	distance, err := planner.TransitDistance(&pickup, &destination)
+	suite.Nil(err)
+	suite.Equal(100, distance)
+	suite.Equal(2, underlying.calls)
+
+	_, err = planner.WaypointsTransitDistance([]*models.Address{&origin})
+	suite.Equal(ErrTooFewWaypoints, err)
+}
+
+func (suite *CachingPlannerSuite) TestCachesEachVehicleSeparately() {
+	here := newHEREStub(362)
+	defer here.Close()
+	appID := "app-id"
+	appCode := "app-code"
+	truck := NewCachingPlanner(suite.logger, suite.db,
+		NewHEREPlanner(suite.logger, &here.URL, &here.URL, &appID, &appCode, DefaultTruckProfile), "here", time.Hour)
+	car := PlannerForVehicle(truck, CarProfile)
+
+	source := LatLong{Latitude: 29.6, Longitude: -81.6}
+	destination := LatLong{Latitude: 32.8, Longitude: -79.9}
+	_, err := truck.LatLongTransitDistance(source, destination)
+	suite.Nil(err)
+	_, err = car.LatLongTransitDistance(source, destination)
+	suite.Nil(err)
+	_, err = car.LatLongTransitDistance(source, destination)
+	suite.Nil(err)
+	suite.Equal(2, here.requestCount())
+
+	_, err = models.FetchPlannerDistance(suite.db, "latlong:"+source.Coords(), "latlong:"+destination.Coords(), "here", DefaultTruckProfile.Key())
+	suite.Nil(err)
+	_, err = models.FetchPlannerDistance(suite.db, "latlong:"+source.Coords(), "latlong:"+destination.Coords(), "here", "car")
+	suite.Nil(err)
+}
+
+type CachingPlannerSuite struct {
+	suite.Suite
+	db     *pop.Connection
+	logger *zap.Logger
+}
+
+func (suite *CachingPlannerSuite) SetupTest() {
+	suite.db.TruncateAll()
+}
+
+func TestCachingPlannerSuite(t *testing.T) {
+	configLocation := "../../config"
+	pop.AddLookupPaths(configLocation)
+	db, err := pop.Connect("test")
+	if err != nil {
+		log.Panic(err)
+	}
+
+	logger, _ := zap.NewDevelopment()
+
+	hs := &CachingPlannerSuite{db: db, logger: logger}
+	suite.Run(t, hs)
+}
@@ -0,0 +1,417 @@
+// Package fakemaps is a stand-in for the HERE and Bing maps APIs used by the route planners. It
+// answers geocode and route requests in the shapes the planners parse, locating addresses by their
+// ZIP and measuring routes along the great circle between ZIP locations, so that planners can be
+// tested end to end without the real services. Failures can be scripted for each API to exercise
+// the planners' error handling.
+package fakemaps
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/transcom/mymove/pkg/models"
+)
+
+// Paths of the endpoints served. The Bing paths match the real API below the server's URL, so
+// that the planner can find the Driving endpoint next to the Truck one.
+const (
+	HEREGeocodePath  = "/here/geocode"
+	HERERoutePath    = "/here/route"
+	BingTruckPath    = "/REST/v1/Routes/Truck"
+	BingDrivingPath  = "/REST/v1/Routes/Driving"
+	earthRadiusMiles = 3958.8
+	metersInAMile    = 1609.34
+)
+
+// DefaultRoadFactor is how much longer than the great circle distance a route is
+const DefaultRoadFactor = 1.2
+
+// API is one of the APIs the server stands in for
+type API string
+
+const (
+	// HEREGeocode is the HERE geocoder
+	HEREGeocode API = "here_geocode"
+	// HERERoute is the HERE routing API
+	HERERoute API = "here_route"
+	// Bing is the Bing routes API, for both trucks and cars
+	Bing API = "bing"
+)
+
+// Failure is a way a request can be made to fail
+type Failure string
+
+const (
+	// ServerError answers with a 503
+	ServerError Failure = "server_error"
+	// MalformedJSON answers with a 200 whose body isn't JSON
+	MalformedJSON Failure = "malformed_json"
+	// EmptyResult answers with well formed JSON holding no results: an empty View from the HERE
+	// geocoder, no route from HERE routing and empty ResourceSets from Bing
+	EmptyResult Failure = "empty_result"
+)
+
+// LatLong is a point on the earth
+type LatLong struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Server answers HERE and Bing requests. Use NewServer for a server listening on a local port,
+// or NewHandler to serve it some other way.
+type Server struct {
+	*httptest.Server
+	handler *Handler
+}
+
+// NewServer starts and returns a Server which locates ZIPs using locations
+func NewServer(locations models.Zip5Locations) *Server {
+	handler := NewHandler(locations)
+	return &Server{Server: httptest.NewServer(handler), handler: handler}
+}
+
+// Handler returns the handler behind the server, through which failures are scripted
+func (s *Server) Handler() *Handler {
+	return s.handler
+}
+
+// HEREGeocodeEndpoint is the endpoint to give the HERE planner for geocoding
+func (s *Server) HEREGeocodeEndpoint() string {
+	return s.URL + HEREGeocodePath
+}
+
+// HERERouteEndpoint is the endpoint to give the HERE planner for routing
+func (s *Server) HERERouteEndpoint() string {
+	return s.URL + HERERoutePath
+}
+
+// BingEndpoint is the Truck endpoint to give the Bing planner
+func (s *Server) BingEndpoint() string {
+	return s.URL + BingTruckPath
+}
+
+// Handler is an http.Handler answering HERE and Bing requests
+type Handler struct {
+	mux        *http.ServeMux
+	locations  map[string]LatLong
+	roadFactor float64
+
+	mutex     sync.Mutex
+	scripted  map[API][]Failure
+	failures  map[API]Failure
+	requests  map[API]int
+	lastQuery map[API]url.Values
+}
+
+// NewHandler returns a Handler which locates ZIPs using locations
+func NewHandler(locations models.Zip5Locations) *Handler {
+	h := &Handler{
+		mux:        http.NewServeMux(),
+		locations:  map[string]LatLong{},
+		roadFactor: DefaultRoadFactor,
+		scripted:   map[API][]Failure{},
+		failures:   map[API]Failure{},
+		requests:   map[API]int{},
+		lastQuery:  map[API]url.Values{},
+	}
+	for _, location := range locations {
+		h.locations[location.Zip5] = LatLong{Latitude: float64(location.Latitude), Longitude: float64(location.Longitude)}
+	}
+	h.mux.HandleFunc(HEREGeocodePath, h.hereGeocode)
+	h.mux.HandleFunc(HERERoutePath, h.hereRoute)
+	h.mux.HandleFunc(BingTruckPath, h.bingRoute)
+	h.mux.HandleFunc(BingDrivingPath, h.bingRoute)
+	return h
+}
+
+// SetRoadFactor sets how much longer than the great circle distance routes are
+func (h *Handler) SetRoadFactor(roadFactor float64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.roadFactor = roadFactor
+}
+
+// FailNext makes the next requests to api fail in the ways given, one failure per request
+func (h *Handler) FailNext(api API, failures ...Failure) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.scripted[api] = append(h.scripted[api], failures...)
+}
+
+// SetFailure makes every request to api fail with failure until ClearFailures is called
+func (h *Handler) SetFailure(api API, failure Failure) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.failures[api] = failure
+}
+
+// ClearFailures removes every scripted and ongoing failure
+func (h *Handler) ClearFailures() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.scripted = map[API][]Failure{}
+	h.failures = map[API]Failure{}
+}
+
+// Requests returns the number of requests made to api
+func (h *Handler) Requests(api API) int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.requests[api]
+}
+
+// LastQuery returns the query parameters of the most recent request to api
+func (h *Handler) LastQuery(api API) url.Values {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.lastQuery[api]
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// start records a request to api and returns the failure it should answer with, if any
+func (h *Handler) start(api API, query url.Values) Failure {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.requests[api]++
+	h.lastQuery[api] = query
+	if scripted := h.scripted[api]; len(scripted) > 0 {
+		h.scripted[api] = scripted[1:]
+		return scripted[0]
+	}
+	return h.failures[api]
+}
+
+// parseQuery parses a raw query like url.ParseQuery, but keeps the semicolons HERE uses to
+// separate the parts of its mode parameter
+func parseQuery(rawQuery string) url.Values {
+	query := url.Values{}
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		key, err := url.QueryUnescape(parts[0])
+		if err != nil {
+			continue
+		}
+		value := ""
+		if len(parts) == 2 {
+			if value, err = url.QueryUnescape(parts[1]); err != nil {
+				continue
+			}
+		}
+		query.Add(key, value)
+	}
+	return query
+}
+
+// fail answers with failure, using empty as the body of an EmptyResult. It reports whether there
+// was a failure to answer with.
+func fail(w http.ResponseWriter, failure Failure, empty string) bool {
+	switch failure {
+	case ServerError:
+		http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+	case MalformedJSON:
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"response": {"route": [`)
+	case EmptyResult:
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, empty)
+	default:
+		return false
+	}
+	return true
+}
+
+// writeJSON answers with body encoded as JSON
+func writeJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+// locateAddress finds an address written the way the planners write them, comma separated and
+// ending with the postal code, by the location of its ZIP5. Coordinates, e.g. "29.6,-81.6", are
+// returned as they are.
+func (h *Handler) locateAddress(address string) (LatLong, bool) {
+	fields := strings.Split(address, ",")
+	if len(fields) == 2 {
+		latitude, latErr := strconv.ParseFloat(strings.TrimSpace(fields[0]), 64)
+		longitude, lonErr := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+		if latErr == nil && lonErr == nil {
+			return LatLong{Latitude: latitude, Longitude: longitude}, true
+		}
+	}
+	postalCode := strings.TrimSpace(fields[len(fields)-1])
+	if len(postalCode) < 5 {
+		return LatLong{}, false
+	}
+	location, ok := h.locations[postalCode[:5]]
+	return location, ok
+}
+
+// miles returns the route distance between two points, the great circle distance scaled by the
+// road factor
+func (h *Handler) miles(from LatLong, to LatLong) float64 {
+	h.mutex.Lock()
+	roadFactor := h.roadFactor
+	h.mutex.Unlock()
+
+	toRadians := math.Pi / 180
+	lat1 := from.Latitude * toRadians
+	lat2 := to.Latitude * toRadians
+	dLat := lat2 - lat1
+	dLon := (to.Longitude - from.Longitude) * toRadians
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMiles * math.Asin(math.Sqrt(a)) * roadFactor
+}
+
+type herePosition struct {
+	Latitude  float64 `json:"Latitude"`
+	Longitude float64 `json:"Longitude"`
+}
+
+type hereLocation struct {
+	NavigationPosition []herePosition `json:"NavigationPosition"`
+}
+
+type hereResult struct {
+	Location hereLocation `json:"Location"`
+}
+
+type hereView struct {
+	Result []hereResult `json:"Result"`
+}
+
+type hereGeocodeResponse struct {
+	Response struct {
+		View []hereView `json:"View"`
+	} `json:"Response"`
+}
+
+// hereGeocode answers a HERE geocoder request for the address in searchtext. Like HERE, it
+// answers an address it can't find with an empty View.
+func (h *Handler) hereGeocode(w http.ResponseWriter, r *http.Request) {
+	query := parseQuery(r.URL.RawQuery)
+	if fail(w, h.start(HEREGeocode, query), `{"Response":{"View":[]}}`) {
+		return
+	}
+
+	response := hereGeocodeResponse{}
+	if location, ok := h.locateAddress(query.Get("searchtext")); ok {
+		position := herePosition{Latitude: location.Latitude, Longitude: location.Longitude}
+		response.Response.View = []hereView{{Result: []hereResult{{
+			Location: hereLocation{NavigationPosition: []herePosition{position}},
+		}}}}
+	} else {
+		response.Response.View = []hereView{}
+	}
+	writeJSON(w, response)
+}
+
+type hereLeg struct {
+	Length int `json:"length"`
+}
+
+type hereRoute struct {
+	Summary struct {
+		Distance int `json:"distance"`
+	} `json:"summary"`
+	Leg []hereLeg `json:"leg"`
+}
+
+type hereRouteResponse struct {
+	Response struct {
+		Route []hereRoute `json:"route"`
+	} `json:"response"`
+}
+
+// hereRoute answers a HERE routing request through waypoint0 to waypointN, each given as geo!lat,lon
+func (h *Handler) hereRoute(w http.ResponseWriter, r *http.Request) {
+	query := parseQuery(r.URL.RawQuery)
+	if fail(w, h.start(HERERoute, query), `{"response":{"route":[]}}`) {
+		return
+	}
+
+	var points []LatLong
+	for i := 0; query.Get(fmt.Sprintf("waypoint%d", i)) != ""; i++ {
+		point, ok := h.locateAddress(strings.TrimPrefix(query.Get(fmt.Sprintf("waypoint%d", i)), "geo!"))
+		if !ok {
+			http.Error(w, fmt.Sprintf(`{"type":"ApplicationError","subtype":"InvalidInputData","details":"invalid waypoint%d"}`, i), http.StatusBadRequest)
+			return
+		}
+		points = append(points, point)
+	}
+	if len(points) < 2 {
+		http.Error(w, `{"type":"ApplicationError","subtype":"InvalidInputData","details":"at least two waypoints are required"}`, http.StatusBadRequest)
+		return
+	}
+
+	route := hereRoute{}
+	for i := 1; i < len(points); i++ {
+		meters := int(math.Round(h.miles(points[i-1], points[i]) * metersInAMile))
+		route.Leg = append(route.Leg, hereLeg{Length: meters})
+		route.Summary.Distance += meters
+	}
+	response := hereRouteResponse{}
+	response.Response.Route = []hereRoute{route}
+	writeJSON(w, response)
+}
+
+type bingRouteLeg struct {
+	TravelDistance float64 `json:"travelDistance"`
+}
+
+type bingResource struct {
+	TravelDistance float64        `json:"travelDistance"`
+	RouteLegs      []bingRouteLeg `json:"routeLegs"`
+}
+
+type bingResourceSet struct {
+	Resources []bingResource `json:"resources"`
+}
+
+type bingResponse struct {
+	ResourceSets []bingResourceSet `json:"resourceSets"`
+}
+
+// bingRoute answers a Bing Truck or Driving request through wp.1 to wp.N, each given as an address
+// or as lat,lon
+func (h *Handler) bingRoute(w http.ResponseWriter, r *http.Request) {
+	query := parseQuery(r.URL.RawQuery)
+	if fail(w, h.start(Bing, query), `{"resourceSets":[]}`) {
+		return
+	}
+
+	var points []LatLong
+	for i := 1; query.Get(fmt.Sprintf("wp.%d", i)) != ""; i++ {
+		point, ok := h.locateAddress(query.Get(fmt.Sprintf("wp.%d", i)))
+		if !ok {
+			http.Error(w, fmt.Sprintf(`{"statusCode":404,"errorDetails":["wp.%d could not be located"]}`, i), http.StatusNotFound)
+			return
+		}
+		points = append(points, point)
+	}
+	if len(points) < 2 {
+		http.Error(w, `{"statusCode":400,"errorDetails":["at least two waypoints are required"]}`, http.StatusBadRequest)
+		return
+	}
+
+	resource := bingResource{}
+	for i := 1; i < len(points); i++ {
+		miles := h.miles(points[i-1], points[i])
+		resource.RouteLegs = append(resource.RouteLegs, bingRouteLeg{TravelDistance: miles})
+		resource.TravelDistance += miles
+	}
+	response := bingResponse{ResourceSets: []bingResourceSet{{Resources: []bingResource{resource}}}}
+	writeJSON(w, response)
+}
@@ -0,0 +1,115 @@
+package fakemaps
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/transcom/mymove/pkg/models"
+)
+
+var testLocations = models.Zip5Locations{
+	{Zip5: "32168", Latitude: 28.951931, Longitude: -81.033705},
+	{Zip5: "29429", Latitude: 33.006254, Longitude: -79.656119},
+}
+
+func TestParseQueryKeepsSemicolons(t *testing.T) {
+	query := parseQuery("waypoint0=geo!1.5,2.5&mode=fastest;truck;traffic:disabled&searchtext=1+Main+St%2CPalatka")
+	if query.Get("mode") != "fastest;truck;traffic:disabled" {
+		t.Errorf("wrong mode: %q", query.Get("mode"))
+	}
+	if query.Get("waypoint0") != "geo!1.5,2.5" {
+		t.Errorf("wrong waypoint: %q", query.Get("waypoint0"))
+	}
+	if query.Get("searchtext") != "1 Main St,Palatka" {
+		t.Errorf("wrong search text: %q", query.Get("searchtext"))
+	}
+}
+
+func TestLocateAddress(t *testing.T) {
+	h := NewHandler(testLocations)
+	if _, ok := h.locateAddress("1 Main St,New Smyrna Beach,FL,32168-1234"); !ok {
+		t.Error("expected a ZIP+4 to be located by its ZIP5")
+	}
+	if location, ok := h.locateAddress("29.5,-81.25"); !ok || location != (LatLong{Latitude: 29.5, Longitude: -81.25}) {
+		t.Errorf("expected coordinates to be returned as they are, got %v", location)
+	}
+	if _, ok := h.locateAddress("1 Main St,Nowhere,FL,00000"); ok {
+		t.Error("expected an unknown ZIP not to be located")
+	}
+}
+
+func TestBingRoute(t *testing.T) {
+	s := NewServer(testLocations)
+	defer s.Close()
+
+	resp, err := http.Get(s.BingEndpoint() + "?key=key&wp.1=a%2CFL%2C32168&wp.2=b%2CSC%2C29429&wp.3=a%2CFL%2C32168")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var response bingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	resource := response.ResourceSets[0].Resources[0]
+	if len(resource.RouteLegs) != 2 || resource.RouteLegs[0].TravelDistance != resource.RouteLegs[1].TravelDistance {
+		t.Fatalf("expected two equal legs, got %v", resource.RouteLegs)
+	}
+	// About 290 miles along the great circle
+	if leg := resource.RouteLegs[0].TravelDistance; math.Abs(leg-290*DefaultRoadFactor) > 10 {
+		t.Errorf("unexpected leg distance %f", leg)
+	}
+	if s.Handler().Requests(Bing) != 1 || s.Handler().LastQuery(Bing).Get("key") != "key" {
+		t.Error("expected the request to be recorded")
+	}
+}
+
+func TestScriptedFailures(t *testing.T) {
+	s := NewServer(testLocations)
+	defer s.Close()
+	s.Handler().FailNext(HERERoute, ServerError, EmptyResult)
+	s.Handler().SetFailure(Bing, ServerError)
+
+	url := s.HERERouteEndpoint() + "?waypoint0=geo!28.95,-81.03&waypoint1=geo!33.0,-79.65"
+	statuses := []int{http.StatusServiceUnavailable, http.StatusOK, http.StatusOK}
+	for i, expected := range statuses {
+		resp, err := http.Get(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var response hereRouteResponse
+		decodeErr := json.NewDecoder(resp.Body).Decode(&response)
+		resp.Body.Close()
+		if resp.StatusCode != expected {
+			t.Errorf("request %d: expected %d, got %d", i, expected, resp.StatusCode)
+		}
+		if i == 1 && (decodeErr != nil || len(response.Response.Route) != 0) {
+			t.Errorf("expected an empty result, got %v, %v", response, decodeErr)
+		}
+		if i == 2 && (decodeErr != nil || len(response.Response.Route) != 1) {
+			t.Errorf("expected a route once the scripted failures were used up, got %v, %v", response, decodeErr)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, err := http.Get(s.BingEndpoint() + "?wp.1=28.95,-81.03&wp.2=33.0,-79.65")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("expected Bing to keep failing, got %d", resp.StatusCode)
+		}
+	}
+	s.Handler().ClearFailures()
+	resp, err := http.Get(s.BingEndpoint() + "?wp.1=28.95,-81.03&wp.2=33.0,-79.65")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected Bing to recover, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,344 @@
+package route
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/models"
+)
+
+// DefaultCircuitFailureThreshold is how many consecutive failures open a provider's circuit.
+const DefaultCircuitFailureThreshold = 3
+
+// DefaultCircuitCooldown is how long an open circuit skips its provider before trying it again.
+const DefaultCircuitCooldown = time.Minute
+
+// DefaultDisagreementTolerance is the fraction by which a cross-checked distance may differ from
+// the answered distance before the result is flagged.
+const DefaultDisagreementTolerance = 0.1
+
+// ErrNoPlannerAvailable is returned when every provider in a FallbackPlanner failed or is skipped
+// because its circuit is open.
+var ErrNoPlannerAvailable = errors.New("no route planner available")
+
+// CircuitState describes whether a provider is being used
+type CircuitState string
+
+const (
+	// CircuitClosed means the provider is healthy and is used in turn
+	CircuitClosed CircuitState = "CLOSED"
+	// CircuitOpen means the provider failed repeatedly and is skipped until the cooldown ends
+	CircuitOpen CircuitState = "OPEN"
+	// CircuitHalfOpen means the cooldown has ended and a single trial lookup is allowed through
+	CircuitHalfOpen CircuitState = "HALF_OPEN"
+)
+
+// PlannerProvider is one of the planners in a FallbackPlanner chain
+type PlannerProvider struct {
+	// Name identifies the provider in results, logs and health reports, e.g. "here"
+	Name    string
+	Planner Planner
+	// CrossCheck providers are also asked for distances answered by an earlier provider, so that
+	// the answer can be compared. This is meant for cheap providers such as the offline planner.
+	CrossCheck bool
+}
+
+// ProviderHealth is a snapshot of the circuit breaker of one provider
+type ProviderHealth struct {
+	Name                string
+	State               CircuitState
+	ConsecutiveFailures int
+	Successes           int
+	Failures            int
+	LastError           string
+	OpenUntil           time.Time
+}
+
+// DistanceResult is a distance along with the provider which answered it
+type DistanceResult struct {
+	Distance int
+	// Legs holds the distance of each leg of a route through several waypoints
+	Legs     []int
+	Provider string
+	// Profile is the key of the vehicle profile the answering provider routed, or empty if it
+	// doesn't route by vehicle
+	Profile string
+	// Distances holds the distance from the answering provider and every cross-check provider
+	Distances map[string]int
+	// Disagreement is set when a cross-checked distance differs from Distance by more than the tolerance
+	Disagreement bool
+}
+
+// providerCircuit tracks the health of one provider
+type providerCircuit struct {
+	PlannerProvider
+	health  ProviderHealth
+	inTrial bool
+}
+
+// FallbackPlanner is a Planner which asks each of its providers in turn until one answers.
+// Providers which fail repeatedly are skipped until a cooldown has passed.
+type FallbackPlanner struct {
+	logger           *zap.Logger
+	failureThreshold int
+	cooldown         time.Duration
+	tolerance        float64
+	now              func() time.Time
+	// vehicle, when set, is the vehicle each provider is asked to route instead of its own
+	vehicle *VehicleProfile
+	// planners holds the planner used for each circuit's provider, routing vehicle if it is set
+	planners []Planner
+
+	// The circuits are shared by the planners for each vehicle
+	mutex    *sync.Mutex
+	circuits []*providerCircuit
+}
+
+// NewFallbackPlanner constructs and returns a FallbackPlanner trying the providers in the order given.
+// A provider's circuit opens after failureThreshold consecutive failures and stays open for cooldown.
+// Results are flagged when cross-checked distances differ by more than the tolerance fraction.
+func NewFallbackPlanner(logger *zap.Logger, providers []PlannerProvider, failureThreshold int, cooldown time.Duration, tolerance float64) *FallbackPlanner {
+	planner := &FallbackPlanner{
+		logger:           logger,
+		failureThreshold: failureThreshold,
+		cooldown:         cooldown,
+		tolerance:        tolerance,
+		now:              time.Now,
+		mutex:            &sync.Mutex{},
+	}
+	for _, provider := range providers {
+		planner.circuits = append(planner.circuits, &providerCircuit{
+			PlannerProvider: provider,
+			health:          ProviderHealth{Name: provider.Name, State: CircuitClosed},
+		})
+		planner.planners = append(planner.planners, provider.Planner)
+	}
+	return planner
+}
+
+// Health returns the state of each provider's circuit, in chain order
+func (p *FallbackPlanner) Health() []ProviderHealth {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var health []ProviderHealth
+	for _, circuit := range p.circuits {
+		health = append(health, circuit.health)
+	}
+	return health
+}
+
+// Profile returns the vehicle the first provider routes
+func (p *FallbackPlanner) Profile() VehicleProfile {
+	if p.vehicle != nil {
+		return *p.vehicle
+	}
+	for _, planner := range p.planners {
+		if vehiclePlanner, ok := planner.(VehiclePlanner); ok {
+			return vehiclePlanner.Profile()
+		}
+	}
+	return DefaultTruckProfile
+}
+
+// ForVehicle returns a FallbackPlanner which asks its providers to route the vehicle described by
+// profile. It shares the health of each provider with p.
+func (p *FallbackPlanner) ForVehicle(profile VehicleProfile) Planner {
+	planner := *p
+	planner.vehicle = &profile
+	planner.planners = make([]Planner, len(p.circuits))
+	for i, circuit := range p.circuits {
+		planner.planners[i] = PlannerForVehicle(circuit.Planner, profile)
+	}
+	return &planner
+}
+
+// allow reports whether circuit may be used now, moving an open circuit whose cooldown has
+// passed to half open
+func (p *FallbackPlanner) allow(circuit *providerCircuit) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	switch circuit.health.State {
+	case CircuitOpen:
+		if p.now().Before(circuit.health.OpenUntil) {
+			return false
+		}
+		circuit.health.State = CircuitHalfOpen
+		circuit.inTrial = true
+		p.logger.Info("Trying route planner after cooldown", zap.String("provider", circuit.Name))
+		return true
+	case CircuitHalfOpen:
+		// Only the trial lookup goes through until it has finished
+		if circuit.inTrial {
+			return false
+		}
+		circuit.inTrial = true
+		return true
+	}
+	return true
+}
+
+// record updates circuit with the outcome of a lookup
+func (p *FallbackPlanner) record(circuit *providerCircuit, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	circuit.inTrial = false
+	if err == nil {
+		circuit.health.Successes++
+		circuit.health.ConsecutiveFailures = 0
+		if circuit.health.State != CircuitClosed {
+			p.logger.Info("Route planner recovered", zap.String("provider", circuit.Name))
+		}
+		circuit.health.State = CircuitClosed
+		return
+	}
+
+	circuit.health.Failures++
+	circuit.health.ConsecutiveFailures++
+	circuit.health.LastError = err.Error()
+	if circuit.health.State == CircuitHalfOpen || circuit.health.ConsecutiveFailures >= p.failureThreshold {
+		circuit.health.State = CircuitOpen
+		circuit.health.OpenUntil = p.now().Add(p.cooldown)
+		p.logger.Warn("Route planner circuit opened",
+			zap.String("provider", circuit.Name),
+			zap.Int("consecutive_failures", circuit.health.ConsecutiveFailures),
+			zap.Time("open_until", circuit.health.OpenUntil),
+			zap.Error(err))
+	}
+}
+
+// distance asks each provider in turn until one answers, then asks the cross-check providers
+// after it and compares their answers
+func (p *FallbackPlanner) distance(lookup func(Planner) (RouteDistance, error)) (DistanceResult, error) {
+	result := DistanceResult{Distances: map[string]int{}}
+	answered := false
+	lastErr := ErrNoPlannerAvailable
+
+	for i, circuit := range p.circuits {
+		if answered && !circuit.CrossCheck {
+			continue
+		}
+		if !p.allow(circuit) {
+			continue
+		}
+
+		distance, err := lookup(p.planners[i])
+		p.record(circuit, err)
+		if err != nil {
+			p.logger.Info("Route planner failed", zap.String("provider", circuit.Name), zap.Error(err))
+			lastErr = err
+			continue
+		}
+
+		result.Distances[circuit.Name] = distance.Total
+		if !answered {
+			result.Distance = distance.Total
+			result.Legs = distance.Legs
+			result.Provider = circuit.Name
+			result.Profile = profileKey(p.planners[i])
+			answered = true
+		} else if p.disagree(result.Distance, distance.Total) {
+			result.Disagreement = true
+		}
+	}
+
+	if !answered {
+		return DistanceResult{}, lastErr
+	}
+	p.logger.Info("Route planner answered",
+		zap.String("provider", result.Provider),
+		zap.String("profile", result.Profile),
+		zap.Int("distance", result.Distance))
+	if result.Disagreement {
+		p.logger.Warn("Route planners disagree on distance",
+			zap.String("provider", result.Provider),
+			zap.Any("distances", result.Distances),
+			zap.Float64("tolerance", p.tolerance))
+	}
+	return result, nil
+}
+
+// disagree reports whether other differs from answered by more than the tolerance
+func (p *FallbackPlanner) disagree(answered int, other int) bool {
+	if answered == other {
+		return false
+	}
+	return math.Abs(float64(other-answered)) > p.tolerance*float64(answered)
+}
+
+// pointToPoint adapts a lookup between two points to a single leg route lookup
+func pointToPoint(lookup func(Planner) (int, error)) func(Planner) (RouteDistance, error) {
+	return func(planner Planner) (RouteDistance, error) {
+		distance, err := lookup(planner)
+		if err != nil {
+			return RouteDistance{}, err
+		}
+		return newRouteDistance([]int{distance}), nil
+	}
+}
+
+// TransitDistanceResult calculates the distance between two addresses, reporting which provider answered
+func (p *FallbackPlanner) TransitDistanceResult(source *models.Address, destination *models.Address) (DistanceResult, error) {
+	return p.distance(pointToPoint(func(planner Planner) (int, error) {
+		return planner.TransitDistance(source, destination)
+	}))
+}
+
+// LatLongTransitDistanceResult calculates the distance between two points, reporting which provider answered
+func (p *FallbackPlanner) LatLongTransitDistanceResult(source LatLong, destination LatLong) (DistanceResult, error) {
+	return p.distance(pointToPoint(func(planner Planner) (int, error) {
+		return planner.LatLongTransitDistance(source, destination)
+	}))
+}
+
+// Zip5TransitDistanceResult calculates the distance between two ZIP5s, reporting which provider answered
+func (p *FallbackPlanner) Zip5TransitDistanceResult(source string, destination string) (DistanceResult, error) {
+	return p.distance(pointToPoint(func(planner Planner) (int, error) {
+		return planner.Zip5TransitDistance(source, destination)
+	}))
+}
+
+// WaypointsTransitDistanceResult calculates the distance of a route through the waypoints, reporting
+// which provider answered
+func (p *FallbackPlanner) WaypointsTransitDistanceResult(waypoints []*models.Address) (DistanceResult, error) {
+	if len(waypoints) < 2 {
+		return DistanceResult{}, ErrTooFewWaypoints
+	}
+	return p.distance(func(planner Planner) (RouteDistance, error) {
+		return planner.WaypointsTransitDistance(waypoints)
+	})
+}
+
+// TransitDistance calculates the distance between two addresses using the first provider that answers
+func (p *FallbackPlanner) TransitDistance(source *models.Address, destination *models.Address) (int, error) {
+	result, err := p.TransitDistanceResult(source, destination)
+	return result.Distance, err
+}
+
+// LatLongTransitDistance calculates the distance between two points using the first provider that answers
+func (p *FallbackPlanner) LatLongTransitDistance(source LatLong, destination LatLong) (int, error) {
+	result, err := p.LatLongTransitDistanceResult(source, destination)
+	return result.Distance, err
+}
+
+// Zip5TransitDistance calculates the distance between two ZIP5s using the first provider that answers
+func (p *FallbackPlanner) Zip5TransitDistance(source string, destination string) (int, error) {
+	result, err := p.Zip5TransitDistanceResult(source, destination)
+	return result.Distance, err
+}
+
+// WaypointsTransitDistance calculates the distance of a route through the waypoints using the first
+// provider that answers
+func (p *FallbackPlanner) WaypointsTransitDistance(waypoints []*models.Address) (RouteDistance, error) {
+	result, err := p.WaypointsTransitDistanceResult(waypoints)
+	if err != nil {
+		return RouteDistance{}, err
+	}
+	return RouteDistance{Total: result.Distance, Legs: result.Legs}, nil
+}
@@ -0,0 +1,362 @@
+package route
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/models"
+)
+
+type FallbackPlannerSuite struct {
+	suite.Suite
+	logger *zap.Logger
+}
+
+// stubServer answers HERE or Bing requests with a fixed distance in miles for each leg of
+// the route, or with a 500 when failing is set
+type stubServer struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	requests int
+	rawQuery string
+	path     string
+	miles    int
+	failing  bool
+}
+
+func newStubServer(miles int, respond func(w http.ResponseWriter, r *http.Request, miles int)) *stubServer {
+	stub := &stubServer{miles: miles}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stub.mutex.Lock()
+		stub.requests++
+		stub.rawQuery = r.URL.RawQuery
+		stub.path = r.URL.Path
+		failing := stub.failing
+		stub.mutex.Unlock()
+		if failing {
+			http.Error(w, "service unavailable", http.StatusInternalServerError)
+			return
+		}
+		respond(w, r, stub.miles)
+	}))
+	return stub
+}
+
+func (s *stubServer) setFailing(failing bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.failing = failing
+}
+
+// lastQuery returns the query parameters of the most recent request. HERE separates the parts of
+// its mode with semicolons, which url.ParseQuery rejects, so modes are only in lastRawQuery.
+func (s *stubServer) lastQuery() url.Values {
+	query, _ := url.ParseQuery(s.lastRawQuery())
+	return query
+}
+
+// lastRawQuery returns the query string of the most recent request
+func (s *stubServer) lastRawQuery() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.rawQuery
+}
+
+// lastPath returns the path of the most recent request
+func (s *stubServer) lastPath() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.path
+}
+
+func (s *stubServer) requestCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.requests
+}
+
+// countLegs returns the number of legs in a route whose waypoints are numbered from first
+func countLegs(r *http.Request, prefix string, first int) int {
+	legs := -1
+	for i := first; r.URL.Query().Get(fmt.Sprintf("%s%d", prefix, i)) != ""; i++ {
+		legs++
+	}
+	return legs
+}
+
+func newHEREStub(miles int) *stubServer {
+	return newStubServer(miles, func(w http.ResponseWriter, r *http.Request, miles int) {
+		if r.URL.Query().Get("searchtext") != "" {
+			fmt.Fprint(w, `{"Response":{"View":[{"Result":[{"Location":{"NavigationPosition":[{"Latitude":29.6,"Longitude":-81.6}]}}]}]}}`)
+			return
+		}
+		meters := int(float64(miles) * metersInAMile)
+		legs := countLegs(r, "waypoint", 0)
+		var legJSON []string
+		for i := 0; i < legs; i++ {
+			legJSON = append(legJSON, fmt.Sprintf(`{"length":%d}`, meters))
+		}
+		fmt.Fprintf(w, `{"response":{"route":[{"summary":{"distance":%d},"leg":[%s]}]}}`, meters*legs, strings.Join(legJSON, ","))
+	})
+}
+
+func newBingStub(miles int) *stubServer {
+	return newStubServer(miles, func(w http.ResponseWriter, r *http.Request, miles int) {
+		legs := countLegs(r, "wp.", 1)
+		var legJSON []string
+		for i := 0; i < legs; i++ {
+			legJSON = append(legJSON, fmt.Sprintf(`{"travelDistance":%d}`, miles))
+		}
+		fmt.Fprintf(w, `{"resourceSets":[{"resources":[{"travelDistance":%d,"routeLegs":[%s]}]}]}`, miles*legs, strings.Join(legJSON, ","))
+	})
+}
+
+func (suite *FallbackPlannerSuite) newPlanners(here *stubServer, bing *stubServer) (Planner, Planner) {
+	appID := "app-id"
+	appCode := "app-code"
+	bingKey := "key"
+	herePlanner := NewHEREPlanner(suite.logger, &here.URL, &here.URL, &appID, &appCode, DefaultTruckProfile)
+	bingPlanner := NewBingPlanner(suite.logger, &bing.URL, &bingKey, DefaultTruckProfile)
+	return herePlanner, bingPlanner
+}
+
+func (suite *FallbackPlannerSuite) TestUsesFirstHealthyProvider() {
+	here := newHEREStub(362)
+	defer here.Close()
+	bing := newBingStub(370)
+	defer bing.Close()
+	herePlanner, bingPlanner := suite.newPlanners(here, bing)
+
+	planner := NewFallbackPlanner(suite.logger, []PlannerProvider{
+		{Name: "here", Planner: herePlanner},
+		{Name: "bing", Planner: bingPlanner},
+	}, DefaultCircuitFailureThreshold, DefaultCircuitCooldown, DefaultDisagreementTolerance)
+
+	result, err := planner.Zip5TransitDistanceResult("32168", "29429")
+	suite.Nil(err)
+	suite.Equal(362, result.Distance)
+	suite.Equal("here", result.Provider)
+	suite.Equal(0, bing.requestCount())
+
+	here.setFailing(true)
+	result, err = planner.Zip5TransitDistanceResult("32168", "29429")
+	suite.Nil(err)
+	suite.Equal(370, result.Distance)
+	suite.Equal("bing", result.Provider)
+	suite.Equal(1, bing.requestCount())
+}
+
+func (suite *FallbackPlannerSuite) TestCircuitOpensAndRecovers() {
+	here := newHEREStub(362)
+	defer here.Close()
+	bing := newBingStub(370)
+	defer bing.Close()
+	herePlanner, bingPlanner := suite.newPlanners(here, bing)
+
+	planner := NewFallbackPlanner(suite.logger, []PlannerProvider{
+		{Name: "here", Planner: herePlanner},
+		{Name: "bing", Planner: bingPlanner},
+	}, 2, time.Minute, DefaultDisagreementTolerance)
+	now := time.Date(2018, time.June, 22, 12, 0, 0, 0, time.UTC)
+	planner.now = func() time.Time { return now }
+
+	here.setFailing(true)
+	for i := 0; i < 4; i++ {
+		distance, err := planner.Zip5TransitDistance("32168", "29429")
+		suite.Nil(err)
+		suite.Equal(370, distance)
+	}
+	// The circuit opened after the second failure, so HERE wasn't asked again
+	suite.Equal(2, here.requestCount())
+	health := planner.Health()
+	suite.Equal(CircuitOpen, health[0].State)
+	suite.Equal(2, health[0].Failures)
+	suite.Equal(CircuitClosed, health[1].State)
+	suite.Equal(4, health[1].Successes)
+
+	// A failed trial after the cooldown opens the circuit again straight away
+	now = now.Add(time.Minute)
+	_, err := planner.Zip5TransitDistance("32168", "29429")
+	suite.Nil(err)
+	suite.Equal(3, here.requestCount())
+	suite.Equal(CircuitOpen, planner.Health()[0].State)
+
+	// A successful trial closes it
+	here.setFailing(false)
+	now = now.Add(time.Minute)
+	result, err := planner.Zip5TransitDistanceResult("32168", "29429")
+	suite.Nil(err)
+	suite.Equal("here", result.Provider)
+	suite.Equal(CircuitClosed, planner.Health()[0].State)
+}
+
+func (suite *FallbackPlannerSuite) TestNoProviderAvailable() {
+	here := newHEREStub(362)
+	defer here.Close()
+	bing := newBingStub(370)
+	defer bing.Close()
+	herePlanner, bingPlanner := suite.newPlanners(here, bing)
+	here.setFailing(true)
+	bing.setFailing(true)
+
+	planner := NewFallbackPlanner(suite.logger, []PlannerProvider{
+		{Name: "here", Planner: herePlanner},
+		{Name: "bing", Planner: bingPlanner},
+	}, 1, time.Minute, DefaultDisagreementTolerance)
+
+	_, err := planner.Zip5TransitDistance("32168", "29429")
+	suite.NotNil(err)
+	suite.NotEqual(ErrNoPlannerAvailable, err)
+
+	// Both circuits are now open
+	_, err = planner.Zip5TransitDistance("32168", "29429")
+	suite.Equal(ErrNoPlannerAvailable, err)
+	suite.Equal(1, here.requestCount())
+	suite.Equal(1, bing.requestCount())
+}
+
+func (suite *FallbackPlannerSuite) TestCrossCheckFlagsDisagreement() {
+	here := newHEREStub(362)
+	defer here.Close()
+	bing := newBingStub(370)
+	defer bing.Close()
+	herePlanner, bingPlanner := suite.newPlanners(here, bing)
+
+	planner := NewFallbackPlanner(suite.logger, []PlannerProvider{
+		{Name: "here", Planner: herePlanner},
+		{Name: "bing", Planner: bingPlanner},
+		{Name: "offline", Planner: NewTestingPlanner(380), CrossCheck: true},
+	}, DefaultCircuitFailureThreshold, DefaultCircuitCooldown, DefaultDisagreementTolerance)
+
+	result, err := planner.Zip5TransitDistanceResult("32168", "29429")
+	suite.Nil(err)
+	suite.Equal("here", result.Provider)
+	suite.Equal(map[string]int{"here": 362, "offline": 380}, result.Distances)
+	suite.False(result.Disagreement)
+	suite.Equal(0, bing.requestCount())
+
+	planner = NewFallbackPlanner(suite.logger, []PlannerProvider{
+		{Name: "here", Planner: herePlanner},
+		{Name: "offline", Planner: NewTestingPlanner(500), CrossCheck: true},
+	}, DefaultCircuitFailureThreshold, DefaultCircuitCooldown, DefaultDisagreementTolerance)
+
+	result, err = planner.Zip5TransitDistanceResult("32168", "29429")
+	suite.Nil(err)
+	suite.Equal(362, result.Distance)
+	suite.True(result.Disagreement)
+}
+
+func (suite *FallbackPlannerSuite) TestWaypointsFallBack() {
+	here := newHEREStub(120)
+	defer here.Close()
+	bing := newBingStub(130)
+	defer bing.Close()
+	herePlanner, bingPlanner := suite.newPlanners(here, bing)
+
+	planner := NewFallbackPlanner(suite.logger, []PlannerProvider{
+		{Name: "here", Planner: herePlanner},
+		{Name: "bing", Planner: bingPlanner},
+	}, DefaultCircuitFailureThreshold, DefaultCircuitCooldown, DefaultDisagreementTolerance)
+
+	origin := models.Address{StreetAddress1: "1 Main St", City: "Palatka", State: "FL", PostalCode: "32177"}
+	pickup := models.Address{StreetAddress1: "2 Main St", City: "Palatka", State: "FL", PostalCode: "32177"}
+	destination := models.Address{StreetAddress1: "3 Main St", City: "Palatka", State: "FL", PostalCode: "32177"}
+	waypoints := []*models.Address{&origin, &pickup, &destination}
+
+	result, err := planner.WaypointsTransitDistanceResult(waypoints)
+	suite.Nil(err)
+	suite.Equal("here", result.Provider)
+	suite.Equal([]int{120, 120}, result.Legs)
+	suite.Equal(240, result.Distance)
+
+	here.setFailing(true)
+	route, err := planner.WaypointsTransitDistance(waypoints)
+	suite.Nil(err)
+	suite.Equal(RouteDistance{Total: 260, Legs: []int{130, 130}}, route)
+
+	_, err = planner.WaypointsTransitDistance(waypoints[:1])
+	suite.Equal(ErrTooFewWaypoints, err)
+}
+
+func (suite *FallbackPlannerSuite) TestVehiclePlannersShareHealth() {
+	here := newHEREStub(362)
+	defer here.Close()
+	bing := newBingStub(370)
+	defer bing.Close()
+	herePlanner, bingPlanner := suite.newPlanners(here, bing)
+
+	truck := NewFallbackPlanner(suite.logger, []PlannerProvider{
+		{Name: "here", Planner: herePlanner},
+		{Name: "bing", Planner: bingPlanner},
+		{Name: "offline", Planner: NewTestingPlanner(380)},
+	}, 1, DefaultCircuitCooldown, DefaultDisagreementTolerance)
+	car := PlannerForVehicle(truck, CarProfile).(*FallbackPlanner)
+	suite.Equal(DefaultTruckProfile, truck.Profile())
+	suite.Equal(CarProfile, car.Profile())
+
+	result, err := car.Zip5TransitDistanceResult("32168", "29429")
+	suite.Nil(err)
+	suite.Equal("here", result.Provider)
+	suite.Equal("car", result.Profile)
+	suite.Contains(here.lastRawQuery(), "&mode=fastest;car;traffic:disabled")
+
+	result, err = truck.Zip5TransitDistanceResult("32168", "29429")
+	suite.Nil(err)
+	suite.Equal(DefaultTruckProfile.Key(), result.Profile)
+
+	// A failure routing the car opens the circuit for the truck too
+	here.setFailing(true)
+	result, err = car.Zip5TransitDistanceResult("32168", "29429")
+	suite.Nil(err)
+	suite.Equal("bing", result.Provider)
+	suite.Equal(CircuitOpen, truck.Health()[0].State)
+
+	bing.setFailing(true)
+	result, err = truck.Zip5TransitDistanceResult("32168", "29429")
+	suite.Nil(err)
+	suite.Equal("offline", result.Provider)
+	suite.Equal("", result.Profile)
+}
+
+func (suite *FallbackPlannerSuite) TestDistanceResultHelpers() {
+	source := &models.Address{PostalCode: "32168"}
+	destination := &models.Address{PostalCode: "29429"}
+
+	// Planners which can't say who answered are reported without a provider
+	result, err := TransitDistanceResult(NewTestingPlanner(380), source, destination)
+	suite.Nil(err)
+	suite.Equal(DistanceResult{Distance: 380, Legs: []int{380}}, result)
+
+	planner := NewFallbackPlanner(suite.logger, []PlannerProvider{
+		{Name: "offline", Planner: NewTestingPlanner(380)},
+	}, DefaultCircuitFailureThreshold, DefaultCircuitCooldown, DefaultDisagreementTolerance)
+	result, err = TransitDistanceResult(planner, source, destination)
+	suite.Nil(err)
+	suite.Equal(380, result.Distance)
+	suite.Equal("offline", result.Provider)
+
+	result, err = WaypointsTransitDistanceResult(planner, []*models.Address{source, destination, source})
+	suite.Nil(err)
+	suite.Equal(760, result.Distance)
+	suite.Equal([]int{380, 380}, result.Legs)
+	suite.Equal("offline", result.Provider)
+}
+
+func TestFallbackPlannerSuite(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		log.Panic(err)
+	}
+	loadTestZip5Locations()
+	suite.Run(t, &FallbackPlannerSuite{logger: logger})
+}
@@ -23,10 +23,12 @@ type herePlanner struct {
 	httpClient              http.Client
 	routeEndPointWithKeys   string
 	geocodeEndPointWithKeys string
+	profile                 VehicleProfile
 }
 
 type addressLatLong struct {
 	err      error
+	index    int
 	address  *models.Address
 	location LatLong
 }
@@ -64,9 +66,10 @@ type GeocodeResponseBody struct {
 
 // getAddressLatLong is expected to run in a goroutine to look up the LatLong of an address using the HERE
 // geocoder endpoint. It returns the data via a channel so two requests can run in parallel
-func (p *herePlanner) getAddressLatLong(responses chan addressLatLong, address *models.Address) {
+func (p *herePlanner) getAddressLatLong(responses chan addressLatLong, index int, address *models.Address) {
 
 	var latLongResponse addressLatLong
+	latLongResponse.index = index
 	latLongResponse.address = address
 
 	// Look up address
@@ -115,9 +118,15 @@ type HereRouteSummary struct {
 	Distance int `json:"distance"` // Distance in meters
 }
 
+// HereRouteLeg is the part of a HERE route between two consecutive waypoints
+type HereRouteLeg struct {
+	Length int `json:"length"` // Distance in meters
+}
+
 // HereRoute is one of the Route responses from the HERE routing API
 type HereRoute struct {
 	Summary HereRouteSummary `json:"summary"`
+	Legs    []HereRouteLeg   `json:"leg"`
 }
 
 // RoutingResponse is the top level object in the response from the HERE routing API
@@ -130,75 +139,163 @@ type RoutingResponseBody struct {
 	Response RoutingResponse `json:"response"`
 }
 
-const routeEndpointFormat = "%s&waypoint0=geo!%s&waypoint1=geo!%s&mode=fastest;truck;traffic:disabled"
+// hereRouteParameters returns the routing mode and vehicle parameters for a profile. Weights are
+// given to HERE in tonnes and heights in meters.
+func hereRouteParameters(profile VehicleProfile) string {
+	if profile.Type == VehicleCar {
+		return "&mode=fastest;car;traffic:disabled"
+	}
+	return fmt.Sprintf("&mode=fastest;truck;traffic:disabled&truckType=%s&height=%.2f&limitedWeight=%.2f",
+		profile.TruckType, profile.HeightMeters, float64(profile.WeightKilograms)/1000)
+}
+
 const metersInAMile = 1609.34
 
-func (p *herePlanner) LatLongTransitDistance(source LatLong, dest LatLong) (int, error) {
-	query := fmt.Sprintf(routeEndpointFormat, p.routeEndPointWithKeys, source.Coords(), dest.Coords())
+// metersToMiles converts a HERE distance to whole miles
+func metersToMiles(meters int) int {
+	return int(math.Round(float64(meters) / metersInAMile))
+}
+
+// route requests a route through the points, waypoint0 to waypointN, and returns the first route
+func (p *herePlanner) route(points []LatLong) (HereRoute, error) {
+	query := p.routeEndPointWithKeys
+	for i, point := range points {
+		query += fmt.Sprintf("&waypoint%d=geo!%s", i, point.Coords())
+	}
+	query += hereRouteParameters(p.profile)
+
 	resp, err := p.httpClient.Get(query)
 	if err != nil {
 		p.logger.Error("Getting route response from HERE.", zap.Error(err))
-		return 0, errors.Wrap(err, "calling HERE routing")
+		return HereRoute{}, errors.Wrap(err, "calling HERE routing")
 	} else if resp.StatusCode != 200 {
 		bodyBytes, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
 			p.logger.Info("Got non-200 response from HERE. Unable to read response body.", zap.Int("http_status", resp.StatusCode))
-			return 0, errors.Wrap(err, "bad Here response, bad body read")
+			return HereRoute{}, errors.Wrap(err, "bad Here response, bad body read")
 		}
 		p.logger.Info("Got non-200 response from HERE routing.", zap.Int("http_status", resp.StatusCode), zap.String("here_error", string(bodyBytes)))
-		return 0, errors.New("error response from HERE")
+		return HereRoute{}, errors.New("error response from HERE")
 	} else {
 		routeDecoder := json.NewDecoder(resp.Body)
 		var response RoutingResponseBody
 		err = routeDecoder.Decode(&response)
 		if err != nil {
 			p.logger.Error("Failed to decode response from HERE routing.", zap.Error(err))
-			return 0, errors.Wrap(err, "decoding routing response from HERE")
+			return HereRoute{}, errors.Wrap(err, "decoding routing response from HERE")
 		} else if len(response.Response.Routes) == 0 {
 			p.logger.Error("Expected at least one route in HERE routing response", zap.Error(err))
-			return 0, errors.New("no Route in HERE routing response")
+			return HereRoute{}, errors.New("no Route in HERE routing response")
 		} else {
-			return int(math.Round(float64(response.Response.Routes[0].Summary.Distance) / metersInAMile)), nil
+			return response.Response.Routes[0], nil
 		}
 	}
 }
 
+func (p *herePlanner) LatLongTransitDistance(source LatLong, dest LatLong) (int, error) {
+	route, err := p.route([]LatLong{source, dest})
+	if err != nil {
+		return 0, err
+	}
+	return metersToMiles(route.Summary.Distance), nil
+}
+
 func (p *herePlanner) Zip5TransitDistance(source string, destination string) (int, error) {
 	return zip5TransitDistanceHelper(p, source, destination)
 }
 
-func (p *herePlanner) TransitDistance(source *models.Address, destination *models.Address) (int, error) {
-
-	// Convert addresses to LatLong using geocode API. Do via goroutines and channel so we can do two
-	// requests in parallel
-	responses := make(chan addressLatLong)
-	var srcLatLong LatLong
-	var destLatLong LatLong
-	go p.getAddressLatLong(responses, source)
-	go p.getAddressLatLong(responses, destination)
-	for count := 0; count < 2; count++ {
+// geocode converts addresses to LatLongs using the geocode API. The requests are made in
+// parallel via goroutines and a channel.
+func (p *herePlanner) geocode(addresses []*models.Address) ([]LatLong, error) {
+	// Buffered so that no goroutine is left blocked when we return on the first error
+	responses := make(chan addressLatLong, len(addresses))
+	for i, address := range addresses {
+		go p.getAddressLatLong(responses, i, address)
+	}
+	locations := make([]LatLong, len(addresses))
+	for count := 0; count < len(addresses); count++ {
 		response := <-responses
 		if response.err != nil {
-			return 0, response.err
-		}
-		if response.address == source {
-			srcLatLong = response.location
-		} else {
-			destLatLong = response.location
+			return nil, response.err
 		}
+		locations[response.index] = response.location
+	}
+	return locations, nil
+}
+
+// GeocodeAddress looks up the location of a single address using the geocode API
+func (p *herePlanner) GeocodeAddress(address *models.Address) (LatLong, error) {
+	locations, err := p.geocode([]*models.Address{address})
+	if err != nil {
+		return LatLong{}, err
 	}
-	return p.LatLongTransitDistance(srcLatLong, destLatLong)
+	return locations[0], nil
+}
+
+func (p *herePlanner) TransitDistance(source *models.Address, destination *models.Address) (int, error) {
+	locations, err := p.geocode([]*models.Address{source, destination})
+	if err != nil {
+		return 0, err
+	}
+	return p.LatLongTransitDistance(locations[0], locations[1])
+}
+
+func (p *herePlanner) WaypointsTransitDistance(waypoints []*models.Address) (RouteDistance, error) {
+	if len(waypoints) < 2 {
+		return RouteDistance{}, ErrTooFewWaypoints
+	}
+	locations, err := p.geocode(waypoints)
+	if err != nil {
+		return RouteDistance{}, err
+	}
+
+	route, err := p.route(locations)
+	if err != nil {
+		return RouteDistance{}, err
+	}
+	if len(route.Legs) != len(waypoints)-1 {
+		p.logger.Error("Expected a route leg between each pair of waypoints",
+			zap.Int("waypoints", len(waypoints)),
+			zap.Int("route_legs", len(route.Legs)))
+		return RouteDistance{}, errors.New("malformed route in HERE routing response")
+	}
+
+	legs := make([]int, len(route.Legs))
+	for i, leg := range route.Legs {
+		legs[i] = metersToMiles(leg.Length)
+	}
+	return newRouteDistance(legs), nil
 }
 
 func addKeysToEndpoint(endpoint *string, id *string, code *string) string {
 	return fmt.Sprintf("%s?app_id=%s&app_code=%s", *endpoint, *id, *code)
 }
 
-// NewHEREPlanner constructs and returns a Planner which uses the HERE Map API to plan routes.
-func NewHEREPlanner(logger *zap.Logger, geocodeEndpoint *string, routeEndpoint *string, appID *string, appCode *string) Planner {
+func (p *herePlanner) Profile() VehicleProfile {
+	return p.profile
+}
+
+func (p *herePlanner) ForVehicle(profile VehicleProfile) Planner {
+	planner := *p
+	planner.profile = profile
+	return &planner
+}
+
+// NewHEREPlanner constructs and returns a Planner which uses the HERE Map API to plan routes for
+// the vehicle described by profile.
+func NewHEREPlanner(logger *zap.Logger, geocodeEndpoint *string, routeEndpoint *string, appID *string, appCode *string, profile VehicleProfile) Planner {
 	return &herePlanner{
 		logger:                  logger,
 		httpClient:              http.Client{Timeout: hereRequestTimeout},
 		routeEndPointWithKeys:   addKeysToEndpoint(routeEndpoint, appID, appCode),
+		geocodeEndPointWithKeys: addKeysToEndpoint(geocodeEndpoint, appID, appCode),
+		profile:                 profile}
+}
+
+// NewHEREGeocoder constructs and returns a Geocoder which uses the HERE Map API to locate addresses.
+func NewHEREGeocoder(logger *zap.Logger, geocodeEndpoint *string, appID *string, appCode *string) Geocoder {
+	return &herePlanner{
+		logger:                  logger,
+		httpClient:              http.Client{Timeout: hereRequestTimeout},
 		geocodeEndPointWithKeys: addKeysToEndpoint(geocodeEndpoint, appID, appCode)}
 }
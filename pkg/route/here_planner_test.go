@@ -2,6 +2,12 @@ package route
 
 import (
 	"os"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/route/fakemaps"
 )
 
 type HereFullSuite struct {
@@ -18,5 +24,85 @@ func (suite *HereFullSuite) SetupTest() {
 		suite.T().Fatal("You must set HERE_... environment variables to run this test")
 	}
 
-	suite.planner = NewHEREPlanner(suite.logger, &geocodeEndpoint, &routingEndpoint, &testAppID, &testAppCode)
+	suite.planner = NewHEREPlanner(suite.logger, &geocodeEndpoint, &routingEndpoint, &testAppID, &testAppCode, DefaultTruckProfile)
+}
+
+func TestHEREGeocoder(t *testing.T) {
+	here := newHEREStub(0)
+	defer here.Close()
+	appID := "app-id"
+	appCode := "app-code"
+	geocoder := NewHEREGeocoder(zap.NewNop(), &here.URL, &appID, &appCode)
+
+	address := models.Address{StreetAddress1: "1 Main St", City: "Palatka", State: "FL", PostalCode: "32177"}
+	location, err := geocoder.GeocodeAddress(&address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if location != (LatLong{Latitude: 29.6, Longitude: -81.6}) {
+		t.Errorf("expected the stub location, got %v", location)
+	}
+
+	here.setFailing(true)
+	if _, err := geocoder.GeocodeAddress(&address); err == nil {
+		t.Error("expected an error from a failing geocoder")
+	}
+}
+
+func TestHEREPlannerWithFakeMaps(t *testing.T) {
+	maps := fakemaps.NewServer(readTestZip5Locations())
+	defer maps.Close()
+	geocodeEndpoint := maps.HEREGeocodeEndpoint()
+	routeEndpoint := maps.HERERouteEndpoint()
+	appID := "app-id"
+	appCode := "app-code"
+	planner := NewHEREPlanner(zap.NewNop(), &geocodeEndpoint, &routeEndpoint, &appID, &appCode, DefaultTruckProfile)
+
+	origin := models.Address{StreetAddress1: "1 Main St", City: "New Smyrna Beach", State: "FL", PostalCode: "32168"}
+	pickup := models.Address{StreetAddress1: "2 Main St", City: "Palatka", State: "FL", PostalCode: "32177"}
+	destination := models.Address{StreetAddress1: "3 Main St", City: "Charleston", State: "SC", PostalCode: "29429"}
+
+	distance, err := planner.TransitDistance(&origin, &destination)
+	if err != nil {
+		t.Fatal(err)
+	}
+	route, err := planner.WaypointsTransitDistance([]*models.Address{&origin, &pickup, &destination})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(route.Legs) != 2 || route.Total <= distance {
+		t.Errorf("expected two legs longer than the direct distance %d, got %v", distance, route)
+	}
+	if query := maps.Handler().LastQuery(fakemaps.HERERoute); query.Get("truckType") != "truck" {
+		t.Errorf("expected truck routing, got %v", query)
+	}
+
+	unknown := models.Address{StreetAddress1: "1 Main St", City: "Nowhere", State: "FL", PostalCode: "00000"}
+	if _, err := planner.TransitDistance(&origin, &unknown); err == nil {
+		t.Error("expected an error for an address the geocoder can't find")
+	}
+
+	failures := []struct {
+		api     fakemaps.API
+		failure fakemaps.Failure
+	}{
+		{fakemaps.HEREGeocode, fakemaps.ServerError},
+		{fakemaps.HEREGeocode, fakemaps.MalformedJSON},
+		{fakemaps.HEREGeocode, fakemaps.EmptyResult},
+		{fakemaps.HERERoute, fakemaps.ServerError},
+		{fakemaps.HERERoute, fakemaps.MalformedJSON},
+		{fakemaps.HERERoute, fakemaps.EmptyResult},
+	}
+	for _, f := range failures {
+		maps.Handler().FailNext(f.api, f.failure)
+		if _, err := planner.TransitDistance(&origin, &destination); err == nil {
+			t.Errorf("expected an error when %s fails with %s", f.api, f.failure)
+		}
+		maps.Handler().ClearFailures()
+	}
+
+	// The planner recovers once the failures stop
+	if recovered, err := planner.TransitDistance(&origin, &destination); err != nil || recovered != distance {
+		t.Errorf("expected %d after the failures, got %d, %v", distance, recovered, err)
+	}
 }
@@ -0,0 +1,151 @@
+package route
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MileageTable holds point-to-point distances between ZIP5s or ZIP3s, as published in
+// the DTOD and HHG mileage guides. Distances are the same in both directions.
+type MileageTable struct {
+	miles map[string]int
+}
+
+// NewMileageTable returns an empty MileageTable
+func NewMileageTable() *MileageTable {
+	return &MileageTable{miles: map[string]int{}}
+}
+
+func mileageTableKey(a string, b string) string {
+	if a > b {
+		a, b = b, a
+	}
+	return a + ":" + b
+}
+
+// Add records the distance between two ZIP5s or two ZIP3s
+func (t *MileageTable) Add(source string, destination string, miles int) error {
+	if len(source) != len(destination) || (len(source) != 3 && len(source) != 5) {
+		return errors.Errorf("mileage table entries must be between two ZIP5s or two ZIP3s, got %q and %q", source, destination)
+	}
+	if miles < 0 {
+		return errors.Errorf("negative distance %d between %s and %s", miles, source, destination)
+	}
+	t.miles[mileageTableKey(source, destination)] = miles
+	return nil
+}
+
+// Len returns the number of entries in the table
+func (t *MileageTable) Len() int {
+	return len(t.miles)
+}
+
+// Lookup returns the distance between two ZIP5s, preferring a ZIP5 to ZIP5 entry over
+// the entry for their ZIP3s.
+func (t *MileageTable) Lookup(sourceZip5 string, destinationZip5 string) (int, bool) {
+	if miles, ok := t.miles[mileageTableKey(sourceZip5, destinationZip5)]; ok {
+		return miles, true
+	}
+	if len(sourceZip5) < 3 || len(destinationZip5) < 3 {
+		return 0, false
+	}
+	miles, ok := t.miles[mileageTableKey(sourceZip5[0:3], destinationZip5[0:3])]
+	return miles, ok
+}
+
+// CalibrateRoadFactor compares the ZIP5 to ZIP5 entries in the table with the great-circle
+// distance between the same ZIPs and returns the median ratio, i.e. how much longer the road
+// distance typically is. It returns false if no entries could be compared.
+func (t *MileageTable) CalibrateRoadFactor() (float64, bool) {
+	var ratios []float64
+	for key, miles := range t.miles {
+		zips := strings.Split(key, ":")
+		if len(zips[0]) != 5 || miles == 0 {
+			continue
+		}
+		source, err := Zip5ToLatLong(zips[0])
+		if err != nil {
+			continue
+		}
+		destination, err := Zip5ToLatLong(zips[1])
+		if err != nil {
+			continue
+		}
+		greatCircle := greatCircleMiles(source, destination)
+		if greatCircle < 1 {
+			continue
+		}
+		ratios = append(ratios, float64(miles)/greatCircle)
+	}
+	if len(ratios) == 0 {
+		return 0, false
+	}
+
+	sort.Float64s(ratios)
+	middle := len(ratios) / 2
+	if len(ratios)%2 == 0 {
+		return (ratios[middle-1] + ratios[middle]) / 2, true
+	}
+	return ratios[middle], true
+}
+
+// LoadMileageTable reads a CSV of source,destination,miles rows. An optional header row is skipped.
+func LoadMileageTable(r io.Reader) (*MileageTable, error) {
+	table := NewMileageTable()
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return table, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "reading mileage table")
+		}
+		miles, err := strconv.Atoi(strings.TrimSpace(record[2]))
+		if err != nil {
+			if line == 1 {
+				continue
+			}
+			return nil, errors.Errorf("invalid distance %q on line %d of mileage table", record[2], line)
+		}
+		if err := table.Add(strings.TrimSpace(record[0]), strings.TrimSpace(record[1]), miles); err != nil {
+			return nil, errors.Wrapf(err, "line %d of mileage table", line)
+		}
+	}
+}
+
+// LoadMileageTableFile reads a mileage table CSV from a file
+func LoadMileageTableFile(path string) (*MileageTable, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening mileage table")
+	}
+	defer file.Close()
+	return LoadMileageTable(file)
+}
+
+// earthRadiusMiles is the mean radius of the Earth
+const earthRadiusMiles = 3958.8
+
+// greatCircleMiles returns the haversine distance between two points
+func greatCircleMiles(source LatLong, destination LatLong) float64 {
+	toRadians := func(degrees float32) float64 {
+		return float64(degrees) * math.Pi / 180
+	}
+	lat1, lat2 := toRadians(source.Latitude), toRadians(destination.Latitude)
+	deltaLat := lat2 - lat1
+	deltaLong := toRadians(destination.Longitude) - toRadians(source.Longitude)
+
+	a := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(deltaLong/2)*math.Sin(deltaLong/2)
+	return 2 * earthRadiusMiles * math.Asin(math.Min(1, math.Sqrt(a)))
+}
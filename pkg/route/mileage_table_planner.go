@@ -0,0 +1,106 @@
+package route
+
+import (
+	"fmt"
+	"math"
+
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/models"
+)
+
+// DefaultRoadFactor is how much longer than the great-circle distance road distances are
+// assumed to be when a mileage table has nothing to calibrate against.
+const DefaultRoadFactor = 1.2
+
+// mileageTablePlanner answers distances from a mileage table without any network access,
+// estimating distances the table doesn't cover from the great-circle distance.
+type mileageTablePlanner struct {
+	logger     *zap.Logger
+	table      *MileageTable
+	roadFactor float64
+}
+
+// NewMileageTablePlanner constructs and returns a Planner which looks distances up in a
+// mileage table. Where the table has no entry, the great-circle distance between the ZIPs is
+// multiplied by the road factor. A roadFactor of 0 calibrates the factor from the table,
+// using DefaultRoadFactor if the table has no ZIP5 entries to calibrate against.
+func NewMileageTablePlanner(logger *zap.Logger, table *MileageTable, roadFactor float64) Planner {
+	if table == nil {
+		table = NewMileageTable()
+	}
+	if roadFactor <= 0 {
+		var ok bool
+		roadFactor, ok = table.CalibrateRoadFactor()
+		if !ok {
+			roadFactor = DefaultRoadFactor
+		}
+		logger.Info("Calibrated mileage table road factor", zap.Float64("road_factor", roadFactor))
+	}
+	return &mileageTablePlanner{logger: logger, table: table, roadFactor: roadFactor}
+}
+
+func (p *mileageTablePlanner) estimate(source LatLong, destination LatLong) int {
+	return int(math.Round(greatCircleMiles(source, destination) * p.roadFactor))
+}
+
+func (p *mileageTablePlanner) Zip5TransitDistance(source string, destination string) (int, error) {
+	if miles, ok := p.table.Lookup(source, destination); ok {
+		return miles, nil
+	}
+
+	sLL, err := Zip5ToLatLong(source)
+	if err != nil {
+		return 0, err
+	}
+	dLL, err := Zip5ToLatLong(destination)
+	if err != nil {
+		return 0, err
+	}
+	p.logger.Debug("No mileage table entry, estimating from great-circle distance",
+		zap.String("source", source),
+		zap.String("destination", destination))
+	return p.estimate(sLL, dLL), nil
+}
+
+func (p *mileageTablePlanner) LatLongTransitDistance(source LatLong, destination LatLong) (int, error) {
+	sourceZip, sourceOK := nearestZip5(source)
+	destinationZip, destinationOK := nearestZip5(destination)
+	if sourceOK && destinationOK {
+		if miles, ok := p.table.Lookup(sourceZip, destinationZip); ok {
+			return miles, nil
+		}
+	}
+	return p.estimate(source, destination), nil
+}
+
+func (p *mileageTablePlanner) TransitDistance(source *models.Address, destination *models.Address) (int, error) {
+	if len(source.PostalCode) < 5 || len(destination.PostalCode) < 5 {
+		return 0, fmt.Errorf("postal codes %q and %q must start with a ZIP5", source.PostalCode, destination.PostalCode)
+	}
+	return p.Zip5TransitDistance(source.PostalCode[0:5], destination.PostalCode[0:5])
+}
+
+func (p *mileageTablePlanner) WaypointsTransitDistance(waypoints []*models.Address) (RouteDistance, error) {
+	return legsTransitDistanceHelper(p, waypoints)
+}
+
+// nearestZip5 returns the ZIP5 whose location is closest to the point. When several ZIPs
+// share a location the lowest is returned, so that the result doesn't depend on map order.
+func nearestZip5(ll LatLong) (string, bool) {
+	zip5Index.RLock()
+	defer zip5Index.RUnlock()
+
+	nearest := -1
+	nearestDistance := math.MaxFloat64
+	for zip, zipLL := range zip5Index.latLongs {
+		distance := greatCircleMiles(ll, zipLL)
+		if distance < nearestDistance || (distance == nearestDistance && zip < nearest) {
+			nearest, nearestDistance = zip, distance
+		}
+	}
+	if nearest < 0 {
+		return "", false
+	}
+	return fmt.Sprintf("%05d", nearest), true
+}
@@ -0,0 +1,68 @@
+package route
+
+import (
+	"math"
+
+	"github.com/transcom/mymove/pkg/models"
+)
+
+func (suite *PlannerSuite) TestMileageTablePlanner() {
+	table := NewMileageTable()
+	suite.Nil(table.Add("94103", "20301", 2800))
+	suite.Nil(table.Add("941", "203", 2750))
+	planner := NewMileageTablePlanner(suite.logger, table, 1.2)
+
+	distance, err := planner.Zip5TransitDistance("20301", "94103")
+	suite.Nil(err)
+	suite.Equal(2800, distance)
+
+	distance, err = planner.TransitDistance(&realAddressSource, &realAddressDestination)
+	suite.Nil(err)
+	suite.Equal(2800, distance)
+
+	source, _ := Zip5ToLatLong("94103")
+	destination, _ := Zip5ToLatLong("20301")
+	distance, err = planner.LatLongTransitDistance(source, destination)
+	suite.Nil(err)
+	suite.True(distance == 2800 || distance == 2750, "expected a mileage table distance, got %d", distance)
+
+	// Not in the table, so estimated from the great-circle distance
+	source, _ = Zip5ToLatLong("32168")
+	destination, _ = Zip5ToLatLong("29429")
+	expected := int(math.Round(greatCircleMiles(source, destination) * 1.2))
+	distance, err = planner.Zip5TransitDistance("32168", "29429")
+	suite.Nil(err)
+	suite.Equal(expected, distance)
+
+	// The same estimate every time
+	again, err := planner.Zip5TransitDistance("32168", "29429")
+	suite.Nil(err)
+	suite.Equal(distance, again)
+
+	_, err = planner.Zip5TransitDistance("32168", "charleston")
+	suite.NotNil(err)
+
+	_, err = planner.TransitDistance(&models.Address{PostalCode: "321"}, &realAddressDestination)
+	suite.NotNil(err)
+}
+
+func (suite *PlannerSuite) TestMileageTablePlannerCalibratesRoadFactor() {
+	table := NewMileageTable()
+	suite.Nil(table.Add("94103", "20301", 2800))
+	calibrated, _ := table.CalibrateRoadFactor()
+
+	planner := NewMileageTablePlanner(suite.logger, table, 0)
+	source, _ := Zip5ToLatLong("32168")
+	destination, _ := Zip5ToLatLong("29429")
+	expected := int(math.Round(greatCircleMiles(source, destination) * calibrated))
+
+	distance, err := planner.Zip5TransitDistance("32168", "29429")
+	suite.Nil(err)
+	suite.Equal(expected, distance)
+
+	// Nothing to calibrate against
+	planner = NewMileageTablePlanner(suite.logger, nil, 0)
+	distance, err = planner.Zip5TransitDistance("32168", "29429")
+	suite.Nil(err)
+	suite.Equal(int(math.Round(greatCircleMiles(source, destination)*DefaultRoadFactor)), distance)
+}
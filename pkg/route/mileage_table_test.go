@@ -0,0 +1,62 @@
+package route
+
+import (
+	"strings"
+)
+
+func (suite *PlannerSuite) TestLoadMileageTable() {
+	input := `source,destination,miles
+94103,20301,2800
+941, 203, 2750
+`
+	table, err := LoadMileageTable(strings.NewReader(input))
+	suite.Nil(err)
+	suite.Equal(2, table.Len())
+
+	miles, ok := table.Lookup("94103", "20301")
+	suite.True(ok)
+	suite.Equal(2800, miles)
+
+	// Entries work in both directions
+	miles, ok = table.Lookup("20301", "94103")
+	suite.True(ok)
+	suite.Equal(2800, miles)
+
+	// Falls back to the ZIP3 entry
+	miles, ok = table.Lookup("94107", "20310")
+	suite.True(ok)
+	suite.Equal(2750, miles)
+
+	_, ok = table.Lookup("32168", "29429")
+	suite.False(ok)
+
+	_, err = LoadMileageTable(strings.NewReader("94103,203,100\n"))
+	suite.NotNil(err)
+	_, err = LoadMileageTable(strings.NewReader("94103,20301,100\n94103,20301,far\n"))
+	suite.NotNil(err)
+}
+
+func (suite *PlannerSuite) TestCalibrateRoadFactor() {
+	table := NewMileageTable()
+	_, ok := table.CalibrateRoadFactor()
+	suite.False(ok)
+
+	source, err := Zip5ToLatLong("94103")
+	suite.Nil(err)
+	destination, err := Zip5ToLatLong("20301")
+	suite.Nil(err)
+	greatCircle := greatCircleMiles(source, destination)
+
+	suite.Nil(table.Add("94103", "20301", 2800))
+	factor, ok := table.CalibrateRoadFactor()
+	suite.True(ok)
+	suite.InDelta(2800/greatCircle, factor, 0.000001)
+}
+
+func (suite *PlannerSuite) TestGreatCircleMiles() {
+	// San Francisco to Washington DC is roughly 2,440 miles as the crow flies
+	source, _ := Zip5ToLatLong("94103")
+	destination, _ := Zip5ToLatLong("20301")
+	suite.InDelta(2440, greatCircleMiles(source, destination), 15)
+	suite.Equal(0.0, greatCircleMiles(source, source))
+}
@@ -6,9 +6,14 @@ import (
 
 	"fmt"
 
+	"github.com/pkg/errors"
+
 	"github.com/transcom/mymove/pkg/models"
 )
 
+// ErrTooFewWaypoints is returned when a route is requested through fewer than two waypoints
+var ErrTooFewWaypoints = errors.New("a route needs at least two waypoints")
+
 // LatLong is used to hold latitude and longitude as floats
 type LatLong struct {
 	Latitude  float32
@@ -45,9 +50,81 @@ func zip5TransitDistanceHelper(planner Planner, source string, destination strin
 	return planner.LatLongTransitDistance(sLL, dLL)
 }
 
+// RouteDistance is the distance of a route through several waypoints. Legs holds the distance
+// between each consecutive pair of waypoints and Total is their sum.
+type RouteDistance struct {
+	Total int
+	Legs  []int
+}
+
+// newRouteDistance returns a RouteDistance over legs, totalling them
+func newRouteDistance(legs []int) RouteDistance {
+	distance := RouteDistance{Legs: legs}
+	for _, leg := range legs {
+		distance.Total += leg
+	}
+	return distance
+}
+
+// legsTransitDistanceHelper finds the distance of a route by asking planner for the distance of each leg in turn
+func legsTransitDistanceHelper(planner Planner, waypoints []*models.Address) (RouteDistance, error) {
+	if len(waypoints) < 2 {
+		return RouteDistance{}, ErrTooFewWaypoints
+	}
+	legs := make([]int, len(waypoints)-1)
+	for i := range legs {
+		leg, err := planner.TransitDistance(waypoints[i], waypoints[i+1])
+		if err != nil {
+			return RouteDistance{}, err
+		}
+		legs[i] = leg
+	}
+	return newRouteDistance(legs), nil
+}
+
 // Planner is the interface needed by Handlers to be able to evaluate the distance to be used for move accounting
 type Planner interface {
 	TransitDistance(source *models.Address, destination *models.Address) (int, error)
 	LatLongTransitDistance(source LatLong, destination LatLong) (int, error)
 	Zip5TransitDistance(source string, destination string) (int, error)
+	// WaypointsTransitDistance returns the distance of a route visiting each of the waypoints in order
+	WaypointsTransitDistance(waypoints []*models.Address) (RouteDistance, error)
+}
+
+// ResultPlanner is a Planner which can also report which provider answered each distance
+type ResultPlanner interface {
+	Planner
+	TransitDistanceResult(source *models.Address, destination *models.Address) (DistanceResult, error)
+	WaypointsTransitDistanceResult(waypoints []*models.Address) (DistanceResult, error)
+}
+
+// TransitDistanceResult asks planner for the distance between two addresses, along with the
+// provider which answered if planner is a ResultPlanner
+func TransitDistanceResult(planner Planner, source *models.Address, destination *models.Address) (DistanceResult, error) {
+	if resultPlanner, ok := planner.(ResultPlanner); ok {
+		return resultPlanner.TransitDistanceResult(source, destination)
+	}
+	distance, err := planner.TransitDistance(source, destination)
+	if err != nil {
+		return DistanceResult{}, err
+	}
+	return DistanceResult{Distance: distance, Legs: []int{distance}, Profile: profileKey(planner)}, nil
+}
+
+// WaypointsTransitDistanceResult asks planner for the distance of a route through the waypoints,
+// along with the provider which answered if planner is a ResultPlanner
+func WaypointsTransitDistanceResult(planner Planner, waypoints []*models.Address) (DistanceResult, error) {
+	if resultPlanner, ok := planner.(ResultPlanner); ok {
+		return resultPlanner.WaypointsTransitDistanceResult(waypoints)
+	}
+	distance, err := planner.WaypointsTransitDistance(waypoints)
+	if err != nil {
+		return DistanceResult{}, err
+	}
+	return DistanceResult{Distance: distance.Total, Legs: distance.Legs, Profile: profileKey(planner)}, nil
+}
+
+// Geocoder is the interface needed to find the location of an address
+type Geocoder interface {
+	GeocodeAddress(address *models.Address) (LatLong, error)
 }
@@ -2,6 +2,7 @@ package route
 
 import (
 	"log"
+	"os"
 	"strings"
 	"testing"
 
@@ -78,11 +79,34 @@ func (suite *PlannerFullSuite) TestZip5Distance() {
 	}
 }
 
+// readTestZip5Locations reads the locations of the ZIP5s used by the tests in this package
+func readTestZip5Locations() models.Zip5Locations {
+	file, err := os.Open("testdata/zip5_locations.csv")
+	if err != nil {
+		log.Panic(err)
+	}
+	defer file.Close()
+
+	locations, err := ReadZip5Locations(file)
+	if err != nil {
+		log.Panic(err)
+	}
+	return locations
+}
+
+// loadTestZip5Locations loads the locations of the ZIP5s used by the tests in this package
+func loadTestZip5Locations() {
+	if err := SetZip5Locations(readTestZip5Locations()); err != nil {
+		log.Panic(err)
+	}
+}
+
 func TestHandlerSuite(t *testing.T) {
 	logger, err := zap.NewDevelopment()
 	if err != nil {
 		log.Panic(err)
 	}
+	loadTestZip5Locations()
 
 	var testSuite suite.TestingSuite
 	if testing.Short() == false {
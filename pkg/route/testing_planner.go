@@ -18,6 +18,10 @@ func (tp testingPlanner) Zip5TransitDistance(source string, destination string)
 	return zip5TransitDistanceHelper(tp, source, destination)
 }
 
+func (tp testingPlanner) WaypointsTransitDistance(waypoints []*models.Address) (RouteDistance, error) {
+	return legsTransitDistanceHelper(tp, waypoints)
+}
+
 // NewTestingPlanner constructs a route.Planner to be used when testing other code
 func NewTestingPlanner(distance int) Planner {
 	return testingPlanner{
@@ -0,0 +1,73 @@
+package route
+
+import (
+	"fmt"
+)
+
+// VehicleType is the kind of vehicle a route is planned for
+type VehicleType string
+
+const (
+	// VehicleTruck plans routes a moving truck can take, avoiding parkways and low bridges
+	VehicleTruck VehicleType = "TRUCK"
+	// VehicleCar plans ordinary driving routes, e.g. for a PPM moved in the member's own car
+	VehicleCar VehicleType = "CAR"
+)
+
+// VehicleProfile describes the vehicle a route is planned for. Truck dimensions are only used
+// when Type is VehicleTruck. Moves never carry hazardous materials, so routes are always planned
+// without them.
+type VehicleProfile struct {
+	Type VehicleType
+	// TruckType is the HERE truck type, either "truck" or "tractorTruck"
+	TruckType string
+	// HeightMeters is the height of the truck including its load
+	HeightMeters float64
+	// WeightKilograms is the weight of the truck including its trailer and load
+	WeightKilograms int
+}
+
+// DefaultTruckProfile is a loaded 26 foot box truck, the largest vehicle commonly used for a move
+var DefaultTruckProfile = VehicleProfile{
+	Type:            VehicleTruck,
+	TruckType:       "truck",
+	HeightMeters:    4.1,
+	WeightKilograms: 11800,
+}
+
+// CarProfile is an ordinary passenger car
+var CarProfile = VehicleProfile{Type: VehicleCar}
+
+// Key identifies the profile in cached distances and results, e.g. "car" or "truck:truck:4.10m:11800kg"
+func (v VehicleProfile) Key() string {
+	if v.Type == VehicleCar {
+		return "car"
+	}
+	return fmt.Sprintf("truck:%s:%.2fm:%dkg", v.TruckType, v.HeightMeters, v.WeightKilograms)
+}
+
+// VehiclePlanner is a Planner whose routes depend on the vehicle being routed
+type VehiclePlanner interface {
+	Planner
+	// Profile returns the vehicle this planner routes
+	Profile() VehicleProfile
+	// ForVehicle returns a planner which plans the same way for a different vehicle
+	ForVehicle(profile VehicleProfile) Planner
+}
+
+// PlannerForVehicle returns a planner which routes the vehicle described by profile. Planners which
+// don't route by vehicle, such as the mileage table, are returned unchanged.
+func PlannerForVehicle(planner Planner, profile VehicleProfile) Planner {
+	if vehiclePlanner, ok := planner.(VehiclePlanner); ok {
+		return vehiclePlanner.ForVehicle(profile)
+	}
+	return planner
+}
+
+// profileKey returns the key of the vehicle profile planner routes, or "" if it doesn't route by vehicle
+func profileKey(planner Planner) string {
+	if vehiclePlanner, ok := planner.(VehiclePlanner); ok {
+		return vehiclePlanner.Profile().Key()
+	}
+	return ""
+}
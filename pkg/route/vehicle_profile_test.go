@@ -0,0 +1,101 @@
+package route
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestVehicleProfileKey(t *testing.T) {
+	if key := DefaultTruckProfile.Key(); key != "truck:truck:4.10m:11800kg" {
+		t.Errorf("wrong key for the default truck: %q", key)
+	}
+	if key := CarProfile.Key(); key != "car" {
+		t.Errorf("wrong key for a car: %q", key)
+	}
+}
+
+func TestHERERoutesVehicle(t *testing.T) {
+	here := newHEREStub(362)
+	defer here.Close()
+	appID := "app-id"
+	appCode := "app-code"
+	truck := NewHEREPlanner(zap.NewNop(), &here.URL, &here.URL, &appID, &appCode, VehicleProfile{
+		Type:            VehicleTruck,
+		TruckType:       "tractorTruck",
+		HeightMeters:    4.2,
+		WeightKilograms: 15500,
+	})
+	source := LatLong{Latitude: 29.6, Longitude: -81.6}
+	destination := LatLong{Latitude: 32.8, Longitude: -79.9}
+
+	if _, err := truck.LatLongTransitDistance(source, destination); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(here.lastRawQuery(), "&mode=fastest;truck;traffic:disabled&") {
+		t.Errorf("expected truck routing, got %s", here.lastRawQuery())
+	}
+	expected := map[string]string{
+		"truckType":     "tractorTruck",
+		"height":        "4.20",
+		"limitedWeight": "15.50",
+	}
+	query := here.lastQuery()
+	for param, value := range expected {
+		if query.Get(param) != value {
+			t.Errorf("expected %s=%s, got %q", param, value, query.Get(param))
+		}
+	}
+	if _, ok := query["shippedHazardousGoods"]; ok {
+		t.Error("expected no hazardous goods")
+	}
+
+	car := PlannerForVehicle(truck, CarProfile)
+	if _, err := car.LatLongTransitDistance(source, destination); err != nil {
+		t.Fatal(err)
+	}
+	if query := here.lastRawQuery(); !strings.HasSuffix(query, "&mode=fastest;car;traffic:disabled") {
+		t.Errorf("expected car routing, got %s", query)
+	}
+}
+
+func TestBingRoutesVehicle(t *testing.T) {
+	bing := newBingStub(370)
+	defer bing.Close()
+	endpoint := bing.URL + "/REST/v1/Routes/Truck"
+	key := "key"
+	truck := NewBingPlanner(zap.NewNop(), &endpoint, &key, DefaultTruckProfile)
+	source := LatLong{Latitude: 29.6, Longitude: -81.6}
+	destination := LatLong{Latitude: 32.8, Longitude: -79.9}
+
+	if _, err := truck.LatLongTransitDistance(source, destination); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"vehicleHeight": "4.10",
+		"vehicleWeight": "11800",
+		"dimensionUnit": "m",
+		"weightUnit":    "kg",
+	}
+	if path := bing.lastPath(); path != "/REST/v1/Routes/Truck" {
+		t.Errorf("expected the Truck endpoint, got %s", path)
+	}
+	query := bing.lastQuery()
+	for param, value := range expected {
+		if query.Get(param) != value {
+			t.Errorf("expected %s=%s, got %q", param, value, query.Get(param))
+		}
+	}
+
+	car := PlannerForVehicle(truck, CarProfile)
+	if _, err := car.LatLongTransitDistance(source, destination); err != nil {
+		t.Fatal(err)
+	}
+	if path := bing.lastPath(); path != "/REST/v1/Routes/Driving" {
+		t.Errorf("expected the Driving endpoint, got %s", path)
+	}
+	if query := bing.lastQuery(); query.Get("vehicleHeight") != "" {
+		t.Errorf("expected car routing, got %v", query)
+	}
+}
@@ -1,5 +1,11 @@
 package route
 
+import (
+	"strings"
+
+	"github.com/transcom/mymove/pkg/models"
+)
+
 func (suite *PlannerSuite) TestZip5ToLatLong() {
 	zip02807LatLong := LatLong{
 		Latitude:  41.18889,
@@ -33,3 +39,26 @@ func (suite *PlannerSuite) TestZip5ToLatLong() {
 	ll, err = Zip5ToLatLong("00001")
 	suite.Assertions.NotNil(err, "Should get error from Zip5 not valid")
 }
+
+func (suite *PlannerSuite) TestReadZip5Locations() {
+	input := `code,city,state,county,area_code,lat,lon
+2807,Block Island,RI,Washington,401,41.188888,-71.577696
+94103,San Francisco,CA,San Francisco,415,37.775678,-122.412131
+`
+	locations, err := ReadZip5Locations(strings.NewReader(input))
+	suite.Nil(err)
+	suite.Equal(models.Zip5Locations{
+		{Zip5: "02807", City: "Block Island", State: "RI", County: "Washington", Latitude: 41.188888, Longitude: -71.577696},
+		{Zip5: "94103", City: "San Francisco", State: "CA", County: "San Francisco", Latitude: 37.775678, Longitude: -122.412131},
+	}, locations)
+
+	// Only the code and location are required
+	locations, err = ReadZip5Locations(strings.NewReader("lon,lat,code\n-81.785957,29.611093,32177\n"))
+	suite.Nil(err)
+	suite.Equal(models.Zip5Locations{{Zip5: "32177", Latitude: 29.611093, Longitude: -81.785957}}, locations)
+
+	_, err = ReadZip5Locations(strings.NewReader("code,city\n32177,Palatka\n"))
+	suite.NotNil(err)
+	_, err = ReadZip5Locations(strings.NewReader("code,lat,lon\ncharleston,29.6,-81.7\n"))
+	suite.NotNil(err)
+}
@@ -0,0 +1,95 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// clamdChunkSize is the size of the chunks files are streamed to clamd in. It must stay below
+// clamd's StreamMaxLength.
+const clamdChunkSize = 64 * 1024
+
+// ClamdScanner scans files by streaming them to a ClamAV clamd daemon with the INSTREAM command
+type ClamdScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamdScanner returns a Scanner which connects to clamd at address, either a unix socket such
+// as unix:///var/run/clamav/clamd.ctl or a TCP address such as tcp://localhost:3310. A scan fails
+// if it takes longer than timeout.
+func NewClamdScanner(address string, timeout time.Duration) (*ClamdScanner, error) {
+	parts := strings.SplitN(address, "://", 2)
+	if len(parts) != 2 || (parts[0] != "unix" && parts[0] != "tcp") || parts[1] == "" {
+		return nil, errors.Errorf("clamd address %q must start with unix:// or tcp://", address)
+	}
+	return &ClamdScanner{network: parts[0], address: parts[1], timeout: timeout}, nil
+}
+
+// Scan streams data to clamd and reports what it found
+func (c *ClamdScanner) Scan(data io.Reader) (Result, error) {
+	conn, err := net.DialTimeout(c.network, c.address, c.timeout)
+	if err != nil {
+		return Result{}, errors.Wrap(err, "could not connect to clamd")
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+		return Result{}, errors.Wrap(err, "could not set clamd deadline")
+	}
+
+	// The z prefix means commands and replies are terminated by a null byte
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return Result{}, errors.Wrap(err, "could not send INSTREAM to clamd")
+	}
+
+	// Each chunk is preceded by its length as a 4 byte big endian integer, and a zero length
+	// chunk ends the stream
+	chunk := make([]byte, clamdChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := data.Read(chunk)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(size); err != nil {
+				return Result{}, errors.Wrap(err, "could not stream file to clamd")
+			}
+			if _, err := conn.Write(chunk[:n]); err != nil {
+				return Result{}, errors.Wrap(err, "could not stream file to clamd")
+			}
+		}
+		if readErr == io.EOF {
+			break
+		} else if readErr != nil {
+			return Result{}, errors.Wrap(readErr, "could not read file")
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return Result{}, errors.Wrap(err, "could not end stream to clamd")
+	}
+
+	reply, err := bufio.NewReader(conn).ReadBytes(0)
+	if err != nil && err != io.EOF {
+		return Result{}, errors.Wrap(err, "could not read clamd reply")
+	}
+	return parseClamdReply(string(bytes.TrimRight(reply, "\x00\n")))
+}
+
+// parseClamdReply interprets a reply such as "stream: OK" or "stream: Eicar-Test-Signature FOUND"
+func parseClamdReply(reply string) (Result, error) {
+	status := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case status == "OK":
+		return Result{}, nil
+	case strings.HasSuffix(status, " FOUND"):
+		return Result{Infected: true, Signature: strings.TrimSuffix(status, " FOUND")}, nil
+	}
+	return Result{}, errors.Errorf("clamd could not scan file: %s", reply)
+}
@@ -0,0 +1,136 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// eicar is the EICAR antivirus test file, which every scanner reports as infected
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// fakeClamd answers INSTREAM commands on listener the way clamd does, finding the EICAR test file
+func fakeClamd(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func(conn net.Conn) {
+			defer conn.Close()
+			command := make([]byte, len("zINSTREAM\x00"))
+			if _, err := io.ReadFull(conn, command); err != nil || string(command) != "zINSTREAM\x00" {
+				conn.Write([]byte("UNKNOWN COMMAND\x00"))
+				return
+			}
+			var content bytes.Buffer
+			size := make([]byte, 4)
+			for {
+				if _, err := io.ReadFull(conn, size); err != nil {
+					return
+				}
+				n := binary.BigEndian.Uint32(size)
+				if n == 0 {
+					break
+				}
+				if _, err := io.CopyN(&content, conn, int64(n)); err != nil {
+					return
+				}
+			}
+			if strings.Contains(content.String(), eicar) {
+				conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+			} else {
+				conn.Write([]byte("stream: OK\x00"))
+			}
+		}(conn)
+	}
+}
+
+// testScanner returns a scanner connected to the clamd at CLAMD_ADDRESS if it is set, so these
+// tests can run against a real clamd, and otherwise to a fake clamd on a unix socket. The returned
+// function stops the fake clamd.
+func testScanner(t *testing.T) (*ClamdScanner, func()) {
+	stop := func() {}
+	address := os.Getenv("CLAMD_ADDRESS")
+	if address == "" {
+		dir, err := ioutil.TempDir("", "clamd")
+		if err != nil {
+			t.Fatal(err)
+		}
+		socket := filepath.Join(dir, "clamd.sock")
+		listener, err := net.Listen("unix", socket)
+		if err != nil {
+			t.Fatal(err)
+		}
+		go fakeClamd(listener)
+		stop = func() {
+			listener.Close()
+			os.RemoveAll(dir)
+		}
+		address = "unix://" + socket
+	}
+	scanner, err := NewClamdScanner(address, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return scanner, stop
+}
+
+func TestClamdScanner(t *testing.T) {
+	scanner, stop := testScanner(t)
+	defer stop()
+
+	result, err := scanner.Scan(strings.NewReader("%PDF-1.4 an ordinary document"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Infected {
+		t.Errorf("expected a clean file, got %v", result)
+	}
+
+	// Long enough to be streamed in several chunks
+	large := strings.Repeat("clean content ", clamdChunkSize/4)
+	result, err = scanner.Scan(strings.NewReader(large))
+	if err != nil || result.Infected {
+		t.Errorf("expected a clean file, got %v, %v", result, err)
+	}
+
+	result, err = scanner.Scan(strings.NewReader(eicar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Infected || !strings.Contains(result.Signature, "Eicar") {
+		t.Errorf("expected the EICAR signature, got %v", result)
+	}
+}
+
+func TestClamdScannerUnavailable(t *testing.T) {
+	scanner, err := NewClamdScanner("unix:///nonexistent/clamd.sock", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := scanner.Scan(strings.NewReader("data")); err == nil {
+		t.Error("expected an error when clamd is unavailable")
+	}
+}
+
+func TestNewClamdScannerAddress(t *testing.T) {
+	for _, address := range []string{"", "localhost:3310", "http://localhost:3310", "tcp://"} {
+		if _, err := NewClamdScanner(address, time.Second); err == nil {
+			t.Errorf("expected an error for address %q", address)
+		}
+	}
+}
+
+func TestParseClamdReply(t *testing.T) {
+	if _, err := parseClamdReply("INSTREAM size limit exceeded. ERROR"); err == nil {
+		t.Error("expected an error for a clamd error reply")
+	}
+}
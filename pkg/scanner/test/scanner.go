@@ -0,0 +1,44 @@
+package test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+
+	"github.com/transcom/mymove/pkg/scanner"
+)
+
+// InfectedMarker is content which FakeScanner reports as infected
+const InfectedMarker = "FAKE-MALWARE"
+
+// FakeScanner is used for local testing to stub out calls to clamd.
+type FakeScanner struct {
+	// Scanned counts the files scanned
+	Scanned     int
+	willSucceed bool
+}
+
+// Scan reports files containing InfectedMarker as infected.
+func (fake *FakeScanner) Scan(data io.Reader) (scanner.Result, error) {
+	content, err := ioutil.ReadAll(data)
+	if err != nil {
+		return scanner.Result{}, err
+	}
+	fake.Scanned++
+	if !fake.willSucceed {
+		return scanner.Result{}, errors.New("failed to scan")
+	}
+	if bytes.Contains(content, []byte(InfectedMarker)) {
+		return scanner.Result{Infected: true, Signature: "Fake-Test-Signature"}, nil
+	}
+	return scanner.Result{}, nil
+}
+
+// NewFakeScanner creates a new FakeScanner for testing purposes.
+func NewFakeScanner(willSucceed bool) *FakeScanner {
+	return &FakeScanner{
+		willSucceed: willSucceed,
+	}
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Config describes where stored files are kept, as given by the storage flags which the server
+// and the upload maintenance commands share.
+type Config struct {
+	// Backend is either "s3" or "filesystem"
+	Backend        string
+	S3Bucket       string
+	S3Region       string
+	S3KeyNamespace string
+	// URLExpiry is how long a URL to a stored file gives access to it, DefaultURLExpiry if unset
+	URLExpiry time.Duration
+	// URLSecret signs URLs to files in filesystem storage. A random one is used if it is empty,
+	// which is fine for commands which don't serve files.
+	URLSecret []byte
+	// EncryptionKeys are the comma separated id:key master keys stored files are encrypted with,
+	// as read by NewKeyring. Files aren't encrypted if it is empty.
+	EncryptionKeys string
+}
+
+// filesystemWebRoot is where files in filesystem storage are served
+const filesystemWebRoot = "/storage"
+
+// New creates the FileStorer config describes, encrypting files when it has encryption keys.
+// With the filesystem backend it also returns the handler which serves the stored files; it is
+// nil for S3, whose files are served by presigned URLs.
+func New(config Config, logger *zap.Logger) (FileStorer, http.HandlerFunc, error) {
+	urlExpiry := config.URLExpiry
+	if urlExpiry == 0 {
+		urlExpiry = DefaultURLExpiry
+	}
+
+	var storer FileStorer
+	var handler http.HandlerFunc
+	switch config.Backend {
+	case "s3":
+		if config.S3Bucket == "" {
+			return nil, nil, errors.New("must provide aws_s3_bucket_name parameter")
+		}
+		if config.S3Region == "" {
+			return nil, nil, errors.New("must provide aws_s3_region parameter")
+		}
+		if config.S3KeyNamespace == "" {
+			return nil, nil, errors.New("must provide aws_s3_key_namespace parameter")
+		}
+		awsSession, err := session.NewSession(&aws.Config{
+			Region: aws.String(config.S3Region),
+		})
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "could not create AWS session")
+		}
+		logger.Info("Using s3 storage backend", zap.String("bucket", config.S3Bucket))
+		storer = NewS3(config.S3Bucket, config.S3KeyNamespace, urlExpiry, logger, awsSession)
+	case "filesystem", "":
+		absTmpPath, err := filepath.Abs("tmp")
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "could not get absolute path for tmp")
+		}
+		urlSigner, err := NewURLSigner(config.URLSecret, urlExpiry)
+		if err != nil {
+			return nil, nil, err
+		}
+		root := filepath.Join(absTmpPath, "storage")
+		logger.Info("Using filesystem storage backend", zap.String("root", root))
+		storer = NewFilesystem(root, filesystemWebRoot, urlSigner, logger)
+		handler = NewFilesystemHandler(root, filesystemWebRoot, urlSigner, logger)
+	default:
+		return nil, nil, errors.Errorf("unknown storage backend %q, must be filesystem or s3", config.Backend)
+	}
+
+	if config.EncryptionKeys != "" {
+		keyring, err := NewKeyring(config.EncryptionKeys)
+		if err != nil {
+			return nil, nil, err
+		}
+		logger.Info("Encrypting stored files", zap.String("key_id", keyring.CurrentID()))
+		storer = NewEncrypted(storer, keyring, logger)
+	}
+	return storer, handler, nil
+}
@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestNew(t *testing.T) {
+	storer, handler, err := New(Config{Backend: "filesystem"}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("could not create filesystem storage: %s", err)
+	}
+	if _, ok := storer.(*Filesystem); !ok {
+		t.Errorf("expected filesystem storage, got %T", storer)
+	}
+	if handler == nil {
+		t.Error("expected a handler for filesystem storage")
+	}
+
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("k"), keySize))
+	storer, _, err = New(Config{Backend: "filesystem", EncryptionKeys: "k:" + key}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("could not create encrypted storage: %s", err)
+	}
+	if rotator, ok := storer.(KeyRotator); !ok || rotator.CurrentKeyID() != "k" {
+		t.Errorf("expected storage encrypted with k, got %T", storer)
+	}
+
+	invalid := []Config{
+		{Backend: "s3", S3Region: "us-west-2", S3KeyNamespace: "test"},
+		{Backend: "tape"},
+		{Backend: "filesystem", EncryptionKeys: "k:short"},
+	}
+	for _, config := range invalid {
+		if _, _, err := New(config, zap.NewNop()); err == nil {
+			t.Errorf("expected an error creating storage from %+v", config)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Encrypted objects start with encryptedMagic, followed by the length and ID of the master key,
+// the data key sealed with the master key, and the content sealed with the data key.
+const encryptedMagic = "MMENC1"
+
+const (
+	keySize      = 32
+	nonceSize    = 12
+	sealedKeyLen = nonceSize + keySize + 16
+)
+
+// ErrUnknownMasterKey is returned when an object was encrypted with a master key which isn't in
+// the Keyring
+var ErrUnknownMasterKey = errors.New("object was encrypted with an unknown master key")
+
+// Keyring holds the master keys which wrap the data keys of Encrypted objects, by ID. New
+// objects are encrypted with the current key; the others are kept to decrypt older objects
+// until they have been rewrapped.
+type Keyring struct {
+	currentID string
+	keys      map[string][]byte
+}
+
+// NewKeyring parses master keys from a comma separated list of id:key pairs, where each key
+// is 32 base64 encoded bytes. The first key is the current one.
+func NewKeyring(spec string) (*Keyring, error) {
+	keyring := &Keyring{keys: map[string][]byte{}}
+	for _, pair := range strings.Split(spec, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), ":", 2)
+		if len(parts) != 2 || parts[0] == "" || len(parts[0]) > 255 {
+			return nil, errors.Errorf("master keys must be given as id:key, got %q", pair)
+		}
+		id := parts[0]
+		key, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil || len(key) != keySize {
+			return nil, errors.Errorf("master key %s must be %d base64 encoded bytes", id, keySize)
+		}
+		if _, ok := keyring.keys[id]; ok {
+			return nil, errors.Errorf("master key %s is given more than once", id)
+		}
+		if keyring.currentID == "" {
+			keyring.currentID = id
+		}
+		keyring.keys[id] = key
+	}
+	return keyring, nil
+}
+
+// CurrentID returns the ID of the master key new objects are encrypted with
+func (k *Keyring) CurrentID() string {
+	return k.currentID
+}
+
+// Encrypted is a FileStorer which encrypts objects before passing them on to another. Each object
+// is encrypted with its own data key, which is stored with it wrapped by a master key. Its
+// objects can't be served directly, so PresignedURL returns ErrNoPresignedURL and they must be
+// streamed through the application with Fetch.
+//
+// Objects stored before encryption was enabled are returned by Fetch as they are, until they
+// are encrypted with Rewrap.
+type Encrypted struct {
+	inner  FileStorer
+	keys   *Keyring
+	logger *zap.Logger
+}
+
+// NewEncrypted creates a new Encrypted storing objects in inner.
+func NewEncrypted(inner FileStorer, keys *Keyring, logger *zap.Logger) *Encrypted {
+	return &Encrypted{inner, keys, logger}
+}
+
+// Store encrypts the content from an io.ReadSeeker and stores it at the specified key. checksum,
+// if given, is checked against the content before it is encrypted.
+func (e *Encrypted) Store(key string, data io.ReadSeeker, checksum string) (*StoreResult, error) {
+	plaintext, err := ioutil.ReadAll(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read content to encrypt")
+	}
+	if checksum != "" {
+		actual, err := ComputeChecksum(bytes.NewReader(plaintext))
+		if err != nil {
+			return nil, err
+		}
+		if actual != checksum {
+			return nil, errors.Errorf("checksum mismatch: expected %s, got %s", checksum, actual)
+		}
+	}
+
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, errors.Wrap(err, "could not generate data key")
+	}
+	header, err := e.header(e.keys.currentID, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := seal(dataKey, plaintext, []byte(key))
+	if err != nil {
+		return nil, err
+	}
+	return e.storeRaw(key, append(header, sealed...))
+}
+
+// Fetch returns the decrypted content of the object at the specified key.
+func (e *Encrypted) Fetch(key string) (io.ReadCloser, error) {
+	raw, err := e.fetchRaw(key)
+	if err != nil {
+		return nil, err
+	}
+	if !isEncrypted(raw) {
+		return ioutil.NopCloser(bytes.NewReader(raw)), nil
+	}
+
+	_, dataKey, sealed, err := e.open(raw)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := unseal(dataKey, sealed, []byte(key))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not decrypt %s", key)
+	}
+	return ioutil.NopCloser(bytes.NewReader(plaintext)), nil
+}
+
+// Rewrap encrypts the object at the specified key with the current master key. Only the
+// wrapped data key changes, unless the object wasn't encrypted at all.
+func (e *Encrypted) Rewrap(key string) (*StoreResult, error) {
+	raw, err := e.fetchRaw(key)
+	if err != nil {
+		return nil, err
+	}
+	if !isEncrypted(raw) {
+		return e.Store(key, bytes.NewReader(raw), "")
+	}
+
+	keyID, dataKey, sealed, err := e.open(raw)
+	if err != nil {
+		return nil, err
+	}
+	if keyID == e.keys.currentID {
+		return &StoreResult{KeyID: keyID}, nil
+	}
+	header, err := e.header(e.keys.currentID, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return e.storeRaw(key, append(header, sealed...))
+}
+
+// CurrentKeyID returns the ID of the master key new objects are encrypted with
+func (e *Encrypted) CurrentKeyID() string {
+	return e.keys.currentID
+}
+
+// Head returns information about the object at the specified key. The size and checksum of
+// the content aren't known without decrypting it.
+func (e *Encrypted) Head(key string) (*ObjectInfo, error) {
+	object, err := e.inner.Head(key)
+	if err != nil {
+		return nil, err
+	}
+	object.Bytes = -1
+	object.Checksum = ""
+	return object, nil
+}
+
+// List returns information about the objects whose keys start with prefix. The sizes and
+// checksums of their content aren't known without decrypting them.
+func (e *Encrypted) List(prefix string) ([]ObjectInfo, error) {
+	objects, err := e.inner.List(prefix)
+	if err != nil {
+		return nil, err
+	}
+	for i := range objects {
+		objects[i].Bytes = -1
+		objects[i].Checksum = ""
+	}
+	return objects, nil
+}
+
+// Delete deletes the object at the specified key
+func (e *Encrypted) Delete(key string) error {
+	return e.inner.Delete(key)
+}
+
+// Key returns a key built using the specified args.
+func (e *Encrypted) Key(args ...string) string {
+	return e.inner.Key(args...)
+}
+
+// PresignedURL returns ErrNoPresignedURL, as stored objects are only readable once decrypted.
+func (e *Encrypted) PresignedURL(key string, contentType string) (string, error) {
+	return "", ErrNoPresignedURL
+}
+
+func (e *Encrypted) fetchRaw(key string) ([]byte, error) {
+	object, err := e.inner.Fetch(key)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+	raw, err := ioutil.ReadAll(object)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read stored object")
+	}
+	return raw, nil
+}
+
+func (e *Encrypted) storeRaw(key string, raw []byte) (*StoreResult, error) {
+	checksum, err := ComputeChecksum(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := e.inner.Store(key, bytes.NewReader(raw), checksum); err != nil {
+		return nil, err
+	}
+	return &StoreResult{KeyID: e.keys.currentID}, nil
+}
+
+// header returns the start of an encrypted object, identifying masterKeyID and holding dataKey
+// wrapped by it
+func (e *Encrypted) header(masterKeyID string, dataKey []byte) ([]byte, error) {
+	wrapped, err := seal(e.keys.keys[masterKeyID], dataKey, []byte(masterKeyID))
+	if err != nil {
+		return nil, err
+	}
+	header := []byte(encryptedMagic)
+	header = append(header, byte(len(masterKeyID)))
+	header = append(header, masterKeyID...)
+	return append(header, wrapped...), nil
+}
+
+// open parses an encrypted object, returning the ID of its master key, its unwrapped data key
+// and its sealed content
+func (e *Encrypted) open(raw []byte) (string, []byte, []byte, error) {
+	rest := raw[len(encryptedMagic):]
+	if len(rest) < 1 || len(rest) < 1+int(rest[0])+sealedKeyLen {
+		return "", nil, nil, errors.New("encrypted object is truncated")
+	}
+	keyIDLen := int(rest[0])
+	keyID := string(rest[1 : 1+keyIDLen])
+	rest = rest[1+keyIDLen:]
+
+	masterKey, ok := e.keys.keys[keyID]
+	if !ok {
+		return "", nil, nil, errors.Wrapf(ErrUnknownMasterKey, "master key %s", keyID)
+	}
+	dataKey, err := unseal(masterKey, rest[:sealedKeyLen], []byte(keyID))
+	if err != nil {
+		return "", nil, nil, errors.Wrap(err, "could not unwrap data key")
+	}
+	return keyID, dataKey, rest[sealedKeyLen:], nil
+}
+
+func isEncrypted(raw []byte) bool {
+	return bytes.HasPrefix(raw, []byte(encryptedMagic))
+}
+
+// seal encrypts plaintext with AES-GCM under key, binding it to additionalData, and returns
+// the nonce followed by the ciphertext
+func seal(key []byte, plaintext []byte, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "could not generate nonce")
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// unseal reverses seal
+func unseal(key []byte, sealed []byte, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < nonceSize {
+		return nil, errors.New("sealed data is truncated")
+	}
+	return aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], additionalData)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create cipher")
+	}
+	return cipher.NewGCM(block)
+}
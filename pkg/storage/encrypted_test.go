@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func newTestKeyring(t *testing.T, ids ...string) *Keyring {
+	pairs := make([]string, len(ids))
+	for i, id := range ids {
+		pairs[i] = id + ":" + base64.StdEncoding.EncodeToString(bytes.Repeat([]byte(id[:1]), keySize))
+	}
+	keyring, err := NewKeyring(strings.Join(pairs, ","))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+func fetchString(t *testing.T, storer FileStorer, key string) string {
+	file, err := storer.Fetch(key)
+	if err != nil {
+		t.Fatalf("could not fetch %s: %s", key, err)
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestEncrypted(t *testing.T) {
+	root, err := ioutil.TempDir("", "storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	fs := NewFilesystem(root, "/storage", newTestSigner(t), zap.NewNop())
+	encrypted := NewEncrypted(fs, newTestKeyring(t, "old"), zap.NewNop())
+
+	checksum, _ := ComputeChecksum(strings.NewReader("content"))
+	result, err := encrypted.Store("documents/1/uploads/a", strings.NewReader("content"), checksum)
+	if err != nil {
+		t.Fatalf("could not store file: %s", err)
+	}
+	if result.KeyID != "old" {
+		t.Errorf("wrong key ID: expected old, got %s", result.KeyID)
+	}
+	if raw := fetchString(t, fs, "documents/1/uploads/a"); strings.Contains(raw, "content") {
+		t.Error("stored file isn't encrypted")
+	}
+	if content := fetchString(t, encrypted, "documents/1/uploads/a"); content != "content" {
+		t.Errorf("wrong content: expected content, got %s", content)
+	}
+	if _, err := encrypted.Store("documents/1/uploads/b", strings.NewReader("other"), checksum); err == nil {
+		t.Error("expected an error storing content which doesn't match its checksum")
+	}
+	if _, err := encrypted.PresignedURL("documents/1/uploads/a", "application/pdf"); err != ErrNoPresignedURL {
+		t.Errorf("expected ErrNoPresignedURL, got %v", err)
+	}
+
+	// Objects can't be moved to another key
+	raw := fetchString(t, fs, "documents/1/uploads/a")
+	fs.Store("documents/1/uploads/c", strings.NewReader(raw), "")
+	if _, err := encrypted.Fetch("documents/1/uploads/c"); err == nil {
+		t.Error("expected an error fetching a file moved to another key")
+	}
+
+	// Rotating the master key rewraps the data key, and the old one is needed until then
+	rotated := NewEncrypted(fs, newTestKeyring(t, "new", "old"), zap.NewNop())
+	if content := fetchString(t, rotated, "documents/1/uploads/a"); content != "content" {
+		t.Errorf("wrong content: expected content, got %s", content)
+	}
+	result, err = rotated.Rewrap("documents/1/uploads/a")
+	if err != nil || result.KeyID != "new" {
+		t.Fatalf("could not rewrap file: %v %v", result, err)
+	}
+	if content := fetchString(t, NewEncrypted(fs, newTestKeyring(t, "new"), zap.NewNop()), "documents/1/uploads/a"); content != "content" {
+		t.Errorf("wrong content: expected content, got %s", content)
+	}
+	if _, err := encrypted.Fetch("documents/1/uploads/a"); err == nil {
+		t.Error("expected an error fetching a file with a missing master key")
+	}
+
+	// Files stored before encryption are read as they are, until they are rewrapped
+	fs.Store("documents/1/uploads/plain", strings.NewReader("plain"), "")
+	if content := fetchString(t, rotated, "documents/1/uploads/plain"); content != "plain" {
+		t.Errorf("wrong content: expected plain, got %s", content)
+	}
+	if _, err := rotated.Rewrap("documents/1/uploads/plain"); err != nil {
+		t.Fatalf("could not encrypt file: %s", err)
+	}
+	if raw := fetchString(t, fs, "documents/1/uploads/plain"); strings.Contains(raw, "plain") {
+		t.Error("rewrapped file isn't encrypted")
+	}
+	if content := fetchString(t, rotated, "documents/1/uploads/plain"); content != "plain" {
+		t.Errorf("wrong content: expected plain, got %s", content)
+	}
+
+	object, err := rotated.Head("documents/1/uploads/a")
+	if err != nil || object.Bytes != -1 || object.Checksum != "" {
+		t.Errorf("wrong file info: %v %v", object, err)
+	}
+}
+
+func TestNewKeyring(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("k"), keySize))
+	keyring, err := NewKeyring("b:" + key + ", a:" + key)
+	if err != nil || keyring.CurrentID() != "b" {
+		t.Errorf("wrong keyring: %v %v", keyring, err)
+	}
+	for _, spec := range []string{"", key, "a:short", "a:" + key + ",a:" + key} {
+		if _, err := NewKeyring(spec); err == nil {
+			t.Errorf("expected an error parsing %q", spec)
+		}
+	}
+}
@@ -3,26 +3,27 @@ package storage
 import (
 	"io"
 	"net/http"
-	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
-// Filesystem is a storage backend that uses the local filesystem. It is intended only
-// for use in development to avoid dependency on an external service.
+// Filesystem is a storage backend that uses the local filesystem. Its files are served
+// by NewFilesystemHandler at webRoot, to anyone with a URL signed by signer.
 type Filesystem struct {
 	root    string
 	webRoot string
+	signer  *URLSigner
 	logger  *zap.Logger
 }
 
-// NewFilesystem creates a new S3 using the provided AWS session.
-func NewFilesystem(root string, webRoot string, logger *zap.Logger) *Filesystem {
-	return &Filesystem{root, webRoot, logger}
+// NewFilesystem creates a new Filesystem storing files under root.
+func NewFilesystem(root string, webRoot string, signer *URLSigner, logger *zap.Logger) *Filesystem {
+	return &Filesystem{root, webRoot, signer, logger}
 }
 
 // Store stores the content from an io.ReadSeeker at the specified key.
@@ -51,6 +52,79 @@ func (fs *Filesystem) Store(key string, data io.ReadSeeker, checksum string) (*S
 	return &StoreResult{}, nil
 }
 
+// Fetch returns the content of the file at the specified key.
+func (fs *Filesystem) Fetch(key string) (io.ReadCloser, error) {
+	joined := filepath.Join(fs.root, key)
+
+	file, err := os.Open(joined)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open file")
+	}
+	return file, nil
+}
+
+// Head returns information about the file at the specified key, or ErrNotFound.
+func (fs *Filesystem) Head(key string) (*ObjectInfo, error) {
+	joined := filepath.Join(fs.root, key)
+
+	info, err := os.Stat(joined)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(err, "could not stat file")
+	}
+	return fs.objectInfo(key, joined, info)
+}
+
+// List returns information about the files under the directory prefix.
+func (fs *Filesystem) List(prefix string) ([]ObjectInfo, error) {
+	objects := []ObjectInfo{}
+	root := filepath.Join(fs.root, prefix)
+	err := filepath.Walk(root, func(joined string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) && joined == root {
+			return filepath.SkipDir
+		} else if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relative, err := filepath.Rel(fs.root, joined)
+		if err != nil {
+			return err
+		}
+		object, err := fs.objectInfo(filepath.ToSlash(relative), joined, info)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, *object)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list files")
+	}
+	return objects, nil
+}
+
+// objectInfo describes the file at joined, reading it to compute its checksum
+func (fs *Filesystem) objectInfo(key string, joined string, info os.FileInfo) (*ObjectInfo, error) {
+	file, err := os.Open(joined)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open file")
+	}
+	defer file.Close()
+	checksum, err := ComputeChecksum(file)
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectInfo{
+		Key:          key,
+		Bytes:        info.Size(),
+		Checksum:     checksum,
+		LastModified: info.ModTime(),
+	}, nil
+}
+
 // Delete deletes the file at the specified key
 func (fs *Filesystem) Delete(key string) error {
 	joined := filepath.Join(fs.root, key)
@@ -63,24 +137,37 @@ func (fs *Filesystem) Key(args ...string) string {
 	return path.Join(args...)
 }
 
-// PresignedURL returns a URL that provides access to a file for 15 mintes.
+// PresignedURL returns a URL that provides access to a file, as contentType, until the
+// signer's expiry has passed.
 func (fs *Filesystem) PresignedURL(key, contentType string) (string, error) {
-	values := url.Values{}
-	values.Add("contentType", contentType)
-	url := fs.webRoot + "/" + key + "?" + values.Encode()
+	url := fs.webRoot + "/" + key + "?" + fs.signer.Sign(key, contentType).Encode()
 	return url, nil
 }
 
-// NewFilesystemHandler returns an Handler that adds a Content-Type header so that
-// files are handled properly by the browser.
-func NewFilesystemHandler(root string) http.HandlerFunc {
+// NewFilesystemHandler returns a Handler that serves the files of a Filesystem storing
+// them under root, at webRoot. Requests must carry a signature from signer, which binds
+// the Content-Type header the file is served with.
+func NewFilesystemHandler(root string, webRoot string, signer *URLSigner, logger *zap.Logger) http.HandlerFunc {
+	prefix := strings.TrimSuffix(webRoot, "/") + "/"
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		contentType := r.URL.Query().Get("contentType")
-		if contentType != "" {
-			w.Header().Add("Content-Type", contentType)
+		cleaned := path.Clean("/" + r.URL.Path)
+		if !strings.HasPrefix(cleaned, prefix) {
+			http.NotFound(w, r)
+			return
+		}
+		key := strings.TrimPrefix(cleaned, prefix)
+
+		values := r.URL.Query()
+		if err := signer.Verify(key, values); err != nil {
+			logger.Info("refused access to stored file", zap.String("key", key), zap.Error(err))
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
 		}
 
-		input := filepath.Join(root, filepath.FromSlash(path.Clean("/"+r.URL.Path)))
-		http.ServeFile(w, r, input)
+		if contentType := values.Get("contentType"); contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		http.ServeFile(w, r, filepath.Join(root, filepath.FromSlash(key)))
 	})
 }
@@ -1,22 +1,158 @@
 package storage
 
 import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"go.uber.org/zap"
 )
 
+func newTestSigner(t *testing.T) *URLSigner {
+	signer, err := NewURLSigner([]byte("secret"), DefaultURLExpiry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
 func TestPresignedURL(t *testing.T) {
 	logger := zap.NewNop()
-	fs := NewFilesystem("/home/username", "https://example.text/files", logger)
+	signer := newTestSigner(t)
+	fs := NewFilesystem("/home/username", "https://example.text/files", signer, logger)
 
-	url, err := fs.PresignedURL("key/to/file/12345", "image/jpeg")
+	presigned, err := fs.PresignedURL("key/to/file/12345", "image/jpeg")
 	if err != nil {
 		t.Fatalf("could not get presigned url: %s", err)
 	}
 
-	expected := "https://example.text/files/key/to/file/12345?contentType=image%2Fjpeg"
-	if url != expected {
-		t.Errorf("wrong presigned url: expected %s, got %s", expected, url)
+	parsed, err := url.Parse(presigned)
+	if err != nil {
+		t.Fatalf("could not parse presigned url: %s", err)
+	}
+	expected := "https://example.text/files/key/to/file/12345"
+	if parsed.Scheme+"://"+parsed.Host+parsed.Path != expected {
+		t.Errorf("wrong presigned url: expected %s, got %s", expected, presigned)
+	}
+	if parsed.Query().Get("contentType") != "image/jpeg" {
+		t.Errorf("wrong content type in presigned url %s", presigned)
+	}
+	if err := signer.Verify("key/to/file/12345", parsed.Query()); err != nil {
+		t.Errorf("presigned url %s doesn't verify: %s", presigned, err)
+	}
+}
+
+func TestFilesystemHandler(t *testing.T) {
+	root, err := ioutil.TempDir("", "storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	signer := newTestSigner(t)
+	fs := NewFilesystem(root, "/storage", signer, zap.NewNop())
+	handler := NewFilesystemHandler(root, "/storage", signer, zap.NewNop())
+
+	if _, err := fs.Store("documents/1/uploads/a", strings.NewReader("content"), ""); err != nil {
+		t.Fatalf("could not store file: %s", err)
+	}
+	presigned, err := fs.PresignedURL("documents/1/uploads/a", "application/pdf")
+	if err != nil {
+		t.Fatalf("could not get presigned url: %s", err)
+	}
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", target, nil))
+		return rr
+	}
+
+	rr := get(presigned)
+	if rr.Code != http.StatusOK || rr.Body.String() != "content" {
+		t.Errorf("wrong response to a signed url: %d %s", rr.Code, rr.Body.String())
+	}
+	if contentType := rr.Header().Get("Content-Type"); contentType != "application/pdf" {
+		t.Errorf("wrong content type: expected application/pdf, got %s", contentType)
+	}
+
+	forbidden := []string{
+		"/storage/documents/1/uploads/a",
+		strings.Replace(presigned, "uploads/a", "uploads/../uploads/b", 1),
+		strings.Replace(presigned, "application%2Fpdf", "text%2Fhtml", 1),
+		"/storage/documents/1/uploads/a?" + signer.signUntil("documents/1/uploads/a", "application/pdf", time.Now().Add(-time.Minute)).Encode(),
+	}
+	for _, target := range forbidden {
+		if rr := get(target); rr.Code != http.StatusForbidden {
+			t.Errorf("expected %s to be forbidden, got %d", target, rr.Code)
+		}
+	}
+}
+
+func TestFetch(t *testing.T) {
+	root, err := ioutil.TempDir("", "storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	fs := NewFilesystem(root, "https://example.text/files", newTestSigner(t), zap.NewNop())
+
+	if _, err := fs.Store("key/to/file/12345", strings.NewReader("content"), ""); err != nil {
+		t.Fatalf("could not store file: %s", err)
+	}
+	file, err := fs.Fetch("key/to/file/12345")
+	if err != nil {
+		t.Fatalf("could not fetch file: %s", err)
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil || string(content) != "content" {
+		t.Errorf("wrong content: expected content, got %s (%v)", content, err)
+	}
+
+	if _, err := fs.Fetch("key/to/missing"); err == nil {
+		t.Error("expected an error fetching a missing file")
+	}
+}
+
+func TestListAndHead(t *testing.T) {
+	root, err := ioutil.TempDir("", "storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	fs := NewFilesystem(root, "https://example.text/files", newTestSigner(t), zap.NewNop())
+
+	for _, key := range []string{"documents/1/uploads/a", "documents/2/uploads/b", "other/c"} {
+		if _, err := fs.Store(key, strings.NewReader("content"), ""); err != nil {
+			t.Fatalf("could not store file: %s", err)
+		}
+	}
+
+	objects, err := fs.List("documents/")
+	if err != nil {
+		t.Fatalf("could not list files: %s", err)
+	}
+	if len(objects) != 2 || objects[0].Key != "documents/1/uploads/a" || objects[1].Key != "documents/2/uploads/b" {
+		t.Errorf("wrong files listed: %v", objects)
+	}
+
+	object, err := fs.Head("other/c")
+	if err != nil {
+		t.Fatalf("could not head file: %s", err)
+	}
+	// the base64 encoded md5 of "content"
+	if object.Bytes != 7 || object.Checksum != "mgNkuembtIDdJeHwKEyFVQ==" {
+		t.Errorf("wrong file info: %v", object)
+	}
+
+	if _, err := fs.Head("other/missing"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if objects, err := fs.List("missing/"); err != nil || len(objects) != 0 {
+		t.Errorf("expected no files, got %v (%v)", objects, err)
 	}
 }
@@ -1,10 +1,20 @@
 package storage
 
 import (
+	/*
+		#nosec - md5 is only used to read S3 ETags
+	*/
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
 	"io"
+	"net/http"
 	"path"
+	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/pkg/errors"
@@ -15,14 +25,15 @@ import (
 type S3 struct {
 	bucket       string
 	keyNamespace string
+	urlExpiry    time.Duration
 	logger       *zap.Logger
 	client       *s3.S3
 }
 
-// NewS3 creates a new S3 using the provided AWS session.
-func NewS3(bucket string, keyNamespace string, logger *zap.Logger, session *session.Session) *S3 {
+// NewS3 creates a new S3 using the provided AWS session. Presigned URLs expire after urlExpiry.
+func NewS3(bucket string, keyNamespace string, urlExpiry time.Duration, logger *zap.Logger, session *session.Session) *S3 {
 	client := s3.New(session)
-	return &S3{bucket, keyNamespace, logger, client}
+	return &S3{bucket, keyNamespace, urlExpiry, logger, client}
 }
 
 // Store stores the content from an io.ReadSeeker at the specified key.
@@ -41,6 +52,81 @@ func (s *S3) Store(key string, data io.ReadSeeker, checksum string) (*StoreResul
 	return &StoreResult{}, nil
 }
 
+// Fetch returns the content of the object at a specified key
+func (s *S3) Fetch(key string) (io.ReadCloser, error) {
+	input := &s3.GetObjectInput{
+		Bucket: &s.bucket,
+		Key:    &key,
+	}
+
+	output, err := s.client.GetObject(input)
+	if err != nil {
+		return nil, errors.Wrap(err, "get from S3 failed")
+	}
+
+	return output.Body, nil
+}
+
+// Head returns information about the object at a specified key, or ErrNotFound
+func (s *S3) Head(key string) (*ObjectInfo, error) {
+	input := &s3.HeadObjectInput{
+		Bucket: &s.bucket,
+		Key:    &key,
+	}
+
+	output, err := s.client.HeadObject(input)
+	if err != nil {
+		if failure, ok := err.(awserr.RequestFailure); ok && failure.StatusCode() == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, errors.Wrap(err, "head from S3 failed")
+	}
+
+	return &ObjectInfo{
+		Key:          key,
+		Bytes:        aws.Int64Value(output.ContentLength),
+		Checksum:     checksumFromETag(aws.StringValue(output.ETag)),
+		LastModified: aws.TimeValue(output.LastModified),
+	}, nil
+}
+
+// List returns information about the objects whose keys start with prefix
+func (s *S3) List(prefix string) ([]ObjectInfo, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket: &s.bucket,
+		Prefix: &prefix,
+	}
+
+	objects := []ObjectInfo{}
+	err := s.client.ListObjectsV2Pages(input, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			objects = append(objects, ObjectInfo{
+				Key:          aws.StringValue(object.Key),
+				Bytes:        aws.Int64Value(object.Size),
+				Checksum:     checksumFromETag(aws.StringValue(object.ETag)),
+				LastModified: aws.TimeValue(object.LastModified),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list from S3 failed")
+	}
+
+	return objects, nil
+}
+
+// checksumFromETag returns the base64 encoded MD5 of an object given its ETag. The ETag of an
+// object uploaded in one part is its hex encoded MD5; for multipart uploads it is something else
+// entirely, and "" is returned.
+func checksumFromETag(etag string) string {
+	sum, err := hex.DecodeString(strings.Trim(etag, `"`))
+	if err != nil || len(sum) != md5.Size {
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString(sum)
+}
+
 // Delete deletes an object at a specified key
 func (s *S3) Delete(key string) error {
 	input := &s3.DeleteObjectInput{
@@ -62,14 +148,14 @@ func (s *S3) Key(args ...string) string {
 	return path.Join(args...)
 }
 
-// PresignedURL returns a URL that provides access to a file for 15 minutes.
+// PresignedURL returns a URL that provides access to a file until the configured expiry has passed.
 func (s *S3) PresignedURL(key string, contentType string) (string, error) {
 	req, _ := s.client.GetObjectRequest(&s3.GetObjectInput{
 		Bucket:              &s.bucket,
 		Key:                 &key,
 		ResponseContentType: &contentType,
 	})
-	url, err := req.Presign(15 * time.Minute)
+	url, err := req.Presign(s.urlExpiry)
 	if err != nil {
 		return "", errors.Wrap(err, "could not generate presigned URL")
 	}
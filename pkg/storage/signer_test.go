@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestURLSigner(t *testing.T) {
+	signer, err := NewURLSigner([]byte("secret"), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := signer.Sign("documents/1/uploads/a", "image/png")
+	if err := signer.Verify("documents/1/uploads/a", values); err != nil {
+		t.Errorf("signed values don't verify: %s", err)
+	}
+	if err := signer.Verify("documents/1/uploads/b", values); err != ErrURLSignature {
+		t.Errorf("expected ErrURLSignature for another key, got %v", err)
+	}
+
+	other, err := NewURLSigner([]byte("other secret"), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Verify("documents/1/uploads/a", values); err != ErrURLSignature {
+		t.Errorf("expected ErrURLSignature for another secret, got %v", err)
+	}
+
+	expired := signer.signUntil("documents/1/uploads/a", "image/png", time.Now().Add(-time.Second))
+	if err := signer.Verify("documents/1/uploads/a", expired); err != ErrURLExpired {
+		t.Errorf("expected ErrURLExpired, got %v", err)
+	}
+
+	extended := signer.Sign("documents/1/uploads/a", "image/png")
+	extended.Set("expires", "99999999999")
+	if err := signer.Verify("documents/1/uploads/a", extended); err != ErrURLSignature {
+		t.Errorf("expected ErrURLSignature for a changed expiry, got %v", err)
+	}
+}
+
+func TestRandomURLSigner(t *testing.T) {
+	first, err := NewURLSigner(nil, DefaultURLExpiry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewURLSigner(nil, DefaultURLExpiry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := second.Verify("key", first.Sign("key", "image/png")); err != ErrURLSignature {
+		t.Errorf("expected random secrets to differ, got %v", err)
+	}
+	if _, err := NewURLSigner(nil, 0); err == nil {
+		t.Error("expected an error for a zero expiry")
+	}
+}
@@ -7,24 +7,59 @@ import (
 		https://aws.amazon.com/premiumsupport/knowledge-center/data-integrity-s3/
 	*/
 	"crypto/md5"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
 // StoreResult represents the result of a call to Store().
-type StoreResult struct{}
+type StoreResult struct {
+	// KeyID is the ID of the master key an object was encrypted with, or "" if it wasn't
+	KeyID string
+}
+
+// ErrNotFound is returned by Head when there is no object at a key
+var ErrNotFound = errors.New("object not found")
+
+// ErrNoPresignedURL is returned by PresignedURL when objects can't be accessed directly, and
+// must be fetched and served by the application instead
+var ErrNoPresignedURL = errors.New("objects can't be accessed directly")
+
+// ObjectInfo describes a stored object.
+type ObjectInfo struct {
+	Key string
+	// Bytes is the size of the object, or -1 if the backend doesn't know the size of its
+	// content, as with encrypted objects.
+	Bytes int64
+	// Checksum is the base64 encoded MD5 of the object, like the checksums of Uploads. It is
+	// empty if the backend doesn't know it, as with objects uploaded to S3 in parts.
+	Checksum     string
+	LastModified time.Time
+}
 
 // FileStorer is the set of methods needed to store and retrieve objects.
 type FileStorer interface {
 	Store(string, io.ReadSeeker, string) (*StoreResult, error)
+	Fetch(string) (io.ReadCloser, error)
+	Head(string) (*ObjectInfo, error)
+	List(string) ([]ObjectInfo, error)
 	Delete(string) error
 	Key(...string) string
 	PresignedURL(string, string) (string, error)
 }
 
+// KeyRotator is implemented by FileStorers which encrypt objects. Rewrap re-encrypts the object
+// at a key with the current master key.
+type KeyRotator interface {
+	Rewrap(string) (*StoreResult, error)
+	CurrentKeyID() string
+}
+
 // ComputeChecksum calculates the MD% checksum for the provided data. It expects that
 // the passed io object will be seeked to its beginning and will seek back to the
 // beginning after reading its content.
@@ -45,6 +80,21 @@ func ComputeChecksum(data io.ReadSeeker) (string, error) {
 	return base64.StdEncoding.EncodeToString(hash.Sum(nil)), nil
 }
 
+// ComputeSHA256 calculates the hex encoded SHA-256 hash of the provided data, which unlike
+// the MD5 checksum is strong enough to identify files by their content. Like ComputeChecksum,
+// it seeks back to the beginning after reading.
+func ComputeSHA256(data io.ReadSeeker) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, data); err != nil {
+		return "", errors.Wrap(err, "could not read file")
+	}
+
+	if _, err := data.Seek(0, io.SeekStart); err != nil { // seek back to beginning of file
+		return "", errors.Wrap(err, "could not seek to beginning of file")
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
 // DetectContentType leverages http.DetectContentType to identify the content type
 // of the provided data. It expects that the passed io object will be seeked to its
 // beginning and will seek back to the beginning after reading its content.
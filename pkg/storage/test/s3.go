@@ -3,7 +3,10 @@ package test
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"path"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -15,6 +18,7 @@ type PutFile struct {
 	Key      string
 	Body     io.ReadSeeker
 	Checksum string
+	StoredAt time.Time
 }
 
 // FakeS3Storage is used for local testing to stub out calls to S3.
@@ -45,12 +49,62 @@ func (fake *FakeS3Storage) Delete(key string) error {
 	return nil
 }
 
+// Fetch returns the content of a stored file.
+func (fake *FakeS3Storage) Fetch(key string) (io.ReadCloser, error) {
+	for _, f := range fake.PutFiles {
+		if f.Key == key {
+			if _, err := f.Body.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+			return ioutil.NopCloser(f.Body), nil
+		}
+	}
+	return nil, errors.New("can't fetch item that doesn't exist")
+}
+
+// Head returns information about a stored file.
+func (fake *FakeS3Storage) Head(key string) (*storage.ObjectInfo, error) {
+	for _, f := range fake.PutFiles {
+		if f.Key == key {
+			return fake.objectInfo(f)
+		}
+	}
+	return nil, storage.ErrNotFound
+}
+
+// List returns information about the stored files whose keys start with prefix.
+func (fake *FakeS3Storage) List(prefix string) ([]storage.ObjectInfo, error) {
+	objects := []storage.ObjectInfo{}
+	for _, f := range fake.PutFiles {
+		if strings.HasPrefix(f.Key, prefix) {
+			object, err := fake.objectInfo(f)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, *object)
+		}
+	}
+	return objects, nil
+}
+
+func (fake *FakeS3Storage) objectInfo(f PutFile) (*storage.ObjectInfo, error) {
+	size, err := f.Body.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Body.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return &storage.ObjectInfo{Key: f.Key, Bytes: size, Checksum: f.Checksum, LastModified: f.StoredAt}, nil
+}
+
 // Store stores a file.
 func (fake *FakeS3Storage) Store(key string, data io.ReadSeeker, md5 string) (*storage.StoreResult, error) {
 	file := PutFile{
 		Key:      key,
 		Body:     data,
 		Checksum: md5,
+		StoredAt: time.Now(),
 	}
 	fake.PutFiles = append(fake.PutFiles, file)
 	buf := []byte{}
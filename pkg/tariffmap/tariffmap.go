@@ -0,0 +1,132 @@
+// Package tariffmap exports the 400NG tariff's ZIP3 assignments as GeoJSON, so that rate areas,
+// regions and service areas can be drawn on a map. Each ZIP3 is a point at the centroid of its
+// ZIP5s, grouped and colored by the area chosen, which makes mis-assigned ZIPs stand out among
+// their neighbors.
+package tariffmap
+
+import (
+	"hash/fnv"
+	"time"
+
+	"github.com/gobuffalo/pop"
+	"github.com/pkg/errors"
+
+	"github.com/transcom/mymove/pkg/models"
+)
+
+// GroupBy is the tariff area ZIP3s are grouped and colored by
+type GroupBy string
+
+const (
+	// GroupByRateArea groups ZIP3s by rate area, e.g. US87
+	GroupByRateArea GroupBy = "rate_area"
+	// GroupByRegion groups ZIP3s by region, e.g. 2
+	GroupByRegion GroupBy = "region"
+	// GroupByServiceArea groups ZIP3s by service area, e.g. 80
+	GroupByServiceArea GroupBy = "service_area"
+)
+
+func (g GroupBy) valid() bool {
+	return g == GroupByRateArea || g == GroupByRegion || g == GroupByServiceArea
+}
+
+// ErrInvalidGroupBy is returned for a GroupBy other than the ones above
+var ErrInvalidGroupBy = errors.New("group by must be rate_area, region or service_area")
+
+// palette holds the colors given to groups. Neighboring groups usually get different colors,
+// though with more groups than colors some neighbors will match.
+var palette = []string{
+	"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b",
+	"#e377c2", "#7f7f7f", "#bcbd22", "#17becf", "#393b79", "#637939",
+}
+
+// Point is a GeoJSON Point geometry. Coordinates are longitude then latitude.
+type Point struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// Feature is a GeoJSON Feature
+type Feature struct {
+	Type       string                 `json:"type"`
+	Geometry   Point                  `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// FeatureCollection is a GeoJSON FeatureCollection
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// color returns the palette color for a group
+func color(group string) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(group))
+	return palette[hash.Sum32()%uint32(len(palette))]
+}
+
+// group returns the value of the area centroid is grouped by
+func group(centroid models.Zip3Centroid, groupBy GroupBy) string {
+	switch groupBy {
+	case GroupByRegion:
+		return centroid.Region
+	case GroupByServiceArea:
+		return centroid.ServiceArea
+	}
+	return centroid.RateArea
+}
+
+// Build returns a FeatureCollection with a point for each centroid. Each point's properties hold
+// the ZIP3's areas and service area rates, along with the group it is in and a marker-color
+// (see https://github.com/mapbox/simplestyle-spec) shared by the group.
+func Build(centroids models.Zip3Centroids, groupBy GroupBy) (FeatureCollection, error) {
+	if !groupBy.valid() {
+		return FeatureCollection{}, ErrInvalidGroupBy
+	}
+
+	collection := FeatureCollection{Type: "FeatureCollection", Features: []Feature{}}
+	for _, centroid := range centroids {
+		g := group(centroid, groupBy)
+		properties := map[string]interface{}{
+			"zip3":           centroid.Zip3,
+			"basepoint_city": centroid.BasepointCity,
+			"state":          centroid.State,
+			"rate_area":      centroid.RateArea,
+			"region":         centroid.Region,
+			"service_area":   centroid.ServiceArea,
+			"split":          centroid.Split,
+			"zip5_count":     centroid.Zip5Count,
+			"group":          g,
+			"marker-color":   color(g),
+		}
+		if centroid.ServiceAreaName != nil {
+			properties["service_area_name"] = *centroid.ServiceAreaName
+		}
+		if centroid.ServicesSchedule != nil {
+			properties["services_schedule"] = *centroid.ServicesSchedule
+		}
+		if centroid.ServiceChargeCents != nil {
+			properties["service_charge_cents"] = centroid.ServiceChargeCents.Int()
+		}
+		collection.Features = append(collection.Features, Feature{
+			Type:       "Feature",
+			Geometry:   Point{Type: "Point", Coordinates: [2]float64{centroid.Longitude, centroid.Latitude}},
+			Properties: properties,
+		})
+	}
+	return collection, nil
+}
+
+// Fetch returns a FeatureCollection of every ZIP3 with known ZIP5 locations, grouped by groupBy,
+// with the service area rates effective on date
+func Fetch(db *pop.Connection, groupBy GroupBy, date time.Time) (FeatureCollection, error) {
+	if !groupBy.valid() {
+		return FeatureCollection{}, ErrInvalidGroupBy
+	}
+	centroids, err := models.FetchZip3Centroids(db, date)
+	if err != nil {
+		return FeatureCollection{}, err
+	}
+	return Build(centroids, groupBy)
+}
@@ -0,0 +1,59 @@
+package tariffmap
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/unit"
+)
+
+func TestBuild(t *testing.T) {
+	name := "San Francisco, CA"
+	schedule := 3
+	charge := unit.Cents(350)
+	centroids := models.Zip3Centroids{
+		{Zip3: "322", RateArea: "US4964400", Region: "13", ServiceArea: "184", Split: true, Latitude: 30.5, Longitude: -81.5, Zip5Count: 2},
+		{Zip3: "940", RateArea: "US87", Region: "2", ServiceArea: "80", Latitude: 37.5, Longitude: -122.2, Zip5Count: 40},
+		{Zip3: "941", RateArea: "US87", Region: "2", ServiceArea: "80", Latitude: 37.7, Longitude: -122.4, Zip5Count: 30,
+			ServiceAreaName: &name, ServicesSchedule: &schedule, ServiceChargeCents: &charge},
+	}
+
+	collection, err := Build(centroids, GroupByRegion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if collection.Type != "FeatureCollection" || len(collection.Features) != 3 {
+		t.Fatalf("expected a collection of 3 features, got %v", collection)
+	}
+	sanFrancisco := collection.Features[2]
+	if sanFrancisco.Geometry.Coordinates != [2]float64{-122.4, 37.7} {
+		t.Errorf("expected longitude then latitude, got %v", sanFrancisco.Geometry.Coordinates)
+	}
+	if sanFrancisco.Properties["group"] != "2" || sanFrancisco.Properties["service_charge_cents"] != 350 || sanFrancisco.Properties["services_schedule"] != 3 {
+		t.Errorf("unexpected properties %v", sanFrancisco.Properties)
+	}
+	if _, ok := collection.Features[0].Properties["service_charge_cents"]; ok {
+		t.Error("expected no service charge without effective rates")
+	}
+	if collection.Features[1].Properties["marker-color"] != sanFrancisco.Properties["marker-color"] {
+		t.Error("expected ZIP3s in the same group to share a color")
+	}
+
+	encoded, err := json.Marshal(collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil || decoded["type"] != "FeatureCollection" {
+		t.Errorf("expected GeoJSON, got %s", encoded)
+	}
+
+	if _, err := Build(centroids, GroupBy("county")); err != ErrInvalidGroupBy {
+		t.Errorf("expected ErrInvalidGroupBy, got %v", err)
+	}
+	empty, err := Build(models.Zip3Centroids{}, GroupByRateArea)
+	if err != nil || empty.Features == nil {
+		t.Errorf("expected an empty list of features, got %v, %v", empty, err)
+	}
+}
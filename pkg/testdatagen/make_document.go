@@ -22,6 +22,7 @@ func MakeDocument(db *pop.Connection, serviceMember *models.ServiceMember, name
 		ServiceMemberID: serviceMember.ID,
 		ServiceMember:   *serviceMember,
 		Name:            name,
+		DocumentType:    models.DocumentTypeOTHER,
 	}
 
 	verrs, err := db.ValidateAndSave(&document)
@@ -19,13 +19,15 @@ func MakeUpload(db *pop.Connection, document *models.Document) (models.Upload, e
 	}
 
 	upload := models.Upload{
-		DocumentID:  document.ID,
-		Document:    *document,
-		UploaderID:  document.ServiceMember.UserID,
-		Filename:    "testFile.pdf",
-		Bytes:       2202009,
-		ContentType: "application/pdf",
-		Checksum:    "ImGQ2Ush0bDHsaQthV5BnQ==",
+		DocumentID:    document.ID,
+		Document:      *document,
+		UploaderID:    document.ServiceMember.UserID,
+		Filename:      "testFile.pdf",
+		Bytes:         2202009,
+		ContentType:   "application/pdf",
+		Checksum:      "ImGQ2Ush0bDHsaQthV5BnQ==",
+		ScanStatus:    models.UploadScanStatusCLEAN,
+		PreviewStatus: models.UploadPreviewStatusPENDING,
 	}
 
 	verrs, err := db.ValidateAndSave(&upload)
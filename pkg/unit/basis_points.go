@@ -0,0 +1,24 @@
+package unit
+
+import "strconv"
+
+// basisPointsPerUnit is the number of BasisPoints in one whole unit
+const basisPointsPerUnit = 10000
+
+// BasisPoints represents a percentage in hundredths of a percent, so that published
+// percentages such as a 10.5% fuel surcharge (1050 basis points) are stored exactly.
+type BasisPoints int
+
+// Rate returns the BasisPoints as an exact Rate.
+func (b BasisPoints) Rate() Rate {
+	return NewRate(int64(b), basisPointsPerUnit)
+}
+
+func (b BasisPoints) String() string {
+	return strconv.Itoa(int(b))
+}
+
+// Int returns the value of self as an int
+func (b BasisPoints) Int() int {
+	return int(b)
+}
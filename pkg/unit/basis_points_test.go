@@ -0,0 +1,19 @@
+package unit
+
+import (
+	"testing"
+)
+
+func TestBasisPointsRate(t *testing.T) {
+	rate := BasisPoints(1050).Rate()
+	expected := NewRate(21, 200)
+	if rate != expected {
+		t.Errorf("wrong rate: expected %s, got %s", expected, rate)
+	}
+
+	// 10.5% of $1,234.57 is $129.6298..., rounded once
+	cents := Cents(123457).MultiplyRate(BasisPoints(1050).Rate(), RoundHalfUp)
+	if cents != Cents(12963) {
+		t.Errorf("wrong number of Cents: expected %d, got %d", 12963, cents)
+	}
+}
@@ -19,7 +19,20 @@ func (c Cents) AddCents(a Cents) Cents {
 	return Cents(c.Int() + a.Int())
 }
 
+// MultiplyRate returns the value of self multiplied by an exact Rate, rounded once using mode
+func (c Cents) MultiplyRate(r Rate, mode RoundingMode) Cents {
+	return Cents(r.apply(c.Int64(), mode))
+}
+
+// ToMillicents returns the value of self in Millicents
+func (c Cents) ToMillicents() Millicents {
+	return Millicents(c.Int() * millicentsPerCent)
+}
+
 // MultiplyFloat64 returns the value of self multiplied by multiplier
+//
+// Note: floating point multipliers are not exact. Money calculations should use
+// MultiplyRate instead.
 func (c Cents) MultiplyFloat64(f float64) Cents {
 	return Cents(math.Round(float64(c.Int()) * f))
 }
@@ -9,14 +9,24 @@ func (r DiscountRate) Float64() float64 {
 	return float64(r)
 }
 
+// Rate returns the DiscountRate as an exact Rate.
+func (r DiscountRate) Rate() Rate {
+	return NewRateFromFloat64(r.Float64())
+}
+
+// PercentOfTariff returns the exact share of the tariff charge that remains after
+// the discount, i.e. 1 minus the discount rate.
+func (r DiscountRate) PercentOfTariff() Rate {
+	return r.Rate().Complement()
+}
+
 // Apply returns the remaining charge after applying a DiscountRate.
 //
 // Note: The value returned is calculated by first determining the Percent of Tariff by
 // subtracting the discount rate from 1 and then multiplying the result by the
-// Cents parameter.
+// Cents parameter. The result is rounded once, half up.
 func (r DiscountRate) Apply(c Cents) Cents {
-	percentOfTariff := 1 - r.Float64()
-	return c.MultiplyFloat64(percentOfTariff)
+	return c.MultiplyRate(r.PercentOfTariff(), RoundHalfUp)
 }
 
 // NewDiscountRateFromPercent createDecimals a new DiscountRate using a float64 with
@@ -24,3 +24,16 @@ func TestApply(t *testing.T) {
 		t.Errorf("wrong number of Cents: expected %d, got %d", expected, result)
 	}
 }
+
+func TestApplyRoundsExactHalves(t *testing.T) {
+	// 50 * (1 - 0.67) is exactly 16.5, which floating point arithmetic rounds down to 16
+	cents := Cents(50)
+	rate := DiscountRate(0.67)
+
+	result := rate.Apply(cents)
+
+	expected := Cents(17)
+	if result != expected {
+		t.Errorf("wrong number of Cents: expected %d, got %d", expected, result)
+	}
+}
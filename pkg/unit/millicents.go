@@ -0,0 +1,43 @@
+package unit
+
+import (
+	"math/big"
+	"strconv"
+)
+
+// millicentsPerCent is the number of Millicents in one Cent
+const millicentsPerCent = 1000
+
+// Millicents represents a value in thousandths of US cents. Some tariff rates are
+// published in fractions of a cent and are kept in Millicents until the charge is
+// rounded to Cents.
+type Millicents int
+
+// Multiply returns the value of self multiplied by multiplier
+func (m Millicents) Multiply(i int) Millicents {
+	return Millicents(i * m.Int())
+}
+
+// MultiplyRate returns the value of self multiplied by an exact Rate, rounded once using mode
+func (m Millicents) MultiplyRate(r Rate, mode RoundingMode) Millicents {
+	return Millicents(r.apply(m.Int64(), mode))
+}
+
+// ToCents returns the value of self in Cents, rounded using mode
+func (m Millicents) ToCents(mode RoundingMode) Cents {
+	return Cents(roundQuotient(big.NewInt(m.Int64()), big.NewInt(millicentsPerCent), mode))
+}
+
+func (m Millicents) String() string {
+	return strconv.Itoa(int(m))
+}
+
+// Int returns the value of self as an int
+func (m Millicents) Int() int {
+	return int(m)
+}
+
+// Int64 returns the value of self as an int64
+func (m Millicents) Int64() int64 {
+	return int64(m)
+}
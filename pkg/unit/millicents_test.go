@@ -0,0 +1,40 @@
+package unit
+
+import (
+	"testing"
+)
+
+func TestMillicentsToCents(t *testing.T) {
+	// 50 CWT at $5.429/CWT
+	millicents := Millicents(542900).Multiply(50)
+	expected := Cents(27145)
+	if result := millicents.ToCents(RoundHalfUp); result != expected {
+		t.Errorf("wrong number of Cents: expected %d, got %d", expected, result)
+	}
+
+	millicents = Millicents(1500)
+	expected = Cents(2)
+	if result := millicents.ToCents(RoundHalfUp); result != expected {
+		t.Errorf("wrong number of Cents: expected %d, got %d", expected, result)
+	}
+	expected = Cents(1)
+	if result := millicents.ToCents(RoundDown); result != expected {
+		t.Errorf("wrong number of Cents: expected %d, got %d", expected, result)
+	}
+}
+
+func TestCentsToMillicents(t *testing.T) {
+	cents := Cents(125)
+	expected := Millicents(125000)
+	if result := cents.ToMillicents(); result != expected {
+		t.Errorf("wrong number of Millicents: expected %d, got %d", expected, result)
+	}
+}
+
+func TestMillicentsMultiplyRate(t *testing.T) {
+	millicents := Millicents(1001)
+	expected := Millicents(501)
+	if result := millicents.MultiplyRate(NewRate(1, 2), RoundHalfUp); result != expected {
+		t.Errorf("wrong number of Millicents: expected %d, got %d", expected, result)
+	}
+}
@@ -0,0 +1,90 @@
+package unit
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// rateFloatPrecision is the number of parts per whole that floating point values are
+// rounded to when they are converted into a Rate.
+const rateFloatPrecision = 1000000
+
+// Rate is an exact ratio, such as a percentage of tariff or a proration factor. It is
+// stored as a reduced fraction so that applying several rates to a charge never loses
+// precision before the single rounding of the result. The zero value is a rate of 0.
+type Rate struct {
+	numerator   int64
+	denominator int64
+}
+
+// NewRate returns the Rate numerator / denominator. It panics if denominator is 0.
+func NewRate(numerator int64, denominator int64) Rate {
+	if denominator == 0 {
+		panic("unit: Rate with a zero denominator")
+	}
+	if denominator < 0 {
+		numerator, denominator = -numerator, -denominator
+	}
+	gcd := new(big.Int).GCD(nil, nil, big.NewInt(abs(numerator)), big.NewInt(denominator)).Int64()
+	if gcd > 1 {
+		numerator, denominator = numerator/gcd, denominator/gcd
+	}
+	return Rate{numerator: numerator, denominator: denominator}
+}
+
+// NewRateFromFloat64 returns a Rate for a value with 1 representing one whole unit, such
+// as a discount rate read from the database. The value is rounded to the nearest millionth,
+// which is well beyond the precision tariffs and discounts are published to.
+func NewRateFromFloat64(f float64) Rate {
+	return NewRate(int64(math.Round(f*rateFloatPrecision)), rateFloatPrecision)
+}
+
+func (r Rate) fraction() (*big.Int, *big.Int) {
+	if r.denominator == 0 {
+		return big.NewInt(0), big.NewInt(1)
+	}
+	return big.NewInt(r.numerator), big.NewInt(r.denominator)
+}
+
+// Multiply returns the product of two rates.
+func (r Rate) Multiply(o Rate) Rate {
+	rNum, rDen := r.fraction()
+	oNum, oDen := o.fraction()
+	product := new(big.Rat).SetFrac(rNum.Mul(rNum, oNum), rDen.Mul(rDen, oDen))
+	if !product.Num().IsInt64() || !product.Denom().IsInt64() {
+		panic(fmt.Sprintf("unit: product of %s and %s overflows a Rate", r, o))
+	}
+	return Rate{numerator: product.Num().Int64(), denominator: product.Denom().Int64()}
+}
+
+// Complement returns 1 minus the rate.
+func (r Rate) Complement() Rate {
+	num, den := r.fraction()
+	return NewRate(den.Int64()-num.Int64(), den.Int64())
+}
+
+// Float64 returns an approximation of the rate as a float64 with 1 representing one whole unit.
+func (r Rate) Float64() float64 {
+	num, den := r.fraction()
+	f, _ := new(big.Rat).SetFrac(num, den).Float64()
+	return f
+}
+
+func (r Rate) String() string {
+	num, den := r.fraction()
+	return fmt.Sprintf("%s/%s", num, den)
+}
+
+// apply returns value multiplied by the rate, rounded once using mode.
+func (r Rate) apply(value int64, mode RoundingMode) int64 {
+	num, den := r.fraction()
+	return roundQuotient(num.Mul(num, big.NewInt(value)), den, mode)
+}
+
+func abs(i int64) int64 {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
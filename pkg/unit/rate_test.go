@@ -0,0 +1,67 @@
+package unit
+
+import (
+	"testing"
+)
+
+func TestNewRateReduces(t *testing.T) {
+	rate := NewRate(800, 1000)
+	expected := NewRate(4, 5)
+	if rate != expected {
+		t.Errorf("wrong rate: expected %s, got %s", expected, rate)
+	}
+
+	rate = NewRate(3, -4)
+	expected = NewRate(-3, 4)
+	if rate != expected {
+		t.Errorf("wrong rate: expected %s, got %s", expected, rate)
+	}
+}
+
+func TestNewRateFromFloat64(t *testing.T) {
+	// 1 - 0.67 is not exactly 0.33 as a float64, but it is as a Rate
+	rate := NewRateFromFloat64(0.67).Complement()
+	expected := NewRate(33, 100)
+	if rate != expected {
+		t.Errorf("wrong rate: expected %s, got %s", expected, rate)
+	}
+}
+
+func TestRateMultiply(t *testing.T) {
+	rate := NewRate(33, 100).Multiply(NewRate(850, 1000))
+	expected := NewRate(561, 2000)
+	if rate != expected {
+		t.Errorf("wrong rate: expected %s, got %s", expected, rate)
+	}
+
+	var zero Rate
+	if result := zero.Multiply(rate); result.Float64() != 0 {
+		t.Errorf("wrong rate: expected 0, got %s", result)
+	}
+}
+
+func TestRoundingModes(t *testing.T) {
+	cases := []struct {
+		cents    Cents
+		rate     Rate
+		mode     RoundingMode
+		expected Cents
+	}{
+		{Cents(5), NewRate(1, 2), RoundHalfUp, Cents(3)},
+		{Cents(-5), NewRate(1, 2), RoundHalfUp, Cents(-3)},
+		{Cents(5), NewRate(1, 2), RoundHalfEven, Cents(2)},
+		{Cents(7), NewRate(1, 2), RoundHalfEven, Cents(4)},
+		{Cents(5), NewRate(1, 2), RoundDown, Cents(2)},
+		{Cents(-5), NewRate(1, 2), RoundDown, Cents(-2)},
+		{Cents(4), NewRate(1, 3), RoundUp, Cents(2)},
+		{Cents(4), NewRate(1, 3), RoundHalfUp, Cents(1)},
+		{Cents(6), NewRate(1, 3), RoundUp, Cents(2)},
+	}
+
+	for _, c := range cases {
+		result := c.cents.MultiplyRate(c.rate, c.mode)
+		if result != c.expected {
+			t.Errorf("%d * %s with mode %d: expected %d, got %d", c.cents, c.rate, c.mode, c.expected, result)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package unit
+
+import (
+	"math/big"
+)
+
+// RoundingMode determines how a value that falls between two whole units is rounded.
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds to the nearest whole unit, rounding halves away from zero. This
+	// is how the 400NG tariff rounds charges and is what the rate engine uses.
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds to the nearest whole unit, rounding halves to the nearest even unit.
+	RoundHalfEven
+	// RoundDown truncates towards zero.
+	RoundDown
+	// RoundUp rounds away from zero.
+	RoundUp
+)
+
+// roundQuotient returns numerator / denominator rounded to a whole number using mode.
+func roundQuotient(numerator *big.Int, denominator *big.Int, mode RoundingMode) int64 {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	if remainder.Sign() == 0 || mode == RoundDown {
+		return quotient.Int64()
+	}
+
+	// Compare the remainder against half of the denominator
+	twiceRemainder := new(big.Int).Abs(remainder)
+	twiceRemainder.Lsh(twiceRemainder, 1)
+	half := twiceRemainder.Cmp(new(big.Int).Abs(denominator))
+
+	awayFromZero := false
+	switch mode {
+	case RoundHalfUp:
+		awayFromZero = half >= 0
+	case RoundHalfEven:
+		awayFromZero = half > 0 || (half == 0 && quotient.Bit(0) == 1)
+	case RoundUp:
+		awayFromZero = true
+	}
+
+	if awayFromZero {
+		sign := int64(numerator.Sign() * denominator.Sign())
+		quotient.Add(quotient, big.NewInt(sign))
+	}
+	return quotient.Int64()
+}
@@ -0,0 +1,173 @@
+package uploader
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/gobuffalo/pop"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/paperwork"
+	"github.com/transcom/mymove/pkg/storage"
+)
+
+// ErrNoPreview is returned when asked for the preview of an upload which doesn't have one yet
+var ErrNoPreview = errors.New("Upload has no preview")
+
+// previewBatchSize is the number of uploads a PreviewWorker makes previews of at a time
+const previewBatchSize = 20
+
+// GeneratePreview makes a small JPEG of a clean upload's file, stores it next to the file, and
+// marks the upload's preview READY. Files no preview can be made of, including PDFs of text and
+// files which can't be decoded, are marked UNAVAILABLE and shown by a placeholder; if the file
+// can't be fetched or the preview stored, the upload is left PENDING, to be tried again.
+func (u *Uploader) GeneratePreview(upload *models.Upload) error {
+	if upload.ScanStatus != models.UploadScanStatusCLEAN {
+		return ErrUploadNotClean
+	}
+	if upload.PreviewStatus != models.UploadPreviewStatusPENDING {
+		return nil
+	}
+
+	object, err := u.storer.Fetch(u.uploadKey(upload))
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadAll(object)
+	object.Close()
+	if err != nil {
+		return errors.Wrap(err, "could not read stored file")
+	}
+
+	preview, err := paperwork.Preview(paperwork.File{ContentType: upload.ContentType, Data: data})
+	if err == paperwork.ErrNoPreview {
+		upload.PreviewStatus = models.UploadPreviewStatusUNAVAILABLE
+	} else if err != nil {
+		return err
+	} else {
+		content := bytes.NewReader(preview)
+		checksum, err := storage.ComputeChecksum(content)
+		if err != nil {
+			return err
+		}
+		if _, err := u.storer.Store(u.previewKey(upload), content, checksum); err != nil {
+			return errors.Wrap(err, "could not store preview")
+		}
+		upload.PreviewStatus = models.UploadPreviewStatusREADY
+	}
+
+	return u.update(u.db, upload)
+}
+
+// FetchPreview returns the content of an Upload's preview, a JPEG. Uploads no preview can be made
+// of are shown by a placeholder, which isn't stored.
+func (u *Uploader) FetchPreview(upload *models.Upload) (io.ReadCloser, error) {
+	if err := checkPreview(upload); err != nil {
+		return nil, err
+	}
+	if upload.PreviewStatus == models.UploadPreviewStatusUNAVAILABLE {
+		placeholder, err := paperwork.PlaceholderPreview()
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.NopCloser(bytes.NewReader(placeholder)), nil
+	}
+	return u.storer.Fetch(u.previewKey(upload))
+}
+
+// checkPreview returns an error unless upload has a preview, or a placeholder, which may be shown
+func checkPreview(upload *models.Upload) error {
+	if upload.ScanStatus != models.UploadScanStatusCLEAN {
+		return ErrUploadNotClean
+	}
+	if upload.PreviewStatus == models.UploadPreviewStatusPENDING {
+		return ErrNoPreview
+	}
+	return nil
+}
+
+// previewKey returns the key of an upload's preview
+func (u *Uploader) previewKey(upload *models.Upload) string {
+	return PreviewKey(u.storer, upload)
+}
+
+// PreviewKey returns the key under which storer keeps an upload's preview. It sits beside the
+// uploads of the document, rather than under the upload's own key, which is a file.
+func PreviewKey(storer storage.FileStorer, upload *models.Upload) string {
+	return storer.Key("documents", upload.DocumentID.String(), "previews", upload.ID.String()+".jpg")
+}
+
+// PreviewWorker makes previews of clean uploads in the background, so that creating an upload
+// doesn't wait on it. It looks for uploads needing previews every interval, and whenever it is
+// notified of a new one.
+type PreviewWorker struct {
+	db       *pop.Connection
+	logger   *zap.Logger
+	uploader *Uploader
+	interval time.Duration
+	notify   chan struct{}
+}
+
+// NewPreviewWorker creates and returns a new PreviewWorker. It does nothing until Run is called.
+func NewPreviewWorker(db *pop.Connection, logger *zap.Logger, storer storage.FileStorer, interval time.Duration) *PreviewWorker {
+	return &PreviewWorker{
+		db:       db,
+		logger:   logger,
+		uploader: NewUploader(db, logger, storer, nil),
+		interval: interval,
+		notify:   make(chan struct{}, 1),
+	}
+}
+
+// Notify tells the worker there may be new uploads to make previews of. It never blocks.
+func (w *PreviewWorker) Notify() {
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Run makes previews until stop is closed. With a nil stop it runs forever.
+func (w *PreviewWorker) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.RunOnce()
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		case <-w.notify:
+		}
+	}
+}
+
+// RunOnce makes previews of all uploads waiting for one
+func (w *PreviewWorker) RunOnce() {
+	for {
+		uploads, err := models.FetchUploadsToPreview(w.db, previewBatchSize)
+		if err != nil {
+			w.logger.Error("Could not fetch uploads to preview", zap.Error(err))
+			return
+		}
+		progress := false
+		for i := range uploads {
+			upload := &uploads[i]
+			if err := w.uploader.GeneratePreview(upload); err != nil {
+				w.logger.Error("Could not generate preview",
+					zap.String("upload_id", upload.ID.String()),
+					zap.Error(err))
+				continue
+			}
+			progress = true
+		}
+		// Stop once everything is done, or when everything left keeps failing
+		if len(uploads) < previewBatchSize || !progress {
+			return
+		}
+	}
+}
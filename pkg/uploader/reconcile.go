@@ -0,0 +1,205 @@
+package uploader
+
+import (
+	"crypto/md5" // #nosec - MD5 is the checksum uploads are stored with, not a security control
+	"encoding/base64"
+	"io"
+	"time"
+
+	"github.com/gobuffalo/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/storage"
+)
+
+// ChecksumMismatch is an Upload whose stored file doesn't match it
+type ChecksumMismatch struct {
+	Upload models.Upload
+	Object storage.ObjectInfo
+}
+
+// ReconcileReport lists the differences Reconcile found between storage and the uploads table
+type ReconcileReport struct {
+	// OrphanedObjects are stored files which no Upload refers to
+	OrphanedObjects []storage.ObjectInfo
+	// MissingObjects are Uploads whose file isn't in storage
+	MissingObjects models.Uploads
+	// ChecksumMismatches are Uploads whose stored file has a different size or checksum
+	ChecksumMismatches []ChecksumMismatch
+	// UnverifiedUploads are Uploads whose stored file is there, but whose size and checksum
+	// storage doesn't report, as with encrypted files. They are only checked when content is
+	// verified.
+	UnverifiedUploads models.Uploads
+	// ExpiredUploadSessions are chunked uploads which were never finalized
+	ExpiredUploadSessions models.UploadSessions
+}
+
+// HasAny returns true if the report found any differences
+func (r *ReconcileReport) HasAny() bool {
+	return len(r.OrphanedObjects) > 0 || len(r.MissingObjects) > 0 || len(r.ChecksumMismatches) > 0 ||
+		len(r.ExpiredUploadSessions) > 0
+}
+
+// Reconcile compares the files in storage with the uploads table. Anything changed in the last
+// minAge is left alone, since it may belong to an upload which is still being created, scanned
+// or deleted.
+//
+// Storage doesn't always report the size and checksum of a file, e.g. when it is encrypted. With
+// verifyContent those files are fetched and checked; otherwise their uploads are reported as
+// unverified.
+//
+// Combined PDFs are orphaned once the uploads of their document change; they are only a cache,
+// and are rendered again when they are next asked for.
+func (u *Uploader) Reconcile(minAge time.Duration, verifyContent bool) (*ReconcileReport, error) {
+	cutoff := time.Now().Add(-minAge)
+
+	uploads := models.Uploads{}
+	if err := u.db.Order("created_at asc").All(&uploads); err != nil {
+		return nil, errors.Wrap(err, "could not fetch uploads")
+	}
+
+	objects := map[string]storage.ObjectInfo{}
+	for _, prefix := range []string{u.storer.Key("documents"), u.storer.Key("quarantine", "documents"), u.storer.Key("content")} {
+		listed, err := u.storer.List(prefix + "/")
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range listed {
+			objects[object.Key] = object
+		}
+	}
+
+	report := &ReconcileReport{}
+	expected := map[string]bool{}
+	documentUploads := map[uuid.UUID]models.Uploads{}
+	for _, upload := range uploads {
+		key := u.uploadKey(&upload)
+		expected[key] = true
+		if upload.PreviewStatus == models.UploadPreviewStatusREADY {
+			expected[u.previewKey(&upload)] = true
+		}
+		if upload.ScanStatus != models.UploadScanStatusINFECTED {
+			documentUploads[upload.DocumentID] = append(documentUploads[upload.DocumentID], upload)
+		}
+		if upload.UpdatedAt.After(cutoff) {
+			continue
+		}
+
+		object, ok := objects[key]
+		if !ok {
+			report.MissingObjects = append(report.MissingObjects, upload)
+			continue
+		}
+		if object.Checksum == "" && verifyContent {
+			if err := u.verifyObject(&object); err != nil {
+				u.logger.Warn("could not verify stored file", zap.String("key", key), zap.Error(err))
+			}
+		}
+		if (object.Bytes >= 0 && object.Bytes != upload.Bytes) || (object.Checksum != "" && object.Checksum != upload.Checksum) {
+			report.ChecksumMismatches = append(report.ChecksumMismatches, ChecksumMismatch{Upload: upload, Object: object})
+		} else if object.Checksum == "" {
+			report.UnverifiedUploads = append(report.UnverifiedUploads, upload)
+		}
+	}
+
+	// Only the combined PDF of a document's current uploads is wanted
+	for documentID, uploads := range documentUploads {
+		clean := models.Uploads{}
+		for _, upload := range uploads {
+			if upload.ScanStatus == models.UploadScanStatusCLEAN {
+				clean = append(clean, upload)
+			}
+		}
+		if len(clean) == len(uploads) {
+			expected[u.combinedKey(&models.Document{ID: documentID}, clean)] = true
+		}
+	}
+
+	for key, object := range objects {
+		if !expected[key] && !object.LastModified.After(cutoff) {
+			report.OrphanedObjects = append(report.OrphanedObjects, object)
+		}
+	}
+
+	expired, err := models.FetchExpiredUploadSessions(u.db, 1000)
+	if err != nil {
+		return nil, err
+	}
+	report.ExpiredUploadSessions = expired
+
+	return report, nil
+}
+
+// verifyObject fetches the content of a stored file to fill in the size and checksum storage
+// didn't report
+func (u *Uploader) verifyObject(object *storage.ObjectInfo) error {
+	content, err := u.storer.Fetch(object.Key)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	hash := md5.New()
+	bytes, err := io.Copy(hash, content)
+	if err != nil {
+		return errors.Wrap(err, "could not read stored file")
+	}
+	object.Bytes = bytes
+	object.Checksum = base64.StdEncoding.EncodeToString(hash.Sum(nil))
+	return nil
+}
+
+// FixReconciled repairs the differences in a report: orphaned files are deleted, as are
+// Uploads without a file and expired upload sessions with their chunks. Uploads whose file doesn't match take on the size and checksum of the
+// file, and unless they are in quarantine are scanned again and given a new preview.
+func (u *Uploader) FixReconciled(report *ReconcileReport) error {
+	for _, object := range report.OrphanedObjects {
+		if err := u.storer.Delete(object.Key); err != nil {
+			return errors.Wrapf(err, "could not delete orphaned object %s", object.Key)
+		}
+		u.logger.Info("deleted orphaned object", zap.String("key", object.Key))
+	}
+
+	for i := range report.ExpiredUploadSessions {
+		session := &report.ExpiredUploadSessions[i]
+		if err := u.AbortUploadSession(session); err != nil {
+			return errors.Wrapf(err, "could not delete upload session %s", session.ID)
+		}
+		u.logger.Info("deleted expired upload session", zap.Any("upload_session_id", session.ID))
+	}
+
+	for i := range report.MissingObjects {
+		upload := &report.MissingObjects[i]
+		if err := models.DeleteUpload(u.db, upload); err != nil {
+			return errors.Wrapf(err, "could not delete upload %s", upload.ID)
+		}
+		u.logger.Info("deleted upload without a stored file", zap.Any("upload_id", upload.ID))
+	}
+
+	for i := range report.ChecksumMismatches {
+		mismatch := &report.ChecksumMismatches[i]
+		upload := &mismatch.Upload
+		if mismatch.Object.Bytes >= 0 {
+			upload.Bytes = mismatch.Object.Bytes
+		}
+		if mismatch.Object.Checksum != "" {
+			upload.Checksum = mismatch.Object.Checksum
+		}
+		if upload.ScanStatus == models.UploadScanStatusCLEAN {
+			upload.ScanStatus = models.UploadScanStatusPENDING
+			upload.ScannedAt = nil
+			upload.PreviewStatus = models.UploadPreviewStatusPENDING
+		}
+		verrs, err := u.db.ValidateAndUpdate(upload)
+		if err != nil {
+			return errors.Wrapf(err, "could not update upload %s", upload.ID)
+		} else if verrs.HasAny() {
+			return errors.Errorf("could not update upload %s: %s", upload.ID, verrs)
+		}
+		u.logger.Info("updated upload to match its stored file", zap.Any("upload_id", upload.ID))
+	}
+
+	return nil
+}
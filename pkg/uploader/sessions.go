@@ -0,0 +1,230 @@
+package uploader
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/gobuffalo/pop"
+	"github.com/gobuffalo/uuid"
+	"github.com/gobuffalo/validate"
+	"github.com/gobuffalo/validate/validators"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/storage"
+)
+
+// UploadSessionTTL is how long an upload session can be resumed for
+const UploadSessionTTL = 24 * time.Hour
+
+// MaxChunkBytes is the size of the largest chunk an upload session accepts
+const MaxChunkBytes int64 = 8 * 1024 * 1024
+
+// ErrChunkOffset is returned when a chunk doesn't start where the last one ended
+var ErrChunkOffset = errors.New("Chunk does not start at the end of the bytes received so far")
+
+// ErrChunkTooLarge is returned when a chunk is larger than MaxChunkBytes, or runs past the end of
+// the file
+var ErrChunkTooLarge = errors.New("Chunk is too large")
+
+// ErrUploadSessionIncomplete is returned when finalizing an upload session which hasn't
+// received the whole file
+var ErrUploadSessionIncomplete = errors.New("Upload session has not received the whole file")
+
+// ErrUploadSessionNotFound is returned when finalizing an upload session which has already been
+// finalized or aborted, such as by a concurrent request
+var ErrUploadSessionNotFound = errors.New("Upload session has already been finalized or aborted")
+
+// CreateUploadSession starts an upload of a file in chunks, after checking what is known of the
+// file against the policy of the document it is added to. Its content type isn't known until
+// the session is finalized.
+func (u *Uploader) CreateUploadSession(documentID uuid.UUID, userID uuid.UUID, filename string, size int64, checksum string) (*models.UploadSession, *validate.Errors, error) {
+	session := &models.UploadSession{
+		ID:         uuid.Must(uuid.NewV4()),
+		DocumentID: documentID,
+		UploaderID: userID,
+		Filename:   filename,
+		Bytes:      size,
+		Checksum:   checksum,
+		ExpiresAt:  time.Now().Add(UploadSessionTTL),
+	}
+
+	verrs, err := session.Validate(u.db)
+	if err != nil {
+		return nil, nil, err
+	}
+	policyVerrs, err := u.validatePolicy(u.db, &models.Upload{DocumentID: documentID, Bytes: size})
+	if err != nil {
+		u.logger.Error("Failed to check document policy", zap.Error(err))
+		return nil, nil, err
+	}
+	delete(policyVerrs.Errors, validators.GenerateKey("ContentType"))
+	verrs.Append(policyVerrs)
+	if verrs.HasAny() {
+		return nil, verrs, nil
+	}
+
+	if err := u.db.Create(session); err != nil {
+		return nil, nil, err
+	}
+	return session, nil, nil
+}
+
+// AppendChunk stores the next chunk of an upload session's file, which must start at offset.
+// A chunk which was stored but not recorded is simply sent again, and overwrites itself.
+func (u *Uploader) AppendChunk(session *models.UploadSession, offset int64, chunk io.Reader) error {
+	if offset != session.ReceivedBytes {
+		return ErrChunkOffset
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(chunk, MaxChunkBytes+1))
+	if err != nil {
+		return errors.Wrap(err, "could not read chunk")
+	}
+	size := int64(len(data))
+	if size > MaxChunkBytes || offset+size > session.Bytes {
+		return ErrChunkTooLarge
+	}
+	if size == 0 {
+		return nil
+	}
+
+	checksum, err := storage.ComputeChecksum(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	// The session is only advanced if nothing else has appended at offset since it was read. The
+	// row stays locked until the chunk is stored, so a concurrent append at the same offset waits
+	// and then finds it taken, rather than overwriting the chunk.
+	err = u.db.Transaction(func(tx *pop.Connection) error {
+		sql := `UPDATE upload_sessions
+			SET received_bytes = $1, chunks = chunks + 1, updated_at = $2
+			WHERE id = $3 AND received_bytes = $4
+			RETURNING *`
+		var updated models.UploadSessions
+		if err := tx.RawQuery(sql, offset+size, time.Now(), session.ID, offset).All(&updated); err != nil {
+			return errors.Wrap(err, "could not record chunk")
+		}
+		if len(updated) == 0 {
+			return ErrChunkOffset
+		}
+
+		index := updated[0].Chunks - 1
+		if _, err := u.storer.Store(u.chunkKey(session, index), bytes.NewReader(data), checksum); err != nil {
+			return errors.Wrap(err, "could not store chunk")
+		}
+		*session = updated[0]
+		return nil
+	})
+	if errors.Cause(err) == ErrChunkOffset {
+		return ErrChunkOffset
+	}
+	return err
+}
+
+// FinalizeUploadSession joins the chunks of a complete upload session and creates an Upload
+// from them, as CreateUpload does, once the file has been checked against its checksum. The
+// session is finished with unless the Upload couldn't be created for reasons other than the file
+// itself, in which case it can be finalized again. Only one of concurrent finalizations of a
+// session creates an Upload; the others return ErrUploadSessionNotFound.
+func (u *Uploader) FinalizeUploadSession(session *models.UploadSession) (*models.Upload, *validate.Errors, error) {
+	if !session.Complete() {
+		return nil, nil, ErrUploadSessionIncomplete
+	}
+
+	// The session is deleted up front, which keeps its row locked until the Upload has been
+	// created. A concurrent finalization waits and then finds it gone; if creating the Upload
+	// fails, the session is rolled back to be finalized again.
+	var upload *models.Upload
+	var verrs *validate.Errors
+	err := u.db.Transaction(func(tx *pop.Connection) error {
+		var deleted models.UploadSessions
+		if err := tx.RawQuery("DELETE FROM upload_sessions WHERE id = $1 RETURNING *", session.ID).All(&deleted); err != nil {
+			return errors.Wrap(err, "could not delete upload session")
+		}
+		if len(deleted) == 0 {
+			return ErrUploadSessionNotFound
+		}
+		var err error
+		upload, verrs, err = u.createUploadFromChunks(session)
+		return err
+	})
+	if errors.Cause(err) == ErrUploadSessionNotFound {
+		return nil, nil, ErrUploadSessionNotFound
+	} else if err != nil {
+		return nil, nil, err
+	}
+	u.deleteChunks(session)
+	return upload, verrs, nil
+}
+
+// createUploadFromChunks joins the chunks of a complete upload session and creates an Upload from
+// them if they match the session's checksum
+func (u *Uploader) createUploadFromChunks(session *models.UploadSession) (*models.Upload, *validate.Errors, error) {
+	file, err := ioutil.TempFile("", "upload-session")
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not create temporary file")
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	for i := 0; i < session.Chunks; i++ {
+		chunk, err := u.storer.Fetch(u.chunkKey(session, i))
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "could not fetch chunk %d", i)
+		}
+		_, err = io.Copy(file, chunk)
+		chunk.Close()
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "could not write temporary file")
+		}
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, nil, errors.Wrap(err, "could not seek to beginning of file")
+	}
+
+	checksum, err := storage.ComputeChecksum(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	if checksum != session.Checksum {
+		u.logger.Info("upload session checksum mismatch", zap.String("upload_session_id", session.ID.String()),
+			zap.String("expected", session.Checksum), zap.String("received", checksum))
+		verrs := validate.NewErrors()
+		verrs.Add(validators.GenerateKey("Checksum"), "The file received doesn't match its checksum. Please upload it again.")
+		return nil, verrs, nil
+	}
+
+	return u.CreateUpload(session.DocumentID, session.UploaderID, &runtime.File{
+		Header: &multipart.FileHeader{Filename: session.Filename, Size: session.Bytes},
+		Data:   file,
+	})
+}
+
+// AbortUploadSession deletes an upload session and the chunks it has received
+func (u *Uploader) AbortUploadSession(session *models.UploadSession) error {
+	u.deleteChunks(session)
+	return u.db.Destroy(session)
+}
+
+// deleteChunks deletes the chunks an upload session has received. Failures are only logged, as
+// the session is finished with either way.
+func (u *Uploader) deleteChunks(session *models.UploadSession) {
+	for i := 0; i < session.Chunks; i++ {
+		if err := u.storer.Delete(u.chunkKey(session, i)); err != nil {
+			u.logger.Error("failed to delete chunk", zap.String("upload_session_id", session.ID.String()), zap.Int("chunk", i), zap.Error(err))
+		}
+	}
+}
+
+// chunkKey returns the key of the chunk of an upload session with the given index
+func (u *Uploader) chunkKey(session *models.UploadSession, index int) string {
+	return u.storer.Key("upload_sessions", session.ID.String(), "chunks", strconv.Itoa(index))
+}
@@ -1,8 +1,14 @@
 package uploader
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
 	"mime/multipart"
 	"os"
+	"time"
 
 	"github.com/go-openapi/runtime"
 	"github.com/gobuffalo/pop"
@@ -12,12 +18,21 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/transcom/mymove/pkg/models"
+	"github.com/transcom/mymove/pkg/paperwork"
+	"github.com/transcom/mymove/pkg/scanner"
 	"github.com/transcom/mymove/pkg/storage"
 )
 
 // ErrZeroLengthFile represents an error caused by a file with no content
 var ErrZeroLengthFile = errors.New("File has length of 0")
 
+// ErrUploadNotClean is returned when asked for the URL of an upload which hasn't been found free of
+// malware
+var ErrUploadNotClean = errors.New("Upload has not been scanned clean")
+
+// ErrNoUploads is returned when asked to combine a document which has no clean uploads
+var ErrNoUploads = errors.New("Document has no uploads")
+
 // NewLocalFile creates a *runtime.File from a file on the local filesystem
 func NewLocalFile(filePath string) (*runtime.File, error) {
 	info, err := os.Stat(filePath)
@@ -43,25 +58,38 @@ func NewLocalFile(filePath string) (*runtime.File, error) {
 }
 
 // Uploader encapsulates a few common processes: creating Uploads for a Document,
-// generating pre-signed URLs for file access, and deleting Uploads.
+// scanning their files for malware, generating pre-signed URLs for file access, and
+// deleting Uploads.
 type Uploader struct {
-	db     *pop.Connection
-	logger *zap.Logger
-	storer storage.FileStorer
+	db               *pop.Connection
+	logger           *zap.Logger
+	storer           storage.FileStorer
+	scanner          scanner.Scanner
+	contentAddressed bool
 }
 
-// NewUploader creates and returns a new uploader
-func NewUploader(db *pop.Connection, logger *zap.Logger, storer storage.FileStorer) *Uploader {
+// NewUploader creates and returns a new uploader. Without a scanner, new uploads are left
+// PENDING until ScanUpload is called with one.
+func NewUploader(db *pop.Connection, logger *zap.Logger, storer storage.FileStorer, scanner scanner.Scanner) *Uploader {
 	return &Uploader{
-		db:     db,
-		logger: logger,
-		storer: storer,
+		db:      db,
+		logger:  logger,
+		storer:  storer,
+		scanner: scanner,
 	}
 }
 
-// CreateUpload creates a new Upload by performing validations, storing the specified
-// file using the supplied storer, and saving an Upload object to the database containing
-// the file's metadata.
+// SetContentAddressed makes the uploader store each new file once, under its SHA-256, however
+// many uploads have the same content. The file is only deleted along with the last of them.
+// Uploads already stored either way can be read and deleted regardless.
+func (u *Uploader) SetContentAddressed() {
+	u.contentAddressed = true
+}
+
+// CreateUpload creates a new Upload by performing validations, including those of the
+// document's DocumentPolicy, scanning the file for malware, storing the specified file using the supplied storer, and saving an Upload
+// object to the database containing the file's metadata. Infected files are stored in
+// quarantine. If the file can't be scanned the upload is left PENDING.
 func (u *Uploader) CreateUpload(documentID uuid.UUID, userID uuid.UUID, file *runtime.File) (*models.Upload, *validate.Errors, error) {
 	if file.Header.Size == 0 {
 		return nil, nil, ErrZeroLengthFile
@@ -79,16 +107,25 @@ func (u *Uploader) CreateUpload(documentID uuid.UUID, userID uuid.UUID, file *ru
 		return nil, nil, err
 	}
 
+	contentSHA256, err := storage.ComputeSHA256(file.Data)
+	if err != nil {
+		u.logger.Error("Could not compute SHA-256", zap.Error(err))
+		return nil, nil, err
+	}
+
 	id := uuid.Must(uuid.NewV4())
 
 	newUpload := &models.Upload{
-		ID:          id,
-		DocumentID:  documentID,
-		UploaderID:  userID,
-		Filename:    file.Header.Filename,
-		Bytes:       int64(file.Header.Size),
-		ContentType: contentType,
-		Checksum:    checksum,
+		ID:            id,
+		DocumentID:    documentID,
+		UploaderID:    userID,
+		Filename:      file.Header.Filename,
+		Bytes:         int64(file.Header.Size),
+		ContentType:   contentType,
+		Checksum:      checksum,
+		ScanStatus:    models.UploadScanStatusPENDING,
+		PreviewStatus: models.UploadPreviewStatusPENDING,
+		ContentSHA256: &contentSHA256,
 	}
 
 	// validate upload before pushing file to S3
@@ -96,56 +133,424 @@ func (u *Uploader) CreateUpload(documentID uuid.UUID, userID uuid.UUID, file *ru
 	if err != nil {
 		u.logger.Error("Failed to validate", zap.Error(err))
 		return nil, nil, err
-	} else if verrs.HasAny() {
-		return nil, verrs, nil
 	}
 
-	// Push file to S3
+	// The document stays locked until the upload is saved, so that concurrent uploads to it are
+	// checked against its policy one at a time
+	err = u.db.Transaction(func(tx *pop.Connection) error {
+		// check it against the policy for the type of document it is added to
+		policyVerrs, err := u.validatePolicy(tx, newUpload)
+		if err != nil {
+			u.logger.Error("Failed to check document policy", zap.Error(err))
+			return err
+		}
+		verrs.Append(policyVerrs)
+		if verrs.HasAny() {
+			return nil
+		}
+
+		if u.scanner != nil {
+			if err := u.scan(newUpload, file.Data); err != nil {
+				u.logger.Error("Could not scan upload, leaving it pending", zap.Error(err))
+			}
+			if _, err := file.Data.Seek(0, io.SeekStart); err != nil {
+				return errors.Wrap(err, "could not seek to beginning of file")
+			}
+		}
+
+		// Infected files are never shared, so they can be quarantined one upload at a time
+		newUpload.ContentAddressed = u.contentAddressed && newUpload.ScanStatus != models.UploadScanStatusINFECTED
+		if newUpload.ContentAddressed {
+			if err := models.LockUploadContent(tx, contentSHA256); err != nil {
+				return err
+			}
+			return u.storeContentAddressed(tx, newUpload, file.Data)
+		}
+		return u.store(tx, newUpload, file.Data)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if verrs.HasAny() {
+		return nil, verrs, nil
+	}
 	key := u.uploadKey(newUpload)
-	if _, err = u.storer.Store(key, file.Data, checksum); err != nil {
+
+	u.logger.Info("created an upload with id and key ", zap.Any("new_upload_id", newUpload.ID), zap.String("key", key))
+
+	return newUpload, nil, nil
+}
+
+// store pushes the file of a new upload to storage and saves the upload
+func (u *Uploader) store(db *pop.Connection, upload *models.Upload, data io.ReadSeeker) error {
+	key := u.uploadKey(upload)
+	result, err := u.storer.Store(key, data, upload.Checksum)
+	if err != nil {
 		u.logger.Error("failed to store object", zap.Error(err))
-		return nil, nil, err
+		return err
 	}
+	upload.EncryptionKeyID = encryptionKeyID(result)
 
 	// Already validated upload, so just save
-	err = u.db.Create(newUpload)
+	err = db.Create(upload)
 	if err != nil {
-		// TODO clean up orphaned S3 file
 		u.logger.Error("DB Insertion", zap.Error(err))
-		return nil, nil, err
+		if deleteErr := u.storer.Delete(key); deleteErr != nil {
+			u.logger.Error("failed to delete orphaned object", zap.String("key", key), zap.Error(deleteErr))
+		}
+		return err
 	}
+	return nil
+}
 
-	u.logger.Info("created an upload with id and key ", zap.Any("new_upload_id", newUpload.ID), zap.String("key", key))
+// storeContentAddressed saves a new content-addressed upload, only pushing its file to storage
+// if no other upload already refers to the same content. It must hold the content's lock.
+func (u *Uploader) storeContentAddressed(tx *pop.Connection, upload *models.Upload, data io.ReadSeeker) error {
+	reference, err := models.FetchContentReference(tx, *upload.ContentSHA256)
+	if err != nil {
+		return err
+	}
+	if reference == nil {
+		return u.store(tx, upload, data)
+	}
 
-	return newUpload, nil, nil
+	upload.EncryptionKeyID = reference.EncryptionKeyID
+	if err := tx.Create(upload); err != nil {
+		u.logger.Error("DB Insertion", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// withContentLock runs fn in a transaction which holds the lock on the content-addressed file
+// with the given SHA-256
+func (u *Uploader) withContentLock(contentSHA256 string, fn func(tx *pop.Connection) error) error {
+	return u.db.Transaction(func(tx *pop.Connection) error {
+		if err := models.LockUploadContent(tx, contentSHA256); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// deleteUnreferencedContent deletes the content-addressed file with the given SHA-256 once no
+// upload refers to it. It must hold the content's lock, so the file isn't taken up again
+// before it is gone.
+func (u *Uploader) deleteUnreferencedContent(tx *pop.Connection, contentSHA256 string) error {
+	reference, err := models.FetchContentReference(tx, contentSHA256)
+	if err != nil || reference != nil {
+		return err
+	}
+	return u.storer.Delete(u.contentKey(contentSHA256))
+}
+
+// validatePolicy checks upload against the policy of the type of document it is added to.
+// Infected uploads don't count towards the document's MaxUploads. In a transaction, the document
+// is locked until it ends.
+func (u *Uploader) validatePolicy(db *pop.Connection, upload *models.Upload) (*validate.Errors, error) {
+	document, err := models.LockDocument(db, upload.DocumentID)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find document")
+	}
+	existingUploads, err := db.Where("document_id = ? AND scan_status != ?", document.ID, models.UploadScanStatusINFECTED).Count(&models.Upload{})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not count uploads")
+	}
+	return document.DocumentType.Policy().ValidateUpload(document.DocumentType, upload, existingUploads), nil
+}
+
+// scan sets upload's scan status from the result of scanning data
+func (u *Uploader) scan(upload *models.Upload, data io.Reader) error {
+	result, err := u.scanner.Scan(data)
+	if err != nil {
+		return err
+	}
+
+	scannedAt := time.Now()
+	upload.ScannedAt = &scannedAt
+	if result.Infected {
+		u.logger.Warn("Upload is infected, quarantining it",
+			zap.String("upload_id", upload.ID.String()),
+			zap.String("signature", result.Signature))
+		upload.ScanStatus = models.UploadScanStatusINFECTED
+	} else {
+		upload.ScanStatus = models.UploadScanStatusCLEAN
+	}
+	return nil
+}
+
+// ScanUpload scans the stored file of a PENDING upload, quarantines it if it is infected, and
+// saves the upload's new scan status.
+func (u *Uploader) ScanUpload(upload *models.Upload) error {
+	if upload.ScanStatus != models.UploadScanStatusPENDING {
+		return nil
+	}
+	if u.scanner == nil {
+		return errors.New("no scanner to scan upload with")
+	}
+
+	key := u.uploadKey(upload)
+	object, err := u.storer.Fetch(key)
+	if err != nil {
+		return err
+	}
+	defer object.Close()
+	// Keep the content, in case it must be moved to quarantine
+	content, err := ioutil.ReadAll(object)
+	if err != nil {
+		return errors.Wrap(err, "could not read stored file")
+	}
+
+	if err := u.scan(upload, bytes.NewReader(content)); err != nil {
+		return err
+	}
+
+	if upload.ScanStatus != models.UploadScanStatusINFECTED {
+		return u.update(u.db, upload)
+	}
+
+	result, err := u.storer.Store(u.uploadKey(upload), bytes.NewReader(content), upload.Checksum)
+	if err != nil {
+		return errors.Wrap(err, "could not quarantine upload")
+	}
+	upload.EncryptionKeyID = encryptionKeyID(result)
+	if !upload.ContentAddressed {
+		if err := u.storer.Delete(key); err != nil {
+			return errors.Wrap(err, "could not delete quarantined upload")
+		}
+		return u.update(u.db, upload)
+	}
+
+	// Other uploads of the same content are quarantined as they are scanned, and the shared
+	// file goes with the last of them
+	upload.ContentAddressed = false
+	return u.withContentLock(*upload.ContentSHA256, func(tx *pop.Connection) error {
+		if err := u.update(tx, upload); err != nil {
+			return err
+		}
+		if err := u.deleteUnreferencedContent(tx, *upload.ContentSHA256); err != nil {
+			return errors.Wrap(err, "could not delete quarantined upload")
+		}
+		return nil
+	})
 }
 
-// PresignedURL returns a URL that can be used to access an Upload's file.
+// update saves changes to an upload
+func (u *Uploader) update(db *pop.Connection, upload *models.Upload) error {
+	verrs, err := db.ValidateAndUpdate(upload)
+	if err != nil {
+		return err
+	} else if verrs.HasAny() {
+		return errors.New(verrs.Error())
+	}
+	return nil
+}
+
+// PresignedURL returns a URL that can be used to access an Upload's file. Files which haven't
+// been scanned clean can't be accessed and return ErrUploadNotClean. Storage which doesn't
+// allow direct access returns storage.ErrNoPresignedURL, and the file must be read with Fetch.
 func (u *Uploader) PresignedURL(upload *models.Upload) (string, error) {
+	if upload.ScanStatus != models.UploadScanStatusCLEAN {
+		return "", ErrUploadNotClean
+	}
 	key := u.uploadKey(upload)
 	url, err := u.storer.PresignedURL(key, upload.ContentType)
 	if err != nil {
-		u.logger.Error("failed to get presigned url", zap.Error(err))
+		if err != storage.ErrNoPresignedURL {
+			u.logger.Error("failed to get presigned url", zap.Error(err))
+		}
 		return "", err
 	}
 	return url, nil
 }
 
-// DeleteUpload removes an Upload from the database and deletes its file from the
-// storer.
-func (u *Uploader) DeleteUpload(upload *models.Upload) error {
-	key := u.uploadKey(upload)
+// Fetch returns the content of an Upload's file. Files which haven't been scanned clean can't
+// be accessed and return ErrUploadNotClean.
+func (u *Uploader) Fetch(upload *models.Upload) (io.ReadCloser, error) {
+	if upload.ScanStatus != models.UploadScanStatusCLEAN {
+		return nil, ErrUploadNotClean
+	}
+	return u.storer.Fetch(u.uploadKey(upload))
+}
 
-	if err := u.storer.Delete(key); err != nil {
+// RewrapUpload encrypts an Upload's file with the current master key, if it wasn't already,
+// and records the key on the Upload. It fails unless files are stored encrypted.
+func (u *Uploader) RewrapUpload(upload *models.Upload) error {
+	rotator, ok := u.storer.(storage.KeyRotator)
+	if !ok {
+		return errors.New("uploads are not stored encrypted")
+	}
+	result, err := rotator.Rewrap(u.uploadKey(upload))
+	if err != nil {
 		return err
 	}
+	upload.EncryptionKeyID = encryptionKeyID(result)
+
+	if upload.ContentAddressed && upload.ScanStatus != models.UploadScanStatusINFECTED {
+		// Every upload of the content shares the rewrapped file
+		return models.UpdateContentEncryptionKeyID(u.db, *upload.ContentSHA256, upload.EncryptionKeyID)
+	}
+	return u.update(u.db, upload)
+}
+
+// CombinedPDFURL returns a URL that can be used to access a single PDF of all of a document's
+// clean uploads, in the order they were uploaded. The PDF is only rendered the first time it
+// is asked for; after that it is found in storage by the checksums of the uploads in it.
+// Infected uploads are left out, and documents with uploads which haven't been scanned yet
+// return ErrUploadNotClean.
+func (u *Uploader) CombinedPDFURL(document *models.Document) (string, error) {
+	key, err := u.combinedPDF(document)
+	if err != nil {
+		return "", err
+	}
+
+	url, err := u.storer.PresignedURL(key, "application/pdf")
+	if err != nil {
+		if err != storage.ErrNoPresignedURL {
+			u.logger.Error("failed to get presigned url", zap.Error(err))
+		}
+		return "", err
+	}
+	return url, nil
+}
 
-	if err := models.DeleteUpload(u.db, upload); err != nil {
+// FetchCombinedPDF returns the content of the PDF of all of a document's clean uploads, for
+// storage which doesn't allow CombinedPDFURL.
+func (u *Uploader) FetchCombinedPDF(document *models.Document) (io.ReadCloser, error) {
+	key, err := u.combinedPDF(document)
+	if err != nil {
+		return nil, err
+	}
+	return u.storer.Fetch(key)
+}
+
+// combinedPDF renders the combined PDF of a document if it isn't already in storage, and
+// returns its key
+func (u *Uploader) combinedPDF(document *models.Document) (string, error) {
+	uploads := models.Uploads{}
+	for _, upload := range document.Uploads {
+		switch upload.ScanStatus {
+		case models.UploadScanStatusCLEAN:
+			uploads = append(uploads, upload)
+		case models.UploadScanStatusINFECTED:
+			continue
+		default:
+			return "", ErrUploadNotClean
+		}
+	}
+	if len(uploads) == 0 {
+		return "", ErrNoUploads
+	}
+
+	key := u.combinedKey(document, uploads)
+	if _, err := u.storer.Head(key); err != nil {
+		if err := u.renderCombinedPDF(key, uploads); err != nil {
+			u.logger.Error("failed to render combined PDF", zap.String("document_id", document.ID.String()), zap.Error(err))
+			return "", err
+		}
+	}
+	return key, nil
+}
+
+// renderCombinedPDF combines the files of uploads into a PDF and stores it at key
+func (u *Uploader) renderCombinedPDF(key string, uploads models.Uploads) error {
+	files := make([]paperwork.File, len(uploads))
+	for i := range uploads {
+		object, err := u.storer.Fetch(u.uploadKey(&uploads[i]))
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadAll(object)
+		object.Close()
+		if err != nil {
+			return errors.Wrap(err, "could not read stored file")
+		}
+		files[i] = paperwork.File{ContentType: uploads[i].ContentType, Data: data}
+	}
+
+	combined, err := paperwork.CombineFiles(files)
+	if err != nil {
 		return err
 	}
+
+	data := bytes.NewReader(combined)
+	checksum, err := storage.ComputeChecksum(data)
+	if err != nil {
+		return err
+	}
+	_, err = u.storer.Store(key, data, checksum)
+	return err
+}
+
+// combinedKey returns the key of the combined PDF of uploads, which changes whenever an upload is
+// added or removed
+func (u *Uploader) combinedKey(document *models.Document, uploads models.Uploads) string {
+	hash := sha256.New()
+	for _, upload := range uploads {
+		io.WriteString(hash, upload.Checksum)
+		io.WriteString(hash, "\n")
+	}
+	return u.storer.Key("documents", document.ID.String(), "combined", hex.EncodeToString(hash.Sum(nil))+".pdf")
+}
+
+// DeleteUpload removes an Upload from the database and deletes its file from the
+// storer. The database goes first so a failure can only leave behind an orphaned file,
+// which Reconcile will find, rather than an Upload without one. Content-addressed files are
+// kept until no other upload refers to them.
+func (u *Uploader) DeleteUpload(upload *models.Upload) error {
+	if upload.ContentAddressed && upload.ScanStatus != models.UploadScanStatusINFECTED {
+		err := u.withContentLock(*upload.ContentSHA256, func(tx *pop.Connection) error {
+			if err := models.DeleteUpload(tx, upload); err != nil {
+				return err
+			}
+			return u.deleteUnreferencedContent(tx, *upload.ContentSHA256)
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		if err := models.DeleteUpload(u.db, upload); err != nil {
+			return err
+		}
+		if err := u.storer.Delete(u.uploadKey(upload)); err != nil {
+			return err
+		}
+	}
+
+	if upload.PreviewStatus == models.UploadPreviewStatusREADY {
+		if err := u.storer.Delete(u.previewKey(upload)); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// encryptionKeyID returns the ID of the master key a stored file was encrypted with, if it was
+func encryptionKeyID(result *storage.StoreResult) *string {
+	if result == nil || result.KeyID == "" {
+		return nil
+	}
+	return &result.KeyID
+}
+
+// uploadKey returns the key of an upload's file
 func (u *Uploader) uploadKey(upload *models.Upload) string {
-	return u.storer.Key("documents", upload.DocumentID.String(), "uploads", upload.ID.String())
+	return UploadKey(u.storer, upload)
+}
+
+// contentKey returns the key of the content-addressed file with the given SHA-256
+func (u *Uploader) contentKey(contentSHA256 string) string {
+	return u.storer.Key("content", "sha256", contentSHA256)
+}
+
+// UploadKey returns the key under which storer keeps an upload's file. Infected files are kept
+// apart from the rest, in quarantine, so nothing else can reach them.
+func UploadKey(storer storage.FileStorer, upload *models.Upload) string {
+	if upload.ScanStatus == models.UploadScanStatusINFECTED {
+		return storer.Key("quarantine", "documents", upload.DocumentID.String(), "uploads", upload.ID.String())
+	}
+	if upload.ContentAddressed {
+		return storer.Key("content", "sha256", *upload.ContentSHA256)
+	}
+	return storer.Key("documents", upload.DocumentID.String(), "uploads", upload.ID.String())
 }
@@ -1,16 +1,26 @@
 package uploader
 
 import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
 	"log"
 	"os"
 	"path"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/go-openapi/runtime"
 	"github.com/gobuffalo/pop"
+	"github.com/gobuffalo/validate"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/zap"
 
+	"github.com/transcom/mymove/pkg/models"
+	scannerTest "github.com/transcom/mymove/pkg/scanner/test"
 	"github.com/transcom/mymove/pkg/storage"
 	storageTest "github.com/transcom/mymove/pkg/storage/test"
 	"github.com/transcom/mymove/pkg/testdatagen"
@@ -83,7 +93,7 @@ func (suite *UploaderSuite) TestUploadFromLocalFile() {
 		suite.T().Fatalf("couldn't create document: %s", err)
 	}
 
-	up := NewUploader(suite.db, suite.logger, suite.storer)
+	up := NewUploader(suite.db, suite.logger, suite.storer, scannerTest.NewFakeScanner(true))
 	file := suite.fixture("test.pdf")
 
 	upload, verrs, err := up.CreateUpload(document.ID, document.ServiceMember.UserID, file)
@@ -91,6 +101,12 @@ func (suite *UploaderSuite) TestUploadFromLocalFile() {
 	suite.Nil(verrs, "failed to validate upload")
 	suite.Equal(upload.ContentType, "application/pdf")
 	suite.Equal(upload.Checksum, "nOE6HwzyE4VEDXn67ULeeA==")
+	suite.Equal(models.UploadScanStatusCLEAN, upload.ScanStatus)
+	suite.NotNil(upload.ScannedAt)
+
+	url, err := up.PresignedURL(upload)
+	suite.Nil(err)
+	suite.NotEmpty(url)
 }
 
 func (suite *UploaderSuite) TestUploadFromLocalFileZeroLength() {
@@ -99,7 +115,7 @@ func (suite *UploaderSuite) TestUploadFromLocalFileZeroLength() {
 		suite.T().Fatalf("couldn't create document: %s", err)
 	}
 
-	up := NewUploader(suite.db, suite.logger, suite.storer)
+	up := NewUploader(suite.db, suite.logger, suite.storer, scannerTest.NewFakeScanner(true))
 	file := suite.fixture("empty.pdf")
 
 	upload, verrs, err := up.CreateUpload(document.ID, document.ServiceMember.UserID, file)
@@ -107,3 +123,566 @@ func (suite *UploaderSuite) TestUploadFromLocalFileZeroLength() {
 	suite.Nil(verrs, "failed to validate upload")
 	suite.Nil(upload, "returned an upload when erroring")
 }
+
+func (suite *UploaderSuite) TestUploadInfectedFile() {
+	document, err := testdatagen.MakeDocument(suite.db, nil, "")
+	if err != nil {
+		suite.T().Fatalf("couldn't create document: %s", err)
+	}
+
+	fakeS3 := storageTest.NewFakeS3Storage(true)
+	up := NewUploader(suite.db, suite.logger, fakeS3, scannerTest.NewFakeScanner(true))
+	file := suite.fixture("infected.pdf")
+
+	upload, verrs, err := up.CreateUpload(document.ID, document.ServiceMember.UserID, file)
+	suite.Nil(err, "failed to create upload")
+	suite.Nil(verrs, "failed to validate upload")
+	suite.Equal(models.UploadScanStatusINFECTED, upload.ScanStatus)
+	suite.Equal(up.storer.Key("quarantine", "documents", document.ID.String(), "uploads", upload.ID.String()), fakeS3.PutFiles[0].Key)
+
+	_, err = up.PresignedURL(upload)
+	suite.Equal(ErrUploadNotClean, err)
+
+	suite.Nil(up.DeleteUpload(upload))
+	suite.Empty(fakeS3.PutFiles)
+}
+
+func (suite *UploaderSuite) TestScanPendingUpload() {
+	document, err := testdatagen.MakeDocument(suite.db, nil, "")
+	if err != nil {
+		suite.T().Fatalf("couldn't create document: %s", err)
+	}
+
+	// Without a scanner uploads are left pending, and can't be opened
+	fakeS3 := storageTest.NewFakeS3Storage(true)
+	up := NewUploader(suite.db, suite.logger, fakeS3, nil)
+	clean, _, err := up.CreateUpload(document.ID, document.ServiceMember.UserID, suite.fixture("test.pdf"))
+	suite.Nil(err, "failed to create upload")
+	infected, _, err := up.CreateUpload(document.ID, document.ServiceMember.UserID, suite.fixture("infected.pdf"))
+	suite.Nil(err, "failed to create upload")
+	suite.Equal(models.UploadScanStatusPENDING, clean.ScanStatus)
+	_, err = up.PresignedURL(clean)
+	suite.Equal(ErrUploadNotClean, err)
+
+	pending, err := models.FetchPendingUploads(suite.db, 10)
+	suite.Nil(err)
+	suite.Len(pending, 2)
+
+	fakeScanner := scannerTest.NewFakeScanner(true)
+	up = NewUploader(suite.db, suite.logger, fakeS3, fakeScanner)
+	suite.Nil(up.ScanUpload(clean))
+	suite.Nil(up.ScanUpload(infected))
+	suite.Equal(2, fakeScanner.Scanned)
+
+	suite.Equal(models.UploadScanStatusCLEAN, clean.ScanStatus)
+	suite.Equal(models.UploadScanStatusINFECTED, infected.ScanStatus)
+	keys := []string{}
+	for _, file := range fakeS3.PutFiles {
+		keys = append(keys, file.Key)
+	}
+	suite.Contains(keys, up.uploadKey(clean))
+	suite.Contains(keys, "quarantine/"+up.storer.Key("documents", document.ID.String(), "uploads", infected.ID.String()))
+	suite.Len(keys, 2)
+
+	pending, err = models.FetchPendingUploads(suite.db, 10)
+	suite.Nil(err)
+	suite.Empty(pending)
+}
+
+func (suite *UploaderSuite) TestUploadViolatesDocumentPolicy() {
+	document, err := testdatagen.MakeDocument(suite.db, nil, "")
+	if err != nil {
+		suite.T().Fatalf("couldn't create document: %s", err)
+	}
+	document.DocumentType = models.DocumentTypeADVANCEWORKSHEET
+	suite.db.ValidateAndSave(&document)
+
+	up := NewUploader(suite.db, suite.logger, suite.storer, scannerTest.NewFakeScanner(true))
+	// Infected uploads don't count towards the limit
+	infected, verrs, err := up.CreateUpload(document.ID, document.ServiceMember.UserID, suite.fixture("infected.pdf"))
+	suite.Nil(err, "failed to create upload")
+	suite.Nil(verrs, "failed to validate upload")
+	suite.Equal(models.UploadScanStatusINFECTED, infected.ScanStatus)
+	for i := 0; i < models.DocumentTypeADVANCEWORKSHEET.Policy().MaxUploads; i++ {
+		_, verrs, err := up.CreateUpload(document.ID, document.ServiceMember.UserID, suite.fixture("test.pdf"))
+		suite.Nil(err, "failed to create upload")
+		suite.Nil(verrs, "failed to validate upload")
+	}
+
+	upload, verrs, err := up.CreateUpload(document.ID, document.ServiceMember.UserID, suite.fixture("test.pdf"))
+	suite.Nil(err)
+	suite.Nil(upload)
+	suite.Equal([]string{"No more than 2 files can be uploaded to advance worksheet documents."}, verrs.Get("uploads"))
+}
+
+func (suite *UploaderSuite) TestConcurrentUploadsRespectDocumentPolicy() {
+	document, err := testdatagen.MakeDocument(suite.db, nil, "")
+	if err != nil {
+		suite.T().Fatalf("couldn't create document: %s", err)
+	}
+	document.DocumentType = models.DocumentTypeADVANCEWORKSHEET
+	suite.db.ValidateAndSave(&document)
+
+	up := NewUploader(suite.db, suite.logger, suite.storer, scannerTest.NewFakeScanner(true))
+	const uploaders = 5
+	files := make([]*runtime.File, uploaders)
+	for i := range files {
+		files[i] = suite.fixture("test.pdf")
+	}
+	results := make([]*validate.Errors, uploaders)
+	errs := make([]error, uploaders)
+	var wg sync.WaitGroup
+	for i := range files {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, results[i], errs[i] = up.CreateUpload(document.ID, document.ServiceMember.UserID, files[i])
+		}(i)
+	}
+	wg.Wait()
+
+	// Only as many as the policy allows get in
+	created := 0
+	for i, verrs := range results {
+		suite.Nil(errs[i])
+		if verrs == nil {
+			created++
+		}
+	}
+	suite.Equal(models.DocumentTypeADVANCEWORKSHEET.Policy().MaxUploads, created)
+	count, err := suite.db.Where("document_id = ?", document.ID).Count(&models.Upload{})
+	suite.Nil(err)
+	suite.Equal(created, count)
+}
+
+func (suite *UploaderSuite) TestReconcile() {
+	document, err := testdatagen.MakeDocument(suite.db, nil, "")
+	if err != nil {
+		suite.T().Fatalf("couldn't create document: %s", err)
+	}
+
+	fakeS3 := storageTest.NewFakeS3Storage(true)
+	up := NewUploader(suite.db, suite.logger, fakeS3, scannerTest.NewFakeScanner(true))
+	uploads := make([]*models.Upload, 3)
+	for i := range uploads {
+		uploads[i], _, err = up.CreateUpload(document.ID, document.ServiceMember.UserID, suite.fixture("test.pdf"))
+		suite.Nil(err, "failed to create upload")
+	}
+	report, err := up.Reconcile(0, false)
+	suite.Nil(err)
+	suite.False(report.HasAny())
+
+	orphanKey := fakeS3.Key("documents", document.ID.String(), "uploads", "orphan")
+	fakeS3.Store(orphanKey, suite.fixture("test.pdf").Data, "")
+	missing, mismatched := uploads[1], uploads[2]
+	suite.Nil(fakeS3.Delete(up.uploadKey(missing)))
+	mismatched.Bytes = 1
+	suite.db.ValidateAndUpdate(mismatched)
+
+	report, err = up.Reconcile(0, false)
+	suite.Nil(err)
+	suite.Len(report.OrphanedObjects, 1)
+	suite.Equal(orphanKey, report.OrphanedObjects[0].Key)
+	suite.Len(report.MissingObjects, 1)
+	suite.Equal(missing.ID, report.MissingObjects[0].ID)
+	suite.Len(report.ChecksumMismatches, 1)
+	suite.Equal(mismatched.ID, report.ChecksumMismatches[0].Upload.ID)
+
+	// Recently changed files and uploads are left alone
+	report, err = up.Reconcile(time.Hour, false)
+	suite.Nil(err)
+	suite.False(report.HasAny())
+
+	report, err = up.Reconcile(0, false)
+	suite.Nil(err)
+	suite.Nil(up.FixReconciled(report))
+	report, err = up.Reconcile(0, false)
+	suite.Nil(err)
+	suite.False(report.HasAny())
+
+	suite.Nil(suite.db.Find(mismatched, mismatched.ID))
+	suite.Equal(models.UploadScanStatusPENDING, mismatched.ScanStatus)
+	count, err := suite.db.Where("id = ?", missing.ID).Count(&models.Upload{})
+	suite.Nil(err)
+	suite.Equal(0, count)
+}
+
+func (suite *UploaderSuite) TestReconcileEncrypted() {
+	document, err := testdatagen.MakeDocument(suite.db, nil, "")
+	if err != nil {
+		suite.T().Fatalf("couldn't create document: %s", err)
+	}
+
+	fakeS3 := storageTest.NewFakeS3Storage(true)
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("k"), 32))
+	keyring, err := storage.NewKeyring("test:" + key)
+	suite.Nil(err)
+	up := NewUploader(suite.db, suite.logger, storage.NewEncrypted(fakeS3, keyring, suite.logger), scannerTest.NewFakeScanner(true))
+
+	upload, _, err := up.CreateUpload(document.ID, document.ServiceMember.UserID, suite.fixture("test.pdf"))
+	suite.Nil(err, "failed to create upload")
+	mismatched, _, err := up.CreateUpload(document.ID, document.ServiceMember.UserID, suite.fixture("test.pdf"))
+	suite.Nil(err, "failed to create upload")
+	mismatched.Bytes = 1
+	suite.db.ValidateAndUpdate(mismatched)
+
+	// Encrypted files can't be checked without fetching them, so they aren't reported as fine
+	report, err := up.Reconcile(0, false)
+	suite.Nil(err)
+	suite.Empty(report.ChecksumMismatches)
+	suite.Len(report.UnverifiedUploads, 2)
+
+	report, err = up.Reconcile(0, true)
+	suite.Nil(err)
+	suite.Empty(report.UnverifiedUploads)
+	if suite.Len(report.ChecksumMismatches, 1) {
+		suite.Equal(mismatched.ID, report.ChecksumMismatches[0].Upload.ID)
+		suite.Equal(upload.Bytes, report.ChecksumMismatches[0].Object.Bytes)
+		suite.Equal(upload.Checksum, report.ChecksumMismatches[0].Object.Checksum)
+	}
+
+	suite.Nil(up.FixReconciled(report))
+	report, err = up.Reconcile(0, true)
+	suite.Nil(err)
+	suite.False(report.HasAny())
+	suite.Empty(report.UnverifiedUploads)
+}
+
+func (suite *UploaderSuite) TestEncryptedUploads() {
+	document, err := testdatagen.MakeDocument(suite.db, nil, "")
+	if err != nil {
+		suite.T().Fatalf("couldn't create document: %s", err)
+	}
+
+	fakeS3 := storageTest.NewFakeS3Storage(true)
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("k"), 32))
+	keyring, err := storage.NewKeyring("old:" + key)
+	suite.Nil(err)
+	up := NewUploader(suite.db, suite.logger, storage.NewEncrypted(fakeS3, keyring, suite.logger), scannerTest.NewFakeScanner(true))
+
+	upload, verrs, err := up.CreateUpload(document.ID, document.ServiceMember.UserID, suite.fixture("test.pdf"))
+	suite.Nil(err, "failed to create upload")
+	suite.Nil(verrs, "failed to validate upload")
+	suite.Equal("old", *upload.EncryptionKeyID)
+
+	_, err = up.PresignedURL(upload)
+	suite.Equal(storage.ErrNoPresignedURL, err)
+	content, err := up.Fetch(upload)
+	suite.Nil(err)
+	checksum, err := storage.ComputeChecksum(readSeeker(suite, content))
+	suite.Nil(err)
+	suite.Equal(upload.Checksum, checksum)
+
+	// After the master key is rotated, files are rewrapped with the new one
+	keyring, err = storage.NewKeyring("new:" + key + ",old:" + key)
+	suite.Nil(err)
+	up = NewUploader(suite.db, suite.logger, storage.NewEncrypted(fakeS3, keyring, suite.logger), nil)
+	toRewrap, err := models.FetchUploadsToRewrap(suite.db, "new", 10)
+	suite.Nil(err)
+	suite.Len(toRewrap, 1)
+	suite.Nil(up.RewrapUpload(&toRewrap[0]))
+	suite.Equal("new", *toRewrap[0].EncryptionKeyID)
+	toRewrap, err = models.FetchUploadsToRewrap(suite.db, "new", 10)
+	suite.Nil(err)
+	suite.Empty(toRewrap)
+}
+
+func readSeeker(suite *UploaderSuite, content io.ReadCloser) io.ReadSeeker {
+	defer content.Close()
+	data, err := ioutil.ReadAll(content)
+	suite.Nil(err)
+	return bytes.NewReader(data)
+}
+
+func (suite *UploaderSuite) TestUploadSession() {
+	document, err := testdatagen.MakeDocument(suite.db, nil, "")
+	if err != nil {
+		suite.T().Fatalf("couldn't create document: %s", err)
+	}
+	content, err := ioutil.ReadAll(suite.fixture("test.pdf").Data)
+	suite.Nil(err)
+	checksum, err := storage.ComputeChecksum(bytes.NewReader(content))
+	suite.Nil(err)
+
+	fakeS3 := storageTest.NewFakeS3Storage(true)
+	up := NewUploader(suite.db, suite.logger, fakeS3, scannerTest.NewFakeScanner(true))
+	session, verrs, err := up.CreateUploadSession(document.ID, document.ServiceMember.UserID, "test.pdf", int64(len(content)), checksum)
+	suite.Nil(err)
+	suite.Nil(verrs)
+
+	half := len(content) / 2
+	suite.Nil(up.AppendChunk(session, 0, bytes.NewReader(content[:half])))
+	suite.Equal(int64(half), session.ReceivedBytes)
+	_, _, err = up.FinalizeUploadSession(session)
+	suite.Equal(ErrUploadSessionIncomplete, err)
+
+	// Chunks must pick up where the last one ended, and not run past the end of the file
+	suite.Equal(ErrChunkOffset, up.AppendChunk(session, 0, bytes.NewReader(content)))
+	suite.Equal(ErrChunkTooLarge, up.AppendChunk(session, int64(half), bytes.NewReader(content)))
+
+	suite.Nil(up.AppendChunk(session, int64(half), bytes.NewReader(content[half:])))
+	upload, verrs, err := up.FinalizeUploadSession(session)
+	suite.Nil(err)
+	suite.Nil(verrs)
+	suite.Equal("test.pdf", upload.Filename)
+	suite.Equal(checksum, upload.Checksum)
+	suite.Equal("application/pdf", upload.ContentType)
+	suite.Equal(models.UploadScanStatusCLEAN, upload.ScanStatus)
+
+	// The session and its chunks are gone
+	count, err := suite.db.Where("id = ?", session.ID).Count(&models.UploadSession{})
+	suite.Nil(err)
+	suite.Equal(0, count)
+	suite.Len(fakeS3.PutFiles, 1)
+}
+
+func (suite *UploaderSuite) TestUploadSessionConcurrentAppends() {
+	document, err := testdatagen.MakeDocument(suite.db, nil, "")
+	if err != nil {
+		suite.T().Fatalf("couldn't create document: %s", err)
+	}
+
+	fakeS3 := storageTest.NewFakeS3Storage(true)
+	up := NewUploader(suite.db, suite.logger, fakeS3, scannerTest.NewFakeScanner(true))
+	session, verrs, err := up.CreateUploadSession(document.ID, document.ServiceMember.UserID, "test.txt", 16, "unchecked")
+	suite.Nil(err)
+	suite.Nil(verrs)
+
+	// Each request reads the session before any of them appends, and sends a different first chunk
+	const appenders = 5
+	chunks := make([][]byte, appenders)
+	results := make([]error, appenders)
+	var wg sync.WaitGroup
+	for i := range chunks {
+		chunks[i] = bytes.Repeat([]byte{byte('a' + i)}, 8)
+		wg.Add(1)
+		go func(i int, session models.UploadSession) {
+			defer wg.Done()
+			results[i] = up.AppendChunk(&session, 0, bytes.NewReader(chunks[i]))
+		}(i, *session)
+	}
+	wg.Wait()
+
+	// Only one of them is recorded, and the stored chunk is the one it sent
+	winner := -1
+	for i, err := range results {
+		if err == nil {
+			suite.Equal(-1, winner, "more than one chunk was appended at offset 0")
+			winner = i
+		} else {
+			suite.Equal(ErrChunkOffset, err)
+		}
+	}
+	if winner == -1 {
+		suite.FailNow("no chunk was appended")
+	}
+	suite.Nil(suite.db.Find(session, session.ID))
+	suite.Equal(int64(8), session.ReceivedBytes)
+	suite.Equal(1, session.Chunks)
+
+	stored, err := fakeS3.Fetch(up.chunkKey(session, 0))
+	suite.Nil(err)
+	content, err := ioutil.ReadAll(stored)
+	suite.Nil(err)
+	suite.Equal(chunks[winner], content)
+}
+
+func (suite *UploaderSuite) TestUploadSessionConcurrentFinalize() {
+	document, err := testdatagen.MakeDocument(suite.db, nil, "")
+	if err != nil {
+		suite.T().Fatalf("couldn't create document: %s", err)
+	}
+	content, err := ioutil.ReadAll(suite.fixture("test.pdf").Data)
+	suite.Nil(err)
+	checksum, err := storage.ComputeChecksum(bytes.NewReader(content))
+	suite.Nil(err)
+
+	up := NewUploader(suite.db, suite.logger, storageTest.NewFakeS3Storage(true), scannerTest.NewFakeScanner(true))
+	session, verrs, err := up.CreateUploadSession(document.ID, document.ServiceMember.UserID, "test.pdf", int64(len(content)), checksum)
+	suite.Nil(err)
+	suite.Nil(verrs)
+	suite.Nil(up.AppendChunk(session, 0, bytes.NewReader(content)))
+
+	// Each request reads the complete session before any of them finalizes it
+	const finalizers = 5
+	uploads := make([]*models.Upload, finalizers)
+	results := make([]error, finalizers)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int, session models.UploadSession) {
+			defer wg.Done()
+			uploads[i], _, results[i] = up.FinalizeUploadSession(&session)
+		}(i, *session)
+	}
+	wg.Wait()
+
+	// Only one of them creates an upload
+	created := 0
+	for i, err := range results {
+		if err == nil {
+			suite.NotNil(uploads[i])
+			created++
+		} else {
+			suite.Equal(ErrUploadSessionNotFound, err)
+		}
+	}
+	suite.Equal(1, created)
+	count, err := suite.db.Where("document_id = ?", document.ID).Count(&models.Upload{})
+	suite.Nil(err)
+	suite.Equal(1, count)
+}
+
+func (suite *UploaderSuite) TestUploadSessionChecksumMismatch() {
+	document, err := testdatagen.MakeDocument(suite.db, nil, "")
+	if err != nil {
+		suite.T().Fatalf("couldn't create document: %s", err)
+	}
+
+	up := NewUploader(suite.db, suite.logger, storageTest.NewFakeS3Storage(true), scannerTest.NewFakeScanner(true))
+	_, verrs, err := up.CreateUploadSession(document.ID, document.ServiceMember.UserID, "test.pdf", 0, "")
+	suite.Nil(err)
+	suite.NotEmpty(verrs.Get("bytes"))
+	suite.NotEmpty(verrs.Get("checksum"))
+
+	session, verrs, err := up.CreateUploadSession(document.ID, document.ServiceMember.UserID, "test.pdf", 7, "nOE6HwzyE4VEDXn67ULeeA==")
+	suite.Nil(err)
+	suite.Nil(verrs)
+	suite.Nil(up.AppendChunk(session, 0, strings.NewReader("content")))
+	upload, verrs, err := up.FinalizeUploadSession(session)
+	suite.Nil(err)
+	suite.Nil(upload)
+	suite.NotEmpty(verrs.Get("checksum"))
+
+	count, err := suite.db.Where("document_id = ?", document.ID).Count(&models.Upload{})
+	suite.Nil(err)
+	suite.Equal(0, count)
+}
+
+func (suite *UploaderSuite) TestGeneratePreviews() {
+	document, err := testdatagen.MakeDocument(suite.db, nil, "")
+	if err != nil {
+		suite.T().Fatalf("couldn't create document: %s", err)
+	}
+
+	fakeS3 := storageTest.NewFakeS3Storage(true)
+	up := NewUploader(suite.db, suite.logger, fakeS3, scannerTest.NewFakeScanner(true))
+	image, _, err := up.CreateUpload(document.ID, document.ServiceMember.UserID, suite.fixture("test.png"))
+	suite.Nil(err)
+	pdf, _, err := up.CreateUpload(document.ID, document.ServiceMember.UserID, suite.fixture("test.pdf"))
+	suite.Nil(err)
+	suite.Equal(models.UploadPreviewStatusPENDING, image.PreviewStatus)
+	_, err = up.FetchPreview(image)
+	suite.Equal(ErrNoPreview, err)
+
+	worker := NewPreviewWorker(suite.db, suite.logger, fakeS3, time.Minute)
+	worker.RunOnce()
+	toPreview, err := models.FetchUploadsToPreview(suite.db, 10)
+	suite.Nil(err)
+	suite.Empty(toPreview)
+
+	suite.Nil(suite.db.Find(image, image.ID))
+	suite.Equal(models.UploadPreviewStatusREADY, image.PreviewStatus)
+	preview, err := up.FetchPreview(image)
+	suite.Nil(err)
+	contentType, err := storage.DetectContentType(readSeeker(suite, preview))
+	suite.Nil(err)
+	suite.Equal("image/jpeg", contentType)
+
+	// The PDF fixture is only text, which can't be rendered here, so it is shown by a placeholder
+	suite.Nil(suite.db.Find(pdf, pdf.ID))
+	suite.Equal(models.UploadPreviewStatusUNAVAILABLE, pdf.PreviewStatus)
+	_, err = fakeS3.Head(up.previewKey(pdf))
+	suite.Equal(storage.ErrNotFound, err)
+	placeholder, err := up.FetchPreview(pdf)
+	suite.Nil(err)
+	contentType, err = storage.DetectContentType(readSeeker(suite, placeholder))
+	suite.Nil(err)
+	suite.Equal("image/jpeg", contentType)
+
+	// Previews are deleted along with their upload
+	suite.Nil(up.DeleteUpload(image))
+	_, err = fakeS3.Head(up.previewKey(image))
+	suite.Equal(storage.ErrNotFound, err)
+}
+
+func (suite *UploaderSuite) TestCorruptUploadsDontBlockPreviews() {
+	fakeS3 := storageTest.NewFakeS3Storage(true)
+	up := NewUploader(suite.db, suite.logger, fakeS3, scannerTest.NewFakeScanner(true))
+
+	// More unreadable PDFs than the worker takes at once, uploaded before a good image. Documents
+	// only hold so many uploads, so they are spread over a few.
+	var corrupt []*models.Upload
+	var document models.Document
+	for i := 0; i < previewBatchSize+1; i++ {
+		if i%10 == 0 {
+			var err error
+			if document, err = testdatagen.MakeDocument(suite.db, nil, ""); err != nil {
+				suite.T().Fatalf("couldn't create document: %s", err)
+			}
+		}
+		upload, _, err := up.CreateUpload(document.ID, document.ServiceMember.UserID, suite.fixture("corrupt.pdf"))
+		suite.Nil(err)
+		corrupt = append(corrupt, upload)
+	}
+	image, _, err := up.CreateUpload(document.ID, document.ServiceMember.UserID, suite.fixture("test.png"))
+	suite.Nil(err)
+
+	worker := NewPreviewWorker(suite.db, suite.logger, fakeS3, time.Minute)
+	worker.RunOnce()
+
+	for _, upload := range corrupt {
+		suite.Nil(suite.db.Find(upload, upload.ID))
+		suite.Equal(models.UploadPreviewStatusUNAVAILABLE, upload.PreviewStatus)
+	}
+	suite.Nil(suite.db.Find(image, image.ID))
+	suite.Equal(models.UploadPreviewStatusREADY, image.PreviewStatus)
+}
+
+func (suite *UploaderSuite) TestContentAddressedUploads() {
+	document, err := testdatagen.MakeDocument(suite.db, nil, "")
+	if err != nil {
+		suite.T().Fatalf("couldn't create document: %s", err)
+	}
+	otherDocument, err := testdatagen.MakeDocument(suite.db, nil, "")
+	if err != nil {
+		suite.T().Fatalf("couldn't create document: %s", err)
+	}
+
+	fakeS3 := storageTest.NewFakeS3Storage(true)
+	up := NewUploader(suite.db, suite.logger, fakeS3, scannerTest.NewFakeScanner(true))
+	up.SetContentAddressed()
+
+	// The same file uploaded to two documents is only stored once
+	upload, _, err := up.CreateUpload(document.ID, document.ServiceMember.UserID, suite.fixture("test.pdf"))
+	suite.Nil(err)
+	otherUpload, _, err := up.CreateUpload(otherDocument.ID, otherDocument.ServiceMember.UserID, suite.fixture("test.pdf"))
+	suite.Nil(err)
+	suite.True(upload.ContentAddressed)
+	suite.Equal(*upload.ContentSHA256, *otherUpload.ContentSHA256)
+	suite.Len(fakeS3.PutFiles, 1)
+	key := up.uploadKey(upload)
+	suite.Equal(key, up.uploadKey(otherUpload))
+
+	// Only the same document's uploads are its duplicates
+	duplicate, err := models.FetchDuplicateUpload(suite.db, upload)
+	suite.Nil(err)
+	suite.Nil(duplicate)
+	again, _, err := up.CreateUpload(document.ID, document.ServiceMember.UserID, suite.fixture("test.pdf"))
+	suite.Nil(err)
+	duplicate, err = models.FetchDuplicateUpload(suite.db, again)
+	suite.Nil(err)
+	suite.Equal(upload.ID, duplicate.ID)
+
+	// The file goes with the last upload which refers to it
+	suite.Nil(up.DeleteUpload(upload))
+	suite.Nil(up.DeleteUpload(again))
+	_, err = fakeS3.Head(key)
+	suite.Nil(err)
+	content, err := up.Fetch(otherUpload)
+	suite.Nil(err)
+	content.Close()
+	suite.Nil(up.DeleteUpload(otherUpload))
+	_, err = fakeS3.Head(key)
+	suite.Equal(storage.ErrNotFound, err)
+}
@@ -188,6 +188,7 @@ export class PpmWeight extends Component {
         currentPpm.pickup_postal_code,
         currentPpm.destination_postal_code,
         currentWeight,
+        currentPpm.additional_pickup_postal_code,
       );
     }
   }
@@ -225,6 +226,7 @@ export class PpmWeight extends Component {
       currentPpm.pickup_postal_code,
       currentPpm.destination_postal_code,
       this.state.pendingPpmWeight,
+      currentPpm.additional_pickup_postal_code,
     );
   };
   render() {
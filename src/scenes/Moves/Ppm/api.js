@@ -42,11 +42,13 @@ export async function GetPpmWeightEstimate(
   originZip,
   destZip,
   weightEstimate,
+  additionalPickupZip,
 ) {
   const client = await getClient();
   const response = await client.apis.ppm.showPPMEstimate({
     planned_move_date: moveDate,
     origin_zip: originZip,
+    additional_pickup_zip: additionalPickupZip || undefined,
     destination_zip: destZip,
     weight_estimate: weightEstimate,
   });
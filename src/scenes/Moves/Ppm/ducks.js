@@ -43,11 +43,18 @@ export function getPpmWeightEstimate(
   originZip,
   destZip,
   weightEstimate,
+  additionalPickupZip,
 ) {
   const action = ReduxHelpers.generateAsyncActions('GET_PPM_ESTIMATE');
   return function(dispatch, getState) {
     dispatch(action.start());
-    return GetPpmWeightEstimate(moveDate, originZip, destZip, weightEstimate)
+    return GetPpmWeightEstimate(
+      moveDate,
+      originZip,
+      destZip,
+      weightEstimate,
+      additionalPickupZip,
+    )
       .then(item => dispatch(action.success(item)))
       .catch(error => dispatch(action.error(error)));
   };
@@ -14,7 +14,23 @@ import './office.css';
 // Page displays an image or PDF.
 const Page = function(props) {
   let content;
-  if (props.contentType === 'application/pdf') {
+  if (!props.url) {
+    content = (
+      <div className="pdf-placeholder">
+        {props.filename && <span className="filename">{props.filename}</span>}
+        {props.scanStatus === 'INFECTED'
+          ? 'This file contains malware and cannot be viewed.'
+          : 'This file is being scanned for viruses and can be viewed shortly.'}
+      </div>
+    );
+  } else if (props.previewUrl) {
+    // The small preview loads quickly; the full file is a click away
+    content = (
+      <a href={props.url} target="_blank">
+        <img src={props.previewUrl} width="100%" alt="document upload" />
+      </a>
+    );
+  } else if (props.contentType === 'application/pdf') {
     content = (
       <div className="pdf-placeholder">
         {props.filename && <span className="filename">{props.filename}</span>}
@@ -46,10 +62,12 @@ class OrdersInfo extends Component {
     if (orders && orders.uploaded_orders) {
       uploads = orders.uploaded_orders.uploads.map(upload => (
         <Page
-          key={upload.url}
+          key={upload.id}
           url={upload.url}
+          previewUrl={upload.preview_url}
           filename={upload.filename}
           contentType={upload.content_type}
+          scanStatus={upload.scan_status}
         />
       ));
     } else {
@@ -213,6 +213,7 @@ class EditWeight extends Component {
         currentPpm.pickup_postal_code,
         currentPpm.destination_postal_code,
         newValue,
+        currentPpm.additional_pickup_postal_code,
       );
     } else {
       this.debouncedGetPpmWeightEstimate.cancel();
@@ -25,9 +25,21 @@ export class UploadsTable extends Component {
           {this.props.uploads.map(upload => (
             <tr key={upload.id}>
               <td>
-                <a href={upload.url} target="_blank">
-                  {upload.filename}
-                </a>
+                {upload.url ? (
+                  <a href={upload.url} target="_blank">
+                    {upload.filename}
+                  </a>
+                ) : (
+                  <span>
+                    {upload.filename}{' '}
+                    {upload.scan_status === 'INFECTED'
+                      ? '(blocked: malware detected)'
+                      : '(scanning for viruses)'}
+                  </span>
+                )}
+                {upload.duplicate_of && (
+                  <span> (the same file was uploaded more than once)</span>
+                )}
               </td>
               <td>{moment(upload.created_at).format('LLL')}</td>
               <td>{bytes(upload.bytes)}</td>
@@ -7,7 +7,7 @@ import { bindActionCreators } from 'redux';
 import PropTypes from 'prop-types';
 import { CreateUpload, DeleteUpload } from 'shared/api.js';
 import isMobile from 'is-mobile';
-import { concat, reject } from 'lodash';
+import { concat, get, reject, values } from 'lodash';
 
 import 'filepond/dist/filepond.min.css';
 import './index.css';
@@ -43,7 +43,11 @@ export class Uploader extends Component {
       labelIdle:
         'Drag & drop or <span class="filepond--label-action">click to upload orders</span>',
       labelTapToUndo: 'tap to delete',
-      acceptedFileTypes: ['image/*', 'application/pdf'],
+      acceptedFileTypes: get(
+        this.props.document,
+        'policy.allowed_content_types',
+        ['image/*', 'application/pdf'],
+      ),
     });
 
     // Don't mention drag and drop if on mobile device
@@ -70,7 +74,11 @@ export class Uploader extends Component {
           }
         });
       })
-      .catch(error);
+      .catch(err => {
+        // Show why the upload was refused, e.g. the document already has too many files
+        const errors = get(err, 'response.body.errors');
+        error(errors ? values(errors).join(' ') : err.message);
+      });
 
     return { abort };
   };